@@ -0,0 +1,80 @@
+package database
+
+import (
+	"fmt"
+	"yathuerp/models"
+
+	"gorm.io/gorm"
+)
+
+// legacyColumns are the columns added to tables imported from the MySQL
+// schema. Those tables keep their own keys and column types, so they only
+// get these columns added rather than being reshaped by AutoMigrate.
+var legacyColumns = []struct {
+	model  interface{}
+	fields []string
+}{
+	{&models.Setting{}, []string{"LeaveConflictShare", "LeaveEncashmentEarningTypeID"}},
+	{&models.EmployeeGrade{}, []string{"ManagerID"}},
+	{&models.AttendanceCode{}, []string{"Category"}},
+	{&models.Attendance{}, []string{"WorkHours"}},
+	{&models.Holiday{}, []string{"Type", "IsRecurring", "BranchID", "ObservedRule"}},
+	{&models.LeaveType{}, []string{"MaxConsecutiveDays", "DaysPerYear", "AccrueByGrade", "IsCarryForward", "CarryForwardLimit", "IsEncashable"}},
+}
+
+// Migrate creates the tables and columns added on top of the imported MySQL schema
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(
+		&models.LeaveApplicationDay{},
+		&models.LeaveBalance{},
+		&models.LeaveAccrual{},
+		&models.LeaveForfeiture{},
+		&models.LeaveGrantAdjustment{},
+		&models.LeaveEncashment{},
+		&models.LeaveApprovalStep{},
+		&models.LeaveApproval{},
+		&models.LeaveDelegation{},
+		&models.LeaveEvent{},
+		&models.LeaveCalendarFeed{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	if err := migrateLegacyColumns(db); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+func migrateLegacyColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for _, table := range legacyColumns {
+		for _, field := range table.fields {
+			if migrator.HasColumn(table.model, field) {
+				continue
+			}
+			if err := migrator.AddColumn(table.model, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !migrator.HasIndex(&models.Holiday{}, "BranchID") {
+		if err := migrator.CreateIndex(&models.Holiday{}, "BranchID"); err != nil {
+			return err
+		}
+	}
+
+	// days_applied was a whole number of days before half days were allowed
+	columns, err := migrator.ColumnTypes(&models.LeaveApplication{})
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if column.Name() == "days_applied" && column.DatabaseTypeName() != "numeric" {
+			return migrator.AlterColumn(&models.LeaveApplication{}, "DaysApplied")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,518 @@
+package attendance
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAnalyticsDays = 365
+	maxAnalyticsDays     = 730
+)
+
+// analyticsSorts maps the accepted sort keys onto the employee and
+// department figures they rank by
+var analyticsSorts = map[string]struct {
+	employee   func(*employeeAnalytics) float64
+	department func(*departmentAnalytics) float64
+}{
+	"bradford_factor": {
+		func(e *employeeAnalytics) float64 { return float64(e.BradfordFactor) },
+		func(d *departmentAnalytics) float64 { return d.AverageBradfordFactor },
+	},
+	"absenteeism_rate": {
+		func(e *employeeAnalytics) float64 { return e.AbsenteeismRate },
+		func(d *departmentAnalytics) float64 { return d.AbsenteeismRate },
+	},
+	"lateness_rate": {
+		func(e *employeeAnalytics) float64 { return e.LatenessRate },
+		func(d *departmentAnalytics) float64 { return d.LatenessRate },
+	},
+	"average_work_hours": {
+		func(e *employeeAnalytics) float64 { return e.AverageWorkHours },
+		func(d *departmentAnalytics) float64 { return d.AverageWorkHours },
+	},
+	"absent_days": {
+		func(e *employeeAnalytics) float64 { return float64(e.AbsentDays) },
+		func(d *departmentAnalytics) float64 { return float64(d.AbsentDays) },
+	},
+	"late_days": {
+		func(e *employeeAnalytics) float64 { return float64(e.LateDays) },
+		func(d *departmentAnalytics) float64 { return float64(d.LateDays) },
+	},
+}
+
+// employeeAnalytics is one employee's attendance over the window. Scheduled
+// days are the working days marked on the register; rest days and holidays
+// only count towards work hours when worked.
+type employeeAnalytics struct {
+	Rank             int     `json:"rank"`
+	EmployeeID       int     `json:"employee_id"`
+	Name             string  `json:"name"`
+	DepartmentID     *int    `json:"department_id"`
+	Department       string  `json:"department"`
+	ScheduledDays    int     `json:"scheduled_days"`
+	AttendedDays     int     `json:"attended_days"`
+	AbsentDays       int     `json:"absent_days"`
+	LeaveDays        int     `json:"leave_days"`
+	LateDays         int     `json:"late_days"`
+	Spells           int     `json:"spells"` // separate runs of absence
+	AbsenteeismRate  float64 `json:"absenteeism_rate"`
+	LatenessRate     float64 `json:"lateness_rate"`
+	AverageWorkHours float64 `json:"average_work_hours"`
+	BradfordFactor   int     `json:"bradford_factor"`
+
+	hours     float64
+	hoursDays int
+}
+
+type departmentAnalytics struct {
+	Rank                  int     `json:"rank"`
+	DepartmentID          *int    `json:"department_id"`
+	Department            string  `json:"department"`
+	Employees             int     `json:"employees"`
+	ScheduledDays         int     `json:"scheduled_days"`
+	AttendedDays          int     `json:"attended_days"`
+	AbsentDays            int     `json:"absent_days"`
+	LateDays              int     `json:"late_days"`
+	AbsenteeismRate       float64 `json:"absenteeism_rate"`
+	LatenessRate          float64 `json:"lateness_rate"`
+	AverageWorkHours      float64 `json:"average_work_hours"`
+	AverageBradfordFactor float64 `json:"average_bradford_factor"`
+	HighestBradfordFactor int     `json:"highest_bradford_factor"`
+
+	hours     float64
+	hoursDays int
+}
+
+type attendanceAnalytics struct {
+	StartDate   string                 `json:"start_date"`
+	EndDate     string                 `json:"end_date"`
+	Days        int                    `json:"days"`
+	Sort        string                 `json:"sort"`
+	Employees   []*employeeAnalytics   `json:"employees"`
+	Departments []*departmentAnalytics `json:"departments"`
+}
+
+// GetAttendanceAnalytics reports absenteeism, lateness, average work hours and
+// the Bradford factor per employee and department over a rolling window
+// ending on end_date, ranked by the chosen figure.
+func (h *Handler) GetAttendanceAnalytics(c *fiber.Ctx) error {
+	report, err := h.attendanceAnalytics(c)
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{"error": err.Message})
+	}
+
+	if limit, _ := strconv.Atoi(c.Query("limit")); limit > 0 && limit < len(report.Employees) {
+		report.Employees = report.Employees[:limit]
+	}
+
+	return c.JSON(report)
+}
+
+// ExportAttendanceAnalytics downloads the ranked analytics as CSV, one row per
+// employee or, with group=department, per department
+func (h *Handler) ExportAttendanceAnalytics(c *fiber.Ctx) error {
+	report, err := h.attendanceAnalytics(c)
+	if err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{"error": err.Message})
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	group := c.Query("group", "employee")
+	switch group {
+	case "employee":
+		writer.Write([]string{
+			"rank", "employee_id", "name", "department", "scheduled_days", "attended_days",
+			"absent_days", "leave_days", "late_days", "spells", "absenteeism_rate",
+			"lateness_rate", "average_work_hours", "bradford_factor",
+		})
+		for _, e := range report.Employees {
+			writer.Write([]string{
+				strconv.Itoa(e.Rank), strconv.Itoa(e.EmployeeID), e.Name, e.Department,
+				strconv.Itoa(e.ScheduledDays), strconv.Itoa(e.AttendedDays), strconv.Itoa(e.AbsentDays),
+				strconv.Itoa(e.LeaveDays), strconv.Itoa(e.LateDays), strconv.Itoa(e.Spells),
+				formatFigure(e.AbsenteeismRate), formatFigure(e.LatenessRate),
+				formatFigure(e.AverageWorkHours), strconv.Itoa(e.BradfordFactor),
+			})
+		}
+	case "department":
+		writer.Write([]string{
+			"rank", "department_id", "department", "employees", "scheduled_days", "attended_days",
+			"absent_days", "late_days", "absenteeism_rate", "lateness_rate", "average_work_hours",
+			"average_bradford_factor", "highest_bradford_factor",
+		})
+		for _, d := range report.Departments {
+			departmentID := ""
+			if d.DepartmentID != nil {
+				departmentID = strconv.Itoa(*d.DepartmentID)
+			}
+			writer.Write([]string{
+				strconv.Itoa(d.Rank), departmentID, d.Department, strconv.Itoa(d.Employees),
+				strconv.Itoa(d.ScheduledDays), strconv.Itoa(d.AttendedDays), strconv.Itoa(d.AbsentDays),
+				strconv.Itoa(d.LateDays), formatFigure(d.AbsenteeismRate), formatFigure(d.LatenessRate),
+				formatFigure(d.AverageWorkHours), formatFigure(d.AverageBradfordFactor),
+				strconv.Itoa(d.HighestBradfordFactor),
+			})
+		}
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid group, expected employee or department"})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to write attendance analytics"})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(
+		`attachment; filename="attendance-analytics-%s-%s.csv"`, group, report.EndDate))
+	return c.Send(buf.Bytes())
+}
+
+// attendanceAnalytics reads the window, filters and sort from the query and
+// computes the report
+func (h *Handler) attendanceAnalytics(c *fiber.Ctx) (*attendanceAnalytics, *fiber.Error) {
+	end := time.Now()
+	if c.Query("end_date") != "" {
+		date, err := time.Parse(dateLayout, c.Query("end_date"))
+		if err != nil {
+			return nil, fiber.NewError(400, "Invalid end_date, expected YYYY-MM-DD")
+		}
+		end = date
+	}
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	days := defaultAnalyticsDays
+	if c.Query("days") != "" {
+		value, err := strconv.Atoi(c.Query("days"))
+		if err != nil || value < 1 || value > maxAnalyticsDays {
+			return nil, fiber.NewError(400, fmt.Sprintf("Invalid days, expected 1 to %d", maxAnalyticsDays))
+		}
+		days = value
+	}
+	start := end.AddDate(0, 0, 1-days)
+
+	sortBy := c.Query("sort", "bradford_factor")
+	if _, ok := analyticsSorts[sortBy]; !ok {
+		return nil, fiber.NewError(400, "Invalid sort")
+	}
+
+	grades := h.db.Model(&models.EmployeeGrade{}).Where("is_current = ? AND deleted = ?", 1, 0)
+	if c.Query("department_id") != "" {
+		grades = grades.Where("department_id = ?", c.Query("department_id"))
+	}
+	if c.Query("branch_id") != "" {
+		grades = grades.Where("branch_id = ?", c.Query("branch_id"))
+	}
+	var employeeGrades []models.EmployeeGrade
+	if err := grades.Order("start_date DESC").Find(&employeeGrades).Error; err != nil {
+		return nil, fiber.NewError(500, "Failed to fetch employees")
+	}
+
+	employees, dbErr := analyseAttendance(h.db, employeeGrades, start, end)
+	if dbErr != nil {
+		return nil, fiber.NewError(500, "Failed to compute attendance analytics")
+	}
+	departments := departmentTotals(employees)
+
+	rankBy := analyticsSorts[sortBy]
+	descending := c.Query("order", "desc") != "asc"
+	sort.SliceStable(employees, func(i, j int) bool {
+		a, b := rankBy.employee(employees[i]), rankBy.employee(employees[j])
+		if a != b {
+			return (a > b) == descending
+		}
+		return employees[i].Name < employees[j].Name
+	})
+	for i := range employees {
+		employees[i].Rank = i + 1
+	}
+	sort.SliceStable(departments, func(i, j int) bool {
+		a, b := rankBy.department(departments[i]), rankBy.department(departments[j])
+		if a != b {
+			return (a > b) == descending
+		}
+		return departments[i].Department < departments[j].Department
+	})
+	for i := range departments {
+		departments[i].Rank = i + 1
+	}
+
+	return &attendanceAnalytics{
+		StartDate:   start.Format(dateLayout),
+		EndDate:     end.Format(dateLayout),
+		Days:        days,
+		Sort:        sortBy,
+		Employees:   employees,
+		Departments: departments,
+	}, nil
+}
+
+// analyseAttendance computes each employee's figures from their register
+// between start and end. grades must be ordered newest first.
+func analyseAttendance(db *gorm.DB, grades []models.EmployeeGrade, start, end time.Time) ([]*employeeAnalytics, error) {
+	byEmployee := map[int]*employeeAnalytics{}
+	var employeeIDs []int
+	departmentIDs := map[int]bool{}
+	for _, grade := range grades {
+		if grade.EmployeeID == nil || byEmployee[*grade.EmployeeID] != nil {
+			continue
+		}
+		byEmployee[*grade.EmployeeID] = &employeeAnalytics{EmployeeID: *grade.EmployeeID, DepartmentID: grade.DepartmentID}
+		employeeIDs = append(employeeIDs, *grade.EmployeeID)
+		if grade.DepartmentID != nil {
+			departmentIDs[*grade.DepartmentID] = true
+		}
+	}
+	employees := make([]*employeeAnalytics, 0, len(employeeIDs))
+	if len(employeeIDs) == 0 {
+		return employees, nil
+	}
+
+	var people []registerEmployee
+	if err := db.Model(&models.Employee{}).
+		Select("id, first_name, middle_name, last_name").
+		Where("id IN ?", employeeIDs).
+		Scan(&people).Error; err != nil {
+		return nil, err
+	}
+	for _, person := range people {
+		if employee, ok := byEmployee[person.ID]; ok {
+			employee.Name = strings.Join(strings.Fields(
+				person.FirstName+" "+person.MiddleName+" "+person.LastName), " ")
+		}
+	}
+
+	names, err := departmentNames(db, departmentIDs)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := codeCategories(db)
+	if err != nil {
+		return nil, err
+	}
+	hoursByShift, err := shiftHours(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var attendances []models.Attendance
+	if err := db.Where("employee_id IN ? AND attendance_date BETWEEN ? AND ? AND deleted = ?",
+		employeeIDs, start, end, 0).
+		Order("employee_id, attendance_date, created_at").
+		Find(&attendances).Error; err != nil {
+		return nil, err
+	}
+
+	// a spell of absence runs until the employee next attends or takes leave;
+	// rest days and holidays in between do not end it
+	inSpell := map[int]bool{}
+	seen := map[string]bool{}
+	for _, attendance := range attendances {
+		if attendance.EmployeeID == nil || attendance.AttendanceDate == nil || attendance.AttendanceCodeID == nil {
+			continue
+		}
+		key := registerKey(*attendance.EmployeeID, *attendance.AttendanceDate)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		employee := byEmployee[*attendance.EmployeeID]
+		category, ok := categories[*attendance.AttendanceCodeID]
+		if !ok || category == models.AttendanceCategoryOff {
+			continue
+		}
+		scheduled := attendance.IsWeekend == 0 && attendance.IsHoliday == 0
+
+		switch category {
+		case models.AttendanceCategoryAbsent:
+			if !scheduled {
+				continue
+			}
+			employee.ScheduledDays++
+			employee.AbsentDays++
+			if !inSpell[employee.EmployeeID] {
+				employee.Spells++
+				inSpell[employee.EmployeeID] = true
+			}
+		case models.AttendanceCategoryLeave:
+			if scheduled {
+				employee.ScheduledDays++
+				employee.LeaveDays++
+			}
+			inSpell[employee.EmployeeID] = false
+		case models.AttendanceCategoryPresent, models.AttendanceCategoryLate:
+			if scheduled {
+				employee.ScheduledDays++
+			}
+			employee.AttendedDays++
+			if category == models.AttendanceCategoryLate {
+				employee.LateDays++
+			}
+			inSpell[employee.EmployeeID] = false
+
+			hours := 0.0
+			if attendance.WorkHours != nil {
+				hours = *attendance.WorkHours
+			} else if attendance.ShiftID != nil {
+				hours = hoursByShift[*attendance.ShiftID]
+			}
+			if hours > 0 {
+				employee.hours += hours
+				employee.hoursDays++
+			}
+		}
+	}
+
+	for _, employeeID := range employeeIDs {
+		employee := byEmployee[employeeID]
+		if employee.DepartmentID != nil {
+			employee.Department = names[*employee.DepartmentID]
+		}
+		employee.AbsenteeismRate = percentage(employee.AbsentDays, employee.ScheduledDays)
+		employee.LatenessRate = percentage(employee.LateDays, employee.AttendedDays)
+		if employee.hoursDays > 0 {
+			employee.AverageWorkHours = roundAmount(employee.hours / float64(employee.hoursDays))
+		}
+		employee.BradfordFactor = employee.Spells * employee.Spells * employee.AbsentDays
+		employees = append(employees, employee)
+	}
+	return employees, nil
+}
+
+// departmentTotals rolls employee figures up to their departments
+func departmentTotals(employees []*employeeAnalytics) []*departmentAnalytics {
+	byDepartment := map[int]*departmentAnalytics{}
+	var unassigned *departmentAnalytics
+	var departments []*departmentAnalytics
+	bradford := map[*departmentAnalytics]int{}
+
+	for _, employee := range employees {
+		var department *departmentAnalytics
+		if employee.DepartmentID == nil {
+			if unassigned == nil {
+				unassigned = &departmentAnalytics{Department: "Unassigned"}
+				departments = append(departments, unassigned)
+			}
+			department = unassigned
+		} else if department = byDepartment[*employee.DepartmentID]; department == nil {
+			department = &departmentAnalytics{DepartmentID: employee.DepartmentID, Department: employee.Department}
+			byDepartment[*employee.DepartmentID] = department
+			departments = append(departments, department)
+		}
+
+		department.Employees++
+		department.ScheduledDays += employee.ScheduledDays
+		department.AttendedDays += employee.AttendedDays
+		department.AbsentDays += employee.AbsentDays
+		department.LateDays += employee.LateDays
+		department.hours += employee.hours
+		department.hoursDays += employee.hoursDays
+		bradford[department] += employee.BradfordFactor
+		if employee.BradfordFactor > department.HighestBradfordFactor {
+			department.HighestBradfordFactor = employee.BradfordFactor
+		}
+	}
+
+	for _, department := range departments {
+		department.AbsenteeismRate = percentage(department.AbsentDays, department.ScheduledDays)
+		department.LatenessRate = percentage(department.LateDays, department.AttendedDays)
+		if department.hoursDays > 0 {
+			department.AverageWorkHours = roundAmount(department.hours / float64(department.hoursDays))
+		}
+		department.AverageBradfordFactor = roundAmount(float64(bradford[department]) / float64(department.Employees))
+	}
+	return departments
+}
+
+func departmentNames(db *gorm.DB, ids map[int]bool) (map[int]string, error) {
+	names := map[int]string{}
+	if len(ids) == 0 {
+		return names, nil
+	}
+	departmentIDs := make([]int, 0, len(ids))
+	for id := range ids {
+		departmentIDs = append(departmentIDs, id)
+	}
+
+	var departments []struct {
+		ID   int
+		Name string
+	}
+	if err := db.Model(&models.Department{}).
+		Select("id, name").
+		Where("id IN ?", departmentIDs).
+		Scan(&departments).Error; err != nil {
+		return nil, err
+	}
+	for _, department := range departments {
+		names[department.ID] = department.Name
+	}
+	return names, nil
+}
+
+// codeCategories maps attendance code IDs to their category
+func codeCategories(db *gorm.DB) (map[int]string, error) {
+	codes, err := attendanceCodes(db)
+	if err != nil {
+		return nil, err
+	}
+	categories := make(map[int]string, len(codes))
+	for _, code := range codes {
+		categories[code.ID] = code.Category
+	}
+	return categories, nil
+}
+
+// shiftHours is each shift's span, used for attended days without recorded
+// work hours. Shifts that end at or before their start cross midnight.
+func shiftHours(db *gorm.DB) (map[int]float64, error) {
+	var shifts []struct {
+		ID        int
+		StartTime *time.Time
+		EndTime   *time.Time
+	}
+	if err := db.Model(&models.Shift{}).
+		Select("id, start_time, end_time").
+		Where("deleted = ?", 0).
+		Scan(&shifts).Error; err != nil {
+		return nil, err
+	}
+
+	hours := make(map[int]float64, len(shifts))
+	for _, shift := range shifts {
+		if shift.StartTime == nil || shift.EndTime == nil {
+			continue
+		}
+		start := shift.StartTime.Hour()*60 + shift.StartTime.Minute()
+		end := shift.EndTime.Hour()*60 + shift.EndTime.Minute()
+		if end <= start {
+			end += 24 * 60
+		}
+		hours[shift.ID] = float64(end-start) / 60
+	}
+	return hours, nil
+}
+
+func percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 100
+}
+
+func formatFigure(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
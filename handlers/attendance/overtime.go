@@ -0,0 +1,228 @@
+package attendance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// errNoOvertimeRate marks employees whose pay cannot be turned into an
+	// hourly rate; their overtime is skipped rather than failing the whole run
+	errNoOvertimeRate = errors.New("no hourly rate")
+
+	// errOvertimePosted is returned when another run posted some of the
+	// exceptions first
+	errOvertimePosted = errors.New("approved overtime was posted by another run")
+)
+
+// approvedOvertime is an overtime exception approved in the attendance
+// service and not yet posted to payroll. The service keys employees by uuid,
+// so they are matched to tbl_employees on email through the shared employees
+// table; EmployeeID is nil when no match was found.
+type approvedOvertime struct {
+	ID              string
+	ServiceEmployee string
+	EmployeeID      *int
+	AttendanceDate  time.Time
+	ApprovedHours   float64
+}
+
+type overtimePostRequest struct {
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+	OvertimeTypeID int    `json:"overtime_type_id"`
+	PayrollID      *int   `json:"payroll_id"`
+}
+
+// skippedOvertime is an approved exception left unposted, with the reason
+type skippedOvertime struct {
+	ExceptionID string `json:"exception_id"`
+	EmployeeID  string `json:"employee_id"`
+	Date        string `json:"date"`
+	Reason      string `json:"reason"`
+}
+
+// PostApprovedOvertime turns the overtime approved in the attendance service
+// between two dates into one overtime line per employee for payroll. Only
+// approved hours are paid, at the employee's hourly rate times the overtime
+// type's rate, and each exception is posted once: the exceptions are locked
+// while they are posted, so concurrent runs cannot pay them twice.
+func (h *Handler) PostApprovedOvertime(c *fiber.Ctx) error {
+	var req overtimePostRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid start_date, expected YYYY-MM-DD"})
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid end_date, expected YYYY-MM-DD"})
+	}
+	if end.Before(start) {
+		return c.Status(400).JSON(fiber.Map{"error": "end_date cannot be before start_date"})
+	}
+
+	var overtimeType models.OvertimeType
+	if err := h.db.Where("deleted = ?", 0).First(&overtimeType, req.OvertimeTypeID).Error; err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Overtime type not found"})
+	}
+	if req.PayrollID != nil {
+		var payroll models.Payroll
+		if err := h.db.Where("deleted = ?", 0).First(&payroll, *req.PayrollID).Error; err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Payroll not found"})
+		}
+	}
+
+	var overtimes []models.Overtime
+	skipped := []skippedOvertime{}
+	posted := 0
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var approved []approvedOvertime
+		if err := tx.Table("attendance_exceptions x").
+			Select("x.id, x.employee_id AS service_employee, te.id AS employee_id, x.attendance_date, x.approved_hours").
+			Joins("LEFT JOIN employees e ON e.id = x.employee_id").
+			Joins("LEFT JOIN tbl_employees te ON LOWER(te.email) = LOWER(e.email) AND e.email <> '' AND te.deleted = 0").
+			Where("x.type = ? AND x.status = ? AND x.overtime_id IS NULL AND x.deleted = ?", "overtime", "approved", false).
+			Where("x.attendance_date BETWEEN ? AND ?", start, end).
+			Order("x.attendance_date").
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "x"}}).
+			Scan(&approved).Error; err != nil {
+			return err
+		}
+
+		byEmployee := map[int][]approvedOvertime{}
+		for _, item := range approved {
+			if item.EmployeeID == nil {
+				skipped = append(skipped, skippedOvertime{
+					ExceptionID: item.ID,
+					EmployeeID:  item.ServiceEmployee,
+					Date:        item.AttendanceDate.Format(dateLayout),
+					Reason:      "no employee with a matching email",
+				})
+				continue
+			}
+			byEmployee[*item.EmployeeID] = append(byEmployee[*item.EmployeeID], item)
+		}
+
+		employeeIDs := make([]int, 0, len(byEmployee))
+		for employeeID := range byEmployee {
+			employeeIDs = append(employeeIDs, employeeID)
+		}
+		sort.Ints(employeeIDs)
+
+		for _, employeeID := range employeeIDs {
+			items := byEmployee[employeeID]
+			dailyRate, hourlyRate, err := overtimeRates(tx, employeeID)
+			if err != nil && !errors.Is(err, errNoOvertimeRate) {
+				return err
+			}
+			if err != nil {
+				for _, item := range items {
+					skipped = append(skipped, skippedOvertime{
+						ExceptionID: item.ID,
+						EmployeeID:  item.ServiceEmployee,
+						Date:        item.AttendanceDate.Format(dateLayout),
+						Reason:      err.Error(),
+					})
+				}
+				continue
+			}
+
+			hours := 0.0
+			days := map[string]bool{}
+			ids := make([]string, 0, len(items))
+			for _, item := range items {
+				hours += item.ApprovedHours
+				days[item.AttendanceDate.Format(dateLayout)] = true
+				ids = append(ids, item.ID)
+			}
+
+			overtimeTypeID := req.OvertimeTypeID
+			overtime := models.Overtime{
+				EmployeeID:     employeeID,
+				PayrollID:      req.PayrollID,
+				OvertimeTypeID: &overtimeTypeID,
+				HourlyRate:     roundAmount(hourlyRate),
+				DailyRate:      roundAmount(dailyRate),
+				Rate:           overtimeType.Rate,
+				Hours:          int(math.Round(hours)),
+				Days:           float64(len(days)),
+				Amount:         roundAmount(hours * hourlyRate * overtimeType.Rate),
+			}
+			if err := tx.Create(&overtime).Error; err != nil {
+				return err
+			}
+			result := tx.Table("attendance_exceptions").
+				Where("id IN ? AND overtime_id IS NULL", ids).
+				Updates(map[string]interface{}{
+					"overtime_id": overtime.ID,
+					"posted_at":   time.Now(),
+					"updated_at":  time.Now(),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected != int64(len(ids)) {
+				return errOvertimePosted
+			}
+
+			overtimes = append(overtimes, overtime)
+			posted += len(items)
+		}
+		return nil
+	})
+	if errors.Is(err, errOvertimePosted) {
+		return c.Status(409).JSON(fiber.Map{"error": "Approved overtime was posted by another run, try again"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to post approved overtime"})
+	}
+
+	return c.JSON(fiber.Map{
+		"data":    overtimes,
+		"posted":  posted,
+		"skipped": skipped,
+	})
+}
+
+// overtimeRates derives the employee's daily rate from their latest basic
+// salary and the days per month of their staff type, and the hourly rate from
+// its hours per day
+func overtimeRates(tx *gorm.DB, employeeID int) (float64, float64, error) {
+	var employeeGrade models.EmployeeGrade
+	if err := tx.Where("employee_id = ? AND deleted = ?", employeeID, 0).
+		Order("is_current DESC, start_date DESC").
+		Limit(1).
+		Find(&employeeGrade).Error; err != nil {
+		return 0, 0, err
+	}
+	if employeeGrade.BasicSalary == nil || employeeGrade.StaffTypeID == nil {
+		return 0, 0, fmt.Errorf("%w: employee %d has no basic salary or staff type", errNoOvertimeRate, employeeID)
+	}
+
+	var staffType models.StaffType
+	if err := tx.First(&staffType, *employeeGrade.StaffTypeID).Error; err != nil {
+		return 0, 0, err
+	}
+	if staffType.DaysPerMonth == nil || *staffType.DaysPerMonth <= 0 ||
+		staffType.HoursPerDay == nil || *staffType.HoursPerDay <= 0 {
+		return 0, 0, fmt.Errorf("%w: staff type %q has no days per month or hours per day", errNoOvertimeRate, staffType.Name)
+	}
+
+	dailyRate := *employeeGrade.BasicSalary / float64(*staffType.DaysPerMonth)
+	return dailyRate, dailyRate / float64(*staffType.HoursPerDay), nil
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,499 @@
+package attendance
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"yathuerp/handlers/holidays"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	dateLayout  = "2006-01-02"
+	monthLayout = "2006-01"
+
+	defaultWorkingDaysPerWeek = 5
+)
+
+// registerEmployee is the part of tbl_employees shown on the register
+type registerEmployee struct {
+	ID         int
+	FirstName  string
+	MiddleName string
+	LastName   string
+}
+
+// registerCode is an attendance code as offered in the register grid
+type registerCode struct {
+	ID          int    `json:"id"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	IsDebit     int    `json:"is_debit"`
+	Category    string `json:"category"`
+}
+
+// registerDate describes one day of the month, the grid's column header
+type registerDate struct {
+	Date      string `json:"date"`
+	Day       string `json:"day"`
+	IsHoliday int    `json:"is_holiday"`
+	Holiday   string `json:"holiday,omitempty"`
+}
+
+// registerCell is one employee's attendance on one day
+type registerCell struct {
+	Date              string   `json:"date"`
+	AttendanceCodeID  *int     `json:"attendance_code_id"`
+	Code              string   `json:"code"`
+	AttendanceComment string   `json:"attendance_comment"`
+	ShiftID           *int     `json:"shift_id"`
+	WorkHours         *float64 `json:"work_hours"`
+	IsWeekend         int      `json:"is_weekend"`
+	IsHoliday         int      `json:"is_holiday"`
+}
+
+type registerRow struct {
+	EmployeeID int            `json:"employee_id"`
+	Name       string         `json:"name"`
+	Days       []registerCell `json:"days"`
+}
+
+// registerEntry sets one cell of the grid. The code is given either by ID or
+// by its short code; an entry with neither clears the day.
+type registerEntry struct {
+	EmployeeID        int      `json:"employee_id"`
+	Date              string   `json:"date"`
+	AttendanceCodeID  *int     `json:"attendance_code_id"`
+	Code              string   `json:"code"`
+	AttendanceComment string   `json:"attendance_comment"`
+	ShiftID           *int     `json:"shift_id"`
+	WorkHours         *float64 `json:"work_hours"`
+}
+
+type registerUpdateRequest struct {
+	Month   string          `json:"month"`
+	Entries []registerEntry `json:"entries"`
+}
+
+// GetAttendanceRegister returns the month's attendance as an employees by
+// days grid, with weekends and holidays pre-filled so HR only marks the days
+// that need a code. Employees are those on a current grade, optionally
+// limited to a department or branch.
+func (h *Handler) GetAttendanceRegister(c *fiber.Ctx) error {
+	start, err := time.Parse(monthLayout, c.Query("month"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid month, expected YYYY-MM"})
+	}
+	end := start.AddDate(0, 1, -1)
+
+	grades := h.db.Model(&models.EmployeeGrade{}).Where("is_current = ? AND deleted = ?", 1, 0)
+	if c.Query("department_id") != "" {
+		grades = grades.Where("department_id = ?", c.Query("department_id"))
+	}
+	if c.Query("branch_id") != "" {
+		grades = grades.Where("branch_id = ?", c.Query("branch_id"))
+	}
+	var employeeGrades []models.EmployeeGrade
+	if err := grades.Order("start_date DESC").Find(&employeeGrades).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch employees"})
+	}
+
+	staffTypes := map[int]bool{}
+	var employeeIDs []int
+	seen := map[int]bool{}
+	for _, grade := range employeeGrades {
+		if grade.EmployeeID == nil || seen[*grade.EmployeeID] {
+			continue
+		}
+		seen[*grade.EmployeeID] = true
+		employeeIDs = append(employeeIDs, *grade.EmployeeID)
+		if grade.StaffTypeID != nil {
+			staffTypes[*grade.StaffTypeID] = true
+		}
+	}
+
+	restDays, err := employeeRestDays(h.db, employeeGrades, staffTypes)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch staff types"})
+	}
+
+	calendar, err := holidays.Load(h.db, start, end)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch holidays"})
+	}
+	branches := employeeBranches(employeeGrades)
+
+	// column headers show the filtered branch's holidays, or those every
+	// branch observes; each cell is flagged for its employee's branch
+	var columnBranch *int
+	if c.Query("branch_id") != "" {
+		branchID, err := strconv.Atoi(c.Query("branch_id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid branch_id"})
+		}
+		columnBranch = &branchID
+	}
+
+	codes, err := attendanceCodes(h.db)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch attendance codes"})
+	}
+	codeNames := make(map[int]string, len(codes))
+	for _, code := range codes {
+		codeNames[code.ID] = code.Code
+	}
+
+	names := map[int]string{}
+	marked := map[string]models.Attendance{}
+	if len(employeeIDs) > 0 {
+		var employees []registerEmployee
+		if err := h.db.Model(&models.Employee{}).
+			Select("id, first_name, middle_name, last_name").
+			Where("id IN ?", employeeIDs).
+			Scan(&employees).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch employees"})
+		}
+		for _, employee := range employees {
+			names[employee.ID] = strings.Join(strings.Fields(
+				employee.FirstName+" "+employee.MiddleName+" "+employee.LastName), " ")
+		}
+
+		var attendances []models.Attendance
+		if err := h.db.Where("employee_id IN ? AND attendance_date BETWEEN ? AND ? AND deleted = ?",
+			employeeIDs, start, end, 0).
+			Order("created_at").
+			Find(&attendances).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch attendance"})
+		}
+		for _, attendance := range attendances {
+			if attendance.EmployeeID == nil || attendance.AttendanceDate == nil {
+				continue
+			}
+			key := registerKey(*attendance.EmployeeID, *attendance.AttendanceDate)
+			if _, ok := marked[key]; !ok {
+				marked[key] = attendance
+			}
+		}
+	}
+
+	var dates []registerDate
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		key := date.Format(dateLayout)
+		column := registerDate{Date: key, Day: date.Weekday().String()}
+		if holiday := calendar.On(date, columnBranch); holiday != nil {
+			column.IsHoliday = 1
+			column.Holiday = holiday.Title
+		}
+		dates = append(dates, column)
+	}
+
+	sort.Slice(employeeIDs, func(i, j int) bool {
+		if names[employeeIDs[i]] != names[employeeIDs[j]] {
+			return names[employeeIDs[i]] < names[employeeIDs[j]]
+		}
+		return employeeIDs[i] < employeeIDs[j]
+	})
+
+	rows := make([]registerRow, 0, len(employeeIDs))
+	for _, employeeID := range employeeIDs {
+		row := registerRow{EmployeeID: employeeID, Name: names[employeeID]}
+		for i, date := 0, start; !date.After(end); i, date = i+1, date.AddDate(0, 0, 1) {
+			cell := registerCell{Date: dates[i].Date}
+			if calendar.On(date, branches[employeeID]) != nil {
+				cell.IsHoliday = 1
+			}
+			if restDays[employeeID][date.Weekday()] {
+				cell.IsWeekend = 1
+			}
+			if attendance, ok := marked[registerKey(employeeID, date)]; ok {
+				cell.AttendanceCodeID = attendance.AttendanceCodeID
+				cell.AttendanceComment = attendance.AttendanceComment
+				cell.ShiftID = attendance.ShiftID
+				cell.WorkHours = attendance.WorkHours
+				if attendance.AttendanceCodeID != nil {
+					cell.Code = codeNames[*attendance.AttendanceCodeID]
+				}
+			}
+			row.Days = append(row.Days, cell)
+		}
+		rows = append(rows, row)
+	}
+
+	return c.JSON(fiber.Map{
+		"month":     start.Format(monthLayout),
+		"dates":     dates,
+		"codes":     codes,
+		"employees": rows,
+	})
+}
+
+// UpdateAttendanceRegister saves a marked register grid. Every entry is
+// checked before anything is written, and the whole grid is upserted in one
+// transaction so a rejected cell leaves the month as it was.
+func (h *Handler) UpdateAttendanceRegister(c *fiber.Ctx) error {
+	var req registerUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	start, err := time.Parse(monthLayout, req.Month)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid month, expected YYYY-MM"})
+	}
+	end := start.AddDate(0, 1, -1)
+	if len(req.Entries) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "entries are required"})
+	}
+
+	codes, err := attendanceCodes(h.db)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch attendance codes"})
+	}
+	codeIDs := make(map[string]int, len(codes))
+	knownCodes := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		codeIDs[strings.ToUpper(code.Code)] = code.ID
+		knownCodes[code.ID] = true
+	}
+
+	calendar, err := holidays.Load(h.db, start, end)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch holidays"})
+	}
+
+	dates := make([]time.Time, len(req.Entries))
+	cells := map[string]int{}
+	employees := map[int]bool{}
+	for i := range req.Entries {
+		entry := &req.Entries[i]
+		if entry.EmployeeID <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("entry %d: employee_id is required", i+1)})
+		}
+		date, err := time.Parse(dateLayout, entry.Date)
+		if err != nil || date.Before(start) || date.After(end) {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("entry %d: date %q is not in %s", i+1, entry.Date, req.Month)})
+		}
+		key := registerKey(entry.EmployeeID, date)
+		if previous, ok := cells[key]; ok {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("entry %d: duplicates entry %d", i+1, previous+1)})
+		}
+		cells[key] = i
+
+		if code := strings.ToUpper(strings.TrimSpace(entry.Code)); code != "" && entry.AttendanceCodeID == nil {
+			id, ok := codeIDs[code]
+			if !ok {
+				return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("entry %d: unknown attendance code %q", i+1, entry.Code)})
+			}
+			entry.AttendanceCodeID = &id
+		}
+		if entry.AttendanceCodeID != nil && !knownCodes[*entry.AttendanceCodeID] {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("entry %d: unknown attendance code %d", i+1, *entry.AttendanceCodeID)})
+		}
+
+		if entry.WorkHours != nil && (*entry.WorkHours < 0 || *entry.WorkHours > 24) {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("entry %d: work_hours must be between 0 and 24", i+1)})
+		}
+
+		dates[i] = date
+		employees[entry.EmployeeID] = true
+	}
+
+	employeeIDs := make([]int, 0, len(employees))
+	for employeeID := range employees {
+		employeeIDs = append(employeeIDs, employeeID)
+	}
+
+	var employeeGrades []models.EmployeeGrade
+	if err := h.db.Where("employee_id IN ? AND is_current = ? AND deleted = ?", employeeIDs, 1, 0).
+		Order("start_date DESC").
+		Find(&employeeGrades).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch employees"})
+	}
+	staffTypes := map[int]bool{}
+	for _, grade := range employeeGrades {
+		if grade.StaffTypeID != nil {
+			staffTypes[*grade.StaffTypeID] = true
+		}
+	}
+	restDays, err := employeeRestDays(h.db, employeeGrades, staffTypes)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch staff types"})
+	}
+	branches := employeeBranches(employeeGrades)
+
+	created, updated, cleared := 0, 0, 0
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.Attendance
+		if err := tx.Where("employee_id IN ? AND attendance_date BETWEEN ? AND ? AND deleted = ?",
+			employeeIDs, start, end, 0).
+			Order("created_at").
+			Find(&existing).Error; err != nil {
+			return err
+		}
+
+		// the register keeps one record per employee and day; duplicates left
+		// by earlier imports are removed when their day is saved
+		current := map[string]models.Attendance{}
+		var duplicates []models.Attendance
+		for _, attendance := range existing {
+			if attendance.EmployeeID == nil || attendance.AttendanceDate == nil {
+				continue
+			}
+			key := registerKey(*attendance.EmployeeID, *attendance.AttendanceDate)
+			if _, ok := cells[key]; !ok {
+				continue
+			}
+			if _, ok := current[key]; ok {
+				duplicates = append(duplicates, attendance)
+				continue
+			}
+			current[key] = attendance
+		}
+		for i := range duplicates {
+			if err := tx.Delete(&duplicates[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		for i, entry := range req.Entries {
+			date := dates[i]
+			key := registerKey(entry.EmployeeID, date)
+			attendance, exists := current[key]
+
+			if entry.AttendanceCodeID == nil && strings.TrimSpace(entry.AttendanceComment) == "" {
+				if exists {
+					if err := tx.Delete(&attendance).Error; err != nil {
+						return err
+					}
+					cleared++
+				}
+				continue
+			}
+
+			employeeID := entry.EmployeeID
+			attendance.EmployeeID = &employeeID
+			attendance.AttendanceDate = &date
+			attendance.AttendanceDay = date.Weekday().String()
+			attendance.AttendanceCodeID = entry.AttendanceCodeID
+			attendance.AttendanceComment = entry.AttendanceComment
+			attendance.ShiftID = entry.ShiftID
+			attendance.WorkHours = entry.WorkHours
+			attendance.IsWeekend = 0
+			if restDays[employeeID][date.Weekday()] {
+				attendance.IsWeekend = 1
+			}
+			attendance.IsHoliday = 0
+			if calendar.On(date, branches[employeeID]) != nil {
+				attendance.IsHoliday = 1
+			}
+
+			if exists {
+				if err := tx.Save(&attendance).Error; err != nil {
+					return err
+				}
+				updated++
+				continue
+			}
+			if err := tx.Create(&attendance).Error; err != nil {
+				return err
+			}
+			created++
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save attendance register"})
+	}
+
+	return c.JSON(fiber.Map{
+		"month":   start.Format(monthLayout),
+		"created": created,
+		"updated": updated,
+		"cleared": cleared,
+	})
+}
+
+// employeeRestDays derives each employee's non-working weekdays from the
+// StaffType on their current grade: 5 working days is Monday to Friday, 6
+// adds Saturday and 7 is every day. grades must be ordered newest first.
+func employeeRestDays(db *gorm.DB, grades []models.EmployeeGrade, staffTypeIDs map[int]bool) (map[int]map[time.Weekday]bool, error) {
+	daysPerWeek := map[int]int{}
+	if len(staffTypeIDs) > 0 {
+		ids := make([]int, 0, len(staffTypeIDs))
+		for id := range staffTypeIDs {
+			ids = append(ids, id)
+		}
+		var staffTypes []struct {
+			ID   int
+			Days *int
+		}
+		if err := db.Model(&models.StaffType{}).Select("id, days").Where("id IN ?", ids).
+			Scan(&staffTypes).Error; err != nil {
+			return nil, err
+		}
+		for _, staffType := range staffTypes {
+			if staffType.Days != nil {
+				daysPerWeek[staffType.ID] = *staffType.Days
+			}
+		}
+	}
+
+	restDays := map[int]map[time.Weekday]bool{}
+	for _, grade := range grades {
+		if grade.EmployeeID == nil {
+			continue
+		}
+		if _, ok := restDays[*grade.EmployeeID]; ok {
+			continue
+		}
+		working := defaultWorkingDaysPerWeek
+		if grade.StaffTypeID != nil {
+			if days, ok := daysPerWeek[*grade.StaffTypeID]; ok {
+				working = days
+			}
+		}
+		rest := map[time.Weekday]bool{}
+		for i := working; i < 7; i++ {
+			rest[time.Weekday((int(time.Monday)+i)%7)] = true
+		}
+		restDays[*grade.EmployeeID] = rest
+	}
+	return restDays, nil
+}
+
+// employeeBranches returns the branch on each employee's current grade.
+// grades must be ordered newest first.
+func employeeBranches(grades []models.EmployeeGrade) map[int]*int {
+	branches := map[int]*int{}
+	for _, grade := range grades {
+		if grade.EmployeeID == nil {
+			continue
+		}
+		if _, ok := branches[*grade.EmployeeID]; !ok {
+			branches[*grade.EmployeeID] = grade.BranchID
+		}
+	}
+	return branches
+}
+
+func attendanceCodes(db *gorm.DB) ([]registerCode, error) {
+	var codes []registerCode
+	err := db.Model(&models.AttendanceCode{}).
+		Select("id, code, description, is_debit, category").
+		Where("deleted = ?", 0).
+		Order("code").
+		Scan(&codes).Error
+	for i := range codes {
+		code := models.AttendanceCode{IsDebit: codes[i].IsDebit, Category: codes[i].Category}
+		codes[i].Category = code.AttendanceCategory()
+	}
+	return codes, err
+}
+
+func registerKey(employeeID int, date time.Time) string {
+	return fmt.Sprintf("%d/%s", employeeID, date.Format(dateLayout))
+}
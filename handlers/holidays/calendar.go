@@ -0,0 +1,311 @@
+package holidays
+
+import (
+	"sort"
+	"strconv"
+	"time"
+	"yathuerp/models"
+
+	"gorm.io/gorm"
+)
+
+// Holiday types
+const (
+	TypeNational  = "national"
+	TypeCompany   = "company"
+	TypeReligious = "religious"
+)
+
+// Observed-date rules, applied when a holiday falls on a Saturday or Sunday
+const (
+	ObservedNone           = "none"            // the day off is lost
+	ObservedNearestWeekday = "nearest_weekday" // Saturday moves to Friday, Sunday to Monday
+	ObservedNextWeekday    = "next_weekday"    // moves to the following Monday
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// observedSlack is how far beyond a range holidays are expanded, so that
+	// one falling just outside but observed inside it is found
+	observedSlack = 7
+)
+
+// Occurrence is a holiday as observed on one date
+type Occurrence struct {
+	HolidayID   string    `json:"holiday_id"`
+	Title       string    `json:"title"`
+	Type        string    `json:"type"`
+	BranchID    *int      `json:"branch_id"`
+	Date        time.Time `json:"date"`        // the day off
+	ActualDate  time.Time `json:"actual_date"` // the day the holiday falls on
+	IsRecurring bool      `json:"is_recurring"`
+
+	rule string
+}
+
+// Calendar holds the holidays observed between two dates across all branches
+type Calendar struct {
+	occurrences []Occurrence
+}
+
+// Load expands the holidays observed between start and end
+func Load(db *gorm.DB, start, end time.Time) (*Calendar, error) {
+	start, end = truncateDay(start), truncateDay(end)
+	from, to := start.AddDate(0, 0, -observedSlack), end.AddDate(0, 0, observedSlack)
+
+	var holidays []models.Holiday
+	if err := db.Where("deleted = ? AND holiday_date IS NOT NULL", 0).
+		Where("holiday_date BETWEEN ? AND ? OR (is_recurring = ? AND holiday_date <= ?)", from, to, 1, to).
+		Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+
+	return Expand(holidays, start, end), nil
+}
+
+// Expand lays out holidays on the dates they are observed between start and
+// end. A recurring holiday repeats every year from its first date; one on 29
+// February falls on the 28th in other years.
+func Expand(holidays []models.Holiday, start, end time.Time) *Calendar {
+	start, end = truncateDay(start), truncateDay(end)
+	from, to := start.AddDate(0, 0, -observedSlack), end.AddDate(0, 0, observedSlack)
+
+	var occurrences []Occurrence
+	for _, holiday := range holidays {
+		if holiday.HolidayDate == nil {
+			continue
+		}
+		first := truncateDay(*holiday.HolidayDate)
+
+		dates := []time.Time{first}
+		if holiday.IsRecurring == 1 {
+			dates = nil
+			for year := max(from.Year(), first.Year()); year <= to.Year(); year++ {
+				dates = append(dates, anniversary(first, year))
+			}
+		}
+
+		for _, date := range dates {
+			if date.Before(from) || date.After(to) {
+				continue
+			}
+			occurrences = append(occurrences, Occurrence{
+				HolidayID:   holiday.ID.String(),
+				Title:       holiday.Title,
+				Type:        holiday.Type,
+				BranchID:    holiday.BranchID,
+				Date:        date,
+				ActualDate:  date,
+				IsRecurring: holiday.IsRecurring == 1,
+				rule:        holiday.ObservedRule,
+			})
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].ActualDate.Before(occurrences[j].ActualDate)
+	})
+	observe(occurrences)
+
+	calendar := &Calendar{}
+	for _, occurrence := range occurrences {
+		if !occurrence.Date.Before(start) && !occurrence.Date.After(end) {
+			calendar.occurrences = append(calendar.occurrences, occurrence)
+		}
+	}
+	sort.SliceStable(calendar.occurrences, func(i, j int) bool {
+		return calendar.occurrences[i].Date.Before(calendar.occurrences[j].Date)
+	})
+	return calendar
+}
+
+// ForBranch returns the holidays observed by a branch in date order: those
+// for every branch and the branch's own. A nil branch sees only the former.
+func (c *Calendar) ForBranch(branchID *int) []Occurrence {
+	occurrences := []Occurrence{}
+	for _, occurrence := range c.occurrences {
+		if applies(occurrence, branchID) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	return occurrences
+}
+
+// ForBranches returns the holidays observed by any of the branches in date
+// order, e.g. across a team spread over several branches
+func (c *Calendar) ForBranches(branchIDs []int) []Occurrence {
+	branches := map[int]bool{}
+	for _, branchID := range branchIDs {
+		branches[branchID] = true
+	}
+
+	occurrences := []Occurrence{}
+	for _, occurrence := range c.occurrences {
+		if occurrence.BranchID == nil || branches[*occurrence.BranchID] {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	return occurrences
+}
+
+// All returns every branch's holidays in date order
+func (c *Calendar) All() []Occurrence {
+	return append([]Occurrence{}, c.occurrences...)
+}
+
+// Dates returns the holidays observed by a branch keyed by date
+func (c *Calendar) Dates(branchID *int) map[string]Occurrence {
+	dates := map[string]Occurrence{}
+	for _, occurrence := range c.occurrences {
+		key := occurrence.Date.Format(dateLayout)
+		if _, ok := dates[key]; !ok && applies(occurrence, branchID) {
+			dates[key] = occurrence
+		}
+	}
+	return dates
+}
+
+// On returns the holiday a branch observes on date, or nil
+func (c *Calendar) On(date time.Time, branchID *int) *Occurrence {
+	date = truncateDay(date)
+	for i := range c.occurrences {
+		if c.occurrences[i].Date.Equal(date) && applies(c.occurrences[i], branchID) {
+			return &c.occurrences[i]
+		}
+	}
+	return nil
+}
+
+// EmployeeBranch returns the branch on the employee's current grade, or nil
+func EmployeeBranch(db *gorm.DB, employeeID int) (*int, error) {
+	var grade models.EmployeeGrade
+	err := db.Where("employee_id = ? AND is_current = ? AND deleted = ?", employeeID, 1, 0).
+		Order("start_date DESC").
+		Limit(1).
+		Find(&grade).Error
+	if err != nil {
+		return nil, err
+	}
+	return grade.BranchID, nil
+}
+
+// EmployeeBranches returns the branch on each employee's current grade;
+// employees without one are left out
+func EmployeeBranches(db *gorm.DB, employeeIDs []int) (map[int]int, error) {
+	branches := map[int]int{}
+	if len(employeeIDs) == 0 {
+		return branches, nil
+	}
+
+	var grades []models.EmployeeGrade
+	if err := db.Where("employee_id IN ? AND is_current = ? AND deleted = ?", employeeIDs, 1, 0).
+		Order("start_date DESC").
+		Find(&grades).Error; err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	for _, grade := range grades {
+		if grade.EmployeeID == nil || seen[*grade.EmployeeID] {
+			continue
+		}
+		seen[*grade.EmployeeID] = true
+		if grade.BranchID != nil {
+			branches[*grade.EmployeeID] = *grade.BranchID
+		}
+	}
+	return branches, nil
+}
+
+// BranchIDs returns the distinct branches in an EmployeeBranches result
+func BranchIDs(branches map[int]int) []int {
+	seen := map[int]bool{}
+	ids := []int{}
+	for _, branchID := range branches {
+		if !seen[branchID] {
+			seen[branchID] = true
+			ids = append(ids, branchID)
+		}
+	}
+	return ids
+}
+
+// observe moves weekend holidays to their observed dates. Holidays already on
+// a weekday keep their date and are placed first, and a moved holiday skips
+// any weekday already taken, so Christmas on a Saturday and Boxing Day on the
+// Sunday are observed on the Monday and Tuesday. occurrences must be in date
+// order.
+func observe(occurrences []Occurrence) {
+	taken := map[string]bool{}
+	for _, occurrence := range occurrences {
+		if !isWeekend(occurrence.ActualDate) {
+			taken[takenKey(occurrence.BranchID, occurrence.ActualDate)] = true
+		}
+	}
+	free := func(occurrence Occurrence, date time.Time) bool {
+		if taken[takenKey(nil, date)] {
+			return false
+		}
+		return occurrence.BranchID == nil || !taken[takenKey(occurrence.BranchID, date)]
+	}
+
+	for i := range occurrences {
+		occurrence := &occurrences[i]
+		if !isWeekend(occurrence.ActualDate) {
+			continue
+		}
+
+		var date time.Time
+		switch occurrence.rule {
+		case ObservedNearestWeekday:
+			date = occurrence.ActualDate.AddDate(0, 0, 1)
+			if occurrence.ActualDate.Weekday() == time.Saturday {
+				date = occurrence.ActualDate.AddDate(0, 0, -1)
+			}
+		case ObservedNextWeekday:
+			date = occurrence.ActualDate.AddDate(0, 0, 1)
+		default:
+			continue
+		}
+		for isWeekend(date) || !free(*occurrence, date) {
+			date = date.AddDate(0, 0, 1)
+		}
+
+		occurrence.Date = date
+		taken[takenKey(occurrence.BranchID, date)] = true
+	}
+}
+
+// applies reports whether a branch observes the holiday
+func applies(occurrence Occurrence, branchID *int) bool {
+	return occurrence.BranchID == nil || (branchID != nil && *occurrence.BranchID == *branchID)
+}
+
+// anniversary is date's day and month in year
+func anniversary(date time.Time, year int) time.Time {
+	month, day := date.Month(), date.Day()
+	if month == time.February && day == 29 && !isLeapYear(year) {
+		day = 28
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func takenKey(branchID *int, date time.Time) string {
+	if branchID == nil {
+		return date.Format(dateLayout)
+	}
+	return date.Format(dateLayout) + "/" + strconv.Itoa(*branchID)
+}
+
+func isWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,215 @@
+package holidays
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	db *gorm.DB
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{db: db}
+}
+
+// holidayRequest is the body accepted when creating or updating a holiday
+type holidayRequest struct {
+	Title        string `json:"title"`
+	HolidayDate  string `json:"holiday_date"` // YYYY-MM-DD, the first occurrence of a recurring holiday
+	Comment      string `json:"comment"`
+	Type         string `json:"type"`
+	IsRecurring  bool   `json:"is_recurring"`
+	BranchID     *int   `json:"branch_id"` // empty for every branch
+	ObservedRule string `json:"observed_rule"`
+}
+
+// GetHolidays lists holiday definitions, optionally limited to a branch's
+// (including those for every branch), a type or recurring holidays
+func (h *Handler) GetHolidays(c *fiber.Ctx) error {
+	query := h.db.Where("deleted = ?", 0)
+	if branchID := c.Query("branch_id"); branchID != "" {
+		query = query.Where("branch_id IS NULL OR branch_id = ?", branchID)
+	}
+	if holidayType := c.Query("type"); holidayType != "" {
+		query = query.Where("type = ?", holidayType)
+	}
+	if c.Query("recurring") != "" {
+		recurring, err := strconv.ParseBool(c.Query("recurring"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid recurring, expected true or false"})
+		}
+		if recurring {
+			query = query.Where("is_recurring = ?", 1)
+		} else {
+			query = query.Where("is_recurring = ?", 0)
+		}
+	}
+
+	var holidays []models.Holiday
+	if err := query.Order("holiday_date").Find(&holidays).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch holidays"})
+	}
+
+	return c.JSON(fiber.Map{"data": holidays})
+}
+
+// GetHolidayCalendar returns the holidays observed in a year, or between
+// start_date and end_date, with recurring holidays expanded and weekend
+// holidays moved to their observed dates. With branch_id only that branch's
+// holidays are returned.
+func (h *Handler) GetHolidayCalendar(c *fiber.Ctx) error {
+	var start, end time.Time
+	if c.Query("start_date") != "" || c.Query("end_date") != "" {
+		var err error
+		if start, err = time.Parse(dateLayout, c.Query("start_date")); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid start_date, expected YYYY-MM-DD"})
+		}
+		end, err = time.Parse(dateLayout, c.Query("end_date"))
+		if err != nil || end.Before(start) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid end_date, expected YYYY-MM-DD on or after start_date"})
+		}
+	} else {
+		year, err := strconv.Atoi(c.Query("year", strconv.Itoa(time.Now().Year())))
+		if err != nil || year < 1 {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid year"})
+		}
+		start = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end = time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	calendar, err := Load(h.db, start, end)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch holidays"})
+	}
+
+	occurrences := calendar.All()
+	if c.Query("branch_id") != "" {
+		branchID, err := strconv.Atoi(c.Query("branch_id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid branch_id"})
+		}
+		occurrences = calendar.ForBranch(&branchID)
+	}
+
+	return c.JSON(fiber.Map{
+		"start_date": start.Format(dateLayout),
+		"end_date":   end.Format(dateLayout),
+		"holidays":   occurrences,
+	})
+}
+
+func (h *Handler) GetHolidayByID(c *fiber.Ctx) error {
+	var holiday models.Holiday
+	if err := h.db.Where("id = ? AND deleted = ?", c.Params("id"), 0).First(&holiday).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Holiday not found"})
+	}
+
+	return c.JSON(holiday)
+}
+
+func (h *Handler) CreateHoliday(c *fiber.Ctx) error {
+	var req holidayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	var holiday models.Holiday
+	if err := h.applyHolidayRequest(&holiday, &req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.db.Create(&holiday).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create holiday"})
+	}
+
+	return c.Status(201).JSON(holiday)
+}
+
+func (h *Handler) UpdateHoliday(c *fiber.Ctx) error {
+	var holiday models.Holiday
+	if err := h.db.Where("id = ? AND deleted = ?", c.Params("id"), 0).First(&holiday).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Holiday not found"})
+	}
+
+	var req holidayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if err := h.applyHolidayRequest(&holiday, &req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.db.Save(&holiday).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update holiday"})
+	}
+
+	return c.JSON(holiday)
+}
+
+func (h *Handler) DeleteHoliday(c *fiber.Ctx) error {
+	var holiday models.Holiday
+	if err := h.db.Where("id = ? AND deleted = ?", c.Params("id"), 0).First(&holiday).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Holiday not found"})
+	}
+
+	if err := h.db.Delete(&holiday).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete holiday"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Holiday deleted successfully"})
+}
+
+// applyHolidayRequest validates req and copies it onto holiday
+func (h *Handler) applyHolidayRequest(holiday *models.Holiday, req *holidayRequest) error {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return fmt.Errorf("title is required")
+	}
+	date, err := time.Parse(dateLayout, req.HolidayDate)
+	if err != nil {
+		return fmt.Errorf("invalid holiday_date, expected YYYY-MM-DD")
+	}
+
+	holidayType := strings.ToLower(strings.TrimSpace(req.Type))
+	switch holidayType {
+	case "":
+		holidayType = TypeNational
+	case TypeNational, TypeCompany, TypeReligious:
+	default:
+		return fmt.Errorf("invalid type %q, expected national, company or religious", req.Type)
+	}
+
+	rule := strings.ToLower(strings.TrimSpace(req.ObservedRule))
+	switch rule {
+	case "":
+		rule = ObservedNone
+	case ObservedNone, ObservedNearestWeekday, ObservedNextWeekday:
+	default:
+		return fmt.Errorf("invalid observed_rule %q, expected none, nearest_weekday or next_weekday", req.ObservedRule)
+	}
+
+	if req.BranchID != nil {
+		var count int64
+		if err := h.db.Model(&models.Branch{}).Where("id = ? AND deleted = ?", *req.BranchID, 0).
+			Count(&count).Error; err != nil || count == 0 {
+			return fmt.Errorf("branch %d not found", *req.BranchID)
+		}
+	}
+
+	holiday.Title = title
+	holiday.HolidayDate = &date
+	holiday.Comment = req.Comment
+	holiday.Type = holidayType
+	holiday.IsRecurring = 0
+	if req.IsRecurring {
+		holiday.IsRecurring = 1
+	}
+	holiday.BranchID = req.BranchID
+	holiday.ObservedRule = rule
+	return nil
+}
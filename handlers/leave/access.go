@@ -0,0 +1,77 @@
+package leave
+
+import (
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// hrRoles are the tbl_roles names that may act on other people's leave
+var hrRoles = []string{"hr", "admin"}
+
+// RequireHR lets only HR through, for the endpoints that configure leave,
+// attendance or holidays or act on every employee's records
+func (h *Handler) RequireHR(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+	allowed, err := isHR(h.db, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check permissions"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Only HR can run this"})
+	}
+	return c.Next()
+}
+
+// isHR reports whether the user holds one of the HR roles
+func isHR(tx *gorm.DB, userID int) (bool, error) {
+	var count int64
+	err := tx.Model(&models.UserRole{}).
+		Joins("JOIN tbl_roles ON tbl_roles.id = tbl_user_roles.role_id").
+		Where("tbl_user_roles.user_id = ? AND LOWER(tbl_roles.name) IN ? AND tbl_roles.deleted = ? AND tbl_roles.is_active = ?",
+			userID, hrRoles, 0, 1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// isApplicant reports whether the user's employee made the application
+func isApplicant(tx *gorm.DB, userID int, application *models.LeaveApplication) (bool, error) {
+	employeeID, err := employeeOfUser(tx, userID)
+	if err != nil || employeeID == 0 {
+		return false, err
+	}
+	return employeeID == application.EmployeeID, nil
+}
+
+// isHROrManagerOf reports whether the user is HR or the employee's current
+// manager
+func isHROrManagerOf(tx *gorm.DB, userID, employeeID int) (bool, error) {
+	allowed, err := isHR(tx, userID)
+	if err != nil || allowed {
+		return allowed, err
+	}
+	managerID, err := managerOf(tx, employeeID)
+	if err != nil || managerID == 0 {
+		return false, err
+	}
+	return managerID == userID, nil
+}
+
+// managesDepartment reports whether the user's employee is the manager of
+// anyone currently in the department
+func managesDepartment(tx *gorm.DB, userID, departmentID int) (bool, error) {
+	employeeID, err := employeeOfUser(tx, userID)
+	if err != nil || employeeID == 0 {
+		return false, err
+	}
+
+	var count int64
+	err = tx.Model(&models.EmployeeGrade{}).
+		Where("manager_id = ? AND department_id = ? AND is_current = ? AND deleted = ?", employeeID, departmentID, 1, 0).
+		Count(&count).Error
+	return count > 0, err
+}
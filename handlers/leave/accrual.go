@@ -0,0 +1,367 @@
+package leave
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+	"yathuerp/models"
+
+	"gorm.io/gorm"
+)
+
+var errNoGradeHistory = errors.New("employee has no grade history")
+
+// gradePeriod is a span during which an employee held one grade. End is
+// inclusive and nil while the grade is still current.
+type gradePeriod struct {
+	GradeID    *int
+	Start      time.Time
+	End        *time.Time
+	AnnualDays float64
+}
+
+// RecomputeBalances rebuilds an employee's accruals, carry-forwards,
+// forfeitures and balances from their grade history up to asOf. A leaver
+// stops accruing on their exit date.
+func RecomputeBalances(db *gorm.DB, employeeID int, asOf time.Time) ([]models.LeaveBalance, error) {
+	var balances []models.LeaveBalance
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		exitDate, err := employeeExitDate(tx, employeeID)
+		if err != nil {
+			return err
+		}
+		periods, err := loadGradePeriods(tx, employeeID, exitDate)
+		if err != nil {
+			return err
+		}
+		// an employee joining in a later year has nothing to accrue yet
+		if periods[0].Start.Year() > asOf.Year() {
+			return nil
+		}
+
+		var leaveTypes []models.LeaveType
+		if err := tx.Where("deleted = ? AND (days_per_year > 0 OR accrue_by_grade = 1)", 0).
+			Find(&leaveTypes).Error; err != nil {
+			return err
+		}
+
+		for _, leaveType := range leaveTypes {
+			balance, err := recomputeLeaveType(tx, employeeID, leaveType, periods, exitDate, asOf)
+			if err != nil {
+				return err
+			}
+			balances = append(balances, *balance)
+		}
+		return nil
+	})
+
+	return balances, err
+}
+
+// recomputeLeaveType walks every year from the employee's first grade up to
+// asOf (a UTC date), carrying the closing balance of each year into the next.
+func recomputeLeaveType(tx *gorm.DB, employeeID int, leaveType models.LeaveType, periods []gradePeriod, exitDate *time.Time, asOf time.Time) (*models.LeaveBalance, error) {
+	leaveTypeID := leaveType.ID.String()
+	carry := 0.0
+
+	var balance *models.LeaveBalance
+	for year := periods[0].Start.Year(); year <= asOf.Year(); year++ {
+		yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+		yearAsOf := asOf
+		if yearEnd.Before(asOf) {
+			yearAsOf = yearEnd
+		}
+
+		accruals := monthlyAccruals(periods, leaveType, year, exitDate, yearAsOf)
+		accrued := 0.0
+		for i := range accruals {
+			accruals[i].EmployeeID = employeeID
+			accruals[i].LeaveTypeID = leaveTypeID
+			accrued += accruals[i].Days
+		}
+
+		used, err := usedDays(tx, employeeID, leaveTypeID, year)
+		if err != nil {
+			return nil, err
+		}
+
+		encashed, err := encashedDays(tx, employeeID, leaveTypeID, year)
+		if err != nil {
+			return nil, err
+		}
+
+		opening := carry
+		total := roundDays(accrued + opening)
+		closing := roundDays(total - used - encashed)
+
+		forfeited := 0.0
+		if year < asOf.Year() {
+			carry, forfeited = carryForward(leaveType, closing)
+		}
+
+		if err := replaceAccruals(tx, employeeID, leaveTypeID, year, accruals); err != nil {
+			return nil, err
+		}
+		if err := replaceForfeiture(tx, employeeID, leaveTypeID, year, forfeited, leaveType); err != nil {
+			return nil, err
+		}
+
+		balance, err = saveBalance(tx, &models.LeaveBalance{
+			EmployeeID:    employeeID,
+			LeaveTypeID:   leaveTypeID,
+			Year:          year,
+			AccruedDays:   roundDays(accrued),
+			CarryForward:  opening,
+			TotalDays:     total,
+			UsedDays:      used,
+			ForfeitedDays: forfeited,
+			EncashedDays:  encashed,
+			BalanceDays:   roundDays(closing - forfeited),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return balance, nil
+}
+
+// monthlyAccruals credits one twelfth of the annual entitlement for every
+// month that has ended by asOf, pro-rated by the days the employee held each
+// grade during that month. The month an employee leaves in is credited for
+// the days up to their exit once asOf reaches it.
+func monthlyAccruals(periods []gradePeriod, leaveType models.LeaveType, year int, exitDate *time.Time, asOf time.Time) []models.LeaveAccrual {
+	var accruals []models.LeaveAccrual
+
+	for month := time.January; month <= time.December; month++ {
+		monthStart := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		monthEnd := monthStart.AddDate(0, 1, -1)
+		leftThisMonth := exitDate != nil && !exitDate.Before(monthStart) && !exitDate.After(asOf)
+		if monthEnd.After(asOf) && !leftThisMonth {
+			break
+		}
+		daysInMonth := float64(monthEnd.Day())
+
+		for _, period := range periods {
+			covered := overlapDays(period.Start, period.End, monthStart, monthEnd)
+			if covered == 0 {
+				continue
+			}
+
+			annual := leaveType.DaysPerYear
+			if leaveType.AccrueByGrade == 1 {
+				annual = period.AnnualDays
+			}
+			if annual <= 0 {
+				continue
+			}
+
+			accruals = append(accruals, models.LeaveAccrual{
+				Year:    year,
+				Month:   int(month),
+				GradeID: period.GradeID,
+				Days:    roundDays(annual / 12 * float64(covered) / daysInMonth),
+			})
+		}
+	}
+
+	return accruals
+}
+
+// carryForward splits a closing balance into the days that move to the next
+// year and the days forfeited above CarryForwardLimit.
+func carryForward(leaveType models.LeaveType, closing float64) (carried, forfeited float64) {
+	if closing <= 0 {
+		return 0, 0
+	}
+	if leaveType.IsCarryForward != 1 {
+		return 0, closing
+	}
+	if leaveType.CarryForwardLimit > 0 && closing > leaveType.CarryForwardLimit {
+		return leaveType.CarryForwardLimit, roundDays(closing - leaveType.CarryForwardLimit)
+	}
+	return closing, 0
+}
+
+// loadGradePeriods returns the employee's grade history, cut off at their
+// exit date when they have left.
+func loadGradePeriods(tx *gorm.DB, employeeID int, exitDate *time.Time) ([]gradePeriod, error) {
+	var employeeGrades []models.EmployeeGrade
+	if err := tx.Where("employee_id = ? AND deleted = ? AND start_date IS NOT NULL", employeeID, 0).
+		Order("start_date").
+		Find(&employeeGrades).Error; err != nil {
+		return nil, err
+	}
+	if len(employeeGrades) == 0 {
+		return nil, errNoGradeHistory
+	}
+
+	grades := map[int]models.Grade{}
+	periods := make([]gradePeriod, 0, len(employeeGrades))
+	for i, eg := range employeeGrades {
+		period := gradePeriod{
+			GradeID: eg.GradeID,
+			Start:   truncateDay(*eg.StartDate),
+		}
+
+		switch {
+		case eg.EndDate != nil:
+			end := truncateDay(*eg.EndDate)
+			period.End = &end
+		case i+1 < len(employeeGrades):
+			end := truncateDay(*employeeGrades[i+1].StartDate).AddDate(0, 0, -1)
+			period.End = &end
+		}
+
+		if eg.GradeID != nil {
+			grade, ok := grades[*eg.GradeID]
+			if !ok {
+				if err := tx.First(&grade, *eg.GradeID).Error; err != nil {
+					return nil, fmt.Errorf("failed to load grade %d: %w", *eg.GradeID, err)
+				}
+				grades[*eg.GradeID] = grade
+			}
+			if grade.AnnualLeaveDays != nil {
+				period.AnnualDays = float64(*grade.AnnualLeaveDays)
+			}
+		}
+
+		if exitDate != nil {
+			if period.Start.After(*exitDate) {
+				continue
+			}
+			if period.End == nil || period.End.After(*exitDate) {
+				end := *exitDate
+				period.End = &end
+			}
+		}
+
+		periods = append(periods, period)
+	}
+	if len(periods) == 0 {
+		return nil, errNoGradeHistory
+	}
+
+	return periods, nil
+}
+
+// employeeExitDate returns the date of the employee's current exit, or nil
+// while they are employed
+func employeeExitDate(tx *gorm.DB, employeeID int) (*time.Time, error) {
+	var exits []models.EmployeeTrash
+	if err := tx.Where("employee_id = ? AND activated_date IS NULL AND deleted = ?", employeeID, 0).
+		Order("action_date DESC").
+		Limit(1).
+		Find(&exits).Error; err != nil {
+		return nil, err
+	}
+	if len(exits) == 0 {
+		return nil, nil
+	}
+	exitDate := truncateDay(exits[0].ActionDate)
+	return &exitDate, nil
+}
+
+func usedDays(tx *gorm.DB, employeeID int, leaveTypeID string, year int) (float64, error) {
+	var used float64
+	err := tx.Model(&models.LeaveApplication{}).
+		Select("COALESCE(SUM(days_applied), 0)").
+		Where("employee_id = ? AND leave_type_id = ? AND application_status = ? AND deleted = ?",
+			employeeID, leaveTypeID, statusApproved, 0).
+		Where("EXTRACT(YEAR FROM start_date) = ?", year).
+		Scan(&used).Error
+	return used, err
+}
+
+func encashedDays(tx *gorm.DB, employeeID int, leaveTypeID string, year int) (float64, error) {
+	var encashed float64
+	err := tx.Model(&models.LeaveEncashment{}).
+		Select("COALESCE(SUM(days), 0)").
+		Where("employee_id = ? AND leave_type_id = ? AND year = ? AND deleted = ?", employeeID, leaveTypeID, year, 0).
+		Scan(&encashed).Error
+	return encashed, err
+}
+
+func replaceAccruals(tx *gorm.DB, employeeID int, leaveTypeID string, year int, accruals []models.LeaveAccrual) error {
+	if err := tx.Unscoped().
+		Where("employee_id = ? AND leave_type_id = ? AND year = ?", employeeID, leaveTypeID, year).
+		Delete(&models.LeaveAccrual{}).Error; err != nil {
+		return err
+	}
+	if len(accruals) == 0 {
+		return nil
+	}
+	return tx.Create(&accruals).Error
+}
+
+func replaceForfeiture(tx *gorm.DB, employeeID int, leaveTypeID string, year int, days float64, leaveType models.LeaveType) error {
+	if err := tx.Unscoped().
+		Where("employee_id = ? AND leave_type_id = ? AND year = ?", employeeID, leaveTypeID, year).
+		Delete(&models.LeaveForfeiture{}).Error; err != nil {
+		return err
+	}
+	if days <= 0 {
+		return nil
+	}
+
+	reason := "Leave type does not carry forward"
+	if leaveType.IsCarryForward == 1 {
+		reason = fmt.Sprintf("Exceeds carry-forward limit of %.2f days", leaveType.CarryForwardLimit)
+	}
+
+	return tx.Create(&models.LeaveForfeiture{
+		EmployeeID:  employeeID,
+		LeaveTypeID: leaveTypeID,
+		Year:        year,
+		Days:        days,
+		Reason:      reason,
+	}).Error
+}
+
+func saveBalance(tx *gorm.DB, balance *models.LeaveBalance) (*models.LeaveBalance, error) {
+	var existing models.LeaveBalance
+	err := tx.Where("employee_id = ? AND leave_type_id = ? AND year = ?",
+		balance.EmployeeID, balance.LeaveTypeID, balance.Year).
+		First(&existing).Error
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := tx.Create(balance).Error; err != nil {
+			return nil, err
+		}
+		return balance, nil
+	case err != nil:
+		return nil, err
+	}
+
+	balance.BaseModel = existing.BaseModel
+	if err := tx.Save(balance).Error; err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// overlapDays counts the days shared by [start, end] and [from, to]; a nil end
+// is open-ended.
+func overlapDays(start time.Time, end *time.Time, from, to time.Time) int {
+	if start.After(from) {
+		from = start
+	}
+	if end != nil && end.Before(to) {
+		to = *end
+	}
+	if from.After(to) {
+		return 0
+	}
+	return int(to.Sub(from).Hours()/24) + 1
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func roundDays(days float64) float64 {
+	return math.Round(days*100) / 100
+}
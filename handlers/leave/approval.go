@@ -0,0 +1,172 @@
+package leave
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var errUnauthenticated = errors.New("authenticated user required")
+
+// leaveDecisionRequest is the optional body of approve and reject calls
+type leaveDecisionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// approvalError is a business rule that prevents an application from being
+// approved; its message is returned to the approver as-is.
+type approvalError struct {
+	reason string
+}
+
+func (e *approvalError) Error() string {
+	return e.reason
+}
+
+func refuse(format string, args ...interface{}) error {
+	return &approvalError{reason: fmt.Sprintf(format, args...)}
+}
+
+// checkApproval verifies the leave type limits, overlapping leave and the
+// employee's remaining balance before an application is approved.
+func checkApproval(tx *gorm.DB, application *models.LeaveApplication) error {
+	if application.ApplicationStatus != statusPending {
+		return refuse("only pending applications can be approved, this one is %s", application.ApplicationStatus)
+	}
+	if application.StartDate == nil || application.EndDate == nil {
+		return refuse("application has no leave period")
+	}
+
+	var leaveType models.LeaveType
+	if err := tx.First(&leaveType, "id = ?", application.LeaveTypeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return refuse("leave type %s does not exist", application.LeaveTypeID)
+		}
+		return err
+	}
+
+	if leaveType.MaximumDays != nil && *leaveType.MaximumDays > 0 && application.DaysApplied > float64(*leaveType.MaximumDays) {
+		return refuse("%s allows at most %d working days per application, %.1f requested",
+			leaveType.Name, *leaveType.MaximumDays, application.DaysApplied)
+	}
+	if leaveType.MaxConsecutiveDays != nil && *leaveType.MaxConsecutiveDays > 0 {
+		// weekends and holidays inside the period count towards the run
+		consecutive := int(truncateDay(*application.EndDate).Sub(truncateDay(*application.StartDate)).Hours()/24) + 1
+		if consecutive > *leaveType.MaxConsecutiveDays {
+			return refuse("%s allows at most %d consecutive days, %d requested",
+				leaveType.Name, *leaveType.MaxConsecutiveDays, consecutive)
+		}
+	}
+
+	var overlapping []models.LeaveApplication
+	if err := tx.Where("employee_id = ? AND application_status = ? AND deleted = ? AND id <> ?",
+		application.EmployeeID, statusApproved, 0, application.ID).
+		Where("start_date <= ? AND end_date >= ?", application.EndDate, application.StartDate).
+		Find(&overlapping).Error; err != nil {
+		return err
+	}
+	if len(overlapping) > 0 {
+		other := overlapping[0]
+		return refuse("overlaps approved leave from %s to %s",
+			other.StartDate.Format(dateLayout), other.EndDate.Format(dateLayout))
+	}
+
+	if leaveType.DaysPerYear <= 0 && leaveType.AccrueByGrade != 1 {
+		return nil
+	}
+
+	balance, err := leaveBalanceFor(tx, application)
+	if err != nil {
+		return err
+	}
+	if balance == nil {
+		return refuse("no %s balance for %d", leaveType.Name, application.StartDate.Year())
+	}
+	if balance.BalanceDays < application.DaysApplied {
+		return refuse("insufficient %s balance: %.2f days available, %.1f requested",
+			leaveType.Name, balance.BalanceDays, application.DaysApplied)
+	}
+
+	return nil
+}
+
+// leaveBalanceFor returns the balance the application draws from, computing
+// balances first if the employee has none yet.
+func leaveBalanceFor(tx *gorm.DB, application *models.LeaveApplication) (*models.LeaveBalance, error) {
+	year := application.StartDate.Year()
+	query := func() (*models.LeaveBalance, error) {
+		var balance models.LeaveBalance
+		err := tx.Where("employee_id = ? AND leave_type_id = ? AND year = ? AND deleted = ?",
+			application.EmployeeID, application.LeaveTypeID, year, 0).
+			First(&balance).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &balance, nil
+	}
+
+	balance, err := query()
+	if balance != nil || err != nil {
+		return balance, err
+	}
+
+	if _, err := RecomputeBalances(tx, application.EmployeeID, truncateDay(time.Now())); err != nil {
+		if errors.Is(err, errNoGradeHistory) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return query()
+}
+
+// deductLeave moves approved days out of the employee's balance for the
+// application's leave type. The legacy tbl_leave_days record is not kept in
+// step: it has no leave type, so it cannot tell annual from sick leave.
+func deductLeave(tx *gorm.DB, application *models.LeaveApplication) error {
+	return adjustLeaveUsage(tx, application, application.DaysApplied)
+}
+
+// adjustLeaveUsage adds days to the used total (negative days restore them)
+func adjustLeaveUsage(tx *gorm.DB, application *models.LeaveApplication, days float64) error {
+	if days == 0 {
+		return nil
+	}
+
+	return tx.Model(&models.LeaveBalance{}).
+		Where("employee_id = ? AND leave_type_id = ? AND year = ? AND deleted = ?",
+			application.EmployeeID, application.LeaveTypeID, application.StartDate.Year(), 0).
+		Updates(map[string]interface{}{
+			"used_days":    gorm.Expr("used_days + ?", days),
+			"balance_days": gorm.Expr("balance_days - ?", days),
+		}).Error
+}
+
+// syncLeaveDayStatus copies the application status onto its materialised dates
+func syncLeaveDayStatus(tx *gorm.DB, application *models.LeaveApplication) error {
+	return tx.Model(&models.LeaveApplicationDay{}).
+		Where("leave_application_id = ?", application.ID.String()).
+		Update("status", application.ApplicationStatus).Error
+}
+
+// currentUserID reads the user id claim set by middleware.JWTAuth
+func currentUserID(c *fiber.Ctx) (int, error) {
+	switch userID := c.Locals("userID").(type) {
+	case float64:
+		return int(userID), nil
+	case string:
+		id, err := strconv.Atoi(userID)
+		if err != nil {
+			return 0, errUnauthenticated
+		}
+		return id, nil
+	}
+	return 0, errUnauthenticated
+}
@@ -0,0 +1,125 @@
+package leave
+
+import (
+	"errors"
+	"strconv"
+	"time"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) GetLeaveBalances(c *fiber.Ctx) error {
+	employeeID, err := strconv.Atoi(c.Params("employeeId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid employee ID"})
+	}
+
+	year, err := strconv.Atoi(c.Query("year", strconv.Itoa(time.Now().Year())))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid year"})
+	}
+
+	var balances []models.LeaveBalance
+	if err := h.db.Where("employee_id = ? AND year = ? AND deleted = ?", employeeID, year, 0).
+		Find(&balances).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch leave balances"})
+	}
+
+	return c.JSON(balances)
+}
+
+func (h *Handler) GetLeaveAccruals(c *fiber.Ctx) error {
+	employeeID, err := strconv.Atoi(c.Params("employeeId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid employee ID"})
+	}
+
+	year, err := strconv.Atoi(c.Query("year", strconv.Itoa(time.Now().Year())))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid year"})
+	}
+
+	var accruals []models.LeaveAccrual
+	if err := h.db.Where("employee_id = ? AND year = ?", employeeID, year).
+		Order("leave_type_id, month").
+		Find(&accruals).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch leave accruals"})
+	}
+
+	var forfeitures []models.LeaveForfeiture
+	if err := h.db.Where("employee_id = ? AND year = ?", employeeID, year).
+		Find(&forfeitures).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch leave forfeitures"})
+	}
+
+	return c.JSON(fiber.Map{
+		"accruals":    accruals,
+		"forfeitures": forfeitures,
+	})
+}
+
+// RecomputeLeaveBalances rebuilds one employee's balances from their grade and
+// leave history.
+func (h *Handler) RecomputeLeaveBalances(c *fiber.Ctx) error {
+	employeeID, err := strconv.Atoi(c.Params("employeeId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid employee ID"})
+	}
+
+	asOf, err := asOfQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid as_of date, expected YYYY-MM-DD"})
+	}
+
+	balances, err := RecomputeBalances(h.db, employeeID, asOf)
+	if err != nil {
+		if errors.Is(err, errNoGradeHistory) {
+			return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to recompute leave balances"})
+	}
+
+	return c.JSON(balances)
+}
+
+// RunLeaveAccrual recomputes balances for every employee with a current
+// grade.
+func (h *Handler) RunLeaveAccrual(c *fiber.Ctx) error {
+	asOf, err := asOfQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid as_of date, expected YYYY-MM-DD"})
+	}
+
+	var employeeIDs []int
+	if err := h.db.Model(&models.EmployeeGrade{}).
+		Where("is_current = ? AND deleted = ?", 1, 0).
+		Distinct().
+		Pluck("employee_id", &employeeIDs).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch employees"})
+	}
+
+	processed := 0
+	failed := map[int]string{}
+	for _, employeeID := range employeeIDs {
+		if _, err := RecomputeBalances(h.db, employeeID, asOf); err != nil {
+			failed[employeeID] = err.Error()
+			continue
+		}
+		processed++
+	}
+
+	return c.JSON(fiber.Map{
+		"as_of":     asOf.Format("2006-01-02"),
+		"processed": processed,
+		"failed":    failed,
+	})
+}
+
+func asOfQuery(c *fiber.Ctx) (time.Time, error) {
+	value := c.Query("as_of")
+	if value == "" {
+		return truncateDay(time.Now()), nil
+	}
+	return time.Parse("2006-01-02", value)
+}
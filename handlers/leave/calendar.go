@@ -0,0 +1,329 @@
+package leave
+
+import (
+	"sort"
+	"strconv"
+	"time"
+	"yathuerp/handlers/holidays"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// defaultLeaveConflictShare applies when Setting.LeaveConflictShare is unset
+const defaultLeaveConflictShare = 0.3
+
+// calendarEntry is one application shown on the team calendar
+type calendarEntry struct {
+	ApplicationID string     `json:"application_id"`
+	EmployeeID    int        `json:"employee_id"`
+	LeaveTypeID   string     `json:"leave_type_id"`
+	StartDate     *time.Time `json:"start_date"`
+	EndDate       *time.Time `json:"end_date"`
+	DaysApplied   float64    `json:"days_applied"`
+	Status        string     `json:"status"`
+}
+
+// calendarDay summarises who is away on a single date
+type calendarDay struct {
+	Date      string  `json:"date"`
+	IsHoliday bool    `json:"is_holiday"`
+	Approved  []int   `json:"approved"`
+	Pending   []int   `json:"pending"`
+	Share     float64 `json:"share"`
+}
+
+// leaveConflict flags a date on which too much of the team would be away
+type leaveConflict struct {
+	Date      string  `json:"date"`
+	Away      int     `json:"away"`
+	TeamSize  int     `json:"team_size"`
+	Share     float64 `json:"share"`
+	Threshold float64 `json:"threshold"`
+}
+
+// leaveApplicationResponse is returned on submission so that conflicts can be
+// shown without failing the request.
+type leaveApplicationResponse struct {
+	models.LeaveApplication
+	Conflicts []leaveConflict `json:"conflicts,omitempty"`
+}
+
+// GetTeamCalendar returns approved and pending leave for a department or a
+// manager's direct reports over a date range, merged with holidays. HR sees
+// any team; a manager sees their own reports and the departments they manage.
+func (h *Handler) GetTeamCalendar(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	from, err := time.Parse(dateLayout, c.Query("from"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid from date, expected YYYY-MM-DD"})
+	}
+	to, err := time.Parse(dateLayout, c.Query("to"))
+	if err != nil || to.Before(from) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid to date, expected YYYY-MM-DD on or after from"})
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		return c.Status(400).JSON(fiber.Map{"error": "Calendar range cannot exceed one year"})
+	}
+
+	allowed, err := isHR(h.db, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check permissions"})
+	}
+
+	team := h.db.Model(&models.EmployeeGrade{}).Where("is_current = ? AND deleted = ?", 1, 0)
+	switch {
+	case c.Query("department_id") != "":
+		departmentID, err := strconv.Atoi(c.Query("department_id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid department_id"})
+		}
+		if !allowed {
+			if allowed, err = managesDepartment(h.db, userID, departmentID); err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to check permissions"})
+			}
+		}
+		team = team.Where("department_id = ?", departmentID)
+	case c.Query("manager_id") != "":
+		managerID, err := strconv.Atoi(c.Query("manager_id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid manager_id"})
+		}
+		if !allowed {
+			employeeID, err := employeeOfUser(h.db, userID)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to check permissions"})
+			}
+			allowed = employeeID != 0 && employeeID == managerID
+		}
+		team = team.Where("manager_id = ?", managerID)
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "department_id or manager_id is required"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Only HR or the team's manager can view its calendar"})
+	}
+
+	var employeeIDs []int
+	if err := team.Distinct().Pluck("employee_id", &employeeIDs).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch team"})
+	}
+
+	var applications []models.LeaveApplication
+	if len(employeeIDs) > 0 {
+		if err := h.db.Where("employee_id IN ? AND application_status IN ? AND deleted = ?",
+			employeeIDs, []string{statusApproved, statusPending}, 0).
+			Where("start_date <= ? AND end_date >= ?", to, from).
+			Order("start_date").
+			Find(&applications).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch leave applications"})
+		}
+	}
+
+	entries := make([]calendarEntry, 0, len(applications))
+	for _, application := range applications {
+		entries = append(entries, calendarEntry{
+			ApplicationID: application.ID.String(),
+			EmployeeID:    application.EmployeeID,
+			LeaveTypeID:   application.LeaveTypeID,
+			StartDate:     application.StartDate,
+			EndDate:       application.EndDate,
+			DaysApplied:   application.DaysApplied,
+			Status:        application.ApplicationStatus,
+		})
+	}
+
+	// a team spread over branches sees every holiday observed by one of them
+	branches, err := holidays.EmployeeBranches(h.db, employeeIDs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch team"})
+	}
+	calendar, err := holidays.Load(h.db, from, to)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch holidays"})
+	}
+	observed := calendar.ForBranches(holidays.BranchIDs(branches))
+
+	days, err := teamCalendarDays(h.db, employeeIDs, from, to)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to build calendar"})
+	}
+	for _, holiday := range observed {
+		if day, ok := days[holiday.Date.Format(dateLayout)]; ok {
+			day.IsHoliday = true
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"team_size": len(employeeIDs),
+		"leave":     entries,
+		"holidays":  observed,
+		"days":      sortedCalendarDays(days),
+	})
+}
+
+// teamCalendarDays builds a per-date view of who is away from the
+// materialised leave dates.
+func teamCalendarDays(db *gorm.DB, employeeIDs []int, from, to time.Time) (map[string]*calendarDay, error) {
+	days := map[string]*calendarDay{}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		key := date.Format(dateLayout)
+		days[key] = &calendarDay{Date: key, Approved: []int{}, Pending: []int{}}
+	}
+	if len(employeeIDs) == 0 {
+		return days, nil
+	}
+
+	var leaveDays []models.LeaveApplicationDay
+	if err := db.Where("employee_id IN ? AND status IN ? AND date BETWEEN ? AND ?",
+		employeeIDs, []string{statusApproved, statusPending}, from, to).
+		Find(&leaveDays).Error; err != nil {
+		return nil, err
+	}
+
+	for _, leaveDay := range leaveDays {
+		day, ok := days[leaveDay.Date.Format(dateLayout)]
+		if !ok {
+			continue
+		}
+		if leaveDay.Status == statusApproved {
+			day.Approved = append(day.Approved, leaveDay.EmployeeID)
+		} else {
+			day.Pending = append(day.Pending, leaveDay.EmployeeID)
+		}
+	}
+
+	for _, day := range days {
+		day.Share = roundDays(float64(len(day.Approved)+len(day.Pending)) / float64(len(employeeIDs)))
+	}
+	return days, nil
+}
+
+func sortedCalendarDays(days map[string]*calendarDay) []*calendarDay {
+	sorted := make([]*calendarDay, 0, len(days))
+	for _, day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+	return sorted
+}
+
+// detectLeaveConflicts reports the dates on which the applicant's department
+// would have more than the configured share of its members away.
+func detectLeaveConflicts(db *gorm.DB, application *models.LeaveApplication, days []models.LeaveApplicationDay) ([]leaveConflict, error) {
+	var employeeGrade models.EmployeeGrade
+	if err := db.Where("employee_id = ? AND is_current = ? AND deleted = ?", application.EmployeeID, 1, 0).
+		Limit(1).
+		Find(&employeeGrade).Error; err != nil {
+		return nil, err
+	}
+	if employeeGrade.DepartmentID == nil {
+		return nil, nil
+	}
+
+	var employeeIDs []int
+	if err := db.Model(&models.EmployeeGrade{}).
+		Where("department_id = ? AND is_current = ? AND deleted = ?", *employeeGrade.DepartmentID, 1, 0).
+		Distinct().
+		Pluck("employee_id", &employeeIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(employeeIDs) < 2 {
+		return nil, nil
+	}
+
+	threshold, err := leaveConflictShare(db)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]time.Time, 0, len(days))
+	for _, day := range days {
+		dates = append(dates, day.Date)
+	}
+
+	type awayCount struct {
+		Date  time.Time
+		Count int
+	}
+	var counts []awayCount
+	if err := db.Model(&models.LeaveApplicationDay{}).
+		Select("date, COUNT(DISTINCT employee_id) AS count").
+		Where("employee_id IN ? AND employee_id <> ? AND status IN ? AND date IN ?",
+			employeeIDs, application.EmployeeID, []string{statusApproved, statusPending}, dates).
+		Group("date").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	// Every requested date counts the applicant, even when nobody else is away
+	away := make(map[string]int, len(dates))
+	for _, date := range dates {
+		away[date.Format(dateLayout)] = 1
+	}
+	for _, count := range counts {
+		away[count.Date.Format(dateLayout)] += count.Count
+	}
+
+	var conflicts []leaveConflict
+	for date, count := range away {
+		share := float64(count) / float64(len(employeeIDs))
+		if share > threshold {
+			conflicts = append(conflicts, leaveConflict{
+				Date:      date,
+				Away:      count,
+				TeamSize:  len(employeeIDs),
+				Share:     roundDays(share),
+				Threshold: threshold,
+			})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Date < conflicts[j].Date })
+	return conflicts, nil
+}
+
+func leaveConflictShare(db *gorm.DB) (float64, error) {
+	var setting models.Setting
+	if err := db.Where("deleted = ?", 0).Limit(1).Find(&setting).Error; err != nil {
+		return 0, err
+	}
+	if setting.LeaveConflictShare != nil && *setting.LeaveConflictShare > 0 {
+		return *setting.LeaveConflictShare, nil
+	}
+	return defaultLeaveConflictShare, nil
+}
+
+func (h *Handler) GetLeaveConflicts(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var application models.LeaveApplication
+	if err := h.db.First(&application, "id = ?", id).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Leave application not found"})
+	}
+
+	var days []models.LeaveApplicationDay
+	if err := h.db.Where("leave_application_id = ? AND status IN ?",
+		application.ID.String(), []string{statusApproved, statusPending}).
+		Find(&days).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch leave days"})
+	}
+
+	conflicts := []leaveConflict{}
+	if len(days) > 0 {
+		found, err := detectLeaveConflicts(h.db, &application, days)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to check leave conflicts"})
+		}
+		conflicts = append(conflicts, found...)
+	}
+
+	return c.JSON(fiber.Map{
+		"application_id": application.ID.String(),
+		"conflicts":      conflicts,
+	})
+}
@@ -0,0 +1,314 @@
+package leave
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	encashmentExit    = "exit"
+	encashmentYearEnd = "year_end"
+)
+
+var (
+	errNoEncashmentEarningType = errors.New("no leave encashment earning type is configured in settings")
+	errNotExited               = errors.New("employee has no exit recorded")
+	errNoDailyRate             = errors.New("employee has no basic salary or staff type days per month to derive a daily rate")
+)
+
+// encashUnusedOnExit pays out every encashable balance left on the
+// employee's exit date. Nothing carries forward for a leaver, so the whole
+// balance is above the limit.
+func encashUnusedOnExit(db *gorm.DB, employeeID int) ([]models.LeaveEncashment, error) {
+	var encashments []models.LeaveEncashment
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		exit, err := employeeExitDate(tx, employeeID)
+		if err != nil {
+			return err
+		}
+		if exit == nil {
+			return errNotExited
+		}
+
+		// the final month is accrued up to the exit date before it is paid out
+		exitDate := *exit
+		balances, err := RecomputeBalances(tx, employeeID, exitDate)
+		if err != nil {
+			return err
+		}
+
+		encashable, err := encashableLeaveTypes(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, balance := range balances {
+			if !encashable[balance.LeaveTypeID] || balance.Year != exitDate.Year() || balance.BalanceDays <= 0 {
+				continue
+			}
+			encashment, err := encashDays(tx, balance.EmployeeID, balance.LeaveTypeID, balance.Year,
+				encashmentExit, balance.BalanceDays, exitDate)
+			if err != nil {
+				return err
+			}
+			if encashment != nil {
+				encashments = append(encashments, *encashment)
+			}
+		}
+
+		if len(encashments) == 0 {
+			return nil
+		}
+		_, err = RecomputeBalances(tx, employeeID, exitDate)
+		return err
+	})
+
+	return encashments, err
+}
+
+// encashYearEnd pays out the days of a closed year that exceed the
+// carry-forward limit of encashable leave types instead of forfeiting them.
+func encashYearEnd(db *gorm.DB, employeeID, year int, asOf time.Time) ([]models.LeaveEncashment, error) {
+	var encashments []models.LeaveEncashment
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := RecomputeBalances(tx, employeeID, asOf); err != nil {
+			return err
+		}
+
+		encashable, err := encashableLeaveTypes(tx)
+		if err != nil {
+			return err
+		}
+
+		var forfeitures []models.LeaveForfeiture
+		if err := tx.Where("employee_id = ? AND year = ? AND days > 0 AND deleted = ?", employeeID, year, 0).
+			Find(&forfeitures).Error; err != nil {
+			return err
+		}
+
+		yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+		for _, forfeiture := range forfeitures {
+			if !encashable[forfeiture.LeaveTypeID] {
+				continue
+			}
+			encashment, err := encashDays(tx, employeeID, forfeiture.LeaveTypeID, year,
+				encashmentYearEnd, forfeiture.Days, yearEnd)
+			if err != nil {
+				return err
+			}
+			if encashment != nil {
+				encashments = append(encashments, *encashment)
+			}
+		}
+
+		if len(encashments) == 0 {
+			return nil
+		}
+		_, err = RecomputeBalances(tx, employeeID, asOf)
+		return err
+	})
+
+	return encashments, err
+}
+
+// encashDays records an encashment and the earning that pays it. It returns
+// nil if the balance has already been encashed for the same reason.
+func encashDays(tx *gorm.DB, employeeID int, leaveTypeID string, year int, reason string, days float64, on time.Time) (*models.LeaveEncashment, error) {
+	var count int64
+	if err := tx.Model(&models.LeaveEncashment{}).
+		Where("employee_id = ? AND leave_type_id = ? AND year = ? AND reason = ? AND deleted = ?",
+			employeeID, leaveTypeID, year, reason, 0).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, nil
+	}
+
+	earningTypeID, err := encashmentEarningType(tx)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := dailyRate(tx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	earning := models.Earning{
+		EmployeeID:    employeeID,
+		EarningTypeID: earningTypeID,
+		Amount:        roundDays(days * rate),
+	}
+	if err := tx.Create(&earning).Error; err != nil {
+		return nil, err
+	}
+
+	encashment := models.LeaveEncashment{
+		EmployeeID:  employeeID,
+		LeaveTypeID: leaveTypeID,
+		Year:        year,
+		Reason:      reason,
+		Days:        days,
+		DailyRate:   roundDays(rate),
+		Amount:      earning.Amount,
+		EarningID:   earning.ID.String(),
+		EncashedOn:  on,
+	}
+	if err := tx.Create(&encashment).Error; err != nil {
+		return nil, err
+	}
+	return &encashment, nil
+}
+
+// dailyRate divides the employee's latest basic salary by the working days
+// per month of their staff type.
+func dailyRate(tx *gorm.DB, employeeID int) (float64, error) {
+	var employeeGrade models.EmployeeGrade
+	if err := tx.Where("employee_id = ? AND deleted = ?", employeeID, 0).
+		Order("is_current DESC, start_date DESC").
+		Limit(1).
+		Find(&employeeGrade).Error; err != nil {
+		return 0, err
+	}
+	if employeeGrade.BasicSalary == nil || employeeGrade.StaffTypeID == nil {
+		return 0, fmt.Errorf("employee %d: %w", employeeID, errNoDailyRate)
+	}
+
+	var staffType models.StaffType
+	if err := tx.First(&staffType, *employeeGrade.StaffTypeID).Error; err != nil {
+		return 0, err
+	}
+	if staffType.DaysPerMonth == nil || *staffType.DaysPerMonth <= 0 {
+		return 0, fmt.Errorf("employee %d: %w", employeeID, errNoDailyRate)
+	}
+
+	return *employeeGrade.BasicSalary / float64(*staffType.DaysPerMonth), nil
+}
+
+func encashmentEarningType(tx *gorm.DB) (int, error) {
+	var setting models.Setting
+	if err := tx.Where("deleted = ?", 0).Limit(1).Find(&setting).Error; err != nil {
+		return 0, err
+	}
+	if setting.LeaveEncashmentEarningTypeID == nil {
+		return 0, errNoEncashmentEarningType
+	}
+	return *setting.LeaveEncashmentEarningTypeID, nil
+}
+
+func encashableLeaveTypes(tx *gorm.DB) (map[string]bool, error) {
+	var leaveTypes []models.LeaveType
+	if err := tx.Where("is_encashable = ? AND deleted = ?", 1, 0).Find(&leaveTypes).Error; err != nil {
+		return nil, err
+	}
+
+	encashable := make(map[string]bool, len(leaveTypes))
+	for _, leaveType := range leaveTypes {
+		encashable[leaveType.ID.String()] = true
+	}
+	return encashable, nil
+}
+
+func isEncashmentRefusal(err error) bool {
+	return errors.Is(err, errNoEncashmentEarningType) ||
+		errors.Is(err, errNotExited) ||
+		errors.Is(err, errNoDailyRate) ||
+		errors.Is(err, errNoGradeHistory)
+}
+
+func (h *Handler) GetLeaveEncashments(c *fiber.Ctx) error {
+	query := h.db.Where("deleted = ?", 0)
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		query = query.Where("employee_id = ?", employeeID)
+	}
+	if year := c.Query("year"); year != "" {
+		query = query.Where("year = ?", year)
+	}
+	if reason := c.Query("reason"); reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+
+	var encashments []models.LeaveEncashment
+	if err := query.Order("encashed_on DESC").Find(&encashments).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch leave encashments"})
+	}
+
+	return c.JSON(encashments)
+}
+
+// EncashLeaveOnExit pays out the remaining leave of an employee whose exit
+// has been recorded.
+func (h *Handler) EncashLeaveOnExit(c *fiber.Ctx) error {
+	employeeID, err := strconv.Atoi(c.Params("employeeId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid employee ID"})
+	}
+
+	encashments, err := encashUnusedOnExit(h.db, employeeID)
+	if err != nil {
+		if isEncashmentRefusal(err) {
+			return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to encash leave"})
+	}
+	if encashments == nil {
+		encashments = []models.LeaveEncashment{}
+	}
+
+	return c.JSON(encashments)
+}
+
+// RunYearEndEncashment encashes the excess over the carry-forward limit for
+// every employee with a balance in a closed year.
+func (h *Handler) RunYearEndEncashment(c *fiber.Ctx) error {
+	asOf := truncateDay(time.Now())
+	year, err := strconv.Atoi(c.Query("year", strconv.Itoa(asOf.Year()-1)))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid year"})
+	}
+	if year >= asOf.Year() {
+		return c.Status(422).JSON(fiber.Map{"error": "Only a year that has ended can be encashed"})
+	}
+	if _, err := encashmentEarningType(h.db); err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// leavers are paid out in full by EncashLeaveOnExit
+	leavers := h.db.Model(&models.EmployeeTrash{}).
+		Select("employee_id").
+		Where("activated_date IS NULL AND deleted = ?", 0)
+
+	var employeeIDs []int
+	if err := h.db.Model(&models.LeaveBalance{}).
+		Where("year = ? AND deleted = ? AND employee_id NOT IN (?)", year, 0, leavers).
+		Distinct().
+		Pluck("employee_id", &employeeIDs).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch employees"})
+	}
+
+	encashed := []models.LeaveEncashment{}
+	failed := map[int]string{}
+	for _, employeeID := range employeeIDs {
+		encashments, err := encashYearEnd(h.db, employeeID, year, asOf)
+		if err != nil {
+			failed[employeeID] = err.Error()
+			continue
+		}
+		encashed = append(encashed, encashments...)
+	}
+
+	return c.JSON(fiber.Map{
+		"year":      year,
+		"encashed":  encashed,
+		"failed":    failed,
+		"employees": len(employeeIDs),
+	})
+}
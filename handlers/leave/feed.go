@@ -0,0 +1,315 @@
+package leave
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"yathuerp/handlers/holidays"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	feedScopeEmployee   = "employee"
+	feedScopeDepartment = "department"
+
+	// feeds cover a window around today so that calendar apps polling them
+	// stay fast as leave history grows
+	feedDaysBack    = 90
+	feedDaysForward = 365
+
+	icsDateLayout  = "20060102"
+	icsStampLayout = "20060102T150405Z"
+)
+
+type calendarFeedRequest struct {
+	Scope        string `json:"scope"`
+	DepartmentID *int   `json:"department_id"`
+}
+
+// calendarFeedResponse is returned once on creation; only the token's hash is
+// stored, so the URL cannot be shown again.
+type calendarFeedResponse struct {
+	models.LeaveCalendarFeed
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// feedEmployee is the part of tbl_employees shown on department feeds
+type feedEmployee struct {
+	ID         int
+	FirstName  string
+	MiddleName string
+	LastName   string
+}
+
+func (h *Handler) GetCalendarFeeds(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var feeds []models.LeaveCalendarFeed
+	if err := h.db.Where("user_id = ? AND deleted = ?", userID, 0).
+		Order("created_at").
+		Find(&feeds).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch calendar feeds"})
+	}
+
+	return c.JSON(feeds)
+}
+
+// CreateCalendarFeed issues a secret feed URL for the current user's own
+// leave or, for HR and the department's manager, for a department, to be
+// added to an external calendar app.
+func (h *Handler) CreateCalendarFeed(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req calendarFeedRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	feed := models.LeaveCalendarFeed{Scope: req.Scope, UserID: userID}
+	switch req.Scope {
+	case feedScopeEmployee:
+		var user models.User
+		if err := h.db.Where("id = ? AND deleted = ?", userID, 0).First(&user).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+		}
+		if user.EmployeeID == nil {
+			return c.Status(422).JSON(fiber.Map{"error": "Your user account is not linked to an employee"})
+		}
+		feed.EmployeeID = user.EmployeeID
+	case feedScopeDepartment:
+		if req.DepartmentID == nil {
+			return c.Status(400).JSON(fiber.Map{"error": "department_id is required for department feeds"})
+		}
+		var count int64
+		if err := h.db.Model(&models.Department{}).
+			Where("id = ? AND deleted = ?", *req.DepartmentID, 0).
+			Count(&count).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch department"})
+		}
+		if count == 0 {
+			return c.Status(404).JSON(fiber.Map{"error": "Department not found"})
+		}
+		allowed, err := isHR(h.db, userID)
+		if err == nil && !allowed {
+			allowed, err = managesDepartment(h.db, userID, *req.DepartmentID)
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to check permissions"})
+		}
+		if !allowed {
+			return c.Status(403).JSON(fiber.Map{"error": "Only HR or the department's manager can create a department feed"})
+		}
+		feed.DepartmentID = req.DepartmentID
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "scope must be employee or department"})
+	}
+
+	token, err := newFeedToken()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate feed token"})
+	}
+	feed.TokenHash = hashFeedToken(token)
+
+	if err := h.db.Create(&feed).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create calendar feed"})
+	}
+
+	return c.Status(201).JSON(calendarFeedResponse{
+		LeaveCalendarFeed: feed,
+		Token:             token,
+		URL:               fmt.Sprintf("%s/api/v1/calendar/feeds/%s.ics", c.BaseURL(), token),
+	})
+}
+
+// DeleteCalendarFeed revokes a feed; calendar apps subscribed to it stop
+// receiving updates.
+func (h *Handler) DeleteCalendarFeed(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	result := h.db.Where("id = ? AND user_id = ?", c.Params("id"), userID).
+		Delete(&models.LeaveCalendarFeed{})
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete calendar feed"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Calendar feed not found"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Calendar feed deleted successfully"})
+}
+
+// GetCalendarFeed serves a feed as iCalendar. It is public: the token in the
+// URL is the only credential.
+func (h *Handler) GetCalendarFeed(c *fiber.Ctx) error {
+	var feed models.LeaveCalendarFeed
+	if err := h.db.Where("token_hash = ? AND deleted = ?", hashFeedToken(c.Params("token")), 0).
+		First(&feed).Error; err != nil {
+		return c.Status(404).SendString("Calendar feed not found")
+	}
+
+	now := time.Now()
+	from := truncateDay(now).AddDate(0, 0, -feedDaysBack)
+	to := truncateDay(now).AddDate(0, 0, feedDaysForward)
+
+	var employeeIDs []int
+	switch feed.Scope {
+	case feedScopeEmployee:
+		if feed.EmployeeID != nil {
+			employeeIDs = []int{*feed.EmployeeID}
+		}
+	case feedScopeDepartment:
+		if err := h.db.Model(&models.EmployeeGrade{}).
+			Where("department_id = ? AND is_current = ? AND deleted = ?", feed.DepartmentID, 1, 0).
+			Distinct().
+			Pluck("employee_id", &employeeIDs).Error; err != nil {
+			return c.Status(500).SendString("Failed to fetch department")
+		}
+	}
+
+	var applications []models.LeaveApplication
+	if len(employeeIDs) > 0 {
+		if err := h.db.Where("employee_id IN ? AND application_status = ? AND deleted = ?",
+			employeeIDs, statusApproved, 0).
+			Where("start_date <= ? AND end_date >= ?", to, from).
+			Order("start_date").
+			Find(&applications).Error; err != nil {
+			return c.Status(500).SendString("Failed to fetch leave applications")
+		}
+	}
+
+	branches, err := holidays.EmployeeBranches(h.db, employeeIDs)
+	if err != nil {
+		return c.Status(500).SendString("Failed to fetch holidays")
+	}
+	calendar, err := holidays.Load(h.db, from, to)
+	if err != nil {
+		return c.Status(500).SendString("Failed to fetch holidays")
+	}
+	observed := calendar.ForBranches(holidays.BranchIDs(branches))
+
+	leaveTypes := map[string]string{}
+	names := map[int]string{}
+	switch {
+	case feed.Scope == feedScopeEmployee:
+		var types []models.LeaveType
+		if err := h.db.Find(&types).Error; err != nil {
+			return c.Status(500).SendString("Failed to fetch leave types")
+		}
+		for _, leaveType := range types {
+			leaveTypes[leaveType.ID.String()] = leaveType.Name
+		}
+	case len(employeeIDs) > 0:
+		var employees []feedEmployee
+		if err := h.db.Model(&models.Employee{}).
+			Select("id, first_name, middle_name, last_name").
+			Where("id IN ?", employeeIDs).
+			Scan(&employees).Error; err != nil {
+			return c.Status(500).SendString("Failed to fetch employees")
+		}
+		for _, employee := range employees {
+			names[employee.ID] = strings.Join(strings.Fields(
+				employee.FirstName+" "+employee.MiddleName+" "+employee.LastName), " ")
+		}
+	}
+
+	// best effort, a failed stamp should not break the subscriber's calendar
+	h.db.Model(&feed).UpdateColumn("last_access_at", now)
+
+	var ics icsWriter
+	ics.line("BEGIN:VCALENDAR")
+	ics.line("VERSION:2.0")
+	ics.line("PRODID:-//YathuERP//Leave Calendar//EN")
+	ics.line("CALSCALE:GREGORIAN")
+	ics.line("METHOD:PUBLISH")
+	ics.property("X-WR-CALNAME", "Leave")
+	stamp := now.UTC().Format(icsStampLayout)
+
+	for _, application := range applications {
+		if application.StartDate == nil || application.EndDate == nil {
+			continue
+		}
+		// department feeds are seen by colleagues, so they leave out the
+		// leave type (e.g. sick or maternity leave)
+		summary := leaveTypes[application.LeaveTypeID]
+		if summary == "" || feed.Scope == feedScopeDepartment {
+			summary = "On leave"
+		}
+		if name := names[application.EmployeeID]; name != "" {
+			summary = name + " - " + summary
+		}
+		ics.allDayEvent("leave-"+application.ID.String(), stamp, summary,
+			*application.StartDate, *application.EndDate)
+	}
+	for _, holiday := range observed {
+		ics.allDayEvent("holiday-"+holiday.HolidayID+"-"+holiday.Date.Format(dateLayout), stamp, holiday.Title,
+			holiday.Date, holiday.Date)
+	}
+	ics.line("END:VCALENDAR")
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=900")
+	return c.SendString(ics.String())
+}
+
+func newFeedToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// icsWriter builds an RFC 5545 document with CRLF line endings and lines
+// folded at 75 octets.
+type icsWriter struct {
+	strings.Builder
+}
+
+func (w *icsWriter) line(s string) {
+	for len(s) > 75 {
+		cut := 75
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut-- // don't split a UTF-8 sequence
+		}
+		w.WriteString(s[:cut] + "\r\n")
+		s = " " + s[cut:]
+	}
+	w.WriteString(s + "\r\n")
+}
+
+func (w *icsWriter) property(name, value string) {
+	value = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+	w.line(name + ":" + value)
+}
+
+// allDayEvent writes an event covering start to end inclusive
+func (w *icsWriter) allDayEvent(uid, stamp, summary string, start, end time.Time) {
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + uid + "@yathuerp")
+	w.line("DTSTAMP:" + stamp)
+	w.line("DTSTART;VALUE=DATE:" + start.Format(icsDateLayout))
+	w.line("DTEND;VALUE=DATE:" + truncateDay(end).AddDate(0, 0, 1).Format(icsDateLayout))
+	w.property("SUMMARY", summary)
+	w.line("TRANSP:TRANSPARENT")
+	w.line("END:VEVENT")
+}
@@ -1,11 +1,20 @@
 package leave
 
 import (
+	"errors"
 	"strconv"
+	"time"
 	"yathuerp/models"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	statusPending  = "pending"
+	statusApproved = "approved"
+	statusRejected = "rejected"
 )
 
 type Handler struct {
@@ -67,16 +76,44 @@ func (h *Handler) GetLeaveApplicationByID(c *fiber.Ctx) error {
 }
 
 func (h *Handler) CreateLeaveApplication(c *fiber.Ctx) error {
-	var application models.LeaveApplication
-	if err := c.BodyParser(&application); err != nil {
+	var req leaveApplicationRequest
+	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	if err := h.db.Create(&application).Error; err != nil {
+	application := req.LeaveApplication
+	application.ApplicationStatus = statusPending
+	if application.DateApplied == nil {
+		now := time.Now()
+		application.DateApplied = &now
+	}
+
+	days, err := planLeaveDays(h.db, &application, req.HalfDays)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	application.DaysApplied = sumLeaveDays(days)
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&application).Error; err != nil {
+			return err
+		}
+		if err := replaceLeaveDays(tx, &application, days); err != nil {
+			return err
+		}
+		return startApprovalWorkflow(tx, &application)
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to create leave application"})
 	}
 
-	return c.Status(201).JSON(application)
+	// Conflicts are a warning for the approver, not a reason to refuse the submission
+	conflicts, _ := detectLeaveConflicts(h.db, &application, days)
+
+	return c.Status(201).JSON(leaveApplicationResponse{
+		LeaveApplication: application,
+		Conflicts:        conflicts,
+	})
 }
 
 func (h *Handler) UpdateLeaveApplication(c *fiber.Ctx) error {
@@ -87,45 +124,237 @@ func (h *Handler) UpdateLeaveApplication(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Leave application not found"})
 	}
 
-	if err := c.BodyParser(&application); err != nil {
+	if application.ApplicationStatus != statusPending {
+		return c.Status(422).JSON(fiber.Map{"error": "Only pending applications can be updated"})
+	}
+
+	req := leaveApplicationRequest{LeaveApplication: application}
+	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	if err := h.db.Save(&application).Error; err != nil {
+	// Status, approver and grant fields only change through the approval workflow
+	leaveTypeChanged := req.LeaveTypeID != application.LeaveTypeID
+	application.StartDate = req.StartDate
+	application.EndDate = req.EndDate
+	application.LeaveTypeID = req.LeaveTypeID
+	application.Comment = req.Comment
+
+	days, err := planLeaveDays(h.db, &application, req.HalfDays)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	application.DaysApplied = sumLeaveDays(days)
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&application).Error; err != nil {
+			return err
+		}
+		if err := replaceLeaveDays(tx, &application, days); err != nil {
+			return err
+		}
+		if !leaveTypeChanged {
+			return nil
+		}
+		// The approval chain belongs to the leave type, so start the new type's chain over
+		if err := closeApprovals(tx, &application, approvalCancelled); err != nil {
+			return err
+		}
+		return startApprovalWorkflow(tx, &application)
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update leave application"})
 	}
 
 	return c.JSON(application)
 }
 
-func (h *Handler) ApproveLeaveApplication(c *fiber.Ctx) error {
+func (h *Handler) GetLeaveApplicationDays(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	var application models.LeaveApplication
-	if err := h.db.First(&application, id).Error; err != nil {
+	if err := h.db.First(&application, "id = ?", id).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Leave application not found"})
 	}
 
-	application.ApplicationStatus = "approved"
+	var days []models.LeaveApplicationDay
+	if err := h.db.Where("leave_application_id = ?", application.ID.String()).
+		Order("date").
+		Find(&days).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch leave days"})
+	}
+
+	return c.JSON(days)
+}
+
+func (h *Handler) ApproveLeaveApplication(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	approverID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req leaveDecisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+	}
 
-	if err := h.db.Save(&application).Error; err != nil {
+	var application models.LeaveApplication
+	final := true
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		// the row lock makes a concurrent approval wait and then see the
+		// application is no longer pending, so leave is only deducted once
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&application, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		approverEmployeeID, err := employeeOfUser(tx, approverID)
+		if err != nil {
+			return err
+		}
+		if approverEmployeeID == application.EmployeeID {
+			return errSelfApproval
+		}
+		if err := checkApproval(tx, &application); err != nil {
+			return err
+		}
+
+		approval, err := currentApproval(tx, &application)
+		if err != nil {
+			return err
+		}
+		if err := canDecide(tx, &application, approval, approverID); err != nil {
+			return err
+		}
+		if approval != nil {
+			if final, err = approveStep(tx, &application, approval, approverID, req.Reason); err != nil || !final {
+				return err
+			}
+		}
+
+		application.ApplicationStatus = statusApproved
+		application.ApplicationStatusBy = &approverID
+		application.ApplicationStatusReason = req.Reason
+		application.IsActive = 1
+
+		if err := tx.Save(&application).Error; err != nil {
+			return err
+		}
+		if err := syncLeaveDayStatus(tx, &application); err != nil {
+			return err
+		}
+		if err := deductLeave(tx, &application); err != nil {
+			return err
+		}
+
+		step := 0
+		if approval != nil {
+			step = approval.StepOrder
+		}
+		return recordEvent(tx, eventApproved, &application, leaveApplicationApprovedEvent{
+			ApplicationID: application.ID.String(),
+			EmployeeID:    application.EmployeeID,
+			ApproverID:    approverID,
+			Step:          step,
+			Final:         true,
+			Timestamp:     time.Now(),
+		})
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Leave application not found"})
+	}
+	var refusal *approvalError
+	if errors.As(err, &refusal) {
+		return c.Status(422).JSON(fiber.Map{"error": refusal.Error()})
+	}
+	if errors.Is(err, errNotApprover) || errors.Is(err, errNotDecider) || errors.Is(err, errSelfApproval) {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to approve leave application"})
 	}
 
+	if !final {
+		return c.JSON(fiber.Map{"message": "Approval recorded, application forwarded to the next approver"})
+	}
 	return c.JSON(fiber.Map{"message": "Leave application approved successfully"})
 }
 
 func (h *Handler) RejectLeaveApplication(c *fiber.Ctx) error {
 	id := c.Params("id")
 
+	approverID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req leaveDecisionRequest
+	if err := c.BodyParser(&req); err != nil || req.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "A rejection reason is required"})
+	}
+
 	var application models.LeaveApplication
-	if err := h.db.First(&application, id).Error; err != nil {
+	if err := h.db.First(&application, "id = ?", id).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Leave application not found"})
 	}
 
-	application.ApplicationStatus = "rejected"
+	if application.ApplicationStatus != statusPending {
+		return c.Status(422).JSON(fiber.Map{"error": "Only pending applications can be rejected"})
+	}
 
-	if err := h.db.Save(&application).Error; err != nil {
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		approval, err := currentApproval(tx, &application)
+		if err != nil {
+			return err
+		}
+		if err := canDecide(tx, &application, approval, approverID); err != nil {
+			return err
+		}
+
+		step := 0
+		if approval != nil {
+			now := time.Now()
+			approval.Status = approvalRejected
+			approval.ActedBy = &approverID
+			approval.ActedAt = &now
+			approval.Notes = req.Reason
+			if err := tx.Save(approval).Error; err != nil {
+				return err
+			}
+			if err := closeApprovals(tx, &application, approvalCancelled); err != nil {
+				return err
+			}
+			step = approval.StepOrder
+		}
+
+		application.ApplicationStatus = statusRejected
+		application.ApplicationStatusBy = &approverID
+		application.ApplicationStatusReason = req.Reason
+
+		if err := tx.Save(&application).Error; err != nil {
+			return err
+		}
+		if err := syncLeaveDayStatus(tx, &application); err != nil {
+			return err
+		}
+		return recordEvent(tx, eventRejected, &application, leaveApplicationRejectedEvent{
+			ApplicationID: application.ID.String(),
+			EmployeeID:    application.EmployeeID,
+			ApproverID:    approverID,
+			Reason:        req.Reason,
+			Step:          step,
+			Timestamp:     time.Now(),
+		})
+	})
+	if errors.Is(err, errNotApprover) || errors.Is(err, errNotDecider) {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to reject leave application"})
 	}
 
@@ -0,0 +1,252 @@
+package leave
+
+import (
+	"errors"
+	"time"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	statusCancelled = "cancelled"
+
+	actionCancelled   = "cancelled"
+	actionRecalled    = "recalled"
+	actionEarlyReturn = "early_return"
+)
+
+var errNothingToRestore = errors.New("no leave days remain after the resume date")
+
+// resumeRequest is the body of recall and early-return calls
+type resumeRequest struct {
+	ResumeDate string `json:"resume_date"` // first day back at work, YYYY-MM-DD
+	Reason     string `json:"reason"`
+}
+
+// CancelLeaveApplication lets the applicant withdraw a pending or approved
+// application before the leave has started.
+func (h *Handler) CancelLeaveApplication(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req leaveDecisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+	}
+
+	var application models.LeaveApplication
+	if err := h.db.First(&application, "id = ?", id).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Leave application not found"})
+	}
+
+	allowed, err := isApplicant(h.db, userID, &application)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check permissions"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Only the applicant can cancel their leave application"})
+	}
+
+	if application.ApplicationStatus != statusPending && application.ApplicationStatus != statusApproved {
+		return c.Status(422).JSON(fiber.Map{"error": "Only pending or approved applications can be cancelled"})
+	}
+	if application.StartDate == nil || !truncateDay(time.Now()).Before(truncateDay(*application.StartDate)) {
+		return c.Status(422).JSON(fiber.Map{"error": "Leave that has already started must be recalled or ended early instead"})
+	}
+
+	wasApproved := application.ApplicationStatus == statusApproved
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		days := application.DaysApplied
+
+		application.ApplicationStatus = statusCancelled
+		application.IsActive = 0
+		if req.Reason != "" {
+			application.ApplicationStatusReason = req.Reason
+		}
+		if err := tx.Save(&application).Error; err != nil {
+			return err
+		}
+		if err := syncLeaveDayStatus(tx, &application); err != nil {
+			return err
+		}
+		if err := closeApprovals(tx, &application, approvalCancelled); err != nil {
+			return err
+		}
+
+		if !wasApproved {
+			return nil
+		}
+		if err := adjustLeaveUsage(tx, &application, -days); err != nil {
+			return err
+		}
+		return recoverLeaveGrant(tx, &application, actionCancelled, days, days)
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to cancel leave application"})
+	}
+
+	return c.JSON(application)
+}
+
+// RecallLeaveApplication brings an employee back from approved leave at the
+// employer's request. Only HR or the employee's manager can recall leave.
+func (h *Handler) RecallLeaveApplication(c *fiber.Ctx) error {
+	return h.shortenLeave(c, actionRecalled)
+}
+
+// ReturnEarlyFromLeave records the applicant resuming work before their
+// approved leave ends.
+func (h *Handler) ReturnEarlyFromLeave(c *fiber.Ctx) error {
+	return h.shortenLeave(c, actionEarlyReturn)
+}
+
+func (h *Handler) shortenLeave(c *fiber.Ctx, action string) error {
+	id := c.Params("id")
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req resumeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	resumeDate, err := time.Parse(dateLayout, req.ResumeDate)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid resume_date, expected YYYY-MM-DD"})
+	}
+
+	var application models.LeaveApplication
+	if err := h.db.First(&application, "id = ?", id).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Leave application not found"})
+	}
+
+	var allowed bool
+	refusal := "Only the applicant can end their leave early"
+	if action == actionRecalled {
+		allowed, err = isHROrManagerOf(h.db, userID, application.EmployeeID)
+		refusal = "Only HR or the employee's manager can recall leave"
+	} else {
+		allowed, err = isApplicant(h.db, userID, &application)
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check permissions"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": refusal})
+	}
+
+	if application.ApplicationStatus != statusApproved || application.StartDate == nil || application.EndDate == nil {
+		return c.Status(422).JSON(fiber.Map{"error": "Only approved leave can be shortened"})
+	}
+	start := truncateDay(*application.StartDate)
+	end := truncateDay(*application.EndDate)
+	if !resumeDate.After(start) || resumeDate.After(end) {
+		return c.Status(422).JSON(fiber.Map{"error": "resume_date must fall after the first day and on or before the last day of the leave"})
+	}
+
+	var restored float64
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var unused []models.LeaveApplicationDay
+		if err := tx.Where("leave_application_id = ? AND date >= ? AND status = ?",
+			application.ID.String(), resumeDate, statusApproved).
+			Find(&unused).Error; err != nil {
+			return err
+		}
+		restored = sumLeaveDays(unused)
+		if restored == 0 {
+			return errNothingToRestore
+		}
+
+		if err := tx.Model(&models.LeaveApplicationDay{}).
+			Where("leave_application_id = ? AND date >= ?", application.ID.String(), resumeDate).
+			Update("status", statusCancelled).Error; err != nil {
+			return err
+		}
+
+		originalDays := application.DaysApplied
+		newEnd := resumeDate.AddDate(0, 0, -1)
+		application.EndDate = &newEnd
+		application.DaysApplied = originalDays - restored
+		if req.Reason != "" {
+			application.ApplicationStatusReason = req.Reason
+		}
+		if err := tx.Save(&application).Error; err != nil {
+			return err
+		}
+
+		if err := adjustLeaveUsage(tx, &application, -restored); err != nil {
+			return err
+		}
+		return recoverLeaveGrant(tx, &application, action, restored, originalDays)
+	})
+	if errors.Is(err, errNothingToRestore) {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update leave application"})
+	}
+
+	return c.JSON(fiber.Map{
+		"application":   application,
+		"restored_days": restored,
+	})
+}
+
+// recoverLeaveGrant raises a payroll adjustment for the unused share of a
+// leave grant that has already gone out on a salary.
+func recoverLeaveGrant(tx *gorm.DB, application *models.LeaveApplication, action string, restoredDays, originalDays float64) error {
+	if application.LeaveGrantAmount <= 0 || originalDays <= 0 {
+		return nil
+	}
+
+	paid := tx.Model(&models.Salary{}).
+		Where("employee_id = ? AND deleted = ? AND leave_grant > 0", application.EmployeeID, 0)
+	if application.DateApplied != nil {
+		paid = paid.Where("date_added >= ?", application.DateApplied)
+	}
+
+	var count int64
+	if err := paid.Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	return tx.Create(&models.LeaveGrantAdjustment{
+		EmployeeID:         application.EmployeeID,
+		LeaveApplicationID: application.ID.String(),
+		Action:             action,
+		Days:               restoredDays,
+		Amount:             roundDays(application.LeaveGrantAmount * restoredDays / originalDays),
+		Status:             statusPending,
+	}).Error
+}
+
+func (h *Handler) GetLeaveGrantAdjustments(c *fiber.Ctx) error {
+	query := h.db.Where("deleted = ?", 0)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		query = query.Where("employee_id = ?", employeeID)
+	}
+
+	var adjustments []models.LeaveGrantAdjustment
+	if err := query.Order("created_at").Find(&adjustments).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch leave grant adjustments"})
+	}
+
+	return c.JSON(adjustments)
+}
@@ -0,0 +1,54 @@
+package leave
+
+import (
+	"strconv"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type managerRequest struct {
+	ManagerID *int `json:"manager_id"` // null clears the manager
+}
+
+// SetEmployeeManager sets who the employee reports to on their current grade.
+// The reporting line routes manager approval steps and decides who may see a
+// team's leave.
+func (h *Handler) SetEmployeeManager(c *fiber.Ctx) error {
+	employeeID, err := strconv.Atoi(c.Params("employeeId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid employee ID"})
+	}
+
+	var req managerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.ManagerID != nil {
+		if *req.ManagerID == employeeID {
+			return c.Status(422).JSON(fiber.Map{"error": "An employee cannot be their own manager"})
+		}
+		var count int64
+		if err := h.db.Model(&models.EmployeeGrade{}).
+			Where("employee_id = ? AND is_current = ? AND deleted = ?", *req.ManagerID, 1, 0).
+			Count(&count).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch manager"})
+		}
+		if count == 0 {
+			return c.Status(422).JSON(fiber.Map{"error": "The manager has no current grade"})
+		}
+	}
+
+	result := h.db.Model(&models.EmployeeGrade{}).
+		Where("employee_id = ? AND is_current = ? AND deleted = ?", employeeID, 1, 0).
+		Update("manager_id", req.ManagerID)
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to set manager"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Employee has no current grade"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Manager set successfully"})
+}
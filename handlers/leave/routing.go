@@ -0,0 +1,306 @@
+package leave
+
+import (
+	"time"
+	"yathuerp/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// approvalStepRequest is one step of the chain accepted by SetApprovalChain
+type approvalStepRequest struct {
+	ApproverType      string `json:"approver_type"`
+	ApproverID        *int   `json:"approver_id"`
+	EscalateAfterDays int    `json:"escalate_after_days"`
+	EscalateToID      *int   `json:"escalate_to_id"`
+}
+
+type delegationRequest struct {
+	DelegateID int    `json:"delegate_id"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	Reason     string `json:"reason"`
+}
+
+func (h *Handler) GetApprovalChain(c *fiber.Ctx) error {
+	var steps []models.LeaveApprovalStep
+	if err := h.db.Where("leave_type_id = ? AND deleted = ?", c.Params("id"), 0).
+		Order("step_order").
+		Find(&steps).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch approval chain"})
+	}
+
+	return c.JSON(steps)
+}
+
+// SetApprovalChain replaces a leave type's approval chain; steps are numbered
+// in the order given. Applications already submitted keep their chain.
+func (h *Handler) SetApprovalChain(c *fiber.Ctx) error {
+	var leaveType models.LeaveType
+	if err := h.db.First(&leaveType, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Leave type not found"})
+	}
+
+	var req []approvalStepRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	steps := make([]models.LeaveApprovalStep, 0, len(req))
+	for i, step := range req {
+		switch step.ApproverType {
+		case approverTypeManager:
+		case approverTypeUser:
+			if step.ApproverID == nil {
+				return c.Status(400).JSON(fiber.Map{"error": "approver_id is required for user steps"})
+			}
+		default:
+			return c.Status(400).JSON(fiber.Map{"error": "approver_type must be manager or user"})
+		}
+		if step.EscalateAfterDays < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "escalate_after_days cannot be negative"})
+		}
+
+		steps = append(steps, models.LeaveApprovalStep{
+			LeaveTypeID:       leaveType.ID.String(),
+			StepOrder:         i + 1,
+			ApproverType:      step.ApproverType,
+			ApproverID:        step.ApproverID,
+			EscalateAfterDays: step.EscalateAfterDays,
+			EscalateToID:      step.EscalateToID,
+		})
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("leave_type_id = ?", leaveType.ID.String()).
+			Delete(&models.LeaveApprovalStep{}).Error; err != nil {
+			return err
+		}
+		if len(steps) == 0 {
+			return nil
+		}
+		return tx.Create(&steps).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save approval chain"})
+	}
+
+	return c.JSON(steps)
+}
+
+func (h *Handler) GetLeaveApprovals(c *fiber.Ctx) error {
+	var approvals []models.LeaveApproval
+	if err := h.db.Where("leave_application_id = ? AND deleted = ?", c.Params("id"), 0).
+		Order("step_order").
+		Find(&approvals).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch approvals"})
+	}
+
+	return c.JSON(approvals)
+}
+
+// GetApprovalInbox lists the open approval steps the current user can act on,
+// including those delegated to them.
+func (h *Handler) GetApprovalInbox(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	today := truncateDay(time.Now())
+	delegators := h.db.Model(&models.LeaveDelegation{}).
+		Select("delegator_id").
+		Where("delegate_id = ? AND start_date <= ? AND end_date >= ? AND deleted = ?", userID, today, today, 0)
+
+	var approvals []models.LeaveApproval
+	if err := h.db.Where("status = ? AND deleted = ?", approvalPending, 0).
+		Where("approver_id = ? OR approver_id IN (?)", userID, delegators).
+		Order("created_at").
+		Find(&approvals).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch approvals"})
+	}
+
+	return c.JSON(approvals)
+}
+
+func (h *Handler) GetDelegations(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var delegations []models.LeaveDelegation
+	if err := h.db.Where("(delegator_id = ? OR delegate_id = ?) AND deleted = ?", userID, userID, 0).
+		Order("start_date DESC").
+		Find(&delegations).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch delegations"})
+	}
+
+	return c.JSON(delegations)
+}
+
+// CreateDelegation lets the current user hand their leave approvals to
+// someone else for a period, e.g. while they are on leave themselves.
+func (h *Handler) CreateDelegation(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req delegationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if req.DelegateID == 0 || req.DelegateID == userID {
+		return c.Status(400).JSON(fiber.Map{"error": "A delegate other than yourself is required"})
+	}
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid start_date, expected YYYY-MM-DD"})
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil || end.Before(start) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid end_date, expected YYYY-MM-DD on or after start_date"})
+	}
+
+	delegation := models.LeaveDelegation{
+		DelegatorID: userID,
+		DelegateID:  req.DelegateID,
+		StartDate:   start,
+		EndDate:     end,
+		Reason:      req.Reason,
+	}
+	if err := h.db.Create(&delegation).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create delegation"})
+	}
+
+	return c.Status(201).JSON(delegation)
+}
+
+func (h *Handler) DeleteDelegation(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	result := h.db.Where("id = ? AND delegator_id = ?", c.Params("id"), userID).
+		Delete(&models.LeaveDelegation{})
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete delegation"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Delegation not found"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Delegation deleted successfully"})
+}
+
+// EscalateApprovals reassigns open steps that are past their deadline to the
+// step's escalation user, or failing that to the approver's own manager.
+func (h *Handler) EscalateApprovals(c *fiber.Ctx) error {
+	now := time.Now()
+
+	var overdue []models.LeaveApproval
+	if err := h.db.Where("status = ? AND due_at < ? AND deleted = ?", approvalPending, now, 0).
+		Find(&overdue).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch overdue approvals"})
+	}
+
+	escalated := []models.LeaveApproval{}
+	for _, approval := range overdue {
+		err := h.db.Transaction(func(tx *gorm.DB) error {
+			var application models.LeaveApplication
+			if err := tx.First(&application, "id = ?", approval.LeaveApplicationID).Error; err != nil {
+				return err
+			}
+
+			var step models.LeaveApprovalStep
+			if err := tx.Where("leave_type_id = ? AND step_order = ? AND deleted = ?",
+				application.LeaveTypeID, approval.StepOrder, 0).
+				Limit(1).
+				Find(&step).Error; err != nil {
+				return err
+			}
+
+			target := 0
+			if step.EscalateToID != nil {
+				target = *step.EscalateToID
+			} else {
+				approverEmployeeID, err := employeeOfUser(tx, approval.ApproverID)
+				if err != nil {
+					return err
+				}
+				if approverEmployeeID != 0 {
+					if target, err = managerOf(tx, approverEmployeeID); err != nil {
+						return err
+					}
+				}
+			}
+			if target == 0 || target == approval.ApproverID {
+				return nil
+			}
+			applicantUserID, err := userOfEmployee(tx, application.EmployeeID)
+			if err != nil {
+				return err
+			}
+			if target == applicantUserID {
+				return nil
+			}
+
+			approval.ApproverID = target
+			approval.Escalated = 1
+			approval.DueAt = nil
+			if step.EscalateAfterDays > 0 {
+				due := now.AddDate(0, 0, step.EscalateAfterDays)
+				approval.DueAt = &due
+			}
+			if err := tx.Save(&approval).Error; err != nil {
+				return err
+			}
+			escalated = append(escalated, approval)
+			return nil
+		})
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to escalate approvals"})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"overdue":   len(overdue),
+		"escalated": escalated,
+	})
+}
+
+func (h *Handler) GetLeaveEvents(c *fiber.Ctx) error {
+	query := h.db.Where("deleted = ?", 0)
+	if c.QueryBool("unpublished") {
+		query = query.Where("published_at IS NULL")
+	}
+	if applicationID := c.Query("application_id"); applicationID != "" {
+		query = query.Where("leave_application_id = ?", applicationID)
+	}
+
+	var events []models.LeaveEvent
+	if err := query.Order("created_at").Find(&events).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch leave events"})
+	}
+
+	return c.JSON(events)
+}
+
+// MarkLeaveEventPublished is called by the relay once an event has been delivered
+func (h *Handler) MarkLeaveEventPublished(c *fiber.Ctx) error {
+	result := h.db.Model(&models.LeaveEvent{}).
+		Where("id = ? AND published_at IS NULL", c.Params("id")).
+		Update("published_at", time.Now())
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update leave event"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Unpublished leave event not found"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Leave event marked as published"})
+}
@@ -0,0 +1,297 @@
+package leave
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"yathuerp/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	approverTypeManager = "manager"
+	approverTypeUser    = "user"
+
+	approvalWaiting   = "waiting"
+	approvalPending   = "pending"
+	approvalApproved  = "approved"
+	approvalRejected  = "rejected"
+	approvalCancelled = "cancelled"
+
+	eventSubmitted = "leave.application.submitted"
+	eventApproved  = "leave.application.approved"
+	eventRejected  = "leave.application.rejected"
+)
+
+var (
+	errNotApprover  = errors.New("you are not the approver for the current step of this application")
+	errNotDecider   = errors.New("only HR or the employee's manager can decide an application without an approval chain")
+	errSelfApproval = errors.New("you cannot approve your own leave application")
+)
+
+// The event payloads mirror the leave service's domain events so consumers
+// can read either source.
+
+type leaveApplicationSubmittedEvent struct {
+	ApplicationID string    `json:"application_id"`
+	EmployeeID    int       `json:"employee_id"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+type leaveApplicationApprovedEvent struct {
+	ApplicationID string    `json:"application_id"`
+	EmployeeID    int       `json:"employee_id"`
+	ApproverID    int       `json:"approver_id"`
+	Step          int       `json:"step"`
+	Final         bool      `json:"final"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+type leaveApplicationRejectedEvent struct {
+	ApplicationID string    `json:"application_id"`
+	EmployeeID    int       `json:"employee_id"`
+	ApproverID    int       `json:"approver_id"`
+	Reason        string    `json:"reason"`
+	Step          int       `json:"step"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// startApprovalWorkflow creates one approval per step of the leave type's
+// chain and opens the first. Leave types without a chain keep single-step
+// approval by any authorised user.
+func startApprovalWorkflow(tx *gorm.DB, application *models.LeaveApplication) error {
+	var steps []models.LeaveApprovalStep
+	if err := tx.Where("leave_type_id = ? AND deleted = ?", application.LeaveTypeID, 0).
+		Order("step_order").
+		Find(&steps).Error; err != nil {
+		return err
+	}
+
+	applicantUserID, err := userOfEmployee(tx, application.EmployeeID)
+	if err != nil {
+		return err
+	}
+
+	var approvals []models.LeaveApproval
+	for _, step := range steps {
+		approverID, err := resolveApprover(tx, step, application.EmployeeID)
+		if err != nil {
+			return err
+		}
+		// nobody approves their own leave, so a step that lands on the applicant is skipped
+		if approverID == 0 || approverID == applicantUserID {
+			continue
+		}
+		approvals = append(approvals, models.LeaveApproval{
+			LeaveApplicationID: application.ID.String(),
+			StepOrder:          step.StepOrder,
+			ApproverID:         approverID,
+			Status:             approvalWaiting,
+		})
+	}
+
+	if len(approvals) > 0 {
+		if err := openApproval(tx, &approvals[0], application.LeaveTypeID, time.Now()); err != nil {
+			return err
+		}
+		if err := tx.Create(&approvals).Error; err != nil {
+			return err
+		}
+	}
+
+	return recordEvent(tx, eventSubmitted, application, leaveApplicationSubmittedEvent{
+		ApplicationID: application.ID.String(),
+		EmployeeID:    application.EmployeeID,
+		Timestamp:     time.Now(),
+	})
+}
+
+// resolveApprover returns the user who approves a step for the employee, or 0
+// when the step has nobody to route to (e.g. an employee without a manager, or
+// a manager without a user account).
+func resolveApprover(tx *gorm.DB, step models.LeaveApprovalStep, employeeID int) (int, error) {
+	switch step.ApproverType {
+	case approverTypeUser:
+		if step.ApproverID == nil {
+			return 0, fmt.Errorf("approval step %d has no approver", step.StepOrder)
+		}
+		return *step.ApproverID, nil
+	case approverTypeManager:
+		return managerOf(tx, employeeID)
+	}
+	return 0, fmt.Errorf("approval step %d has unknown approver type %q", step.StepOrder, step.ApproverType)
+}
+
+// managerOf returns the user account of the employee's current manager.
+// EmployeeGrade.ManagerID is an employee id, while approvals are made by users.
+func managerOf(tx *gorm.DB, employeeID int) (int, error) {
+	var employeeGrade models.EmployeeGrade
+	if err := tx.Where("employee_id = ? AND is_current = ? AND deleted = ?", employeeID, 1, 0).
+		Limit(1).
+		Find(&employeeGrade).Error; err != nil {
+		return 0, err
+	}
+	if employeeGrade.ManagerID == nil {
+		return 0, nil
+	}
+	return userOfEmployee(tx, *employeeGrade.ManagerID)
+}
+
+// userOfEmployee returns the employee's user account, or 0 if they have none
+func userOfEmployee(tx *gorm.DB, employeeID int) (int, error) {
+	var user models.User
+	if err := tx.Where("employee_id = ? AND deleted = ?", employeeID, 0).
+		Limit(1).
+		Find(&user).Error; err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+// employeeOfUser returns the employee a user account belongs to, or 0 for
+// accounts that are not linked to an employee
+func employeeOfUser(tx *gorm.DB, userID int) (int, error) {
+	var user models.User
+	if err := tx.Where("id = ? AND deleted = ?", userID, 0).
+		Limit(1).
+		Find(&user).Error; err != nil {
+		return 0, err
+	}
+	if user.EmployeeID == nil {
+		return 0, nil
+	}
+	return *user.EmployeeID, nil
+}
+
+// openApproval makes an approval the current step and sets its escalation deadline
+func openApproval(tx *gorm.DB, approval *models.LeaveApproval, leaveTypeID string, now time.Time) error {
+	approval.Status = approvalPending
+	approval.DueAt = nil
+
+	var step models.LeaveApprovalStep
+	err := tx.Where("leave_type_id = ? AND step_order = ? AND deleted = ?", leaveTypeID, approval.StepOrder, 0).
+		First(&step).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if step.EscalateAfterDays > 0 {
+		due := now.AddDate(0, 0, step.EscalateAfterDays)
+		approval.DueAt = &due
+	}
+	return nil
+}
+
+// currentApproval returns the open step of an application, or nil if the
+// application has no approval chain.
+func currentApproval(tx *gorm.DB, application *models.LeaveApplication) (*models.LeaveApproval, error) {
+	var approval models.LeaveApproval
+	err := tx.Where("leave_application_id = ? AND status = ? AND deleted = ?",
+		application.ID.String(), approvalPending, 0).
+		Order("step_order").
+		First(&approval).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// canAct reports whether userID is the step's approver or holds a delegation
+// from them covering today.
+func canAct(tx *gorm.DB, approval *models.LeaveApproval, userID int) (bool, error) {
+	if approval.ApproverID == userID {
+		return true, nil
+	}
+
+	today := truncateDay(time.Now())
+	var count int64
+	err := tx.Model(&models.LeaveDelegation{}).
+		Where("delegator_id = ? AND delegate_id = ? AND start_date <= ? AND end_date >= ? AND deleted = ?",
+			approval.ApproverID, userID, today, today, 0).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// canDecide reports whether userID may act on the application: the open
+// step's approver or their delegate, or HR or the employee's manager when the
+// application has no chain.
+func canDecide(tx *gorm.DB, application *models.LeaveApplication, approval *models.LeaveApproval, userID int) error {
+	if approval == nil {
+		allowed, err := isHROrManagerOf(tx, userID, application.EmployeeID)
+		if err == nil && !allowed {
+			err = errNotDecider
+		}
+		return err
+	}
+	allowed, err := canAct(tx, approval, userID)
+	if err == nil && !allowed {
+		err = errNotApprover
+	}
+	return err
+}
+
+// approveStep records the user's approval of the open step and opens the next
+// one. It reports whether the chain is now complete.
+func approveStep(tx *gorm.DB, application *models.LeaveApplication, approval *models.LeaveApproval, userID int, notes string) (bool, error) {
+	now := time.Now()
+	approval.Status = approvalApproved
+	approval.ActedBy = &userID
+	approval.ActedAt = &now
+	approval.Notes = notes
+	if err := tx.Save(approval).Error; err != nil {
+		return false, err
+	}
+
+	var next models.LeaveApproval
+	err := tx.Where("leave_application_id = ? AND status = ? AND deleted = ?",
+		application.ID.String(), approvalWaiting, 0).
+		Order("step_order").
+		First(&next).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := openApproval(tx, &next, application.LeaveTypeID, now); err != nil {
+		return false, err
+	}
+	if err := tx.Save(&next).Error; err != nil {
+		return false, err
+	}
+
+	return false, recordEvent(tx, eventApproved, application, leaveApplicationApprovedEvent{
+		ApplicationID: application.ID.String(),
+		EmployeeID:    application.EmployeeID,
+		ApproverID:    userID,
+		Step:          approval.StepOrder,
+		Timestamp:     now,
+	})
+}
+
+// closeApprovals marks every step that has not been acted on with status
+func closeApprovals(tx *gorm.DB, application *models.LeaveApplication, status string) error {
+	return tx.Model(&models.LeaveApproval{}).
+		Where("leave_application_id = ? AND status IN ?", application.ID.String(), []string{approvalWaiting, approvalPending}).
+		Update("status", status).Error
+}
+
+func recordEvent(tx *gorm.DB, eventType string, application *models.LeaveApplication, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.LeaveEvent{
+		EventType:          eventType,
+		LeaveApplicationID: application.ID.String(),
+		Payload:            string(data),
+	}).Error
+}
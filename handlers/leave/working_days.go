@@ -0,0 +1,158 @@
+package leave
+
+import (
+	"fmt"
+	"time"
+	"yathuerp/handlers/holidays"
+	"yathuerp/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	dayTypeFull          = "full_day"
+	dayTypeHalfMorning   = "half_day_morning"
+	dayTypeHalfAfternoon = "half_day_afternoon"
+
+	dateLayout = "2006-01-02"
+
+	defaultWorkingDaysPerWeek = 5
+)
+
+// halfDayRequest marks a single date of a leave application as a half day
+type halfDayRequest struct {
+	Date string `json:"date"`
+	Type string `json:"type"` // half_day_morning, half_day_afternoon
+}
+
+// leaveApplicationRequest is the body accepted when creating or updating a
+// leave application. DaysApplied is always computed server-side.
+type leaveApplicationRequest struct {
+	models.LeaveApplication
+	HalfDays []halfDayRequest `json:"half_days"`
+}
+
+// planLeaveDays expands an application into one row per working date between
+// StartDate and EndDate, skipping the employee's rest days and the holidays
+// their branch observes.
+func planLeaveDays(tx *gorm.DB, application *models.LeaveApplication, halfDays []halfDayRequest) ([]models.LeaveApplicationDay, error) {
+	if application.StartDate == nil || application.EndDate == nil {
+		return nil, fmt.Errorf("start_date and end_date are required")
+	}
+	start := truncateDay(*application.StartDate)
+	end := truncateDay(*application.EndDate)
+	if end.Before(start) {
+		return nil, fmt.Errorf("end_date cannot be before start_date")
+	}
+
+	halfDayTypes := map[string]string{}
+	for _, halfDay := range halfDays {
+		if halfDay.Type != dayTypeHalfMorning && halfDay.Type != dayTypeHalfAfternoon {
+			return nil, fmt.Errorf("invalid half day type %q", halfDay.Type)
+		}
+		date, err := time.Parse(dateLayout, halfDay.Date)
+		if err != nil || date.Before(start) || date.After(end) {
+			return nil, fmt.Errorf("half day %q is outside the leave period", halfDay.Date)
+		}
+		halfDayTypes[halfDay.Date] = halfDay.Type
+	}
+
+	workdays, err := workingWeekdays(tx, application.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	branchID, err := holidays.EmployeeBranch(tx, application.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := holidays.Load(tx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	observed := calendar.Dates(branchID)
+
+	status := application.ApplicationStatus
+	if status == "" {
+		status = statusPending
+	}
+
+	var days []models.LeaveApplicationDay
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		key := date.Format(dateLayout)
+		if _, holiday := observed[key]; holiday || !workdays[date.Weekday()] {
+			continue
+		}
+
+		day := models.LeaveApplicationDay{
+			EmployeeID:  application.EmployeeID,
+			LeaveTypeID: application.LeaveTypeID,
+			Date:        date,
+			Type:        dayTypeFull,
+			Days:        1,
+			Status:      status,
+		}
+		if dayType, ok := halfDayTypes[key]; ok {
+			day.Type = dayType
+			day.Days = 0.5
+		}
+		days = append(days, day)
+	}
+
+	if len(days) == 0 {
+		return nil, fmt.Errorf("the selected period contains no working days")
+	}
+
+	return days, nil
+}
+
+// replaceLeaveDays swaps the materialised dates of an application for a new set
+func replaceLeaveDays(tx *gorm.DB, application *models.LeaveApplication, days []models.LeaveApplicationDay) error {
+	if err := tx.Unscoped().
+		Where("leave_application_id = ?", application.ID.String()).
+		Delete(&models.LeaveApplicationDay{}).Error; err != nil {
+		return err
+	}
+
+	for i := range days {
+		days[i].LeaveApplicationID = application.ID.String()
+	}
+	return tx.Create(&days).Error
+}
+
+// sumLeaveDays totals the day fractions of materialised dates
+func sumLeaveDays(days []models.LeaveApplicationDay) float64 {
+	total := 0.0
+	for _, day := range days {
+		total += day.Days
+	}
+	return total
+}
+
+// workingWeekdays derives the employee's working week from the StaffType on
+// their current grade: 5 days is Monday to Friday, 6 adds Saturday and 7 is
+// every day.
+func workingWeekdays(tx *gorm.DB, employeeID int) (map[time.Weekday]bool, error) {
+	daysPerWeek := defaultWorkingDaysPerWeek
+
+	var employeeGrade models.EmployeeGrade
+	err := tx.Where("employee_id = ? AND is_current = ? AND deleted = ?", employeeID, 1, 0).
+		Order("start_date DESC").
+		Limit(1).
+		Find(&employeeGrade).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if employeeGrade.StaffTypeID != nil {
+		var staffType models.StaffType
+		if err := tx.First(&staffType, *employeeGrade.StaffTypeID).Error; err == nil && staffType.Days != nil {
+			daysPerWeek = *staffType.Days
+		}
+	}
+
+	workdays := map[time.Weekday]bool{}
+	for i := 0; i < daysPerWeek && i < 7; i++ {
+		workdays[time.Weekday((int(time.Monday)+i)%7)] = true
+	}
+	return workdays, nil
+}
@@ -23,6 +23,10 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	if err := database.Migrate(db); err != nil {
+		log.Fatal(err)
+	}
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		ErrorHandler: middleware.ErrorHandler,
@@ -1,5 +1,15 @@
 package models
 
+// Attendance code categories, used by attendance analytics. Codes without one
+// count as absent when they are debited and present otherwise.
+const (
+	AttendanceCategoryPresent = "present"
+	AttendanceCategoryLate    = "late"
+	AttendanceCategoryAbsent  = "absent"
+	AttendanceCategoryLeave   = "leave"
+	AttendanceCategoryOff     = "off"
+)
+
 // AttendanceCode represents tbl_attendance_codes
 type AttendanceCode struct {
 	BaseModel
@@ -7,4 +17,17 @@ type AttendanceCode struct {
 	Description    string `json:"description"`
 	IsDebit        int    `gorm:"default:0" json:"is_debit"`
 	OvertimeTypeID *int   `json:"overtime_type_id"`
+	Category       string `gorm:"size:20" json:"category"` // present, late, absent, leave, off
+}
+
+// AttendanceCategory returns the code's category, falling back on IsDebit
+// for codes imported before categories existed
+func (c *AttendanceCode) AttendanceCategory() string {
+	if c.Category != "" {
+		return c.Category
+	}
+	if c.IsDebit == 1 {
+		return AttendanceCategoryAbsent
+	}
+	return AttendanceCategoryPresent
 }
@@ -15,4 +15,5 @@ type Attendance struct {
 	AttendanceCodeID  *int       `json:"attendance_code_id"`
 	EmployeeID        *int       `json:"employee_id"`
 	ShiftID           *int       `json:"shift_id"`
+	WorkHours         *float64   `json:"work_hours"` // hours actually worked, when known
 }
@@ -23,6 +23,12 @@ type Setting struct {
 	UpdatedAt         time.Time `json:"updated_at"`
 	CreatedBy         *int      `json:"created_by"`
 	DeductPayee       int       `gorm:"default:1" json:"deduct_payee"`
+	// LeaveConflictShare is the share of a team (0-1) that may be on leave on
+	// the same day before a new application is flagged
+	LeaveConflictShare *float64 `json:"leave_conflict_share"`
+	// LeaveEncashmentEarningTypeID is the earning type used to pay out
+	// encashed leave
+	LeaveEncashmentEarningTypeID *int `json:"leave_encashment_earning_type_id"`
 }
 
 // Month represents tbl_months
@@ -13,6 +13,7 @@ type EmployeeGrade struct {
 	BasicSalary      *float64   `json:"basic_salary"`
 	BranchID         *int       `json:"branch_id"`
 	DepartmentID     *int       `json:"department_id"`
+	ManagerID        *int       `json:"manager_id"`
 	JobID            *int       `json:"job_id"`
 	ActionDate       *time.Time `json:"action_date"`
 	IsCurrent        int        `gorm:"default:1" json:"is_current"`
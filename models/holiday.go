@@ -4,10 +4,16 @@ import (
 	"time"
 )
 
-// Holiday represents tbl_holidays
+// Holiday represents tbl_holidays. A recurring holiday falls on the same day
+// and month every year from HolidayDate on; a holiday with a BranchID is only
+// observed by that branch.
 type Holiday struct {
 	BaseModel
-	Title       string     `json:"title"`
-	HolidayDate *time.Time `json:"holiday_date"`
-	Comment     string     `json:"comment"`
+	Title        string     `json:"title"`
+	HolidayDate  *time.Time `json:"holiday_date"`
+	Comment      string     `json:"comment"`
+	Type         string     `gorm:"default:national" json:"type"` // national, company, religious
+	IsRecurring  int        `gorm:"default:0" json:"is_recurring"`
+	BranchID     *int       `gorm:"index" json:"branch_id"`
+	ObservedRule string     `gorm:"default:none" json:"observed_rule"` // none, nearest_weekday, next_weekday
 }
@@ -7,16 +7,22 @@ import (
 // LeaveType represents tbl_leave_types
 type LeaveType struct {
 	BaseModel
-	Name                  string `json:"name"`
-	Description           string `json:"description"`
-	MaximumDays           *int   `json:"maximum_days"`
-	LeaveGrantEntitlement int    `gorm:"default:0" json:"leave_grant_entitlement"`
+	Name                  string  `json:"name"`
+	Description           string  `json:"description"`
+	MaximumDays           *int    `json:"maximum_days"`         // working days per application
+	MaxConsecutiveDays    *int    `json:"max_consecutive_days"` // calendar days from start to end
+	LeaveGrantEntitlement int     `gorm:"default:0" json:"leave_grant_entitlement"`
+	DaysPerYear           float64 `gorm:"default:0" json:"days_per_year"`
+	AccrueByGrade         int     `gorm:"default:0" json:"accrue_by_grade"` // entitlement comes from Grade.AnnualLeaveDays
+	IsCarryForward        int     `gorm:"default:0" json:"is_carry_forward"`
+	CarryForwardLimit     float64 `gorm:"default:0" json:"carry_forward_limit"` // 0 means no cap
+	IsEncashable          int     `gorm:"default:0" json:"is_encashable"`       // unused days are paid out instead of forfeited
 }
 
 // LeaveApplication represents tbl_leave_applications
 type LeaveApplication struct {
 	BaseModel
-	DaysApplied             int        `gorm:"default:0" json:"days_applied"`
+	DaysApplied             float64    `gorm:"default:0" json:"days_applied"`
 	EmployeeID              int        `gorm:"not null" json:"employee_id"`
 	LeaveGrantAmount        float64    `gorm:"default:0" json:"leave_grant_amount"`
 	StartDate               *time.Time `json:"start_date"`
@@ -31,6 +37,31 @@ type LeaveApplication struct {
 	LodgedBy                *int       `json:"lodged_by"`
 }
 
+// LeaveApplicationDay represents tbl_leave_application_days, one row per date taken on a leave application
+type LeaveApplicationDay struct {
+	BaseModel
+	LeaveApplicationID string    `gorm:"not null;index" json:"leave_application_id"`
+	EmployeeID         int       `gorm:"not null;index" json:"employee_id"`
+	LeaveTypeID        string    `json:"leave_type_id"`
+	Date               time.Time `gorm:"type:date;not null" json:"date"`
+	Type               string    `gorm:"default:full_day" json:"type"` // full_day, half_day_morning, half_day_afternoon
+	Days               float64   `gorm:"default:1" json:"days"`
+	Status             string    `json:"status"` // pending, approved, rejected, cancelled
+}
+
+// LeaveGrantAdjustment represents tbl_leave_grant_adjustments, a leave grant
+// payroll has already paid that must be recovered after leave was cut short
+type LeaveGrantAdjustment struct {
+	BaseModel
+	EmployeeID         int     `gorm:"not null;index" json:"employee_id"`
+	LeaveApplicationID string  `gorm:"not null;index" json:"leave_application_id"`
+	Action             string  `json:"action"` // cancelled, recalled, early_return
+	Days               float64 `gorm:"default:0" json:"days"`
+	Amount             float64 `gorm:"default:0" json:"amount"`
+	Status             string  `gorm:"default:pending" json:"status"` // pending, processed
+	PayrollID          *int    `json:"payroll_id"`
+}
+
 // LeaveDay represents tbl_leave_days
 type LeaveDay struct {
 	BaseModel
@@ -49,3 +80,110 @@ type FinancialYear struct {
 	AddedBy   *int       `json:"added_by"`
 	IsActive  int        `gorm:"default:0" json:"is_active"`
 }
+
+// LeaveBalance represents tbl_leave_balances, one row per employee, leave type and year
+type LeaveBalance struct {
+	BaseModel
+	EmployeeID    int     `gorm:"not null;index" json:"employee_id"`
+	LeaveTypeID   string  `gorm:"not null;index" json:"leave_type_id"`
+	Year          int     `gorm:"not null" json:"year"`
+	AccruedDays   float64 `gorm:"default:0" json:"accrued_days"`
+	CarryForward  float64 `gorm:"default:0" json:"carry_forward"`
+	TotalDays     float64 `gorm:"default:0" json:"total_days"`
+	UsedDays      float64 `gorm:"default:0" json:"used_days"`
+	ForfeitedDays float64 `gorm:"default:0" json:"forfeited_days"`
+	EncashedDays  float64 `gorm:"default:0" json:"encashed_days"`
+	BalanceDays   float64 `gorm:"default:0" json:"balance_days"`
+}
+
+// LeaveAccrual represents tbl_leave_accruals, the days credited for one month
+type LeaveAccrual struct {
+	BaseModel
+	EmployeeID  int     `gorm:"not null;index" json:"employee_id"`
+	LeaveTypeID string  `gorm:"not null;index" json:"leave_type_id"`
+	Year        int     `gorm:"not null" json:"year"`
+	Month       int     `gorm:"not null" json:"month"`
+	GradeID     *int    `json:"grade_id"`
+	Days        float64 `gorm:"default:0" json:"days"`
+}
+
+// LeaveForfeiture represents tbl_leave_forfeitures, days lost at year end above the carry-forward limit
+type LeaveForfeiture struct {
+	BaseModel
+	EmployeeID  int     `gorm:"not null;index" json:"employee_id"`
+	LeaveTypeID string  `gorm:"not null;index" json:"leave_type_id"`
+	Year        int     `gorm:"not null" json:"year"`
+	Days        float64 `gorm:"default:0" json:"days"`
+	Reason      string  `json:"reason"`
+}
+
+// LeaveEncashment represents tbl_leave_encashments, unused leave paid out
+// through an earning on the next payroll
+type LeaveEncashment struct {
+	BaseModel
+	EmployeeID  int       `gorm:"not null;index" json:"employee_id"`
+	LeaveTypeID string    `gorm:"not null;index" json:"leave_type_id"`
+	Year        int       `gorm:"not null" json:"year"`
+	Reason      string    `gorm:"not null" json:"reason"` // exit, year_end
+	Days        float64   `gorm:"default:0" json:"days"`
+	DailyRate   float64   `gorm:"default:0" json:"daily_rate"`
+	Amount      float64   `gorm:"default:0" json:"amount"`
+	EarningID   string    `json:"earning_id"`
+	EncashedOn  time.Time `gorm:"type:date" json:"encashed_on"`
+}
+
+// LeaveCalendarFeed represents tbl_leave_calendar_feeds, a tokenised ICS
+// subscription to an employee's or a department's leave
+type LeaveCalendarFeed struct {
+	BaseModel
+	TokenHash    string     `gorm:"not null;uniqueIndex;size:64" json:"-"` // SHA-256 of the token in the feed URL
+	Scope        string     `gorm:"not null" json:"scope"`                 // employee, department
+	UserID       int        `gorm:"not null;index" json:"user_id"`
+	EmployeeID   *int       `json:"employee_id"`
+	DepartmentID *int       `json:"department_id"`
+	LastAccessAt *time.Time `json:"last_access_at"`
+}
+
+// LeaveApprovalStep represents tbl_leave_approval_steps, one stage of a leave type's approval chain
+type LeaveApprovalStep struct {
+	BaseModel
+	LeaveTypeID       string `gorm:"not null;index" json:"leave_type_id"`
+	StepOrder         int    `gorm:"not null" json:"step_order"`
+	ApproverType      string `gorm:"not null" json:"approver_type"` // manager, user
+	ApproverID        *int   `json:"approver_id"`                   // required when approver_type is user
+	EscalateAfterDays int    `gorm:"default:0" json:"escalate_after_days"`
+	EscalateToID      *int   `json:"escalate_to_id"`
+}
+
+// LeaveApproval represents tbl_leave_approvals, the progress of one approval step for an application
+type LeaveApproval struct {
+	BaseModel
+	LeaveApplicationID string     `gorm:"not null;index" json:"leave_application_id"`
+	StepOrder          int        `gorm:"not null" json:"step_order"`
+	ApproverID         int        `gorm:"not null;index" json:"approver_id"`
+	Status             string     `gorm:"not null" json:"status"` // waiting, pending, approved, rejected, cancelled
+	DueAt              *time.Time `json:"due_at"`
+	Escalated          int        `gorm:"default:0" json:"escalated"`
+	ActedBy            *int       `json:"acted_by"`
+	ActedAt            *time.Time `json:"acted_at"`
+	Notes              string     `json:"notes"`
+}
+
+// LeaveDelegation represents tbl_leave_delegations, a window in which one user acts on another's approvals
+type LeaveDelegation struct {
+	BaseModel
+	DelegatorID int       `gorm:"not null;index" json:"delegator_id"`
+	DelegateID  int       `gorm:"not null;index" json:"delegate_id"`
+	StartDate   time.Time `gorm:"type:date;not null" json:"start_date"`
+	EndDate     time.Time `gorm:"type:date;not null" json:"end_date"`
+	Reason      string    `json:"reason"`
+}
+
+// LeaveEvent represents tbl_leave_events, an outbox of leave workflow events for other services
+type LeaveEvent struct {
+	BaseModel
+	EventType          string     `gorm:"not null;index" json:"event_type"`
+	LeaveApplicationID string     `gorm:"not null;index" json:"leave_application_id"`
+	Payload            string     `gorm:"type:text" json:"payload"`
+	PublishedAt        *time.Time `json:"published_at"`
+}
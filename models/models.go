@@ -5,53 +5,64 @@ package models
 
 // Table name constants for reference
 const (
-	TableAttendanceCodes   = "tbl_attendance_codes"
-	TableAttendances       = "tbl_attendances"
-	TableAuditLogs         = "tbl_audit_logs"
-	TableBankDetails       = "tbl_bank_details"
-	TableBanks             = "tbl_banks"
-	TableBranches          = "tbl_branches"
-	TableDeductionTypes    = "tbl_deduction_types"
-	TableDeductions        = "tbl_deductions"
-	TableDepartments       = "tbl_departments"
-	TableDependants        = "tbl_dependants"
-	TableEarningTypes      = "tbl_earning_types"
-	TableEarnings          = "tbl_earnings"
-	TableEmployeeGrades    = "tbl_employee_grades"
-	TableEmployeeTrash     = "tbl_employee_trash"
-	TableEmployees         = "tbl_employees"
-	TableFinancialYears    = "tbl_financial_years"
-	TableGrades            = "tbl_grades"
-	TableHolidays          = "tbl_holidays"
-	TableJobs              = "tbl_jobs"
-	TableLeaveApplications = "tbl_leave_applications"
-	TableLeaveDays         = "tbl_leave_days"
-	TableLeaveTypes        = "tbl_leave_types"
-	TableLoanApplications  = "tbl_loan_applications"
-	TableLoanPayments      = "tbl_loan_payments"
-	TableLoanTypes         = "tbl_loan_types"
-	TableMembershipTypes   = "tbl_membership_types"
-	TableModuleRights      = "tbl_module_rights"
-	TableMonths            = "tbl_months"
-	TableNavigationMenus   = "tbl_navigation_menus"
-	TableOffenceTypes      = "tbl_offence_types"
-	TableOvertimeTypes     = "tbl_overtime_types"
-	TableOvertimes         = "tbl_overtimes"
-	TablePayrolls          = "tbl_payrolls"
-	TablePensionParameters = "tbl_pension_parameters"
-	TablePermissions       = "tbl_permissions"
-	TableRoles             = "tbl_roles"
-	TableSalaries          = "tbl_salaries"
-	TableSchemeTypes       = "tbl_scheme_types"
-	TableSettings          = "tbl_settings"
-	TableShifts            = "tbl_shifts"
-	TableSpouses           = "tbl_spounses"
-	TableStaffCategories   = "tbl_staff_categories"
-	TableStaffTypes        = "tbl_staff_types"
-	TableTaxBands          = "tbl_tax_bands"
-	TableUserRoles         = "tbl_user_roles"
-	TableUsers             = "tbl_users"
-	TableYears             = "tbl_years"
+	TableAttendanceCodes       = "tbl_attendance_codes"
+	TableAttendances           = "tbl_attendances"
+	TableAuditLogs             = "tbl_audit_logs"
+	TableBankDetails           = "tbl_bank_details"
+	TableBanks                 = "tbl_banks"
+	TableBranches              = "tbl_branches"
+	TableDeductionTypes        = "tbl_deduction_types"
+	TableDeductions            = "tbl_deductions"
+	TableDepartments           = "tbl_departments"
+	TableDependants            = "tbl_dependants"
+	TableEarningTypes          = "tbl_earning_types"
+	TableEarnings              = "tbl_earnings"
+	TableEmployeeGrades        = "tbl_employee_grades"
+	TableEmployeeTrash         = "tbl_employee_trash"
+	TableEmployees             = "tbl_employees"
+	TableFinancialYears        = "tbl_financial_years"
+	TableGrades                = "tbl_grades"
+	TableHolidays              = "tbl_holidays"
+	TableJobs                  = "tbl_jobs"
+	TableLeaveAccruals         = "tbl_leave_accruals"
+	TableLeaveApplications     = "tbl_leave_applications"
+	TableLeaveApplicationDays  = "tbl_leave_application_days"
+	TableLeaveApprovals        = "tbl_leave_approvals"
+	TableLeaveApprovalSteps    = "tbl_leave_approval_steps"
+	TableLeaveBalances         = "tbl_leave_balances"
+	TableLeaveCalendarFeeds    = "tbl_leave_calendar_feeds"
+	TableLeaveDelegations      = "tbl_leave_delegations"
+	TableLeaveDays             = "tbl_leave_days"
+	TableLeaveEncashments      = "tbl_leave_encashments"
+	TableLeaveEvents           = "tbl_leave_events"
+	TableLeaveForfeitures      = "tbl_leave_forfeitures"
+	TableLeaveGrantAdjustments = "tbl_leave_grant_adjustments"
+	TableLeaveTypes            = "tbl_leave_types"
+	TableLoanApplications      = "tbl_loan_applications"
+	TableLoanPayments          = "tbl_loan_payments"
+	TableLoanTypes             = "tbl_loan_types"
+	TableMembershipTypes       = "tbl_membership_types"
+	TableModuleRights          = "tbl_module_rights"
+	TableMonths                = "tbl_months"
+	TableNavigationMenus       = "tbl_navigation_menus"
+	TableOffenceTypes          = "tbl_offence_types"
+	TableOvertimeTypes         = "tbl_overtime_types"
+	TableOvertimes             = "tbl_overtimes"
+	TablePayrolls              = "tbl_payrolls"
+	TablePensionParameters     = "tbl_pension_parameters"
+	TablePermissions           = "tbl_permissions"
+	TableRoles                 = "tbl_roles"
+	TableSalaries              = "tbl_salaries"
+	TableSchemeTypes           = "tbl_scheme_types"
+	TableSettings              = "tbl_settings"
+	TableShifts                = "tbl_shifts"
+	TableSpouses               = "tbl_spounses"
+	TableStaffCategories       = "tbl_staff_categories"
+	TableStaffTypes            = "tbl_staff_types"
+	TableTaxBands              = "tbl_tax_bands"
+	TableUserRoles             = "tbl_user_roles"
+	TableUsers                 = "tbl_users"
+	TableYears                 = "tbl_years"
 	// Performance management tables (tbl_pf_*)
 	TablePerformanceCycles       = "tbl_pf_cycles"
 	TablePerformanceAppraisals   = "tbl_pf_appraisals"
@@ -60,6 +71,22 @@ const (
 	TablePerformanceCompetencies = "tbl_pf_competencies"
 	TablePerformancePIPs         = "tbl_pf_pips"
 	TablePerformanceTrainings    = "tbl_pf_trainings"
+	// Appraisal scoring and KPI tracking
+	TablePerformanceCompetencyRatings = "tbl_pf_competency_ratings"
+	TablePerformanceRatingScales      = "tbl_pf_rating_scales"
+	TablePerformanceKPIMeasurements   = "tbl_pf_kpi_measurements"
+	// 360 feedback
+	TablePerformanceFeedbackNominations = "tbl_pf_feedback_nominations"
+	TablePerformanceFeedbackResponses   = "tbl_pf_feedback_responses"
+	// Rating calibration
+	TablePerformanceCalibrationSessions    = "tbl_pf_calibration_sessions"
+	TablePerformanceCalibrationLimits      = "tbl_pf_calibration_limits"
+	TablePerformanceCalibrationAdjustments = "tbl_pf_calibration_adjustments"
+	// Performance improvement plans
+	TablePerformancePIPCheckIns = "tbl_pf_pip_checkins"
+	// Training
+	TablePerformanceTrainingEnrolments = "tbl_pf_training_enrolments"
+	TablePerformanceTrainingBudgets    = "tbl_pf_training_budgets"
 )
 
 // TableName method for each model to ensure correct table names
@@ -80,8 +107,19 @@ func (StaffCategory) TableName() string         { return TableStaffCategories }
 func (StaffType) TableName() string             { return TableStaffTypes }
 func (Holiday) TableName() string               { return TableHolidays }
 func (Job) TableName() string                   { return TableJobs }
+func (LeaveAccrual) TableName() string          { return TableLeaveAccruals }
 func (LeaveApplication) TableName() string      { return TableLeaveApplications }
+func (LeaveApplicationDay) TableName() string   { return TableLeaveApplicationDays }
+func (LeaveCalendarFeed) TableName() string     { return TableLeaveCalendarFeeds }
+func (LeaveBalance) TableName() string          { return TableLeaveBalances }
+func (LeaveApproval) TableName() string         { return TableLeaveApprovals }
+func (LeaveApprovalStep) TableName() string     { return TableLeaveApprovalSteps }
+func (LeaveDelegation) TableName() string       { return TableLeaveDelegations }
+func (LeaveEncashment) TableName() string       { return TableLeaveEncashments }
+func (LeaveEvent) TableName() string            { return TableLeaveEvents }
 func (LeaveDay) TableName() string              { return TableLeaveDays }
+func (LeaveForfeiture) TableName() string       { return TableLeaveForfeitures }
+func (LeaveGrantAdjustment) TableName() string  { return TableLeaveGrantAdjustments }
 func (LeaveType) TableName() string             { return TableLeaveTypes }
 func (LoanApplication) TableName() string       { return TableLoanApplications }
 func (LoanPayment) TableName() string           { return TableLoanPayments }
@@ -113,3 +151,21 @@ func (PerformanceKPI) TableName() string        { return TablePerformanceKPIs }
 func (PerformanceCompetency) TableName() string { return TablePerformanceCompetencies }
 func (PerformancePIP) TableName() string        { return TablePerformancePIPs }
 func (PerformanceTraining) TableName() string   { return TablePerformanceTrainings }
+
+func (PerformanceCompetencyRating) TableName() string { return TablePerformanceCompetencyRatings }
+func (PerformanceRatingScale) TableName() string      { return TablePerformanceRatingScales }
+func (PerformanceKPIMeasurement) TableName() string   { return TablePerformanceKPIMeasurements }
+
+func (PerformanceFeedbackNomination) TableName() string { return TablePerformanceFeedbackNominations }
+func (PerformanceFeedbackResponse) TableName() string   { return TablePerformanceFeedbackResponses }
+
+func (PerformanceCalibrationSession) TableName() string { return TablePerformanceCalibrationSessions }
+func (PerformanceCalibrationLimit) TableName() string   { return TablePerformanceCalibrationLimits }
+func (PerformanceCalibrationAdjustment) TableName() string {
+	return TablePerformanceCalibrationAdjustments
+}
+
+func (PerformancePIPCheckIn) TableName() string { return TablePerformancePIPCheckIns }
+
+func (PerformanceTrainingEnrolment) TableName() string { return TablePerformanceTrainingEnrolments }
+func (PerformanceTrainingBudget) TableName() string    { return TablePerformanceTrainingBudgets }
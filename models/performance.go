@@ -14,6 +14,11 @@ type PerformanceCycle struct {
 	EvaluationStartDate *time.Time `json:"evaluation_start_date"`
 	EvaluationEndDate   time.Time  `json:"evaluation_end_date"`
 	Status              string     `gorm:"default:'draft'" json:"status"`
+	GoalWeight          float64    `gorm:"default:70.00" json:"goal_weight"`
+	CompetencyWeight    float64    `gorm:"default:30.00" json:"competency_weight"`
+	FeedbackWeight      float64    `gorm:"default:0.00" json:"feedback_weight"`
+	MinRespondents      int        `gorm:"column:feedback_min_respondents;default:3" json:"feedback_min_respondents"`
+	LaunchedAt          *time.Time `json:"launched_at"`
 	CreatedBy           *int       `json:"created_by"`
 	CreatedAt           time.Time  `json:"created_at"`
 	UpdatedAt           time.Time  `json:"updated_at"`
@@ -68,6 +73,7 @@ type PerformanceKPI struct {
 	Description              string    `json:"description"`
 	Unit                     string    `gorm:"not null" json:"unit"`
 	DataType                 string    `gorm:"default:'numeric'" json:"data_type"`
+	Aggregation              string    `gorm:"default:'latest'" json:"aggregation"`
 	TargetCalculationFormula string    `json:"target_calculation_formula"`
 	IsActive                 int       `gorm:"default:1" json:"is_active"`
 	CreatedBy                *int      `json:"created_by"`
@@ -75,6 +81,17 @@ type PerformanceKPI struct {
 	UpdatedAt                time.Time `json:"updated_at"`
 }
 
+// PerformanceKPIMeasurement represents tbl_pf_kpi_measurements
+type PerformanceKPIMeasurement struct {
+	ID         int       `gorm:"primary_key" json:"id"`
+	GoalID     int       `gorm:"not null" json:"goal_id"`
+	MeasuredOn time.Time `json:"measured_on"`
+	Value      float64   `gorm:"not null" json:"value"`
+	Notes      string    `json:"notes"`
+	RecordedBy *int      `json:"recorded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 // PerformanceCompetency represents tbl_pf_competencies
 type PerformanceCompetency struct {
 	ID                   int       `gorm:"primary_key" json:"id"`
@@ -89,21 +106,120 @@ type PerformanceCompetency struct {
 	UpdatedAt            time.Time `json:"updated_at"`
 }
 
+// PerformanceCompetencyRating represents tbl_pf_competency_ratings
+type PerformanceCompetencyRating struct {
+	ID           int       `gorm:"primary_key" json:"id"`
+	AppraisalID  int       `gorm:"not null" json:"appraisal_id"`
+	CompetencyID int       `gorm:"not null" json:"competency_id"`
+	Rating       int       `gorm:"not null" json:"rating"`
+	Comments     string    `json:"comments"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PerformanceRatingScale represents tbl_pf_rating_scales
+type PerformanceRatingScale struct {
+	ID          int       `gorm:"primary_key" json:"id"`
+	MinScore    float64   `gorm:"not null" json:"min_score"`
+	Rating      string    `gorm:"not null" json:"rating"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PerformanceFeedbackNomination represents tbl_pf_feedback_nominations
+type PerformanceFeedbackNomination struct {
+	ID                 int        `gorm:"primary_key" json:"id"`
+	AppraisalID        int        `gorm:"not null" json:"appraisal_id"`
+	ReviewerType       string     `gorm:"not null" json:"reviewer_type"`
+	ReviewerEmployeeID *int       `json:"reviewer_employee_id"`
+	ReviewerName       string     `json:"reviewer_name"`
+	ReviewerEmail      string     `json:"reviewer_email"`
+	TokenHash          string     `gorm:"not null" json:"-"`
+	Status             string     `gorm:"default:'pending'" json:"status"`
+	NominatedBy        *int       `json:"nominated_by"`
+	RespondedAt        *time.Time `json:"responded_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// PerformanceFeedbackResponse represents tbl_pf_feedback_responses
+type PerformanceFeedbackResponse struct {
+	ID           int       `gorm:"primary_key" json:"id"`
+	NominationID int       `gorm:"not null" json:"nomination_id"`
+	CompetencyID int       `gorm:"not null" json:"competency_id"`
+	Rating       int       `gorm:"not null" json:"rating"`
+	Comments     string    `json:"comments"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PerformanceCalibrationSession represents tbl_pf_calibration_sessions
+type PerformanceCalibrationSession struct {
+	ID           int        `gorm:"primary_key" json:"id"`
+	CycleID      int        `gorm:"not null" json:"cycle_id"`
+	DepartmentID *int       `json:"department_id"`
+	Name         string     `gorm:"not null" json:"name"`
+	Notes        string     `json:"notes"`
+	Status       string     `gorm:"default:'open'" json:"status"`
+	CreatedBy    *int       `json:"created_by"`
+	FinalizedAt  *time.Time `json:"finalized_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// PerformanceCalibrationLimit represents tbl_pf_calibration_limits
+type PerformanceCalibrationLimit struct {
+	ID         int     `gorm:"primary_key" json:"id"`
+	SessionID  int     `gorm:"not null" json:"session_id"`
+	Rating     string  `gorm:"not null" json:"rating"`
+	MaxPercent float64 `gorm:"not null" json:"max_percent"`
+}
+
+// PerformanceCalibrationAdjustment represents tbl_pf_calibration_adjustments
+type PerformanceCalibrationAdjustment struct {
+	ID             int       `gorm:"primary_key" json:"id"`
+	SessionID      int       `gorm:"not null" json:"session_id"`
+	AppraisalID    int       `gorm:"not null" json:"appraisal_id"`
+	PreviousRating string    `gorm:"not null" json:"previous_rating"`
+	NewRating      string    `gorm:"not null" json:"new_rating"`
+	Justification  string    `gorm:"not null" json:"justification"`
+	AdjustedBy     *int      `json:"adjusted_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
 // PerformancePIP represents tbl_pf_pips
 type PerformancePIP struct {
-	ID              int       `gorm:"primary_key" json:"id"`
-	EmployeeID      int       `gorm:"not null" json:"employee_id"`
-	AppraisalID     *int      `json:"appraisal_id"`
-	Reason          string    `gorm:"not null" json:"reason"`
-	Objectives      string    `gorm:"not null" json:"objectives"`
-	SuccessCriteria string    `gorm:"not null" json:"success_criteria"`
-	StartDate       time.Time `json:"start_date"`
-	EndDate         time.Time `json:"end_date"`
-	Status          string    `gorm:"default:'active'" json:"status"`
-	Outcome         string    `json:"outcome"`
-	CreatedBy       *int      `json:"created_by"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              int        `gorm:"primary_key" json:"id"`
+	EmployeeID      int        `gorm:"not null" json:"employee_id"`
+	AppraisalID     *int       `json:"appraisal_id"`
+	Reason          string     `gorm:"not null" json:"reason"`
+	Objectives      string     `gorm:"not null" json:"objectives"`
+	SuccessCriteria string     `gorm:"not null" json:"success_criteria"`
+	StartDate       time.Time  `json:"start_date"`
+	EndDate         time.Time  `json:"end_date"`
+	OriginalEndDate *time.Time `json:"original_end_date"`
+	ReminderDays    int        `gorm:"default:7" json:"reminder_days"`
+	ReminderSentAt  *time.Time `json:"reminder_sent_at"`
+	Status          string     `gorm:"default:'active'" json:"status"`
+	Outcome         string     `json:"outcome"`
+	OutcomeNotes    string     `json:"outcome_notes"`
+	OutcomeDate     *time.Time `json:"outcome_date"`
+	CreatedBy       *int       `json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// PerformancePIPCheckIn represents tbl_pf_pip_checkins
+type PerformancePIPCheckIn struct {
+	ID             int        `gorm:"primary_key" json:"id"`
+	PIPID          int        `gorm:"column:pip_id;not null" json:"pip_id"`
+	ScheduledDate  time.Time  `gorm:"not null" json:"scheduled_date"`
+	HeldDate       *time.Time `json:"held_date"`
+	Notes          string     `json:"notes"`
+	ProgressRating *int       `json:"progress_rating"`
+	RecordedBy     *int       `json:"recorded_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 }
 
 // PerformanceTraining represents tbl_pf_trainings
@@ -121,7 +237,33 @@ type PerformanceTraining struct {
 	Completed            int        `gorm:"default:0" json:"completed"`
 	CompletionDate       *time.Time `json:"completion_date"`
 	Cost                 *float64   `json:"cost"`
+	Hours                *float64   `json:"hours"`
 	Provider             string     `json:"provider"`
 	CreatedAt            time.Time  `json:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at"`
 }
+
+// PerformanceTrainingEnrolment represents tbl_pf_training_enrolments
+type PerformanceTrainingEnrolment struct {
+	ID           int        `gorm:"primary_key" json:"id"`
+	TrainingID   int        `gorm:"not null" json:"training_id"`
+	EmployeeID   int        `gorm:"not null" json:"employee_id"`
+	DepartmentID *int       `json:"department_id"`
+	Status       string     `gorm:"default:'enrolled'" json:"status"`
+	Cost         float64    `gorm:"default:0.00" json:"cost"`
+	Hours        float64    `gorm:"default:0.00" json:"hours"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	EnrolledBy   *int       `json:"enrolled_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// PerformanceTrainingBudget represents tbl_pf_training_budgets
+type PerformanceTrainingBudget struct {
+	ID           int       `gorm:"primary_key" json:"id"`
+	DepartmentID int       `gorm:"not null" json:"department_id"`
+	Year         int       `gorm:"not null" json:"year"`
+	Amount       float64   `gorm:"not null" json:"amount"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
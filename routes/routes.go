@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"yathuerp/handlers/attendance"
 	"yathuerp/handlers/employees"
+	"yathuerp/handlers/holidays"
+	"yathuerp/handlers/leave"
+	"yathuerp/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -29,4 +33,79 @@ func Setup(app *fiber.App, db *gorm.DB) {
 		employeesGroup.Put("/:id", employeeHandler.UpdateEmployee)
 		employeesGroup.Delete("/:id", employeeHandler.DeleteEmployee)
 	}
+
+	// The leave handler's HR check also guards the other modules' HR-only
+	// endpoints
+	leaveHandler := leave.NewHandler(db)
+
+	// Attendance module routes
+	attendanceHandler := attendance.NewHandler(db)
+	attendanceGroup := api.Group("/attendance", middleware.JWTAuth())
+	{
+		attendanceGroup.Get("/register", attendanceHandler.GetAttendanceRegister)
+		attendanceGroup.Put("/register", leaveHandler.RequireHR, attendanceHandler.UpdateAttendanceRegister)
+		attendanceGroup.Post("/overtime/post", leaveHandler.RequireHR, attendanceHandler.PostApprovedOvertime)
+		attendanceGroup.Get("/analytics", attendanceHandler.GetAttendanceAnalytics)
+		attendanceGroup.Get("/analytics/export", attendanceHandler.ExportAttendanceAnalytics)
+	}
+
+	// Holiday calendar routes
+	holidayHandler := holidays.NewHandler(db)
+	holidaysGroup := api.Group("/holidays", middleware.JWTAuth())
+	{
+		holidaysGroup.Get("/", holidayHandler.GetHolidays)
+		holidaysGroup.Get("/calendar", holidayHandler.GetHolidayCalendar)
+		holidaysGroup.Get("/:id", holidayHandler.GetHolidayByID)
+		holidaysGroup.Post("/", leaveHandler.RequireHR, holidayHandler.CreateHoliday)
+		holidaysGroup.Put("/:id", leaveHandler.RequireHR, holidayHandler.UpdateHoliday)
+		holidaysGroup.Delete("/:id", leaveHandler.RequireHR, holidayHandler.DeleteHoliday)
+	}
+
+	// Leave module routes
+	leaveGroup := api.Group("/leave", middleware.JWTAuth())
+	{
+		leaveGroup.Get("/applications", leaveHandler.GetAllLeaveApplications)
+		leaveGroup.Get("/applications/:id", leaveHandler.GetLeaveApplicationByID)
+		leaveGroup.Post("/applications", leaveHandler.CreateLeaveApplication)
+		leaveGroup.Put("/applications/:id", leaveHandler.UpdateLeaveApplication)
+		leaveGroup.Post("/applications/:id/approve", leaveHandler.ApproveLeaveApplication)
+		leaveGroup.Post("/applications/:id/reject", leaveHandler.RejectLeaveApplication)
+		leaveGroup.Post("/applications/:id/cancel", leaveHandler.CancelLeaveApplication)
+		leaveGroup.Post("/applications/:id/recall", leaveHandler.RecallLeaveApplication)
+		leaveGroup.Post("/applications/:id/return", leaveHandler.ReturnEarlyFromLeave)
+		leaveGroup.Get("/applications/:id/days", leaveHandler.GetLeaveApplicationDays)
+		leaveGroup.Get("/applications/:id/conflicts", leaveHandler.GetLeaveConflicts)
+		leaveGroup.Get("/calendar", leaveHandler.GetTeamCalendar)
+		leaveGroup.Get("/grant-adjustments", leaveHandler.GetLeaveGrantAdjustments)
+		leaveGroup.Get("/applications/:id/approvals", leaveHandler.GetLeaveApprovals)
+		leaveGroup.Get("/types", leaveHandler.GetLeaveTypes)
+		leaveGroup.Get("/types/:id/approval-chain", leaveHandler.GetApprovalChain)
+		leaveGroup.Put("/types/:id/approval-chain", leaveHandler.RequireHR, leaveHandler.SetApprovalChain)
+
+		leaveGroup.Get("/approvals/inbox", leaveHandler.GetApprovalInbox)
+		leaveGroup.Post("/approvals/escalate", leaveHandler.RequireHR, leaveHandler.EscalateApprovals)
+		leaveGroup.Get("/delegations", leaveHandler.GetDelegations)
+		leaveGroup.Post("/delegations", leaveHandler.CreateDelegation)
+		leaveGroup.Delete("/delegations/:id", leaveHandler.DeleteDelegation)
+		leaveGroup.Get("/events", leaveHandler.GetLeaveEvents)
+		leaveGroup.Post("/events/:id/published", leaveHandler.RequireHR, leaveHandler.MarkLeaveEventPublished)
+
+		leaveGroup.Get("/balances/:employeeId", leaveHandler.GetLeaveBalances)
+		leaveGroup.Get("/balances/:employeeId/accruals", leaveHandler.GetLeaveAccruals)
+		leaveGroup.Post("/balances/:employeeId/recompute", leaveHandler.RecomputeLeaveBalances)
+		leaveGroup.Post("/accruals/run", leaveHandler.RequireHR, leaveHandler.RunLeaveAccrual)
+		leaveGroup.Get("/encashments", leaveHandler.GetLeaveEncashments)
+		leaveGroup.Post("/encashments/exit/:employeeId", leaveHandler.RequireHR, leaveHandler.EncashLeaveOnExit)
+		leaveGroup.Post("/encashments/year-end", leaveHandler.RequireHR, leaveHandler.RunYearEndEncashment)
+		leaveGroup.Get("/feeds", leaveHandler.GetCalendarFeeds)
+		leaveGroup.Post("/feeds", leaveHandler.CreateCalendarFeed)
+		leaveGroup.Delete("/feeds/:id", leaveHandler.DeleteCalendarFeed)
+	}
+
+	// Reporting lines route leave approvals and scope team calendars
+	api.Put("/employees/:employeeId/manager", middleware.JWTAuth(), leaveHandler.RequireHR, leaveHandler.SetEmployeeManager)
+
+	// Calendar feeds are fetched by external calendar apps, which authenticate
+	// with the token in the URL rather than a JWT
+	api.Get("/calendar/feeds/:token.ics", leaveHandler.GetCalendarFeed)
 }
@@ -13,9 +13,13 @@ import (
 	"yathuerp/shared/logger"
 	"yathuerp/shared/middleware"
 
+	"yathuerp/services/attendance/internal/application"
+	"yathuerp/services/attendance/internal/infrastructure/http"
+	"yathuerp/services/attendance/internal/infrastructure/persistence/postgres"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	fiberlogger "github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
@@ -46,7 +50,7 @@ func main() {
 
 	// Global middleware
 	app.Use(recover.New())
-	app.Use(logger.New())
+	app.Use(fiberlogger.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowMethods: "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
@@ -66,7 +70,48 @@ func main() {
 	})
 
 	// Setup routes
-	setupRoutes(app, db)
+	pool := db.GetPool()
+	serviceLogger := logger.ServiceLogger{}
+
+	authMiddleware := middleware.NewAuthMiddleware(serviceLogger)
+	app.Use(authMiddleware.JWTAuth(cfg.JWTSecret))
+
+	attendanceRepo := postgres.NewAttendanceRepository(pool, serviceLogger)
+	shiftRepo := postgres.NewShiftRepository(pool, serviceLogger)
+	employeeShiftRepo := postgres.NewEmployeeShiftRepository(pool, serviceLogger)
+	punchRepo := postgres.NewPunchRepository(pool, serviceLogger)
+	punchImportRepo := postgres.NewPunchImportRepository(pool, serviceLogger)
+	punchExceptionRepo := postgres.NewPunchExceptionRepository(pool, serviceLogger)
+	deviceUserRepo := postgres.NewDeviceUserRepository(pool, serviceLogger)
+	rosterPatternRepo := postgres.NewRosterPatternRepository(pool, serviceLogger)
+	rosterAssignmentRepo := postgres.NewRosterAssignmentRepository(pool, serviceLogger)
+	rosterEntryRepo := postgres.NewRosterEntryRepository(pool, serviceLogger)
+	shiftSwapRepo := postgres.NewShiftSwapRepository(pool, serviceLogger)
+	employeeDirectory := postgres.NewEmployeeDirectory(pool, serviceLogger)
+	geofenceRepo := postgres.NewGeofenceRepository(pool, serviceLogger)
+	evidenceRepo := postgres.NewAttendanceEvidenceRepository(pool, serviceLogger)
+	exceptionRepo := postgres.NewAttendanceExceptionRepository(pool, serviceLogger)
+
+	timeClockUseCase := application.NewTimeClockUseCase(
+		attendanceRepo, shiftRepo, employeeShiftRepo, rosterEntryRepo,
+		geofenceRepo, evidenceRepo, exceptionRepo, employeeDirectory, serviceLogger,
+	)
+	shiftUseCase := application.NewShiftUseCase(shiftRepo, employeeShiftRepo, serviceLogger)
+	punchUseCase := application.NewPunchUseCase(
+		punchRepo, punchImportRepo, punchExceptionRepo, deviceUserRepo,
+		attendanceRepo, shiftRepo, employeeShiftRepo, rosterEntryRepo, exceptionRepo, serviceLogger,
+	)
+	rosterUseCase := application.NewRosterUseCase(
+		rosterPatternRepo, rosterAssignmentRepo, rosterEntryRepo, shiftSwapRepo,
+		shiftRepo, employeeDirectory, serviceLogger,
+	)
+
+	geofenceUseCase := application.NewGeofenceUseCase(geofenceRepo, evidenceRepo, attendanceRepo, employeeDirectory, serviceLogger)
+	exceptionUseCase := application.NewExceptionUseCase(exceptionRepo, attendanceRepo, shiftRepo, employeeDirectory, serviceLogger)
+
+	http.SetupRoutes(app, http.NewHandler(
+		timeClockUseCase, shiftUseCase, punchUseCase, rosterUseCase, geofenceUseCase, exceptionUseCase, serviceLogger,
+	), authMiddleware)
 
 	// Graceful shutdown
 	go func() {
@@ -88,15 +133,3 @@ func main() {
 	logger.Info("Attendance service starting on port %s", cfg.Port)
 	log.Fatal(app.Listen(":" + cfg.Port))
 }
-
-func setupRoutes(app *fiber.App, db interface{}) {
-	// TODO: Setup routes from internal/infrastructure/http
-	// This will be implemented in next step
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message":    "YathuERP Attendance Service is running",
-			"service":    "attendance",
-			"go_version": "1.22",
-		})
-	})
-}
@@ -0,0 +1,34 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"yathuerp/services/attendance/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Caller is the authenticated user a request is made by, taken from the JWT
+// rather than the request body
+type Caller struct {
+	UserID *uuid.UUID
+	Email  string
+	IsHR   bool
+}
+
+// callerEmployee finds the employee the caller signs in as. It returns nil
+// for an HR account that is not an employee.
+func callerEmployee(directory domain.EmployeeDirectory, caller *Caller) (*domain.DirectoryEmployee, error) {
+	if caller == nil || caller.Email == "" {
+		return nil, fmt.Errorf("%w: the request is not made by a user", ErrForbidden)
+	}
+	employee, err := directory.GetByEmail(caller.Email)
+	if errors.Is(err, domain.ErrNotFound) {
+		if caller.IsHR {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: your account is not linked to an employee", ErrForbidden)
+	}
+	return employee, err
+}
@@ -0,0 +1,380 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+type ExceptionUseCase struct {
+	exceptionRepo  domain.AttendanceExceptionRepository
+	attendanceRepo domain.AttendanceRepository
+	shiftRepo      domain.ShiftRepository
+	directory      domain.EmployeeDirectory
+	logger         utils.Logger
+}
+
+func NewExceptionUseCase(
+	exceptionRepo domain.AttendanceExceptionRepository,
+	attendanceRepo domain.AttendanceRepository,
+	shiftRepo domain.ShiftRepository,
+	directory domain.EmployeeDirectory,
+	logger utils.Logger,
+) *ExceptionUseCase {
+	return &ExceptionUseCase{
+		exceptionRepo:  exceptionRepo,
+		attendanceRepo: attendanceRepo,
+		shiftRepo:      shiftRepo,
+		directory:      directory,
+		logger:         logger,
+	}
+}
+
+type JustifyExceptionRequest struct {
+	Justification string `json:"justification" validate:"required"`
+}
+
+// OvertimeClaimRequest claims overtime the clock did not compute, or a
+// different number of hours than it did
+type OvertimeClaimRequest struct {
+	AttendanceID  string  `json:"attendance_id" validate:"required,uuid"`
+	Hours         float64 `json:"hours" validate:"gt=0"`
+	Justification string  `json:"justification" validate:"required"`
+}
+
+type DecideExceptionRequest struct {
+	Approve       bool     `json:"approve"`
+	ApprovedHours *float64 `json:"approved_hours"` // overtime only, defaults to the hours claimed
+	Comment       string   `json:"comment"`
+}
+
+func (uc *ExceptionUseCase) Get(ctx context.Context, id uuid.UUID) (*domain.AttendanceException, error) {
+	return uc.exceptionRepo.GetByID(id)
+}
+
+func (uc *ExceptionUseCase) List(ctx context.Context, filter *domain.AttendanceExceptionFilter) ([]*domain.AttendanceException, error) {
+	return uc.exceptionRepo.GetAll(filter)
+}
+
+// Justify records the calling employee's explanation for one of their
+// exceptions
+func (uc *ExceptionUseCase) Justify(ctx context.Context, id uuid.UUID, req *JustifyExceptionRequest, caller *Caller) (*domain.AttendanceException, error) {
+	employee, err := callerEmployee(uc.directory, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	exception, err := uc.exceptionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if employee == nil || exception.EmployeeID != employee.ID {
+		return nil, fmt.Errorf("%w: only the employee can justify their attendance exception", ErrForbidden)
+	}
+	if !exception.Pending() {
+		return nil, fmt.Errorf("%w: attendance exception is %s", ErrInvalidState, exception.Status)
+	}
+
+	now := time.Now()
+	exception.Justification = strings.TrimSpace(req.Justification)
+	exception.Status = domain.ExceptionStatusJustified
+	exception.JustifiedAt = &now
+	if err := uc.exceptionRepo.Update(exception); err != nil {
+		return nil, err
+	}
+
+	return exception, nil
+}
+
+// ClaimOvertime raises or amends the overtime exception of one of the
+// calling employee's completed attendances with the hours they say they
+// worked
+func (uc *ExceptionUseCase) ClaimOvertime(ctx context.Context, req *OvertimeClaimRequest, caller *Caller) (*domain.AttendanceException, error) {
+	attendanceID, err := uuid.Parse(req.AttendanceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attendance ID")
+	}
+	employee, err := callerEmployee(uc.directory, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	attendance, err := uc.attendanceRepo.GetByID(attendanceID)
+	if err != nil {
+		return nil, err
+	}
+	if employee == nil || attendance.EmployeeID != employee.ID {
+		return nil, fmt.Errorf("%w: overtime can only be claimed on your own attendance", ErrForbidden)
+	}
+	if attendance.CheckOut == nil {
+		return nil, fmt.Errorf("%w: attendance has no clock-out", ErrInvalidState)
+	}
+	if req.Hours > attendance.WorkHours {
+		return nil, fmt.Errorf("claimed hours exceed the %.2f hours worked", attendance.WorkHours)
+	}
+
+	existing, err := uc.exceptionRepo.GetByAttendance(attendance.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, exception := range existing {
+		if exception.Type != domain.ExceptionOvertime {
+			continue
+		}
+		if !exception.Pending() {
+			return nil, fmt.Errorf("%w: overtime for this attendance is already %s", ErrInvalidState, exception.Status)
+		}
+		exception.Hours = req.Hours
+		exception.Claimed = true
+		exception.Justification = strings.TrimSpace(req.Justification)
+		exception.Status = domain.ExceptionStatusJustified
+		exception.JustifiedAt = &now
+		if err := uc.exceptionRepo.Update(exception); err != nil {
+			return nil, err
+		}
+		return exception, nil
+	}
+
+	exception := &domain.AttendanceException{
+		ID:             uuid.New(),
+		AttendanceID:   attendance.ID,
+		EmployeeID:     attendance.EmployeeID,
+		AttendanceDate: attendance.AttendanceDate,
+		Type:           domain.ExceptionOvertime,
+		Hours:          req.Hours,
+		Claimed:        true,
+		Details:        fmt.Sprintf("%.2f hours claimed, %.2f computed", req.Hours, attendance.OvertimeHours),
+		Justification:  strings.TrimSpace(req.Justification),
+		Status:         domain.ExceptionStatusJustified,
+		JustifiedAt:    &now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := uc.exceptionRepo.Create(exception); err != nil {
+		return nil, err
+	}
+
+	return exception, nil
+}
+
+// Decide approves or rejects a pending exception. Only HR or the employee's
+// manager can decide, and never on their own attendance. Approved overtime
+// keeps the hours the manager accepted for payroll to post. Once an
+// attendance has no exception left pending it is marked approved by the
+// manager who settled the last one.
+func (uc *ExceptionUseCase) Decide(ctx context.Context, id uuid.UUID, req *DecideExceptionRequest, caller *Caller) (*domain.AttendanceException, error) {
+	exception, err := uc.exceptionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !exception.Pending() {
+		return nil, fmt.Errorf("%w: attendance exception is %s", ErrInvalidState, exception.Status)
+	}
+	if err := uc.checkDecider(exception, caller); err != nil {
+		return nil, err
+	}
+
+	exception.Status = domain.ExceptionStatusRejected
+	exception.ApprovedHours = 0
+	if req.Approve {
+		exception.Status = domain.ExceptionStatusApproved
+		if exception.Type == domain.ExceptionOvertime {
+			exception.ApprovedHours = exception.Hours
+			if req.ApprovedHours != nil {
+				if *req.ApprovedHours <= 0 || *req.ApprovedHours > exception.Hours {
+					return nil, fmt.Errorf("approved hours must be between 0 and the %.2f hours claimed", exception.Hours)
+				}
+				exception.ApprovedHours = *req.ApprovedHours
+			}
+		}
+	}
+
+	now := time.Now()
+	exception.DecidedBy = caller.UserID
+	exception.DecidedAt = &now
+	exception.DecisionComment = req.Comment
+	if err := uc.exceptionRepo.Update(exception); err != nil {
+		return nil, err
+	}
+
+	if err := uc.settleAttendance(exception.AttendanceID, *caller.UserID); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Attendance exception decided",
+		"attendance_exception_id", exception.ID,
+		"type", exception.Type,
+		"status", exception.Status)
+	return exception, nil
+}
+
+// checkDecider makes sure the caller is HR or the employee's manager, and not
+// the employee
+func (uc *ExceptionUseCase) checkDecider(exception *domain.AttendanceException, caller *Caller) error {
+	if caller.UserID == nil {
+		return fmt.Errorf("%w: attendance exceptions must be decided by a user", ErrForbidden)
+	}
+	decider, err := callerEmployee(uc.directory, caller)
+	if err != nil {
+		return err
+	}
+	if decider != nil && decider.ID == exception.EmployeeID {
+		return fmt.Errorf("%w: you cannot decide your own attendance exception", ErrForbidden)
+	}
+	if caller.IsHR {
+		return nil
+	}
+
+	employee, err := uc.directory.GetByID(exception.EmployeeID)
+	if err != nil {
+		return err
+	}
+	if employee.ManagerID == nil || *employee.ManagerID != decider.ID {
+		return fmt.Errorf("%w: only HR or the employee's manager can decide this exception", ErrForbidden)
+	}
+	return nil
+}
+
+// settleAttendance marks the attendance approved once none of its exceptions
+// is pending
+func (uc *ExceptionUseCase) settleAttendance(attendanceID, approvedBy uuid.UUID) error {
+	exceptions, err := uc.exceptionRepo.GetByAttendance(attendanceID)
+	if err != nil {
+		return err
+	}
+	for _, exception := range exceptions {
+		if exception.Pending() {
+			return nil
+		}
+	}
+
+	attendance, err := uc.attendanceRepo.GetByID(attendanceID)
+	if err != nil {
+		return err
+	}
+	attendance.ApprovedBy = &approvedBy
+	return uc.attendanceRepo.Update(attendance)
+}
+
+// ScanMissedPunches raises a missed punch for every attendance on date that
+// was clocked in but never out once its shift has ended.
+func (uc *ExceptionUseCase) ScanMissedPunches(ctx context.Context, date time.Time) ([]*domain.AttendanceException, error) {
+	attendances, err := uc.attendanceRepo.GetByDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	shifts := map[uuid.UUID]*domain.Shift{}
+	raised := []*domain.AttendanceException{}
+	for _, attendance := range attendances {
+		if attendance.CheckIn == nil || attendance.CheckOut != nil || attendance.ShiftID == nil {
+			continue
+		}
+
+		shift, ok := shifts[*attendance.ShiftID]
+		if !ok {
+			if shift, err = uc.shiftRepo.GetByID(*attendance.ShiftID); err != nil {
+				return nil, err
+			}
+			shifts[shift.ID] = shift
+		}
+		window, err := shift.Window(localDate(attendance.AttendanceDate, attendance.CheckIn.Location()))
+		if err != nil {
+			return nil, err
+		}
+
+		created, err := syncExceptions(uc.exceptionRepo, attendance, shift, window, now)
+		if err != nil {
+			return nil, err
+		}
+		raised = append(raised, created...)
+	}
+
+	return raised, nil
+}
+
+// syncExceptions brings the exceptions raised by the clock for an attendance
+// in line with it: a late check-in, a clock-in without a clock-out after the
+// shift has ended, and overtime. Pending exceptions whose cause has gone, e.g.
+// a missed punch after the clock-out is imported, are dropped; decided ones
+// and overtime the employee claimed are left alone. It returns the exceptions
+// it created.
+func syncExceptions(repo domain.AttendanceExceptionRepository, attendance *domain.Attendance, shift *domain.Shift, window domain.ShiftWindow, now time.Time) ([]*domain.AttendanceException, error) {
+	wanted := map[string]*domain.AttendanceException{}
+	if attendance.CheckIn != nil {
+		if minutes := domain.LateMinutes(shift, window, *attendance.CheckIn); minutes > 0 {
+			wanted[domain.ExceptionLate] = &domain.AttendanceException{
+				Type:    domain.ExceptionLate,
+				Minutes: minutes,
+				Details: fmt.Sprintf("checked in at %s for shift %s starting %s",
+					attendance.CheckIn.In(window.Start.Location()).Format("15:04"), shift.Code, window.Start.Format("15:04")),
+			}
+		}
+		if attendance.CheckOut == nil && now.After(window.End) {
+			wanted[domain.ExceptionMissedPunch] = &domain.AttendanceException{
+				Type:    domain.ExceptionMissedPunch,
+				Details: fmt.Sprintf("no clock-out after shift %s ended at %s", shift.Code, window.End.Format("15:04")),
+			}
+		}
+	}
+	if attendance.OvertimeHours > 0 {
+		wanted[domain.ExceptionOvertime] = &domain.AttendanceException{
+			Type:    domain.ExceptionOvertime,
+			Hours:   attendance.OvertimeHours,
+			Details: fmt.Sprintf("%.2f hours worked beyond the %.2f scheduled", attendance.OvertimeHours, shift.ScheduledHours(window)),
+		}
+	}
+
+	existing, err := repo.GetByAttendance(attendance.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, exception := range existing {
+		want, ok := wanted[exception.Type]
+		delete(wanted, exception.Type)
+		if !exception.Pending() || exception.Claimed {
+			continue
+		}
+		if !ok {
+			if err := repo.Delete(exception.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if exception.Minutes == want.Minutes && exception.Hours == want.Hours && exception.Details == want.Details {
+			continue
+		}
+		exception.Minutes = want.Minutes
+		exception.Hours = want.Hours
+		exception.Details = want.Details
+		if err := repo.Update(exception); err != nil {
+			return nil, err
+		}
+	}
+
+	created := []*domain.AttendanceException{}
+	for _, exception := range wanted {
+		exception.ID = uuid.New()
+		exception.AttendanceID = attendance.ID
+		exception.EmployeeID = attendance.EmployeeID
+		exception.AttendanceDate = attendance.AttendanceDate
+		exception.Status = domain.ExceptionStatusOpen
+		exception.CreatedAt = now
+		exception.UpdatedAt = now
+		if err := repo.Create(exception); err != nil {
+			return nil, err
+		}
+		created = append(created, exception)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,323 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+// maxPhotoBytes caps the decoded size of a clock-in photo
+const maxPhotoBytes = 2 << 20
+
+type GeofenceUseCase struct {
+	geofenceRepo   domain.GeofenceRepository
+	evidenceRepo   domain.AttendanceEvidenceRepository
+	attendanceRepo domain.AttendanceRepository
+	directory      domain.EmployeeDirectory
+	logger         utils.Logger
+}
+
+func NewGeofenceUseCase(
+	geofenceRepo domain.GeofenceRepository,
+	evidenceRepo domain.AttendanceEvidenceRepository,
+	attendanceRepo domain.AttendanceRepository,
+	directory domain.EmployeeDirectory,
+	logger utils.Logger,
+) *GeofenceUseCase {
+	return &GeofenceUseCase{
+		geofenceRepo:   geofenceRepo,
+		evidenceRepo:   evidenceRepo,
+		attendanceRepo: attendanceRepo,
+		directory:      directory,
+		logger:         logger,
+	}
+}
+
+// GeofenceRequest describes a circle around Latitude/Longitude, or a polygon
+// when three or more points are given; the centre is then the polygon's
+// average point unless set.
+type GeofenceRequest struct {
+	BranchID     string            `json:"branch_id" validate:"required,uuid"`
+	Name         string            `json:"name" validate:"required"`
+	Latitude     *float64          `json:"latitude" validate:"omitempty,min=-90,max=90"`
+	Longitude    *float64          `json:"longitude" validate:"omitempty,min=-180,max=180"`
+	RadiusMeters float64           `json:"radius_meters" validate:"min=0"`
+	Polygon      []domain.GeoPoint `json:"polygon"`
+	Active       *bool             `json:"active"` // defaults to true
+}
+
+// LocationRequest is a GPS fix reported by the mobile app
+type LocationRequest struct {
+	Latitude  float64 `json:"latitude" validate:"min=-90,max=90"`
+	Longitude float64 `json:"longitude" validate:"min=-180,max=180"`
+	Accuracy  float64 `json:"accuracy" validate:"min=0"` // meters, as reported by the device
+}
+
+type ReviewEvidenceRequest struct {
+	Approve bool   `json:"approve"`
+	Comment string `json:"comment"`
+}
+
+func (uc *GeofenceUseCase) Create(ctx context.Context, req *GeofenceRequest) (*domain.Geofence, error) {
+	now := time.Now()
+	geofence := &domain.Geofence{
+		ID:        uuid.New(),
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := applyGeofenceRequest(geofence, req); err != nil {
+		return nil, fmt.Errorf("business validation failed: %w", err)
+	}
+
+	if err := uc.geofenceRepo.Create(geofence); err != nil {
+		return nil, err
+	}
+
+	return geofence, nil
+}
+
+func (uc *GeofenceUseCase) Update(ctx context.Context, id uuid.UUID, req *GeofenceRequest) (*domain.Geofence, error) {
+	geofence, err := uc.geofenceRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyGeofenceRequest(geofence, req); err != nil {
+		return nil, fmt.Errorf("business validation failed: %w", err)
+	}
+
+	if err := uc.geofenceRepo.Update(geofence); err != nil {
+		return nil, err
+	}
+
+	return geofence, nil
+}
+
+func (uc *GeofenceUseCase) Get(ctx context.Context, id uuid.UUID) (*domain.Geofence, error) {
+	return uc.geofenceRepo.GetByID(id)
+}
+
+func (uc *GeofenceUseCase) List(ctx context.Context, branchID *uuid.UUID) ([]*domain.Geofence, error) {
+	return uc.geofenceRepo.GetAll(branchID, false)
+}
+
+func (uc *GeofenceUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := uc.geofenceRepo.GetByID(id); err != nil {
+		return err
+	}
+	return uc.geofenceRepo.Delete(id)
+}
+
+func (uc *GeofenceUseCase) GetEvidence(ctx context.Context, id uuid.UUID) (*domain.AttendanceEvidence, error) {
+	return uc.evidenceRepo.GetByID(id)
+}
+
+func (uc *GeofenceUseCase) ListEvidence(ctx context.Context, filter *domain.AttendanceEvidenceFilter) ([]*domain.AttendanceEvidence, error) {
+	return uc.evidenceRepo.GetAll(filter)
+}
+
+// GetEvidencePhoto returns the photo taken with a punch, shown only to HR and
+// the employee's manager
+func (uc *GeofenceUseCase) GetEvidencePhoto(ctx context.Context, id uuid.UUID, caller *Caller) ([]byte, string, error) {
+	evidence, err := uc.evidenceRepo.GetByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := uc.checkReviewer(evidence, caller); err != nil {
+		return nil, "", err
+	}
+	return uc.evidenceRepo.GetPhoto(id)
+}
+
+// ReviewEvidence records a decision by HR or the employee's manager on a punch
+// made outside every geofence, never on their own punch. Approving it also
+// marks the attendance as approved by them.
+func (uc *GeofenceUseCase) ReviewEvidence(ctx context.Context, id uuid.UUID, req *ReviewEvidenceRequest, caller *Caller) (*domain.AttendanceEvidence, error) {
+	evidence, err := uc.evidenceRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if evidence.ReviewStatus != domain.ReviewPending {
+		return nil, fmt.Errorf("%w: evidence review is %s", ErrInvalidState, evidence.ReviewStatus)
+	}
+	if caller.UserID == nil {
+		return nil, fmt.Errorf("%w: evidence must be reviewed by a user", ErrForbidden)
+	}
+	reviewer, err := uc.checkReviewer(evidence, caller)
+	if err != nil {
+		return nil, err
+	}
+	if reviewer != nil && reviewer.ID == evidence.EmployeeID {
+		return nil, fmt.Errorf("%w: you cannot review your own punch", ErrForbidden)
+	}
+	reviewedBy := caller.UserID
+
+	now := time.Now()
+	evidence.ReviewStatus = domain.ReviewRejected
+	if req.Approve {
+		evidence.ReviewStatus = domain.ReviewApproved
+	}
+	evidence.ReviewedBy = reviewedBy
+	evidence.ReviewedAt = &now
+	evidence.ReviewComment = req.Comment
+	if err := uc.evidenceRepo.UpdateReview(evidence); err != nil {
+		return nil, err
+	}
+
+	if req.Approve {
+		attendance, err := uc.attendanceRepo.GetByID(evidence.AttendanceID)
+		if err != nil {
+			return nil, err
+		}
+		attendance.ApprovedBy = reviewedBy
+		if err := uc.attendanceRepo.Update(attendance); err != nil {
+			return nil, err
+		}
+	}
+
+	uc.logger.Info("Attendance evidence reviewed",
+		"attendance_evidence_id", evidence.ID,
+		"review_status", evidence.ReviewStatus)
+	return evidence, nil
+}
+
+// checkReviewer makes sure the caller is HR or the employee's manager. It
+// returns the caller's employee, nil for an HR account that is not one.
+func (uc *GeofenceUseCase) checkReviewer(evidence *domain.AttendanceEvidence, caller *Caller) (*domain.DirectoryEmployee, error) {
+	reviewer, err := callerEmployee(uc.directory, caller)
+	if err != nil {
+		return nil, err
+	}
+	if caller.IsHR {
+		return reviewer, nil
+	}
+
+	employee, err := uc.directory.GetByID(evidence.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	if employee.ManagerID == nil || *employee.ManagerID != reviewer.ID {
+		return nil, fmt.Errorf("%w: only HR or the employee's manager can review this evidence", ErrForbidden)
+	}
+	return reviewer, nil
+}
+
+func applyGeofenceRequest(geofence *domain.Geofence, req *GeofenceRequest) error {
+	branchID, err := uuid.Parse(req.BranchID)
+	if err != nil {
+		return fmt.Errorf("invalid branch ID")
+	}
+
+	for i, point := range req.Polygon {
+		if !point.Valid() {
+			return fmt.Errorf("polygon point %d is not a valid coordinate", i+1)
+		}
+	}
+	polygon := req.Polygon
+	if polygon == nil {
+		polygon = []domain.GeoPoint{}
+	}
+	if len(polygon) > 0 && len(polygon) < 3 {
+		return fmt.Errorf("a polygon needs at least three points")
+	}
+
+	var centre domain.GeoPoint
+	switch {
+	case req.Latitude != nil && req.Longitude != nil:
+		centre = domain.GeoPoint{Latitude: *req.Latitude, Longitude: *req.Longitude}
+	case len(polygon) > 0:
+		for _, point := range polygon {
+			centre.Latitude += point.Latitude / float64(len(polygon))
+			centre.Longitude += point.Longitude / float64(len(polygon))
+		}
+	default:
+		return fmt.Errorf("latitude and longitude are required without a polygon")
+	}
+	if len(polygon) == 0 && req.RadiusMeters <= 0 {
+		return fmt.Errorf("radius_meters is required without a polygon")
+	}
+
+	geofence.BranchID = branchID
+	geofence.Name = strings.TrimSpace(req.Name)
+	geofence.Latitude = centre.Latitude
+	geofence.Longitude = centre.Longitude
+	geofence.RadiusMeters = req.RadiusMeters
+	geofence.Polygon = polygon
+	if req.Active != nil {
+		geofence.Active = *req.Active
+	}
+	return nil
+}
+
+// checkGeofences places a punch against the active geofences of the
+// employee's branch, or of every branch when their branch is not known.
+// Inside any of them needs no review; outside all of them is recorded against
+// the nearest and waits for a manager. With no geofences to check against the
+// punch is not judged.
+func checkGeofences(evidence *domain.AttendanceEvidence, point domain.GeoPoint, geofences []*domain.Geofence) {
+	evidence.ReviewStatus = domain.ReviewNotRequired
+	if len(geofences) == 0 {
+		return
+	}
+
+	var nearest *domain.Geofence
+	nearestDistance := math.Inf(1)
+	for _, geofence := range geofences {
+		distance := geofence.Centre().DistanceTo(point)
+		if geofence.Contains(point) {
+			nearest, nearestDistance = geofence, distance
+			evidence.WithinFence = boolPtr(true)
+			break
+		}
+		if distance < nearestDistance {
+			nearest, nearestDistance = geofence, distance
+		}
+	}
+
+	distance := math.Round(nearestDistance*10) / 10
+	evidence.GeofenceID = &nearest.ID
+	evidence.BranchID = &nearest.BranchID
+	evidence.DistanceMeters = &distance
+	if evidence.WithinFence == nil {
+		evidence.WithinFence = boolPtr(false)
+		evidence.ReviewStatus = domain.ReviewPending
+	}
+}
+
+// decodePhoto reads a base64 photo, optionally given as a data URL, and checks
+// it is a JPEG or PNG of acceptable size
+func decodePhoto(value string) ([]byte, string, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "data:") {
+		if i := strings.Index(value, ","); i >= 0 {
+			value = value[i+1:]
+		}
+	}
+
+	photo, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, "", fmt.Errorf("photo is not valid base64")
+	}
+	if len(photo) > maxPhotoBytes {
+		return nil, "", fmt.Errorf("photo exceeds %d MB", maxPhotoBytes>>20)
+	}
+
+	contentType := http.DetectContentType(photo)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return nil, "", fmt.Errorf("photo must be a JPEG or PNG image")
+	}
+	return photo, contentType, nil
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
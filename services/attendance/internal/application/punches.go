@@ -0,0 +1,471 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+type PunchUseCase struct {
+	punchRepo          domain.PunchRepository
+	punchImportRepo    domain.PunchImportRepository
+	punchExceptionRepo domain.PunchExceptionRepository
+	deviceUserRepo     domain.DeviceUserRepository
+	attendanceRepo     domain.AttendanceRepository
+	shiftRepo          domain.ShiftRepository
+	employeeShiftRepo  domain.EmployeeShiftRepository
+	rosterEntryRepo    domain.RosterEntryRepository
+	exceptionRepo      domain.AttendanceExceptionRepository
+	logger             utils.Logger
+}
+
+func NewPunchUseCase(
+	punchRepo domain.PunchRepository,
+	punchImportRepo domain.PunchImportRepository,
+	punchExceptionRepo domain.PunchExceptionRepository,
+	deviceUserRepo domain.DeviceUserRepository,
+	attendanceRepo domain.AttendanceRepository,
+	shiftRepo domain.ShiftRepository,
+	employeeShiftRepo domain.EmployeeShiftRepository,
+	rosterEntryRepo domain.RosterEntryRepository,
+	exceptionRepo domain.AttendanceExceptionRepository,
+	logger utils.Logger,
+) *PunchUseCase {
+	return &PunchUseCase{
+		punchRepo:          punchRepo,
+		punchImportRepo:    punchImportRepo,
+		punchExceptionRepo: punchExceptionRepo,
+		deviceUserRepo:     deviceUserRepo,
+		attendanceRepo:     attendanceRepo,
+		shiftRepo:          shiftRepo,
+		employeeShiftRepo:  employeeShiftRepo,
+		rosterEntryRepo:    rosterEntryRepo,
+		exceptionRepo:      exceptionRepo,
+		logger:             logger,
+	}
+}
+
+type PunchBatchRequest struct {
+	DeviceID string         `json:"device_id"`
+	Punches  []PunchRequest `json:"punches" validate:"required,min=1,dive"`
+}
+
+type PunchRequest struct {
+	DeviceID     string    `json:"device_id"` // overrides the batch device
+	DeviceUserID string    `json:"device_user_id" validate:"required"`
+	Time         time.Time `json:"time" validate:"required"`
+	Direction    string    `json:"direction" validate:"omitempty,oneof=in out"`
+}
+
+type DeviceUserRequest struct {
+	DeviceID     string `json:"device_id"` // empty maps the user on every terminal
+	DeviceUserID string `json:"device_user_id" validate:"required"`
+	EmployeeID   string `json:"employee_id" validate:"required,uuid"`
+}
+
+type ResolvePunchExceptionRequest struct {
+	Notes string `json:"notes"`
+}
+
+// IngestRequest is a parsed punch log or pushed batch of punches
+type IngestRequest struct {
+	Source     string
+	FileName   string
+	DeviceID   string // applies to punches that do not name a terminal
+	Log        *domain.PunchLog
+	ImportedBy *uuid.UUID
+}
+
+// punchDay groups the new punches of one employee's shift on one date
+type punchDay struct {
+	employeeID uuid.UUID
+	date       time.Time
+	shift      *domain.Shift
+}
+
+// Push ingests punches sent directly by a terminal or middleware
+func (uc *PunchUseCase) Push(ctx context.Context, req *PunchBatchRequest, pushedBy *uuid.UUID) (*domain.PunchImport, error) {
+	log := &domain.PunchLog{Lines: len(req.Punches)}
+	for i, punch := range req.Punches {
+		log.Punches = append(log.Punches, domain.RawPunch{
+			Line:         i + 1,
+			DeviceID:     strings.TrimSpace(punch.DeviceID),
+			DeviceUserID: strings.TrimSpace(punch.DeviceUserID),
+			Time:         punch.Time,
+			Direction:    punch.Direction,
+		})
+	}
+
+	return uc.Ingest(ctx, &IngestRequest{
+		Source:     domain.PunchSourceAPI,
+		DeviceID:   strings.TrimSpace(req.DeviceID),
+		Log:        log,
+		ImportedBy: pushedBy,
+	})
+}
+
+// Ingest stores a log's punches, skipping ones already seen, and rebuilds the
+// attendance of every shift that received new punches. Lines and punches that
+// cannot be turned into attendance are recorded as exceptions on the import.
+func (uc *PunchUseCase) Ingest(ctx context.Context, req *IngestRequest) (*domain.PunchImport, error) {
+	now := time.Now()
+	punchImport := &domain.PunchImport{
+		ID:         uuid.New(),
+		Source:     req.Source,
+		FileName:   req.FileName,
+		DeviceID:   req.DeviceID,
+		TotalLines: req.Log.Lines,
+		ImportedBy: req.ImportedBy,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := uc.punchImportRepo.Create(punchImport); err != nil {
+		return nil, err
+	}
+
+	for _, rejected := range req.Log.Rejected {
+		err := uc.raise(punchImport, &domain.PunchException{
+			DeviceID: req.DeviceID,
+			Line:     rejected.Line,
+			Raw:      rejected.Raw,
+			Reason:   domain.PunchExceptionUnparseable,
+			Details:  rejected.Reason,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	shifts := newShiftLookup(uc.shiftRepo, uc.employeeShiftRepo, uc.rosterEntryRepo)
+	employees := map[string]*uuid.UUID{}
+	days := map[string]*punchDay{}
+	var order []string
+
+	for _, raw := range req.Log.Punches {
+		deviceID := raw.DeviceID
+		if deviceID == "" {
+			deviceID = req.DeviceID
+		}
+
+		employeeID, err := uc.employeeFor(employees, deviceID, raw.DeviceUserID)
+		if err != nil {
+			return nil, err
+		}
+
+		punch := &domain.Punch{
+			ID:           uuid.New(),
+			ImportID:     &punchImport.ID,
+			DeviceID:     deviceID,
+			DeviceUserID: raw.DeviceUserID,
+			EmployeeID:   employeeID,
+			PunchTime:    raw.Time,
+			Direction:    raw.Direction,
+			Source:       req.Source,
+			Status:       domain.PunchStatusPending,
+			CreatedAt:    now,
+		}
+		if employeeID == nil {
+			punch.Status = domain.PunchStatusException
+		}
+
+		inserted, err := uc.punchRepo.CreateIfNew(punch)
+		if err != nil {
+			return nil, err
+		}
+		if !inserted {
+			punchImport.Duplicates++
+			continue
+		}
+		punchImport.Imported++
+
+		exception := &domain.PunchException{
+			PunchID:      &punch.ID,
+			EmployeeID:   employeeID,
+			DeviceID:     deviceID,
+			DeviceUserID: raw.DeviceUserID,
+			PunchTime:    &punch.PunchTime,
+			Line:         raw.Line,
+			Raw:          raw.Raw,
+		}
+		if employeeID == nil {
+			exception.Reason = domain.PunchExceptionUnmappedUser
+			exception.Details = fmt.Sprintf("device user %s is not mapped to an employee", raw.DeviceUserID)
+			if err := uc.raise(punchImport, exception); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		shift, date, err := shifts.forLoggedPunch(*employeeID, raw.Time)
+		if errors.Is(err, ErrNoShift) {
+			exception.Reason = domain.PunchExceptionNoShift
+			exception.Details = err.Error()
+			if err := uc.raise(punchImport, exception); err != nil {
+				return nil, err
+			}
+			if err := uc.punchRepo.SetResult([]uuid.UUID{punch.ID}, domain.PunchStatusException, nil); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		key := employeeID.String() + date.Format(dateLayout)
+		if _, ok := days[key]; !ok {
+			days[key] = &punchDay{employeeID: *employeeID, date: date, shift: shift}
+			order = append(order, key)
+		}
+	}
+
+	for _, key := range order {
+		if err := uc.recordDay(punchImport, days[key], now); err != nil {
+			return nil, err
+		}
+		punchImport.Sessions++
+	}
+
+	if err := uc.punchImportRepo.Update(punchImport); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Punch log ingested",
+		"punch_import_id", punchImport.ID,
+		"imported", punchImport.Imported,
+		"duplicates", punchImport.Duplicates,
+		"exceptions", punchImport.Exceptions)
+	return punchImport, nil
+}
+
+// recordDay pairs all stored punches of one shift into a session and writes
+// it to the employee's attendance for the date, keeping an earlier check-in or
+// later check-out already recorded by the time clock.
+func (uc *PunchUseCase) recordDay(punchImport *domain.PunchImport, day *punchDay, now time.Time) error {
+	window, err := day.shift.Window(day.date)
+	if err != nil {
+		return err
+	}
+	from, to := window.PunchRange()
+
+	punches, err := uc.punchRepo.GetByEmployeeBetween(day.employeeID, from, to)
+	if err != nil {
+		return err
+	}
+	session := domain.PairPunches(punches)
+	if session == nil {
+		return nil
+	}
+
+	attendance, err := uc.attendanceRepo.GetByEmployeeAndDate(day.employeeID, day.date)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	isNew := attendance == nil
+	if isNew {
+		attendance = &domain.Attendance{
+			ID:             uuid.New(),
+			EmployeeID:     day.employeeID,
+			AttendanceDate: day.date,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+	}
+
+	checkIn, checkOut := session.In, session.Out
+	if attendance.CheckIn != nil && attendance.CheckIn.Before(checkIn) {
+		checkIn = *attendance.CheckIn
+	}
+	if attendance.CheckOut != nil && (checkOut == nil || attendance.CheckOut.After(*checkOut)) {
+		checkOut = attendance.CheckOut
+	}
+	if checkOut != nil && !checkOut.After(checkIn) {
+		checkOut = nil
+	}
+
+	attendance.ShiftID = &day.shift.ID
+	attendance.CheckIn = &checkIn
+	attendance.CheckOut = checkOut
+	if checkOut != nil {
+		attendance.WorkHours = domain.WorkedHours(day.shift, window, checkIn, *checkOut)
+		attendance.OvertimeHours = domain.OvertimeHours(day.shift, window, attendance.WorkHours)
+		attendance.Status = domain.ClassifyAttendance(day.shift, window, checkIn, attendance.WorkHours)
+	} else {
+		attendance.WorkHours = 0
+		attendance.OvertimeHours = 0
+		attendance.Status = domain.ArrivalStatus(day.shift, window, checkIn)
+	}
+
+	if isNew {
+		err = uc.attendanceRepo.Create(attendance)
+	} else {
+		err = uc.attendanceRepo.Update(attendance)
+	}
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0, len(punches))
+	for _, punch := range punches {
+		ids = append(ids, punch.ID)
+	}
+	if err := uc.punchRepo.SetResult(ids, domain.PunchStatusProcessed, &attendance.ID); err != nil {
+		return err
+	}
+	if _, err := syncExceptions(uc.exceptionRepo, attendance, day.shift, window, now); err != nil {
+		return err
+	}
+
+	date := day.date
+	employeeID := day.employeeID
+	// a missing check-out only needs attention once the shift's punches are in
+	if checkOut == nil && now.After(to) {
+		err := uc.raise(punchImport, &domain.PunchException{
+			EmployeeID:     &employeeID,
+			PunchID:        &punches[0].ID,
+			PunchTime:      &checkIn,
+			AttendanceDate: &date,
+			Reason:         domain.PunchExceptionSinglePunch,
+			Details:        fmt.Sprintf("only a check-in at %s for shift %s", checkIn.Format("15:04"), day.shift.Code),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if session.OddOrder {
+		err := uc.raise(punchImport, &domain.PunchException{
+			EmployeeID:     &employeeID,
+			AttendanceDate: &date,
+			Reason:         domain.PunchExceptionOddDirection,
+			Details:        "punch directions do not match the paired check-in and check-out",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// employeeFor resolves a device user to an employee, caching the result for
+// the import; nil means the user is not mapped
+func (uc *PunchUseCase) employeeFor(cache map[string]*uuid.UUID, deviceID, deviceUserID string) (*uuid.UUID, error) {
+	key := deviceID + "\x00" + deviceUserID
+	if employeeID, ok := cache[key]; ok {
+		return employeeID, nil
+	}
+
+	var employeeID *uuid.UUID
+	deviceUser, err := uc.deviceUserRepo.Resolve(deviceID, deviceUserID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		employeeID = &deviceUser.EmployeeID
+	}
+
+	cache[key] = employeeID
+	return employeeID, nil
+}
+
+func (uc *PunchUseCase) raise(punchImport *domain.PunchImport, exception *domain.PunchException) error {
+	now := time.Now()
+	exception.ID = uuid.New()
+	exception.ImportID = &punchImport.ID
+	exception.CreatedAt = now
+	exception.UpdatedAt = now
+	if err := uc.punchExceptionRepo.Create(exception); err != nil {
+		return err
+	}
+	punchImport.Exceptions++
+	return nil
+}
+
+// Imports
+
+func (uc *PunchUseCase) GetImport(ctx context.Context, id uuid.UUID) (*domain.PunchImport, error) {
+	return uc.punchImportRepo.GetByID(id)
+}
+
+func (uc *PunchUseCase) ListImports(ctx context.Context, limit, offset int) ([]*domain.PunchImport, error) {
+	return uc.punchImportRepo.GetAll(limit, offset)
+}
+
+// Exceptions
+
+func (uc *PunchUseCase) ListExceptions(ctx context.Context, filter *domain.PunchExceptionFilter) ([]*domain.PunchException, error) {
+	return uc.punchExceptionRepo.GetAll(filter)
+}
+
+func (uc *PunchUseCase) ResolveException(ctx context.Context, id uuid.UUID, req *ResolvePunchExceptionRequest) (*domain.PunchException, error) {
+	exception, err := uc.punchExceptionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if exception.Resolved {
+		return nil, fmt.Errorf("%w: exception is already resolved", ErrInvalidState)
+	}
+
+	exception.Resolved = true
+	if notes := strings.TrimSpace(req.Notes); notes != "" {
+		exception.Details = strings.TrimSpace(exception.Details + "\nResolution: " + notes)
+	}
+	if err := uc.punchExceptionRepo.Update(exception); err != nil {
+		return nil, err
+	}
+
+	return exception, nil
+}
+
+// Device users
+
+func (uc *PunchUseCase) ListDeviceUsers(ctx context.Context) ([]*domain.DeviceUser, error) {
+	return uc.deviceUserRepo.GetAll()
+}
+
+func (uc *PunchUseCase) CreateDeviceUser(ctx context.Context, req *DeviceUserRequest) (*domain.DeviceUser, error) {
+	employeeID, err := uuid.Parse(req.EmployeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+	deviceID := strings.TrimSpace(req.DeviceID)
+	deviceUserID := strings.TrimSpace(req.DeviceUserID)
+
+	existing, err := uc.deviceUserRepo.Resolve(deviceID, deviceUserID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if existing != nil && existing.DeviceID == deviceID {
+		return nil, fmt.Errorf("%w: device user %s is already mapped", ErrInvalidState, deviceUserID)
+	}
+
+	now := time.Now()
+	deviceUser := &domain.DeviceUser{
+		ID:           uuid.New(),
+		DeviceID:     deviceID,
+		DeviceUserID: deviceUserID,
+		EmployeeID:   employeeID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := uc.deviceUserRepo.Create(deviceUser); err != nil {
+		return nil, err
+	}
+
+	return deviceUser, nil
+}
+
+func (uc *PunchUseCase) DeleteDeviceUser(ctx context.Context, id uuid.UUID) error {
+	if _, err := uc.deviceUserRepo.GetByID(id); err != nil {
+		return err
+	}
+	return uc.deviceUserRepo.Delete(id)
+}
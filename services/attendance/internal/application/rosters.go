@@ -0,0 +1,677 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+// restDayCode marks a rest day in a pattern's list of shift codes
+const restDayCode = "OFF"
+
+type RosterUseCase struct {
+	patternRepo       domain.RosterPatternRepository
+	assignmentRepo    domain.RosterAssignmentRepository
+	entryRepo         domain.RosterEntryRepository
+	swapRepo          domain.ShiftSwapRepository
+	shiftRepo         domain.ShiftRepository
+	employeeDirectory domain.EmployeeDirectory
+	logger            utils.Logger
+}
+
+func NewRosterUseCase(
+	patternRepo domain.RosterPatternRepository,
+	assignmentRepo domain.RosterAssignmentRepository,
+	entryRepo domain.RosterEntryRepository,
+	swapRepo domain.ShiftSwapRepository,
+	shiftRepo domain.ShiftRepository,
+	employeeDirectory domain.EmployeeDirectory,
+	logger utils.Logger,
+) *RosterUseCase {
+	return &RosterUseCase{
+		patternRepo:       patternRepo,
+		assignmentRepo:    assignmentRepo,
+		entryRepo:         entryRepo,
+		swapRepo:          swapRepo,
+		shiftRepo:         shiftRepo,
+		employeeDirectory: employeeDirectory,
+		logger:            logger,
+	}
+}
+
+// RosterPatternRequest describes a cycle as one shift code per day, with OFF
+// for rest days, e.g. ["DAY", "DAY", "DAY", "DAY", "OFF", "OFF", "OFF", "OFF"]
+type RosterPatternRequest struct {
+	Name        string   `json:"name" validate:"required"`
+	Code        string   `json:"code" validate:"required"`
+	Description string   `json:"description"`
+	Days        []string `json:"days" validate:"required,min=1,max=366"`
+}
+
+type RosterAssignmentRequest struct {
+	PatternID     string `json:"pattern_id" validate:"required,uuid"`
+	EmployeeID    string `json:"employee_id" validate:"omitempty,uuid"`
+	DepartmentID  string `json:"department_id" validate:"omitempty,uuid"`
+	EffectiveFrom string `json:"effective_from" validate:"required"` // YYYY-MM-DD
+	EffectiveTo   string `json:"effective_to"`                       // YYYY-MM-DD, empty while open-ended
+	AnchorDate    string `json:"anchor_date"`                        // YYYY-MM-DD, defaults to effective_from
+}
+
+type GenerateRosterRequest struct {
+	From      string `json:"from" validate:"required"` // YYYY-MM-DD
+	To        string `json:"to" validate:"required"`   // YYYY-MM-DD
+	Overwrite bool   `json:"overwrite"`                // also replace entries changed by swaps
+}
+
+type RosterGenerationResult struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Employees int       `json:"employees"`
+	Generated int       `json:"generated"`
+	Kept      int       `json:"kept"`
+}
+
+// ShiftSwapRequestBody is made by the requester; who they are comes from the
+// caller
+type ShiftSwapRequestBody struct {
+	RequesterDate   string `json:"requester_date" validate:"required"` // YYYY-MM-DD
+	CounterpartID   string `json:"counterpart_id" validate:"required,uuid"`
+	CounterpartDate string `json:"counterpart_date"` // YYYY-MM-DD, defaults to requester_date
+	Reason          string `json:"reason"`
+}
+
+type RespondShiftSwapRequest struct {
+	Accept  bool   `json:"accept"`
+	Comment string `json:"comment"`
+}
+
+type DecideShiftSwapRequest struct {
+	Approve bool   `json:"approve"`
+	Comment string `json:"comment"`
+}
+
+// Patterns
+
+func (uc *RosterUseCase) CreatePattern(ctx context.Context, req *RosterPatternRequest) (*domain.RosterPattern, error) {
+	days, err := uc.patternDays(req.Days)
+	if err != nil {
+		return nil, fmt.Errorf("business validation failed: %w", err)
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if existing, err := uc.patternRepo.GetByCode(code); err == nil && existing != nil {
+		return nil, fmt.Errorf("roster pattern with code %s already exists", code)
+	} else if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	pattern := &domain.RosterPattern{
+		ID:          uuid.New(),
+		Name:        strings.TrimSpace(req.Name),
+		Code:        code,
+		Description: req.Description,
+		Days:        days,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := uc.patternRepo.Create(pattern); err != nil {
+		return nil, err
+	}
+
+	return pattern, nil
+}
+
+// UpdatePattern changes a pattern; rosters already generated keep the old
+// cycle until they are generated again.
+func (uc *RosterUseCase) UpdatePattern(ctx context.Context, id uuid.UUID, req *RosterPatternRequest) (*domain.RosterPattern, error) {
+	days, err := uc.patternDays(req.Days)
+	if err != nil {
+		return nil, fmt.Errorf("business validation failed: %w", err)
+	}
+
+	pattern, err := uc.patternRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if code != pattern.Code {
+		if existing, err := uc.patternRepo.GetByCode(code); err == nil && existing != nil {
+			return nil, fmt.Errorf("roster pattern with code %s already exists", code)
+		}
+	}
+
+	pattern.Name = strings.TrimSpace(req.Name)
+	pattern.Code = code
+	pattern.Description = req.Description
+	pattern.Days = days
+	if err := uc.patternRepo.Update(pattern); err != nil {
+		return nil, err
+	}
+
+	return pattern, nil
+}
+
+func (uc *RosterUseCase) GetPattern(ctx context.Context, id uuid.UUID) (*domain.RosterPattern, error) {
+	return uc.patternRepo.GetByID(id)
+}
+
+func (uc *RosterUseCase) ListPatterns(ctx context.Context) ([]*domain.RosterPattern, error) {
+	return uc.patternRepo.GetAll()
+}
+
+func (uc *RosterUseCase) DeletePattern(ctx context.Context, id uuid.UUID) error {
+	if _, err := uc.patternRepo.GetByID(id); err != nil {
+		return err
+	}
+	return uc.patternRepo.Delete(id)
+}
+
+// patternDays resolves a cycle's shift codes
+func (uc *RosterUseCase) patternDays(codes []string) ([]domain.RosterDay, error) {
+	shifts := map[string]*uuid.UUID{}
+	days := make([]domain.RosterDay, 0, len(codes))
+	working := false
+
+	for i, value := range codes {
+		code := strings.ToUpper(strings.TrimSpace(value))
+		if code == "" || code == restDayCode {
+			days = append(days, domain.RosterDay{})
+			continue
+		}
+
+		shiftID, ok := shifts[code]
+		if !ok {
+			shift, err := uc.shiftRepo.GetByCode(code)
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("day %d: unknown shift code %s", i+1, code)
+			}
+			if err != nil {
+				return nil, err
+			}
+			shiftID = &shift.ID
+			shifts[code] = shiftID
+		}
+		days = append(days, domain.RosterDay{ShiftID: shiftID})
+		working = true
+	}
+
+	if !working {
+		return nil, fmt.Errorf("a pattern needs at least one working day")
+	}
+	return days, nil
+}
+
+// Assignments
+
+func (uc *RosterUseCase) Assign(ctx context.Context, req *RosterAssignmentRequest) (*domain.RosterAssignment, error) {
+	if (req.EmployeeID == "") == (req.DepartmentID == "") {
+		return nil, fmt.Errorf("either employee_id or department_id is required")
+	}
+
+	patternID, err := uuid.Parse(req.PatternID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern ID")
+	}
+	if _, err := uc.patternRepo.GetByID(patternID); err != nil {
+		return nil, err
+	}
+
+	from, err := time.Parse(dateLayout, req.EffectiveFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid effective_from, expected YYYY-MM-DD")
+	}
+	var to *time.Time
+	if req.EffectiveTo != "" {
+		end, err := time.Parse(dateLayout, req.EffectiveTo)
+		if err != nil || end.Before(from) {
+			return nil, fmt.Errorf("invalid effective_to, expected YYYY-MM-DD on or after effective_from")
+		}
+		to = &end
+	}
+	anchor := from
+	if req.AnchorDate != "" {
+		if anchor, err = time.Parse(dateLayout, req.AnchorDate); err != nil {
+			return nil, fmt.Errorf("invalid anchor_date, expected YYYY-MM-DD")
+		}
+	}
+
+	now := time.Now()
+	assignment := &domain.RosterAssignment{
+		ID:            uuid.New(),
+		PatternID:     patternID,
+		EffectiveFrom: from,
+		EffectiveTo:   to,
+		AnchorDate:    anchor,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if req.EmployeeID != "" {
+		employeeID, err := uuid.Parse(req.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid employee ID")
+		}
+		assignment.EmployeeID = &employeeID
+	} else {
+		departmentID, err := uuid.Parse(req.DepartmentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid department ID")
+		}
+		assignment.DepartmentID = &departmentID
+	}
+
+	if err := uc.assignmentRepo.Create(assignment); err != nil {
+		return nil, err
+	}
+
+	return assignment, nil
+}
+
+func (uc *RosterUseCase) ListAssignments(ctx context.Context) ([]*domain.RosterAssignment, error) {
+	return uc.assignmentRepo.GetAll()
+}
+
+func (uc *RosterUseCase) DeleteAssignment(ctx context.Context, id uuid.UUID) error {
+	if _, err := uc.assignmentRepo.GetByID(id); err != nil {
+		return err
+	}
+	return uc.assignmentRepo.Delete(id)
+}
+
+// Generation
+
+// rosterSlot is the assignment that decides an employee's roster on a date
+type rosterSlot struct {
+	assignment *domain.RosterAssignment
+	shiftID    *uuid.UUID
+}
+
+// Generate writes every assigned employee's roster for the period. An
+// employee's own assignment takes precedence over their department's, and
+// among assignments of the same kind the one effective latest wins. Entries
+// changed by approved swaps are kept unless Overwrite is set.
+func (uc *RosterUseCase) Generate(ctx context.Context, req *GenerateRosterRequest) (*RosterGenerationResult, error) {
+	from, err := time.Parse(dateLayout, req.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from, expected YYYY-MM-DD")
+	}
+	to, err := time.Parse(dateLayout, req.To)
+	if err != nil || to.Before(from) {
+		return nil, fmt.Errorf("invalid to, expected YYYY-MM-DD on or after from")
+	}
+	if int(to.Sub(from).Hours()/24)+1 > domain.MaxRosterDays {
+		return nil, fmt.Errorf("a roster can be generated for at most %d days at a time", domain.MaxRosterDays)
+	}
+
+	assignments, err := uc.assignmentRepo.GetActiveBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := map[uuid.UUID]*domain.RosterPattern{}
+	departments := map[uuid.UUID][]uuid.UUID{}
+	// department assignments are laid down first so employee ones replace them
+	slots := map[uuid.UUID]map[string]rosterSlot{}
+	for _, personal := range []bool{false, true} {
+		for _, assignment := range assignments {
+			if (assignment.EmployeeID != nil) != personal {
+				continue
+			}
+
+			pattern, ok := patterns[assignment.PatternID]
+			if !ok {
+				pattern, err = uc.patternRepo.GetByID(assignment.PatternID)
+				if errors.Is(err, domain.ErrNotFound) {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				patterns[assignment.PatternID] = pattern
+			}
+
+			employeeIDs := []uuid.UUID{}
+			if assignment.EmployeeID != nil {
+				employeeIDs = append(employeeIDs, *assignment.EmployeeID)
+			} else {
+				members, ok := departments[*assignment.DepartmentID]
+				if !ok {
+					members, err = uc.employeeDirectory.GetActiveByDepartment(*assignment.DepartmentID)
+					if err != nil {
+						return nil, err
+					}
+					departments[*assignment.DepartmentID] = members
+				}
+				employeeIDs = members
+			}
+
+			for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+				if !assignment.Covers(date) {
+					continue
+				}
+				slot := rosterSlot{assignment: assignment, shiftID: pattern.ShiftOn(assignment.AnchorDate, date)}
+				for _, employeeID := range employeeIDs {
+					if slots[employeeID] == nil {
+						slots[employeeID] = map[string]rosterSlot{}
+					}
+					slots[employeeID][date.Format(dateLayout)] = slot
+				}
+			}
+		}
+	}
+
+	result := &RosterGenerationResult{From: from, To: to, Employees: len(slots)}
+	now := time.Now()
+	for employeeID, days := range slots {
+		kept := map[string]bool{}
+		if !req.Overwrite {
+			existing, err := uc.entryRepo.GetAll(&domain.RosterEntryFilter{EmployeeID: &employeeID, StartDate: from, EndDate: to})
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range existing {
+				if entry.Source != domain.RosterSourceGenerated {
+					kept[entry.RosterDate.Format(dateLayout)] = true
+				}
+			}
+		}
+
+		for key, slot := range days {
+			if kept[key] {
+				result.Kept++
+				continue
+			}
+			date, _ := time.Parse(dateLayout, key)
+			entry := &domain.RosterEntry{
+				ID:           uuid.New(),
+				EmployeeID:   employeeID,
+				RosterDate:   date,
+				ShiftID:      slot.shiftID,
+				AssignmentID: &slot.assignment.ID,
+				Source:       domain.RosterSourceGenerated,
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			}
+			if err := uc.entryRepo.Upsert(entry); err != nil {
+				return nil, err
+			}
+			result.Generated++
+		}
+	}
+
+	uc.logger.Info("Roster generated",
+		"from", from.Format(dateLayout),
+		"to", to.Format(dateLayout),
+		"employees", result.Employees,
+		"generated", result.Generated)
+	return result, nil
+}
+
+func (uc *RosterUseCase) GetRoster(ctx context.Context, filter *domain.RosterEntryFilter) ([]*domain.RosterEntry, error) {
+	return uc.entryRepo.GetAll(filter)
+}
+
+// Swaps
+
+// RequestSwap asks a colleague to exchange rosters on the caller's behalf.
+// Both employees must already be rostered on the dates involved, and the
+// exchange must change something.
+func (uc *RosterUseCase) RequestSwap(ctx context.Context, req *ShiftSwapRequestBody, caller *Caller) (*domain.ShiftSwapRequest, error) {
+	requester, err := uc.swapEmployee(caller)
+	if err != nil {
+		return nil, err
+	}
+	requesterID := requester.ID
+	counterpartID, err := uuid.Parse(req.CounterpartID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid counterpart ID")
+	}
+	if requesterID == counterpartID {
+		return nil, fmt.Errorf("cannot swap shifts with yourself")
+	}
+
+	requesterDate, err := time.Parse(dateLayout, req.RequesterDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid requester_date, expected YYYY-MM-DD")
+	}
+	counterpartDate := requesterDate
+	if req.CounterpartDate != "" {
+		if counterpartDate, err = time.Parse(dateLayout, req.CounterpartDate); err != nil {
+			return nil, fmt.Errorf("invalid counterpart_date, expected YYYY-MM-DD")
+		}
+	}
+
+	today := localDate(time.Now(), time.UTC)
+	if requesterDate.Before(today) || counterpartDate.Before(today) {
+		return nil, fmt.Errorf("%w: past rosters cannot be swapped", ErrInvalidState)
+	}
+
+	now := time.Now()
+	swap := &domain.ShiftSwapRequest{
+		ID:              uuid.New(),
+		RequesterID:     requesterID,
+		RequesterDate:   requesterDate,
+		CounterpartID:   counterpartID,
+		CounterpartDate: counterpartDate,
+		Reason:          req.Reason,
+		Status:          domain.SwapStatusPending,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if _, err := uc.swapEntries(swap); err != nil {
+		return nil, err
+	}
+
+	if err := uc.swapRepo.Create(swap); err != nil {
+		return nil, err
+	}
+
+	return swap, nil
+}
+
+// RespondToSwap records the counterpart's answer, given by the caller
+func (uc *RosterUseCase) RespondToSwap(ctx context.Context, id uuid.UUID, req *RespondShiftSwapRequest, caller *Caller) (*domain.ShiftSwapRequest, error) {
+	employee, err := uc.swapEmployee(caller)
+	if err != nil {
+		return nil, err
+	}
+
+	swap, err := uc.swapRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if swap.CounterpartID != employee.ID {
+		return nil, fmt.Errorf("%w: only the counterpart can respond to a swap request", ErrForbidden)
+	}
+	if swap.Status != domain.SwapStatusPending {
+		return nil, fmt.Errorf("%w: swap request is %s", ErrInvalidState, swap.Status)
+	}
+
+	now := time.Now()
+	swap.RespondedAt = &now
+	swap.Comment = req.Comment
+	swap.Status = domain.SwapStatusDeclined
+	if req.Accept {
+		swap.Status = domain.SwapStatusAccepted
+	}
+	if err := uc.swapRepo.Update(swap); err != nil {
+		return nil, err
+	}
+
+	return swap, nil
+}
+
+// DecideSwap approves or rejects an accepted swap. Only HR or the manager of
+// both employees can decide, and never on a swap they are part of. Approval
+// exchanges the two employees' roster entries on every date involved.
+func (uc *RosterUseCase) DecideSwap(ctx context.Context, id uuid.UUID, req *DecideShiftSwapRequest, caller *Caller) (*domain.ShiftSwapRequest, error) {
+	swap, err := uc.swapRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if swap.Status != domain.SwapStatusAccepted {
+		return nil, fmt.Errorf("%w: swap request is %s, only accepted requests can be decided", ErrInvalidState, swap.Status)
+	}
+	if err := uc.checkSwapDecider(swap, caller); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	swap.DecidedBy = caller.UserID
+	swap.DecidedAt = &now
+	if req.Comment != "" {
+		swap.Comment = req.Comment
+	}
+
+	if !req.Approve {
+		swap.Status = domain.SwapStatusRejected
+		if err := uc.swapRepo.Update(swap); err != nil {
+			return nil, err
+		}
+		return swap, nil
+	}
+
+	entries, err := uc.swapEntries(swap)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(entries); i += 2 {
+		requester, counterpart := entries[i], entries[i+1]
+		requester.ShiftID, counterpart.ShiftID = counterpart.ShiftID, requester.ShiftID
+		for _, entry := range []*domain.RosterEntry{requester, counterpart} {
+			entry.SwapRequestID = &swap.ID
+			entry.Source = domain.RosterSourceSwap
+			entry.UpdatedAt = now
+		}
+	}
+	if err := uc.entryRepo.UpdateShifts(entries); err != nil {
+		return nil, err
+	}
+
+	swap.Status = domain.SwapStatusApproved
+	if err := uc.swapRepo.Update(swap); err != nil {
+		return nil, err
+	}
+
+	return swap, nil
+}
+
+// CancelSwap withdraws the caller's request while it has not been decided
+func (uc *RosterUseCase) CancelSwap(ctx context.Context, id uuid.UUID, caller *Caller) (*domain.ShiftSwapRequest, error) {
+	employee, err := uc.swapEmployee(caller)
+	if err != nil {
+		return nil, err
+	}
+
+	swap, err := uc.swapRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if swap.RequesterID != employee.ID {
+		return nil, fmt.Errorf("%w: only the requester can cancel a swap request", ErrForbidden)
+	}
+	if swap.Status != domain.SwapStatusPending && swap.Status != domain.SwapStatusAccepted {
+		return nil, fmt.Errorf("%w: swap request is %s", ErrInvalidState, swap.Status)
+	}
+
+	swap.Status = domain.SwapStatusCancelled
+	if err := uc.swapRepo.Update(swap); err != nil {
+		return nil, err
+	}
+
+	return swap, nil
+}
+
+// swapEmployee finds the employee the caller swaps shifts as
+func (uc *RosterUseCase) swapEmployee(caller *Caller) (*domain.DirectoryEmployee, error) {
+	employee, err := callerEmployee(uc.employeeDirectory, caller)
+	if err != nil {
+		return nil, err
+	}
+	if employee == nil {
+		return nil, fmt.Errorf("%w: only employees can swap shifts", ErrForbidden)
+	}
+	return employee, nil
+}
+
+// checkSwapDecider makes sure the caller is HR or manages both employees, and
+// is neither of them
+func (uc *RosterUseCase) checkSwapDecider(swap *domain.ShiftSwapRequest, caller *Caller) error {
+	if caller.UserID == nil {
+		return fmt.Errorf("%w: shift swaps must be decided by a user", ErrForbidden)
+	}
+	decider, err := callerEmployee(uc.employeeDirectory, caller)
+	if err != nil {
+		return err
+	}
+	if decider != nil && (decider.ID == swap.RequesterID || decider.ID == swap.CounterpartID) {
+		return fmt.Errorf("%w: you cannot decide a swap you are part of", ErrForbidden)
+	}
+	if caller.IsHR {
+		return nil
+	}
+
+	for _, employeeID := range []uuid.UUID{swap.RequesterID, swap.CounterpartID} {
+		employee, err := uc.employeeDirectory.GetByID(employeeID)
+		if err != nil {
+			return err
+		}
+		if employee.ManagerID == nil || *employee.ManagerID != decider.ID {
+			return fmt.Errorf("%w: only HR or the manager of both employees can decide this swap", ErrForbidden)
+		}
+	}
+	return nil
+}
+
+func (uc *RosterUseCase) GetSwap(ctx context.Context, id uuid.UUID) (*domain.ShiftSwapRequest, error) {
+	return uc.swapRepo.GetByID(id)
+}
+
+func (uc *RosterUseCase) ListSwaps(ctx context.Context, filter *domain.ShiftSwapFilter) ([]*domain.ShiftSwapRequest, error) {
+	return uc.swapRepo.GetAll(filter)
+}
+
+// swapEntries returns the requester's and counterpart's entries for each date
+// of the swap, in pairs, and checks the swap would change at least one shift
+func (uc *RosterUseCase) swapEntries(swap *domain.ShiftSwapRequest) ([]*domain.RosterEntry, error) {
+	var entries []*domain.RosterEntry
+	changes := false
+
+	for _, date := range swap.Dates() {
+		pair := make([]*domain.RosterEntry, 0, 2)
+		for _, employeeID := range []uuid.UUID{swap.RequesterID, swap.CounterpartID} {
+			entry, err := uc.entryRepo.GetForDate(employeeID, date)
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("%w: employee %s is not rostered on %s", ErrInvalidState, employeeID, date.Format(dateLayout))
+			}
+			if err != nil {
+				return nil, err
+			}
+			pair = append(pair, entry)
+		}
+		if !sameShift(pair[0].ShiftID, pair[1].ShiftID) {
+			changes = true
+		}
+		entries = append(entries, pair...)
+	}
+
+	if !changes {
+		return nil, fmt.Errorf("%w: both employees have the same roster on the dates requested", ErrInvalidState)
+	}
+	return entries, nil
+}
+
+func sameShift(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,220 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+type ShiftUseCase struct {
+	shiftRepo         domain.ShiftRepository
+	employeeShiftRepo domain.EmployeeShiftRepository
+	logger            utils.Logger
+}
+
+func NewShiftUseCase(
+	shiftRepo domain.ShiftRepository,
+	employeeShiftRepo domain.EmployeeShiftRepository,
+	logger utils.Logger,
+) *ShiftUseCase {
+	return &ShiftUseCase{
+		shiftRepo:         shiftRepo,
+		employeeShiftRepo: employeeShiftRepo,
+		logger:            logger,
+	}
+}
+
+type ShiftRequest struct {
+	Name         string  `json:"name" validate:"required"`
+	Code         string  `json:"code" validate:"required"`
+	StartTime    string  `json:"start_time" validate:"required"`
+	EndTime      string  `json:"end_time" validate:"required"`
+	BreakTime    string  `json:"break_time"`
+	WorkHours    float64 `json:"work_hours" validate:"min=0"`
+	Description  string  `json:"description"`
+	IsNightShift bool    `json:"is_night_shift"`
+	GraceMinutes int     `json:"grace_minutes" validate:"min=0"`
+	HalfDayHours float64 `json:"half_day_hours" validate:"min=0"`
+}
+
+type AssignShiftRequest struct {
+	EmployeeID    string `json:"employee_id" validate:"required,uuid"`
+	ShiftID       string `json:"shift_id" validate:"required,uuid"`
+	EffectiveFrom string `json:"effective_from" validate:"required"` // YYYY-MM-DD
+	EffectiveTo   string `json:"effective_to"`                       // YYYY-MM-DD, empty while open-ended
+}
+
+func (uc *ShiftUseCase) Create(ctx context.Context, req *ShiftRequest) (*domain.Shift, error) {
+	if err := validateShiftRequest(req); err != nil {
+		return nil, fmt.Errorf("business validation failed: %w", err)
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if existing, err := uc.shiftRepo.GetByCode(code); err == nil && existing != nil {
+		return nil, fmt.Errorf("shift with code %s already exists", code)
+	} else if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	shift := &domain.Shift{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyShiftRequest(shift, req)
+	shift.Code = code
+
+	if err := uc.shiftRepo.Create(shift); err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+func (uc *ShiftUseCase) Update(ctx context.Context, id uuid.UUID, req *ShiftRequest) (*domain.Shift, error) {
+	if err := validateShiftRequest(req); err != nil {
+		return nil, fmt.Errorf("business validation failed: %w", err)
+	}
+
+	shift, err := uc.shiftRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if code != shift.Code {
+		if existing, err := uc.shiftRepo.GetByCode(code); err == nil && existing != nil {
+			return nil, fmt.Errorf("shift with code %s already exists", code)
+		}
+	}
+
+	applyShiftRequest(shift, req)
+	shift.Code = code
+
+	if err := uc.shiftRepo.Update(shift); err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+func (uc *ShiftUseCase) Get(ctx context.Context, id uuid.UUID) (*domain.Shift, error) {
+	return uc.shiftRepo.GetByID(id)
+}
+
+func (uc *ShiftUseCase) List(ctx context.Context) ([]*domain.Shift, error) {
+	return uc.shiftRepo.GetAll()
+}
+
+func (uc *ShiftUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := uc.shiftRepo.GetByID(id); err != nil {
+		return err
+	}
+	return uc.shiftRepo.Delete(id)
+}
+
+// Assign puts an employee on a shift from a date. An open-ended assignment
+// already running on that date is closed the day before.
+func (uc *ShiftUseCase) Assign(ctx context.Context, req *AssignShiftRequest) (*domain.EmployeeShift, error) {
+	employeeID, err := uuid.Parse(req.EmployeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+	shiftID, err := uuid.Parse(req.ShiftID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shift ID")
+	}
+	if _, err := uc.shiftRepo.GetByID(shiftID); err != nil {
+		return nil, err
+	}
+
+	from, err := time.Parse(dateLayout, req.EffectiveFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid effective_from, expected YYYY-MM-DD")
+	}
+	var to *time.Time
+	if req.EffectiveTo != "" {
+		end, err := time.Parse(dateLayout, req.EffectiveTo)
+		if err != nil || end.Before(from) {
+			return nil, fmt.Errorf("invalid effective_to, expected YYYY-MM-DD on or after effective_from")
+		}
+		to = &end
+	}
+
+	current, err := uc.employeeShiftRepo.GetForDate(employeeID, from)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if current != nil && current.EffectiveTo == nil && current.EffectiveFrom.Before(from) {
+		end := from.AddDate(0, 0, -1)
+		current.EffectiveTo = &end
+		if err := uc.employeeShiftRepo.Update(current); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	assignment := &domain.EmployeeShift{
+		ID:            uuid.New(),
+		EmployeeID:    employeeID,
+		ShiftID:       shiftID,
+		EffectiveFrom: from,
+		EffectiveTo:   to,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := uc.employeeShiftRepo.Create(assignment); err != nil {
+		return nil, err
+	}
+
+	return assignment, nil
+}
+
+func (uc *ShiftUseCase) GetAssignments(ctx context.Context, employeeID uuid.UUID) ([]*domain.EmployeeShift, error) {
+	return uc.employeeShiftRepo.GetByEmployee(employeeID)
+}
+
+func (uc *ShiftUseCase) DeleteAssignment(ctx context.Context, id uuid.UUID) error {
+	if _, err := uc.employeeShiftRepo.GetByID(id); err != nil {
+		return err
+	}
+	return uc.employeeShiftRepo.Delete(id)
+}
+
+func validateShiftRequest(req *ShiftRequest) error {
+	if strings.TrimSpace(req.Name) == "" || strings.TrimSpace(req.Code) == "" {
+		return fmt.Errorf("name and code are required")
+	}
+	if err := domain.ValidateClock(req.StartTime); err != nil {
+		return fmt.Errorf("start_time: %w", err)
+	}
+	if err := domain.ValidateClock(req.EndTime); err != nil {
+		return fmt.Errorf("end_time: %w", err)
+	}
+	if strings.TrimSpace(req.BreakTime) != "" {
+		if err := domain.ValidateClock(req.BreakTime); err != nil {
+			return fmt.Errorf("break_time: %w", err)
+		}
+	}
+	return nil
+}
+
+func applyShiftRequest(shift *domain.Shift, req *ShiftRequest) {
+	shift.Name = strings.TrimSpace(req.Name)
+	shift.StartTime = strings.TrimSpace(req.StartTime)
+	shift.EndTime = strings.TrimSpace(req.EndTime)
+	shift.BreakTime = strings.TrimSpace(req.BreakTime)
+	shift.WorkHours = req.WorkHours
+	shift.Description = req.Description
+	shift.IsNightShift = req.IsNightShift
+	shift.GraceMinutes = req.GraceMinutes
+	shift.HalfDayHours = req.HalfDayHours
+}
@@ -0,0 +1,554 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	// ErrInvalidState is returned when a punch does not fit the employee's
+	// current attendance, e.g. clocking in twice
+	ErrInvalidState = errors.New("invalid attendance state")
+
+	// ErrNoShift is returned when the employee has no shift on the punch date
+	ErrNoShift = errors.New("employee has no shift assigned")
+
+	// ErrForbidden is returned when the caller may not act on the employee,
+	// e.g. deciding their own overtime
+	ErrForbidden = errors.New("not allowed")
+)
+
+type TimeClockUseCase struct {
+	attendanceRepo    domain.AttendanceRepository
+	shiftRepo         domain.ShiftRepository
+	employeeShiftRepo domain.EmployeeShiftRepository
+	rosterEntryRepo   domain.RosterEntryRepository
+	geofenceRepo      domain.GeofenceRepository
+	evidenceRepo      domain.AttendanceEvidenceRepository
+	exceptionRepo     domain.AttendanceExceptionRepository
+	directory         domain.EmployeeDirectory
+	logger            utils.Logger
+}
+
+func NewTimeClockUseCase(
+	attendanceRepo domain.AttendanceRepository,
+	shiftRepo domain.ShiftRepository,
+	employeeShiftRepo domain.EmployeeShiftRepository,
+	rosterEntryRepo domain.RosterEntryRepository,
+	geofenceRepo domain.GeofenceRepository,
+	evidenceRepo domain.AttendanceEvidenceRepository,
+	exceptionRepo domain.AttendanceExceptionRepository,
+	directory domain.EmployeeDirectory,
+	logger utils.Logger,
+) *TimeClockUseCase {
+	return &TimeClockUseCase{
+		attendanceRepo:    attendanceRepo,
+		shiftRepo:         shiftRepo,
+		employeeShiftRepo: employeeShiftRepo,
+		rosterEntryRepo:   rosterEntryRepo,
+		geofenceRepo:      geofenceRepo,
+		evidenceRepo:      evidenceRepo,
+		exceptionRepo:     exceptionRepo,
+		directory:         directory,
+		logger:            logger,
+	}
+}
+
+// ClockRequest is a punch from a kiosk, the web or the mobile app. The app
+// also sends the phone's location and optionally a photo, kept as evidence
+// with the attendance.
+type ClockRequest struct {
+	EmployeeID string           `json:"employee_id" validate:"omitempty,uuid"` // only HR may punch for another employee
+	Time       *time.Time       `json:"time"`                                  // only honoured for HR keying in a punch later, otherwise now
+	Notes      string           `json:"notes"`
+	Location   *LocationRequest `json:"location"` // required from employees whose branch has geofences
+	Photo      string           `json:"photo"`    // base64 JPEG or PNG, or a data URL
+}
+
+// ClockIn opens the employee's attendance for the shift the punch falls in
+func (uc *TimeClockUseCase) ClockIn(ctx context.Context, req *ClockRequest, caller *Caller) (*domain.Attendance, error) {
+	employee, err := uc.punchEmployee(req, caller)
+	if err != nil {
+		return nil, err
+	}
+	employeeID := employee.ID
+	at := punchTime(req.Time, caller)
+
+	open, err := uc.attendanceRepo.GetOpenByEmployee(employeeID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if open != nil && at.Sub(*open.CheckIn) < domain.MaxShiftSpan {
+		return nil, fmt.Errorf("%w: already clocked in at %s", ErrInvalidState, open.CheckIn.Format(time.RFC3339))
+	}
+
+	shift, date, err := newShiftLookup(uc.shiftRepo, uc.employeeShiftRepo, uc.rosterEntryRepo).forPunch(employeeID, at)
+	if err != nil {
+		return nil, err
+	}
+	window, err := shift.Window(date)
+	if err != nil {
+		return nil, err
+	}
+
+	attendance, err := uc.attendanceRepo.GetByEmployeeAndDate(employeeID, date)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if attendance != nil && attendance.CheckIn != nil {
+		return nil, fmt.Errorf("%w: attendance for %s is already recorded", ErrInvalidState, date.Format(dateLayout))
+	}
+
+	evidence, err := uc.captureEvidence(employee, req, caller, domain.EvidenceClockIn, at)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	isNew := attendance == nil
+	if isNew {
+		attendance = &domain.Attendance{
+			ID:             uuid.New(),
+			EmployeeID:     employeeID,
+			AttendanceDate: date,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+	}
+	attendance.ShiftID = &shift.ID
+	attendance.CheckIn = &at
+	attendance.Status = domain.ArrivalStatus(shift, window, at)
+	if req.Notes != "" {
+		attendance.Notes = req.Notes
+	}
+
+	if isNew {
+		err = uc.attendanceRepo.Create(attendance)
+	} else {
+		// an absence marked before the employee turned up is replaced
+		err = uc.attendanceRepo.Update(attendance)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.saveEvidence(attendance, evidence); err != nil {
+		return nil, err
+	}
+	if _, err := syncExceptions(uc.exceptionRepo, attendance, shift, window, now); err != nil {
+		return nil, err
+	}
+
+	return attendance, nil
+}
+
+// ClockOut closes the employee's open attendance and computes the hours
+// worked, overtime and final status against its shift.
+func (uc *TimeClockUseCase) ClockOut(ctx context.Context, req *ClockRequest, caller *Caller) (*domain.Attendance, error) {
+	employee, err := uc.punchEmployee(req, caller)
+	if err != nil {
+		return nil, err
+	}
+	employeeID := employee.ID
+	at := punchTime(req.Time, caller)
+
+	attendance, err := uc.attendanceRepo.GetOpenByEmployee(employeeID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: not clocked in", ErrInvalidState)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if at.Sub(*attendance.CheckIn) >= domain.MaxShiftSpan {
+		return nil, fmt.Errorf("%w: no clock-in in the last %s", ErrInvalidState, domain.MaxShiftSpan)
+	}
+	if !at.After(*attendance.CheckIn) {
+		return nil, fmt.Errorf("%w: clock-out must be after the clock-in at %s",
+			ErrInvalidState, attendance.CheckIn.Format(time.RFC3339))
+	}
+	if attendance.ShiftID == nil {
+		return nil, ErrNoShift
+	}
+
+	shift, err := uc.shiftRepo.GetByID(*attendance.ShiftID)
+	if err != nil {
+		return nil, err
+	}
+	window, err := shift.Window(localDate(attendance.AttendanceDate, at.Location()))
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := uc.captureEvidence(employee, req, caller, domain.EvidenceClockOut, at)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	attendance.CheckOut = &at
+	attendance.WorkHours = domain.WorkedHours(shift, window, *attendance.CheckIn, at)
+	attendance.OvertimeHours = domain.OvertimeHours(shift, window, attendance.WorkHours)
+	attendance.Status = domain.ClassifyAttendance(shift, window, *attendance.CheckIn, attendance.WorkHours)
+	if req.Notes != "" {
+		attendance.Notes = req.Notes
+	}
+
+	if err := uc.attendanceRepo.Update(attendance); err != nil {
+		return nil, err
+	}
+	if err := uc.saveEvidence(attendance, evidence); err != nil {
+		return nil, err
+	}
+	if _, err := syncExceptions(uc.exceptionRepo, attendance, shift, window, now); err != nil {
+		return nil, err
+	}
+
+	return attendance, nil
+}
+
+// punchEmployee is the employee a punch is for: the caller, or the employee
+// HR names when keying in a punch for someone else
+func (uc *TimeClockUseCase) punchEmployee(req *ClockRequest, caller *Caller) (*domain.DirectoryEmployee, error) {
+	if keyedIn(req, caller) {
+		employeeID, err := uuid.Parse(req.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid employee ID")
+		}
+		return uc.directory.GetByID(employeeID)
+	}
+
+	employee, err := callerEmployee(uc.directory, caller)
+	if err != nil {
+		return nil, err
+	}
+	if employee == nil {
+		return nil, fmt.Errorf("employee_id is required for an account that is not an employee")
+	}
+	if req.EmployeeID != "" {
+		if named, err := uuid.Parse(req.EmployeeID); err != nil || named != employee.ID {
+			return nil, fmt.Errorf("%w: only HR can punch for another employee", ErrForbidden)
+		}
+	}
+	return employee, nil
+}
+
+// keyedIn reports whether HR is keying in a punch for an employee rather than
+// the employee punching
+func keyedIn(req *ClockRequest, caller *Caller) bool {
+	return req.EmployeeID != "" && caller != nil && caller.IsHR
+}
+
+// captureEvidence reads the location and photo sent with a punch, checking the
+// location against the geofences of the employee's branch. Employees punching
+// themselves must send their location while their branch has geofences; HR
+// keying in a punch need not. It returns nil when neither was sent.
+func (uc *TimeClockUseCase) captureEvidence(employee *domain.DirectoryEmployee, req *ClockRequest, caller *Caller, kind string, at time.Time) (*domain.AttendanceEvidence, error) {
+	geofences, err := uc.geofenceRepo.GetAll(employee.BranchID, true)
+	if err != nil {
+		return nil, err
+	}
+	if req.Location == nil && len(geofences) > 0 && !keyedIn(req, caller) {
+		return nil, fmt.Errorf("location is required to punch at a branch with geofences")
+	}
+	if req.Location == nil && req.Photo == "" {
+		return nil, nil
+	}
+
+	now := time.Now()
+	evidence := &domain.AttendanceEvidence{
+		ID:           uuid.New(),
+		EmployeeID:   employee.ID,
+		BranchID:     employee.BranchID,
+		Kind:         kind,
+		CapturedAt:   at,
+		ReviewStatus: domain.ReviewNotRequired,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if req.Photo != "" {
+		photo, contentType, err := decodePhoto(req.Photo)
+		if err != nil {
+			return nil, err
+		}
+		evidence.Photo = photo
+		evidence.PhotoContentType = contentType
+	}
+
+	if req.Location == nil {
+		return evidence, nil
+	}
+
+	point := domain.GeoPoint{Latitude: req.Location.Latitude, Longitude: req.Location.Longitude}
+	if !point.Valid() {
+		return nil, fmt.Errorf("invalid location")
+	}
+	evidence.Latitude = &point.Latitude
+	evidence.Longitude = &point.Longitude
+	if req.Location.Accuracy > 0 {
+		evidence.AccuracyMeters = &req.Location.Accuracy
+	}
+	checkGeofences(evidence, point, geofences)
+
+	return evidence, nil
+}
+
+// saveEvidence stores evidence against the attendance it was captured for
+func (uc *TimeClockUseCase) saveEvidence(attendance *domain.Attendance, evidence *domain.AttendanceEvidence) error {
+	if evidence == nil {
+		return nil
+	}
+
+	evidence.AttendanceID = attendance.ID
+	if err := uc.evidenceRepo.Create(evidence); err != nil {
+		return err
+	}
+	attendance.Evidence = evidence
+
+	if evidence.ReviewStatus == domain.ReviewPending {
+		uc.logger.Info("Punch outside geofence flagged for review",
+			"attendance_id", attendance.ID,
+			"employee_id", attendance.EmployeeID,
+			"kind", evidence.Kind,
+			"distance_meters", *evidence.DistanceMeters)
+	}
+	return nil
+}
+
+// MarkAbsentees records an absence for every employee whose shift on date has
+// ended without a clock-in. Rostered employees are taken from the roster,
+// which also excuses them on rest days; everyone else from their assigned
+// shift.
+func (uc *TimeClockUseCase) MarkAbsentees(ctx context.Context, date time.Time) ([]*domain.Attendance, error) {
+	scheduled := map[uuid.UUID]uuid.UUID{}
+	assignments, err := uc.employeeShiftRepo.GetActiveOn(date)
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		scheduled[assignment.EmployeeID] = assignment.ShiftID
+	}
+	entries, err := uc.rosterEntryRepo.GetByDate(date)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.ShiftID == nil {
+			delete(scheduled, entry.EmployeeID)
+			continue
+		}
+		scheduled[entry.EmployeeID] = *entry.ShiftID
+	}
+
+	present := map[uuid.UUID]bool{}
+	recorded, err := uc.attendanceRepo.GetByDate(date)
+	if err != nil {
+		return nil, err
+	}
+	for _, attendance := range recorded {
+		present[attendance.EmployeeID] = true
+	}
+
+	shifts := newShiftLookup(uc.shiftRepo, uc.employeeShiftRepo, uc.rosterEntryRepo)
+	now := time.Now()
+	absentees := []*domain.Attendance{}
+	for employeeID, shiftID := range scheduled {
+		if present[employeeID] {
+			continue
+		}
+
+		shift, err := shifts.shift(shiftID)
+		if err != nil {
+			return nil, err
+		}
+		window, err := shift.Window(localDate(date, now.Location()))
+		if err != nil {
+			return nil, err
+		}
+		if now.Before(window.End) {
+			continue
+		}
+
+		attendance := &domain.Attendance{
+			ID:             uuid.New(),
+			EmployeeID:     employeeID,
+			AttendanceDate: date,
+			ShiftID:        &shift.ID,
+			Status:         domain.AttendanceStatusAbsent,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := uc.attendanceRepo.Create(attendance); err != nil {
+			return nil, err
+		}
+		absentees = append(absentees, attendance)
+	}
+
+	return absentees, nil
+}
+
+func (uc *TimeClockUseCase) Get(ctx context.Context, id uuid.UUID) (*domain.Attendance, error) {
+	return uc.attendanceRepo.GetByID(id)
+}
+
+func (uc *TimeClockUseCase) List(ctx context.Context, filter *domain.AttendanceFilter) ([]*domain.Attendance, error) {
+	return uc.attendanceRepo.GetAll(filter)
+}
+
+// shiftLookup resolves employees' assigned shifts, caching them for the
+// lifetime of one operation
+type shiftLookup struct {
+	shiftRepo         domain.ShiftRepository
+	employeeShiftRepo domain.EmployeeShiftRepository
+	rosterEntryRepo   domain.RosterEntryRepository
+	shifts            map[uuid.UUID]*domain.Shift
+	assignments       map[string]*domain.Shift
+}
+
+func newShiftLookup(
+	shiftRepo domain.ShiftRepository,
+	employeeShiftRepo domain.EmployeeShiftRepository,
+	rosterEntryRepo domain.RosterEntryRepository,
+) *shiftLookup {
+	return &shiftLookup{
+		shiftRepo:         shiftRepo,
+		employeeShiftRepo: employeeShiftRepo,
+		rosterEntryRepo:   rosterEntryRepo,
+		shifts:            map[uuid.UUID]*domain.Shift{},
+		assignments:       map[string]*domain.Shift{},
+	}
+}
+
+// forPunch finds the shift a live punch belongs to and its attendance date.
+// A punch in the early hours may still belong to the previous day's night
+// shift, in which case that day's assignment applies.
+func (l *shiftLookup) forPunch(employeeID uuid.UUID, at time.Time) (*domain.Shift, time.Time, error) {
+	today := localDate(at, at.Location())
+	yesterday := today.AddDate(0, 0, -1)
+
+	shift, err := l.assigned(employeeID, yesterday)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if shift != nil {
+		date, err := shift.AttendanceDate(at)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if date.Equal(yesterday) {
+			return shift, date, nil
+		}
+	}
+
+	shift, err = l.assigned(employeeID, today)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if shift == nil {
+		return nil, time.Time{}, fmt.Errorf("%w on %s", ErrNoShift, today.Format(dateLayout))
+	}
+	return shift, today, nil
+}
+
+// forLoggedPunch finds the shift and attendance date of a punch read from a
+// terminal log, where early arrivals and late leavers are common: the punch
+// belongs to whichever of the surrounding days' shifts has it in its punch
+// range, trying the punch's own day first.
+func (l *shiftLookup) forLoggedPunch(employeeID uuid.UUID, at time.Time) (*domain.Shift, time.Time, error) {
+	today := localDate(at, at.Location())
+
+	for _, date := range []time.Time{today, today.AddDate(0, 0, -1), today.AddDate(0, 0, 1)} {
+		shift, err := l.assigned(employeeID, date)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if shift == nil {
+			continue
+		}
+		window, err := shift.Window(date)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		from, to := window.PunchRange()
+		if !at.Before(from) && at.Before(to) {
+			return shift, date, nil
+		}
+	}
+
+	return nil, time.Time{}, fmt.Errorf("%w around %s", ErrNoShift, today.Format(dateLayout))
+}
+
+// assigned returns the employee's shift on date, or nil if unassigned or
+// rostered off. A roster entry takes precedence over the assigned shift.
+func (l *shiftLookup) assigned(employeeID uuid.UUID, date time.Time) (*domain.Shift, error) {
+	key := employeeID.String() + date.Format(dateLayout)
+	if shift, ok := l.assignments[key]; ok {
+		return shift, nil
+	}
+
+	var shift *domain.Shift
+	entry, err := l.rosterEntryRepo.GetForDate(employeeID, date)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		if entry.ShiftID != nil {
+			if shift, err = l.shift(*entry.ShiftID); err != nil {
+				return nil, err
+			}
+		}
+		l.assignments[key] = shift
+		return shift, nil
+	}
+
+	assignment, err := l.employeeShiftRepo.GetForDate(employeeID, date)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		if shift, err = l.shift(assignment.ShiftID); err != nil {
+			return nil, err
+		}
+	}
+
+	l.assignments[key] = shift
+	return shift, nil
+}
+
+func (l *shiftLookup) shift(id uuid.UUID) (*domain.Shift, error) {
+	if shift, ok := l.shifts[id]; ok {
+		return shift, nil
+	}
+	shift, err := l.shiftRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	l.shifts[id] = shift
+	return shift, nil
+}
+
+// punchTime is the server's clock, so that punches cannot be backdated. Only
+// HR may key in a punch at another time.
+func punchTime(t *time.Time, caller *Caller) time.Time {
+	if t != nil && caller != nil && caller.IsHR {
+		return *t
+	}
+	return time.Now()
+}
+
+// localDate is midnight of t's calendar date in loc; DATE columns come back
+// as UTC midnight and need placing in the punch's time zone.
+func localDate(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
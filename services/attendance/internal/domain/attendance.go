@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,6 +12,7 @@ type Attendance struct {
 	ID             uuid.UUID  `json:"id" db:"id"`
 	EmployeeID     uuid.UUID  `json:"employee_id" db:"employee_id"`
 	AttendanceDate time.Time  `json:"attendance_date" db:"attendance_date"`
+	ShiftID        *uuid.UUID `json:"shift_id" db:"shift_id"`
 	CheckIn        *time.Time `json:"check_in" db:"check_in"`
 	CheckOut       *time.Time `json:"check_out" db:"check_out"`
 	WorkHours      float64    `json:"work_hours" db:"work_hours"`
@@ -20,6 +22,32 @@ type Attendance struct {
 	ApprovedBy     *uuid.UUID `json:"approved_by" db:"approved_by"`
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Evidence is the location and photo captured with the punch just made
+	Evidence *AttendanceEvidence `json:"evidence,omitempty" db:"-"`
+}
+
+// Attendance statuses
+const (
+	AttendanceStatusPresent = "present"
+	AttendanceStatusAbsent  = "absent"
+	AttendanceStatusLate    = "late"
+	AttendanceStatusHalfDay = "half_day"
+)
+
+// ErrNotFound is returned by repositories when a record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// EmployeeShift assigns a shift to an employee from EffectiveFrom until
+// EffectiveTo (inclusive, nil while open-ended)
+type EmployeeShift struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	EmployeeID    uuid.UUID  `json:"employee_id" db:"employee_id"`
+	ShiftID       uuid.UUID  `json:"shift_id" db:"shift_id"`
+	EffectiveFrom time.Time  `json:"effective_from" db:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to" db:"effective_to"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // AttendanceCode represents attendance status codes
@@ -45,6 +73,8 @@ type Shift struct {
 	WorkHours    float64   `json:"work_hours" db:"work_hours"`
 	Description  string    `json:"description" db:"description"`
 	IsNightShift bool      `json:"is_night_shift" db:"is_night_shift"`
+	GraceMinutes int       `json:"grace_minutes" db:"grace_minutes"`   // minutes after start before a check-in is late
+	HalfDayHours float64   `json:"half_day_hours" db:"half_day_hours"` // 0 means half the scheduled hours
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -70,6 +100,8 @@ type AttendanceRepository interface {
 	Update(attendance *Attendance) error
 	Delete(id uuid.UUID) error
 	GetByDate(date time.Time) ([]*Attendance, error)
+	GetByEmployeeAndDate(employeeID uuid.UUID, date time.Time) (*Attendance, error)
+	GetOpenByEmployee(employeeID uuid.UUID) (*Attendance, error)
 }
 
 type AttendanceCodeRepository interface {
@@ -90,6 +122,16 @@ type ShiftRepository interface {
 	GetByCode(code string) (*Shift, error)
 }
 
+type EmployeeShiftRepository interface {
+	Create(assignment *EmployeeShift) error
+	GetByID(id uuid.UUID) (*EmployeeShift, error)
+	GetByEmployee(employeeID uuid.UUID) ([]*EmployeeShift, error)
+	GetForDate(employeeID uuid.UUID, date time.Time) (*EmployeeShift, error)
+	GetActiveOn(date time.Time) ([]*EmployeeShift, error)
+	Update(assignment *EmployeeShift) error
+	Delete(id uuid.UUID) error
+}
+
 type HolidayRepository interface {
 	Create(holiday *Holiday) error
 	GetByID(id uuid.UUID) (*Holiday, error)
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attendance exception types
+const (
+	ExceptionLate        = "late"
+	ExceptionMissedPunch = "missed_punch"
+	ExceptionOvertime    = "overtime"
+)
+
+// Attendance exception statuses. An exception opens when the time clock or
+// punch import finds it, the employee justifies it, then a manager approves or
+// rejects it.
+const (
+	ExceptionStatusOpen      = "open"
+	ExceptionStatusJustified = "justified"
+	ExceptionStatusApproved  = "approved"
+	ExceptionStatusRejected  = "rejected"
+)
+
+// AttendanceException is a late arrival, a missing clock-out or overtime on
+// one attendance that a manager has to accept. Only approved overtime is paid:
+// payroll posts ApprovedHours and records the overtime it created in
+// OvertimeID.
+type AttendanceException struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	AttendanceID    uuid.UUID  `json:"attendance_id" db:"attendance_id"`
+	EmployeeID      uuid.UUID  `json:"employee_id" db:"employee_id"`
+	AttendanceDate  time.Time  `json:"attendance_date" db:"attendance_date"`
+	Type            string     `json:"type" db:"type"`                     // late, missed_punch, overtime
+	Minutes         int        `json:"minutes" db:"minutes"`               // late arrivals: minutes after the shift start
+	Hours           float64    `json:"hours" db:"hours"`                   // overtime: hours claimed
+	ApprovedHours   float64    `json:"approved_hours" db:"approved_hours"` // overtime: hours the manager accepted
+	Claimed         bool       `json:"claimed" db:"claimed"`               // raised by the employee rather than the clock
+	Details         string     `json:"details" db:"details"`
+	Justification   string     `json:"justification" db:"justification"`
+	Status          string     `json:"status" db:"status"` // open, justified, approved, rejected
+	JustifiedAt     *time.Time `json:"justified_at" db:"justified_at"`
+	DecidedBy       *uuid.UUID `json:"decided_by" db:"decided_by"`
+	DecidedAt       *time.Time `json:"decided_at" db:"decided_at"`
+	DecisionComment string     `json:"decision_comment" db:"decision_comment"`
+	OvertimeID      *uuid.UUID `json:"overtime_id" db:"overtime_id"`
+	PostedAt        *time.Time `json:"posted_at" db:"posted_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Pending reports whether the exception still waits for a decision
+func (e *AttendanceException) Pending() bool {
+	return e.Status == ExceptionStatusOpen || e.Status == ExceptionStatusJustified
+}
+
+// LateMinutes is how long after the shift start a late check-in was, or zero
+// when the check-in is within the grace period
+func LateMinutes(shift *Shift, window ShiftWindow, checkIn time.Time) int {
+	if ArrivalStatus(shift, window, checkIn) != AttendanceStatusLate {
+		return 0
+	}
+	return int(math.Ceil(checkIn.Sub(window.Start).Minutes()))
+}
+
+type AttendanceExceptionRepository interface {
+	Create(exception *AttendanceException) error
+	GetByID(id uuid.UUID) (*AttendanceException, error)
+	GetByAttendance(attendanceID uuid.UUID) ([]*AttendanceException, error)
+	GetAll(filter *AttendanceExceptionFilter) ([]*AttendanceException, error)
+	Update(exception *AttendanceException) error
+	Delete(id uuid.UUID) error
+}
+
+type AttendanceExceptionFilter struct {
+	EmployeeID *uuid.UUID
+	Type       string
+	Status     string
+	StartDate  *time.Time
+	EndDate    *time.Time
+	Limit      int
+	Offset     int
+}
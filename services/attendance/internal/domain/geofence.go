@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Clock evidence kinds
+const (
+	EvidenceClockIn  = "clock_in"
+	EvidenceClockOut = "clock_out"
+)
+
+// Evidence review statuses. Punches inside a geofence need no review; those
+// outside wait for a manager.
+const (
+	ReviewNotRequired = "not_required"
+	ReviewPending     = "pending"
+	ReviewApproved    = "approved"
+	ReviewRejected    = "rejected"
+)
+
+// earthRadiusMeters is the mean radius used for distances between coordinates
+const earthRadiusMeters = 6371000
+
+// GeoPoint is a WGS84 coordinate
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Valid reports whether the point is a possible coordinate
+func (p GeoPoint) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// DistanceTo returns the great-circle distance to q in meters
+func (p GeoPoint) DistanceTo(q GeoPoint) float64 {
+	lat1, lat2 := radians(p.Latitude), radians(q.Latitude)
+	dLat, dLon := lat2-lat1, radians(q.Longitude-p.Longitude)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+// Geofence is an area around a branch where clocking in counts as on site: a
+// polygon when Polygon has three or more points, otherwise a circle of
+// RadiusMeters around the centre. A branch may have several.
+type Geofence struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	BranchID     uuid.UUID  `json:"branch_id" db:"branch_id"`
+	Name         string     `json:"name" db:"name"`
+	Latitude     float64    `json:"latitude" db:"latitude"`
+	Longitude    float64    `json:"longitude" db:"longitude"`
+	RadiusMeters float64    `json:"radius_meters" db:"radius_meters"`
+	Polygon      []GeoPoint `json:"polygon" db:"polygon"`
+	Active       bool       `json:"active" db:"active"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Centre returns the geofence's centre point
+func (g *Geofence) Centre() GeoPoint {
+	return GeoPoint{Latitude: g.Latitude, Longitude: g.Longitude}
+}
+
+// Contains reports whether point lies inside the geofence
+func (g *Geofence) Contains(point GeoPoint) bool {
+	if len(g.Polygon) >= 3 {
+		return polygonContains(g.Polygon, point)
+	}
+	return g.Centre().DistanceTo(point) <= g.RadiusMeters
+}
+
+// AttendanceEvidence is the location and photo captured with a clock-in or
+// clock-out from a phone. WithinFence is nil when no geofence applied, e.g.
+// the branch has none configured.
+type AttendanceEvidence struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	AttendanceID     uuid.UUID  `json:"attendance_id" db:"attendance_id"`
+	EmployeeID       uuid.UUID  `json:"employee_id" db:"employee_id"`
+	Kind             string     `json:"kind" db:"kind"` // clock_in, clock_out
+	CapturedAt       time.Time  `json:"captured_at" db:"captured_at"`
+	Latitude         *float64   `json:"latitude" db:"latitude"`
+	Longitude        *float64   `json:"longitude" db:"longitude"`
+	AccuracyMeters   *float64   `json:"accuracy_meters" db:"accuracy_meters"`
+	BranchID         *uuid.UUID `json:"branch_id" db:"branch_id"`
+	GeofenceID       *uuid.UUID `json:"geofence_id" db:"geofence_id"`         // the fence the punch was in, or the nearest
+	DistanceMeters   *float64   `json:"distance_meters" db:"distance_meters"` // from the geofence centre
+	WithinFence      *bool      `json:"within_fence" db:"within_fence"`
+	Photo            []byte     `json:"-" db:"photo"`
+	PhotoContentType string     `json:"photo_content_type" db:"photo_content_type"`
+	HasPhoto         bool       `json:"has_photo" db:"-"`
+	ReviewStatus     string     `json:"review_status" db:"review_status"` // not_required, pending, approved, rejected
+	ReviewedBy       *uuid.UUID `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt       *time.Time `json:"reviewed_at" db:"reviewed_at"`
+	ReviewComment    string     `json:"review_comment" db:"review_comment"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type GeofenceRepository interface {
+	Create(geofence *Geofence) error
+	GetByID(id uuid.UUID) (*Geofence, error)
+	// GetAll returns the geofences of a branch, or of every branch when
+	// branchID is nil
+	GetAll(branchID *uuid.UUID, activeOnly bool) ([]*Geofence, error)
+	Update(geofence *Geofence) error
+	Delete(id uuid.UUID) error
+}
+
+type AttendanceEvidenceRepository interface {
+	Create(evidence *AttendanceEvidence) error
+	// GetByID returns the evidence without its photo
+	GetByID(id uuid.UUID) (*AttendanceEvidence, error)
+	GetPhoto(id uuid.UUID) ([]byte, string, error)
+	GetAll(filter *AttendanceEvidenceFilter) ([]*AttendanceEvidence, error)
+	UpdateReview(evidence *AttendanceEvidence) error
+}
+
+type AttendanceEvidenceFilter struct {
+	EmployeeID   *uuid.UUID
+	AttendanceID *uuid.UUID
+	ReviewStatus string
+	StartDate    *time.Time
+	EndDate      *time.Time
+	Limit        int
+	Offset       int
+}
+
+// polygonContains is the even-odd ray casting test, treating longitude and
+// latitude as planar, which holds for site-sized polygons
+func polygonContains(polygon []GeoPoint, point GeoPoint) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Latitude > point.Latitude) != (b.Latitude > point.Latitude) &&
+			point.Longitude < (b.Longitude-a.Longitude)*(point.Latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
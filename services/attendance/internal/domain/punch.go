@@ -0,0 +1,226 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Punch directions reported by terminals. Most devices let staff pick the
+// wrong state, so pairing relies on order and direction is only a hint.
+const (
+	PunchDirectionIn      = "in"
+	PunchDirectionOut     = "out"
+	PunchDirectionUnknown = ""
+)
+
+// Punch sources
+const (
+	PunchSourceCSV    = "csv"
+	PunchSourceZKTeco = "zkteco"
+	PunchSourceAPI    = "api"
+)
+
+// Punch statuses
+const (
+	PunchStatusPending   = "pending"
+	PunchStatusProcessed = "processed"
+	PunchStatusException = "exception"
+)
+
+// Punch exception reasons
+const (
+	PunchExceptionUnparseable  = "unparseable"
+	PunchExceptionUnmappedUser = "unmapped_user"
+	PunchExceptionNoShift      = "no_shift"
+	PunchExceptionSinglePunch  = "single_punch"
+	PunchExceptionOddDirection = "odd_direction"
+)
+
+// DoublePunchWindow is the gap under which repeated punches by the same
+// person are treated as one, e.g. a second press after a failed read.
+const DoublePunchWindow = 2 * time.Minute
+
+// DeviceUser maps a terminal's enrolment number to an employee. An empty
+// DeviceID applies to every terminal, for sites that enrol staff with the
+// same number everywhere.
+type DeviceUser struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	DeviceID     string    `json:"device_id" db:"device_id"`
+	DeviceUserID string    `json:"device_user_id" db:"device_user_id"`
+	EmployeeID   uuid.UUID `json:"employee_id" db:"employee_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RawPunch is one punch as read from a terminal or log file
+type RawPunch struct {
+	Line         int       `json:"line"`
+	DeviceID     string    `json:"device_id"`
+	DeviceUserID string    `json:"device_user_id"`
+	Time         time.Time `json:"time"`
+	Direction    string    `json:"direction"`
+	Raw          string    `json:"raw"`
+}
+
+// RejectedLine is a log line that could not be read as a punch
+type RejectedLine struct {
+	Line   int    `json:"line"`
+	Raw    string `json:"raw"`
+	Reason string `json:"reason"`
+}
+
+// PunchLog is the content of one terminal export
+type PunchLog struct {
+	Lines    int
+	Punches  []RawPunch
+	Rejected []RejectedLine
+}
+
+// Punch is a stored terminal punch
+type Punch struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	ImportID     *uuid.UUID `json:"import_id" db:"import_id"`
+	DeviceID     string     `json:"device_id" db:"device_id"`
+	DeviceUserID string     `json:"device_user_id" db:"device_user_id"`
+	EmployeeID   *uuid.UUID `json:"employee_id" db:"employee_id"`
+	PunchTime    time.Time  `json:"punch_time" db:"punch_time"`
+	Direction    string     `json:"direction" db:"direction"`
+	Source       string     `json:"source" db:"source"`
+	AttendanceID *uuid.UUID `json:"attendance_id" db:"attendance_id"`
+	Status       string     `json:"status" db:"status"` // pending, processed, exception
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PunchImport summarises one ingestion run
+type PunchImport struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Source     string     `json:"source" db:"source"`
+	FileName   string     `json:"file_name" db:"file_name"`
+	DeviceID   string     `json:"device_id" db:"device_id"`
+	TotalLines int        `json:"total_lines" db:"total_lines"`
+	Imported   int        `json:"imported" db:"imported"`
+	Duplicates int        `json:"duplicates" db:"duplicates"`
+	Exceptions int        `json:"exceptions" db:"exceptions"`
+	Sessions   int        `json:"sessions" db:"sessions"`
+	ImportedBy *uuid.UUID `json:"imported_by" db:"imported_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// PunchException records a punch or log line that could not be turned into
+// attendance as-is and needs a person to look at it
+type PunchException struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	ImportID       *uuid.UUID `json:"import_id" db:"import_id"`
+	PunchID        *uuid.UUID `json:"punch_id" db:"punch_id"`
+	EmployeeID     *uuid.UUID `json:"employee_id" db:"employee_id"`
+	DeviceID       string     `json:"device_id" db:"device_id"`
+	DeviceUserID   string     `json:"device_user_id" db:"device_user_id"`
+	PunchTime      *time.Time `json:"punch_time" db:"punch_time"`
+	AttendanceDate *time.Time `json:"attendance_date" db:"attendance_date"`
+	Line           int        `json:"line" db:"line"`
+	Raw            string     `json:"raw" db:"raw"`
+	Reason         string     `json:"reason" db:"reason"`
+	Details        string     `json:"details" db:"details"`
+	Resolved       bool       `json:"resolved" db:"resolved"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// PunchSession is the span between the first and last punch of a shift
+type PunchSession struct {
+	In          time.Time
+	Out         *time.Time
+	Punches     int
+	DoublePunch int
+	OddOrder    bool
+}
+
+// PairPunches turns one shift's punches into a session: the first punch is the
+// check-in and the last the check-out. Punches within DoublePunchWindow of the
+// previous one are ignored, and directions that contradict the pairing are
+// reported as odd.
+func PairPunches(punches []*Punch) *PunchSession {
+	if len(punches) == 0 {
+		return nil
+	}
+
+	sorted := make([]*Punch, len(punches))
+	copy(sorted, punches)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PunchTime.Before(sorted[j].PunchTime) })
+
+	kept := []*Punch{sorted[0]}
+	doubles := 0
+	for _, punch := range sorted[1:] {
+		if punch.PunchTime.Sub(kept[len(kept)-1].PunchTime) < DoublePunchWindow {
+			doubles++
+			continue
+		}
+		kept = append(kept, punch)
+	}
+
+	first := kept[0]
+	session := &PunchSession{In: first.PunchTime, Punches: len(kept), DoublePunch: doubles}
+	session.OddOrder = first.Direction == PunchDirectionOut
+	if len(kept) > 1 {
+		last := kept[len(kept)-1]
+		session.Out = &last.PunchTime
+		session.OddOrder = session.OddOrder || last.Direction == PunchDirectionIn
+	}
+	return session
+}
+
+// PunchRange is the span of punches recorded against the window's shift. It
+// widens the window by half the off-shift time either side, so consecutive
+// days on the same shift split the gap between them and early arrivals or
+// late leavers still land on the right day.
+func (w ShiftWindow) PunchRange() (time.Time, time.Time) {
+	slack := (MaxShiftSpan - w.End.Sub(w.Start)) / 2
+	if slack < 0 {
+		slack = 0
+	}
+	return w.Start.Add(-slack), w.End.Add(slack)
+}
+
+type DeviceUserRepository interface {
+	Create(deviceUser *DeviceUser) error
+	GetByID(id uuid.UUID) (*DeviceUser, error)
+	GetAll() ([]*DeviceUser, error)
+	Resolve(deviceID, deviceUserID string) (*DeviceUser, error)
+	Delete(id uuid.UUID) error
+}
+
+type PunchRepository interface {
+	// CreateIfNew stores a punch unless the same device, user and time is
+	// already stored, and reports whether it was inserted
+	CreateIfNew(punch *Punch) (bool, error)
+	GetByEmployeeBetween(employeeID uuid.UUID, from, to time.Time) ([]*Punch, error)
+	SetResult(ids []uuid.UUID, status string, attendanceID *uuid.UUID) error
+}
+
+type PunchImportRepository interface {
+	Create(punchImport *PunchImport) error
+	GetByID(id uuid.UUID) (*PunchImport, error)
+	GetAll(limit, offset int) ([]*PunchImport, error)
+	Update(punchImport *PunchImport) error
+}
+
+type PunchExceptionRepository interface {
+	Create(exception *PunchException) error
+	GetByID(id uuid.UUID) (*PunchException, error)
+	GetAll(filter *PunchExceptionFilter) ([]*PunchException, error)
+	Update(exception *PunchException) error
+}
+
+type PunchExceptionFilter struct {
+	ImportID   *uuid.UUID
+	EmployeeID *uuid.UUID
+	Reason     string
+	Resolved   *bool
+	StartDate  *time.Time
+	EndDate    *time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Roster entry sources. Generation only overwrites generated entries unless
+// asked to, so approved swaps survive a re-run.
+const (
+	RosterSourceGenerated = "generated"
+	RosterSourceSwap      = "swap"
+)
+
+// Shift swap statuses. The counterpart accepts or declines first, then a
+// manager approves or rejects.
+const (
+	SwapStatusPending   = "pending"
+	SwapStatusAccepted  = "accepted"
+	SwapStatusDeclined  = "declined"
+	SwapStatusApproved  = "approved"
+	SwapStatusRejected  = "rejected"
+	SwapStatusCancelled = "cancelled"
+)
+
+// MaxRosterDays is the longest period generated in one run
+const MaxRosterDays = 93
+
+// RosterDay is one day of a pattern's cycle; a nil ShiftID is a rest day
+type RosterDay struct {
+	ShiftID *uuid.UUID `json:"shift_id"`
+}
+
+// RosterPattern is a repeating cycle of shifts and rest days, e.g. four day
+// shifts then four off, or a week of days followed by a week of nights
+type RosterPattern struct {
+	ID          uuid.UUID   `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name"`
+	Code        string      `json:"code" db:"code"`
+	Description string      `json:"description" db:"description"`
+	Days        []RosterDay `json:"days" db:"days"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// ShiftOn returns the pattern's shift on date for a cycle that starts on
+// anchor, or nil on a rest day
+func (p *RosterPattern) ShiftOn(anchor, date time.Time) *uuid.UUID {
+	if len(p.Days) == 0 {
+		return nil
+	}
+	days := daysBetween(anchor, date) % len(p.Days)
+	if days < 0 {
+		days += len(p.Days)
+	}
+	return p.Days[days].ShiftID
+}
+
+// RosterAssignment puts an employee, or every employee of a department, on a
+// pattern from EffectiveFrom until EffectiveTo (inclusive, nil while
+// open-ended). AnchorDate is the date the cycle's first day falls on, so
+// teams on the same pattern can be staggered.
+type RosterAssignment struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	PatternID     uuid.UUID  `json:"pattern_id" db:"pattern_id"`
+	EmployeeID    *uuid.UUID `json:"employee_id" db:"employee_id"`
+	DepartmentID  *uuid.UUID `json:"department_id" db:"department_id"`
+	EffectiveFrom time.Time  `json:"effective_from" db:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to" db:"effective_to"`
+	AnchorDate    time.Time  `json:"anchor_date" db:"anchor_date"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Covers reports whether the assignment is in effect on date
+func (a *RosterAssignment) Covers(date time.Time) bool {
+	return !date.Before(a.EffectiveFrom) && (a.EffectiveTo == nil || !date.After(*a.EffectiveTo))
+}
+
+// RosterEntry is an employee's rostered shift on one date; a nil ShiftID is
+// a rest day
+type RosterEntry struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	EmployeeID    uuid.UUID  `json:"employee_id" db:"employee_id"`
+	RosterDate    time.Time  `json:"roster_date" db:"roster_date"`
+	ShiftID       *uuid.UUID `json:"shift_id" db:"shift_id"`
+	AssignmentID  *uuid.UUID `json:"assignment_id" db:"assignment_id"`
+	SwapRequestID *uuid.UUID `json:"swap_request_id" db:"swap_request_id"`
+	Source        string     `json:"source" db:"source"` // generated, swap
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ShiftSwapRequest asks to exchange the requester's roster on RequesterDate
+// with the counterpart's. When CounterpartDate differs, the two employees
+// also exchange their rosters on that date, i.e. each works the other's day.
+type ShiftSwapRequest struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	RequesterID     uuid.UUID  `json:"requester_id" db:"requester_id"`
+	RequesterDate   time.Time  `json:"requester_date" db:"requester_date"`
+	CounterpartID   uuid.UUID  `json:"counterpart_id" db:"counterpart_id"`
+	CounterpartDate time.Time  `json:"counterpart_date" db:"counterpart_date"`
+	Reason          string     `json:"reason" db:"reason"`
+	Status          string     `json:"status" db:"status"` // pending, accepted, declined, approved, rejected, cancelled
+	RespondedAt     *time.Time `json:"responded_at" db:"responded_at"`
+	DecidedBy       *uuid.UUID `json:"decided_by" db:"decided_by"`
+	DecidedAt       *time.Time `json:"decided_at" db:"decided_at"`
+	Comment         string     `json:"comment" db:"comment"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Dates returns the dates whose entries the swap exchanges
+func (s *ShiftSwapRequest) Dates() []time.Time {
+	if s.CounterpartDate.Equal(s.RequesterDate) {
+		return []time.Time{s.RequesterDate}
+	}
+	return []time.Time{s.RequesterDate, s.CounterpartDate}
+}
+
+func daysBetween(from, to time.Time) int {
+	fromYear, fromMonth, fromDay := from.Date()
+	toYear, toMonth, toDay := to.Date()
+	start := time.Date(fromYear, fromMonth, fromDay, 0, 0, 0, 0, time.UTC)
+	end := time.Date(toYear, toMonth, toDay, 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
+
+type RosterPatternRepository interface {
+	Create(pattern *RosterPattern) error
+	GetByID(id uuid.UUID) (*RosterPattern, error)
+	GetByCode(code string) (*RosterPattern, error)
+	GetAll() ([]*RosterPattern, error)
+	Update(pattern *RosterPattern) error
+	Delete(id uuid.UUID) error
+}
+
+type RosterAssignmentRepository interface {
+	Create(assignment *RosterAssignment) error
+	GetByID(id uuid.UUID) (*RosterAssignment, error)
+	GetAll() ([]*RosterAssignment, error)
+	// GetActiveBetween returns assignments in effect on any date from from to to
+	GetActiveBetween(from, to time.Time) ([]*RosterAssignment, error)
+	Delete(id uuid.UUID) error
+}
+
+type RosterEntryRepository interface {
+	// Upsert stores the employee's entry for its date, replacing any existing one
+	Upsert(entry *RosterEntry) error
+	GetForDate(employeeID uuid.UUID, date time.Time) (*RosterEntry, error)
+	GetByDate(date time.Time) ([]*RosterEntry, error)
+	GetAll(filter *RosterEntryFilter) ([]*RosterEntry, error)
+	// UpdateShifts saves the entries' shifts in one transaction, so a swap
+	// reaches both employees or neither
+	UpdateShifts(entries []*RosterEntry) error
+}
+
+type ShiftSwapRepository interface {
+	Create(swap *ShiftSwapRequest) error
+	GetByID(id uuid.UUID) (*ShiftSwapRequest, error)
+	GetAll(filter *ShiftSwapFilter) ([]*ShiftSwapRequest, error)
+	Update(swap *ShiftSwapRequest) error
+}
+
+// EmployeeDirectory reads the employee records shared by the services
+type EmployeeDirectory interface {
+	GetActiveByDepartment(departmentID uuid.UUID) ([]uuid.UUID, error)
+	GetByID(id uuid.UUID) (*DirectoryEmployee, error)
+	// GetByEmail finds the employee a user signs in as
+	GetByEmail(email string) (*DirectoryEmployee, error)
+}
+
+// DirectoryEmployee is the part of an employee record attendance relies on.
+// BranchID is the branch of the employee's current grade, whose geofences
+// their punches are checked against.
+type DirectoryEmployee struct {
+	ID        uuid.UUID
+	ManagerID *uuid.UUID
+	BranchID  *uuid.UUID
+}
+
+type RosterEntryFilter struct {
+	EmployeeID *uuid.UUID
+	StartDate  time.Time
+	EndDate    time.Time
+}
+
+type ShiftSwapFilter struct {
+	EmployeeID *uuid.UUID // requester or counterpart
+	Status     string
+	Limit      int
+	Offset     int
+}
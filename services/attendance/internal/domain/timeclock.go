@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxShiftSpan is the longest a clock-in stays open; older open records are
+// treated as a missed clock-out.
+const MaxShiftSpan = 24 * time.Hour
+
+// ShiftWindow is a shift's schedule on one attendance date
+type ShiftWindow struct {
+	Start time.Time
+	End   time.Time
+	Break time.Duration
+}
+
+// Window places the shift on date (in date's location). A shift that ends at
+// or before its start time crosses midnight and ends the next day.
+func (s *Shift) Window(date time.Time) (ShiftWindow, error) {
+	startHour, startMinute, err := parseClock(s.StartTime)
+	if err != nil {
+		return ShiftWindow{}, fmt.Errorf("shift %s start time: %w", s.Code, err)
+	}
+	endHour, endMinute, err := parseClock(s.EndTime)
+	if err != nil {
+		return ShiftWindow{}, fmt.Errorf("shift %s end time: %w", s.Code, err)
+	}
+
+	var breakTime time.Duration
+	if strings.TrimSpace(s.BreakTime) != "" {
+		breakHour, breakMinute, err := parseClock(s.BreakTime)
+		if err != nil {
+			return ShiftWindow{}, fmt.Errorf("shift %s break time: %w", s.Code, err)
+		}
+		breakTime = time.Duration(breakHour)*time.Hour + time.Duration(breakMinute)*time.Minute
+	}
+
+	year, month, day := date.Date()
+	start := time.Date(year, month, day, startHour, startMinute, 0, 0, date.Location())
+	end := time.Date(year, month, day, endHour, endMinute, 0, 0, date.Location())
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return ShiftWindow{Start: start, End: end, Break: breakTime}, nil
+}
+
+// ScheduledHours is the shift's configured WorkHours, or the window less the
+// break when WorkHours is not set.
+func (s *Shift) ScheduledHours(window ShiftWindow) float64 {
+	if s.WorkHours > 0 {
+		return s.WorkHours
+	}
+	return math.Max(0, (window.End.Sub(window.Start) - window.Break).Hours())
+}
+
+// AttendanceDate returns the date a punch at t is recorded against. Punches
+// in the early hours before the previous day's shift has ended belong to that
+// previous day, so a night shift stays on one record.
+func (s *Shift) AttendanceDate(t time.Time) (time.Time, error) {
+	year, month, day := t.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+
+	previous, err := s.Window(today.AddDate(0, 0, -1))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if previous.End.After(today) && t.Before(previous.End) {
+		return today.AddDate(0, 0, -1), nil
+	}
+	return today, nil
+}
+
+// WorkedHours is the time between check-in and check-out less the shift
+// break. The break is only deducted once more than half the shift has been
+// worked, since shorter attendances usually run without one.
+func WorkedHours(shift *Shift, window ShiftWindow, checkIn, checkOut time.Time) float64 {
+	span := checkOut.Sub(checkIn)
+	if span <= 0 {
+		return 0
+	}
+	if span.Hours() > shift.ScheduledHours(window)/2 && span > window.Break {
+		span -= window.Break
+	}
+	return roundHours(span.Hours())
+}
+
+// OvertimeHours is the time worked beyond the scheduled hours
+func OvertimeHours(shift *Shift, window ShiftWindow, workHours float64) float64 {
+	return roundHours(math.Max(0, workHours-shift.ScheduledHours(window)))
+}
+
+// ArrivalStatus classifies a check-in as present or late against the shift
+// start plus its grace period.
+func ArrivalStatus(shift *Shift, window ShiftWindow, checkIn time.Time) string {
+	if checkIn.After(window.Start.Add(time.Duration(shift.GraceMinutes) * time.Minute)) {
+		return AttendanceStatusLate
+	}
+	return AttendanceStatusPresent
+}
+
+// ClassifyAttendance grades a completed day. Working under half the half-day
+// threshold counts as absent and under the threshold as a half day; otherwise
+// the arrival decides between present and late.
+func ClassifyAttendance(shift *Shift, window ShiftWindow, checkIn time.Time, workHours float64) string {
+	halfDay := shift.HalfDayHours
+	if halfDay <= 0 {
+		halfDay = shift.ScheduledHours(window) / 2
+	}
+
+	switch {
+	case workHours < halfDay/2:
+		return AttendanceStatusAbsent
+	case workHours < halfDay:
+		return AttendanceStatusHalfDay
+	}
+	return ArrivalStatus(shift, window, checkIn)
+}
+
+// parseClock reads an HH:MM time of day or duration
+func parseClock(value string) (int, int, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, fmt.Errorf("invalid hour in %q", value)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid minute in %q", value)
+	}
+	return hour, minute, nil
+}
+
+// ValidateClock reports whether value is a valid HH:MM time
+func ValidateClock(value string) error {
+	_, _, err := parseClock(value)
+	return err
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
@@ -0,0 +1,1133 @@
+package http
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"yathuerp/services/attendance/internal/application"
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/services/attendance/internal/infrastructure/punchlog"
+	"yathuerp/shared/middleware"
+	"yathuerp/shared/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+type Handler struct {
+	timeClockUseCase *application.TimeClockUseCase
+	shiftUseCase     *application.ShiftUseCase
+	punchUseCase     *application.PunchUseCase
+	rosterUseCase    *application.RosterUseCase
+	geofenceUseCase  *application.GeofenceUseCase
+	exceptionUseCase *application.ExceptionUseCase
+	logger           utils.Logger
+}
+
+func NewHandler(
+	timeClockUseCase *application.TimeClockUseCase,
+	shiftUseCase *application.ShiftUseCase,
+	punchUseCase *application.PunchUseCase,
+	rosterUseCase *application.RosterUseCase,
+	geofenceUseCase *application.GeofenceUseCase,
+	exceptionUseCase *application.ExceptionUseCase,
+	logger utils.Logger,
+) *Handler {
+	return &Handler{
+		timeClockUseCase: timeClockUseCase,
+		shiftUseCase:     shiftUseCase,
+		punchUseCase:     punchUseCase,
+		rosterUseCase:    rosterUseCase,
+		geofenceUseCase:  geofenceUseCase,
+		exceptionUseCase: exceptionUseCase,
+		logger:           logger,
+	}
+}
+
+// Time clock
+
+func (h *Handler) ClockIn(c *fiber.Ctx) error {
+	var req application.ClockRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	attendance, err := h.timeClockUseCase.ClockIn(c.Context(), &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to clock in")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Clocked in successfully",
+		Data:    attendance,
+	})
+}
+
+func (h *Handler) ClockOut(c *fiber.Ctx) error {
+	var req application.ClockRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	attendance, err := h.timeClockUseCase.ClockOut(c.Context(), &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to clock out")
+	}
+
+	return utils.SendSuccess(c, "Clocked out successfully", attendance)
+}
+
+func (h *Handler) MarkAbsentees(c *fiber.Ctx) error {
+	date, err := dateQuery(c, "date", time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid date, expected YYYY-MM-DD")
+	}
+
+	absentees, err := h.timeClockUseCase.MarkAbsentees(c.Context(), date)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to mark absentees")
+	}
+
+	return utils.SendSuccess(c, "Absentees marked successfully", absentees)
+}
+
+// Attendance records
+
+func (h *Handler) GetAttendances(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.AttendanceFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+		}
+		filter.EmployeeID = &id
+	}
+	if c.Query("start_date") != "" {
+		start, err := time.Parse(dateLayout, c.Query("start_date"))
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid start_date, expected YYYY-MM-DD")
+		}
+		filter.StartDate = &start
+	}
+	if c.Query("end_date") != "" {
+		end, err := time.Parse(dateLayout, c.Query("end_date"))
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid end_date, expected YYYY-MM-DD")
+		}
+		filter.EndDate = &end
+	}
+
+	attendances, err := h.timeClockUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get attendance", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get attendance")
+	}
+
+	return utils.SendSuccess(c, "Attendance retrieved successfully", attendances)
+}
+
+func (h *Handler) GetAttendanceByID(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid attendance ID")
+	}
+
+	attendance, err := h.timeClockUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get attendance")
+	}
+
+	return utils.SendSuccess(c, "Attendance retrieved successfully", attendance)
+}
+
+// Shifts
+
+func (h *Handler) GetShifts(c *fiber.Ctx) error {
+	shifts, err := h.shiftUseCase.List(c.Context())
+	if err != nil {
+		h.logger.Error("Failed to get shifts", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get shifts")
+	}
+
+	return utils.SendSuccess(c, "Shifts retrieved successfully", shifts)
+}
+
+func (h *Handler) GetShiftByID(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shift ID")
+	}
+
+	shift, err := h.shiftUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get shift")
+	}
+
+	return utils.SendSuccess(c, "Shift retrieved successfully", shift)
+}
+
+func (h *Handler) CreateShift(c *fiber.Ctx) error {
+	var req application.ShiftRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	shift, err := h.shiftUseCase.Create(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create shift")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Shift created successfully",
+		Data:    shift,
+	})
+}
+
+func (h *Handler) UpdateShift(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shift ID")
+	}
+
+	var req application.ShiftRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	shift, err := h.shiftUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update shift")
+	}
+
+	return utils.SendSuccess(c, "Shift updated successfully", shift)
+}
+
+func (h *Handler) DeleteShift(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shift ID")
+	}
+
+	if err := h.shiftUseCase.Delete(c.Context(), id); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete shift")
+	}
+
+	return utils.SendSuccess(c, "Shift deleted successfully", nil)
+}
+
+// Shift assignments
+
+func (h *Handler) AssignShift(c *fiber.Ctx) error {
+	var req application.AssignShiftRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	assignment, err := h.shiftUseCase.Assign(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to assign shift")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Shift assigned successfully",
+		Data:    assignment,
+	})
+}
+
+func (h *Handler) GetEmployeeShifts(c *fiber.Ctx) error {
+	employeeID, err := uuidParam(c, "employeeId")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+
+	assignments, err := h.shiftUseCase.GetAssignments(c.Context(), employeeID)
+	if err != nil {
+		h.logger.Error("Failed to get shift assignments", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get shift assignments")
+	}
+
+	return utils.SendSuccess(c, "Shift assignments retrieved successfully", assignments)
+}
+
+func (h *Handler) DeleteShiftAssignment(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shift assignment ID")
+	}
+
+	if err := h.shiftUseCase.DeleteAssignment(c.Context(), id); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete shift assignment")
+	}
+
+	return utils.SendSuccess(c, "Shift assignment deleted successfully", nil)
+}
+
+// Terminal punches
+
+func (h *Handler) PushPunches(c *fiber.Ctx) error {
+	var req application.PunchBatchRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	punchImport, err := h.punchUseCase.Push(c.Context(), &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to ingest punches")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Punches ingested successfully",
+		Data:    punchImport,
+	})
+}
+
+// ImportPunchLog ingests an uploaded terminal export. The format is csv or
+// zkteco, taken from the file extension when not given; timestamps without a
+// zone are read in the timezone field, defaulting to the server's.
+func (h *Handler) ImportPunchLog(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "A punch log file is required")
+	}
+
+	format := strings.ToLower(c.FormValue("format"))
+	if format == "" {
+		format = punchlog.FormatZKTeco
+		if strings.EqualFold(filepath.Ext(fileHeader.Filename), ".csv") {
+			format = punchlog.FormatCSV
+		}
+	}
+
+	loc := time.Local
+	if name := c.FormValue("timezone"); name != "" {
+		if loc, err = time.LoadLocation(name); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid timezone")
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Failed to read punch log file")
+	}
+	defer file.Close()
+
+	log, err := punchlog.Parse(format, file, loc)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	punchImport, err := h.punchUseCase.Ingest(c.Context(), &application.IngestRequest{
+		Source:     format,
+		FileName:   fileHeader.Filename,
+		DeviceID:   strings.TrimSpace(c.FormValue("device_id")),
+		Log:        log,
+		ImportedBy: currentUserID(c),
+	})
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to import punch log")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Punch log imported successfully",
+		Data:    punchImport,
+	})
+}
+
+func (h *Handler) GetPunchImports(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+
+	punchImports, err := h.punchUseCase.ListImports(c.Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to get punch imports", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get punch imports")
+	}
+
+	return utils.SendSuccess(c, "Punch imports retrieved successfully", punchImports)
+}
+
+func (h *Handler) GetPunchImportByID(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid punch import ID")
+	}
+
+	punchImport, err := h.punchUseCase.GetImport(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get punch import")
+	}
+
+	return utils.SendSuccess(c, "Punch import retrieved successfully", punchImport)
+}
+
+// GetPunchExceptions is the exceptions report, open exceptions by default
+func (h *Handler) GetPunchExceptions(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.PunchExceptionFilter{
+		Reason: c.Query("reason"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	if importID := c.Query("import_id"); importID != "" {
+		id, err := uuid.Parse(importID)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid import ID")
+		}
+		filter.ImportID = &id
+	}
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+		}
+		filter.EmployeeID = &id
+	}
+	if resolved := c.Query("resolved", "false"); resolved != "all" {
+		value, err := strconv.ParseBool(resolved)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid resolved, expected true, false or all")
+		}
+		filter.Resolved = &value
+	}
+	if c.Query("start_date") != "" {
+		start, err := time.Parse(dateLayout, c.Query("start_date"))
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid start_date, expected YYYY-MM-DD")
+		}
+		filter.StartDate = &start
+	}
+	if c.Query("end_date") != "" {
+		end, err := time.Parse(dateLayout, c.Query("end_date"))
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid end_date, expected YYYY-MM-DD")
+		}
+		filter.EndDate = &end
+	}
+
+	exceptions, err := h.punchUseCase.ListExceptions(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get punch exceptions", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get punch exceptions")
+	}
+
+	return utils.SendSuccess(c, "Punch exceptions retrieved successfully", exceptions)
+}
+
+func (h *Handler) ResolvePunchException(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid punch exception ID")
+	}
+
+	var req application.ResolvePunchExceptionRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	exception, err := h.punchUseCase.ResolveException(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to resolve punch exception")
+	}
+
+	return utils.SendSuccess(c, "Punch exception resolved successfully", exception)
+}
+
+// Device users
+
+func (h *Handler) GetDeviceUsers(c *fiber.Ctx) error {
+	deviceUsers, err := h.punchUseCase.ListDeviceUsers(c.Context())
+	if err != nil {
+		h.logger.Error("Failed to get device users", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get device users")
+	}
+
+	return utils.SendSuccess(c, "Device users retrieved successfully", deviceUsers)
+}
+
+func (h *Handler) CreateDeviceUser(c *fiber.Ctx) error {
+	var req application.DeviceUserRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	deviceUser, err := h.punchUseCase.CreateDeviceUser(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to map device user")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Device user mapped successfully",
+		Data:    deviceUser,
+	})
+}
+
+func (h *Handler) DeleteDeviceUser(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid device user ID")
+	}
+
+	if err := h.punchUseCase.DeleteDeviceUser(c.Context(), id); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete device user")
+	}
+
+	return utils.SendSuccess(c, "Device user deleted successfully", nil)
+}
+
+// Rosters
+
+func (h *Handler) GetRosterPatterns(c *fiber.Ctx) error {
+	patterns, err := h.rosterUseCase.ListPatterns(c.Context())
+	if err != nil {
+		h.logger.Error("Failed to get roster patterns", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get roster patterns")
+	}
+
+	return utils.SendSuccess(c, "Roster patterns retrieved successfully", patterns)
+}
+
+func (h *Handler) GetRosterPatternByID(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid roster pattern ID")
+	}
+
+	pattern, err := h.rosterUseCase.GetPattern(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get roster pattern")
+	}
+
+	return utils.SendSuccess(c, "Roster pattern retrieved successfully", pattern)
+}
+
+func (h *Handler) CreateRosterPattern(c *fiber.Ctx) error {
+	var req application.RosterPatternRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	pattern, err := h.rosterUseCase.CreatePattern(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create roster pattern")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Roster pattern created successfully",
+		Data:    pattern,
+	})
+}
+
+func (h *Handler) UpdateRosterPattern(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid roster pattern ID")
+	}
+
+	var req application.RosterPatternRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	pattern, err := h.rosterUseCase.UpdatePattern(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update roster pattern")
+	}
+
+	return utils.SendSuccess(c, "Roster pattern updated successfully", pattern)
+}
+
+func (h *Handler) DeleteRosterPattern(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid roster pattern ID")
+	}
+
+	if err := h.rosterUseCase.DeletePattern(c.Context(), id); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete roster pattern")
+	}
+
+	return utils.SendSuccess(c, "Roster pattern deleted successfully", nil)
+}
+
+func (h *Handler) GetRosterAssignments(c *fiber.Ctx) error {
+	assignments, err := h.rosterUseCase.ListAssignments(c.Context())
+	if err != nil {
+		h.logger.Error("Failed to get roster assignments", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get roster assignments")
+	}
+
+	return utils.SendSuccess(c, "Roster assignments retrieved successfully", assignments)
+}
+
+func (h *Handler) AssignRoster(c *fiber.Ctx) error {
+	var req application.RosterAssignmentRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	assignment, err := h.rosterUseCase.Assign(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to assign roster")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Roster assigned successfully",
+		Data:    assignment,
+	})
+}
+
+func (h *Handler) DeleteRosterAssignment(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid roster assignment ID")
+	}
+
+	if err := h.rosterUseCase.DeleteAssignment(c.Context(), id); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete roster assignment")
+	}
+
+	return utils.SendSuccess(c, "Roster assignment deleted successfully", nil)
+}
+
+func (h *Handler) GenerateRoster(c *fiber.Ctx) error {
+	var req application.GenerateRosterRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.rosterUseCase.Generate(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to generate roster")
+	}
+
+	return utils.SendSuccess(c, "Roster generated successfully", result)
+}
+
+func (h *Handler) GetRoster(c *fiber.Ctx) error {
+	start, err := dateQuery(c, "start_date", time.Now())
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid start_date, expected YYYY-MM-DD")
+	}
+	end, err := dateQuery(c, "end_date", start.AddDate(0, 0, 6))
+	if err != nil || end.Before(start) {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid end_date, expected YYYY-MM-DD on or after start_date")
+	}
+
+	filter := &domain.RosterEntryFilter{StartDate: start, EndDate: end}
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+		}
+		filter.EmployeeID = &id
+	}
+
+	entries, err := h.rosterUseCase.GetRoster(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get roster", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get roster")
+	}
+
+	return utils.SendSuccess(c, "Roster retrieved successfully", entries)
+}
+
+func (h *Handler) GetShiftSwaps(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.ShiftSwapFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+		}
+		filter.EmployeeID = &id
+	}
+
+	swaps, err := h.rosterUseCase.ListSwaps(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get shift swaps", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get shift swaps")
+	}
+
+	return utils.SendSuccess(c, "Shift swaps retrieved successfully", swaps)
+}
+
+func (h *Handler) GetShiftSwapByID(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shift swap ID")
+	}
+
+	swap, err := h.rosterUseCase.GetSwap(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get shift swap")
+	}
+
+	return utils.SendSuccess(c, "Shift swap retrieved successfully", swap)
+}
+
+func (h *Handler) RequestShiftSwap(c *fiber.Ctx) error {
+	var req application.ShiftSwapRequestBody
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	swap, err := h.rosterUseCase.RequestSwap(c.Context(), &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to request shift swap")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Shift swap requested successfully",
+		Data:    swap,
+	})
+}
+
+func (h *Handler) RespondToShiftSwap(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shift swap ID")
+	}
+
+	var req application.RespondShiftSwapRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	swap, err := h.rosterUseCase.RespondToSwap(c.Context(), id, &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to respond to shift swap")
+	}
+
+	return utils.SendSuccess(c, "Shift swap response recorded successfully", swap)
+}
+
+func (h *Handler) DecideShiftSwap(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shift swap ID")
+	}
+
+	var req application.DecideShiftSwapRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	swap, err := h.rosterUseCase.DecideSwap(c.Context(), id, &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to decide shift swap")
+	}
+
+	return utils.SendSuccess(c, "Shift swap decided successfully", swap)
+}
+
+func (h *Handler) CancelShiftSwap(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid shift swap ID")
+	}
+
+	swap, err := h.rosterUseCase.CancelSwap(c.Context(), id, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to cancel shift swap")
+	}
+
+	return utils.SendSuccess(c, "Shift swap cancelled successfully", swap)
+}
+
+// Geofences
+
+func (h *Handler) GetGeofences(c *fiber.Ctx) error {
+	var branchID *uuid.UUID
+	if c.Query("branch_id") != "" {
+		id, err := uuid.Parse(c.Query("branch_id"))
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid branch ID")
+		}
+		branchID = &id
+	}
+
+	geofences, err := h.geofenceUseCase.List(c.Context(), branchID)
+	if err != nil {
+		h.logger.Error("Failed to get geofences", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get geofences")
+	}
+
+	return utils.SendSuccess(c, "Geofences retrieved successfully", geofences)
+}
+
+func (h *Handler) GetGeofenceByID(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid geofence ID")
+	}
+
+	geofence, err := h.geofenceUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get geofence")
+	}
+
+	return utils.SendSuccess(c, "Geofence retrieved successfully", geofence)
+}
+
+func (h *Handler) CreateGeofence(c *fiber.Ctx) error {
+	var req application.GeofenceRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	geofence, err := h.geofenceUseCase.Create(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create geofence")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Geofence created successfully",
+		Data:    geofence,
+	})
+}
+
+func (h *Handler) UpdateGeofence(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid geofence ID")
+	}
+
+	var req application.GeofenceRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	geofence, err := h.geofenceUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update geofence")
+	}
+
+	return utils.SendSuccess(c, "Geofence updated successfully", geofence)
+}
+
+func (h *Handler) DeleteGeofence(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid geofence ID")
+	}
+
+	if err := h.geofenceUseCase.Delete(c.Context(), id); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete geofence")
+	}
+
+	return utils.SendSuccess(c, "Geofence deleted successfully", nil)
+}
+
+// Clock evidence
+
+func (h *Handler) GetAttendanceEvidence(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.AttendanceEvidenceFilter{
+		ReviewStatus: c.Query("review_status"),
+		Limit:        limit,
+		Offset:       offset,
+	}
+
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+		}
+		filter.EmployeeID = &id
+	}
+	if attendanceID := c.Query("attendance_id"); attendanceID != "" {
+		id, err := uuid.Parse(attendanceID)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid attendance ID")
+		}
+		filter.AttendanceID = &id
+	}
+	if c.Query("start_date") != "" {
+		start, err := time.Parse(dateLayout, c.Query("start_date"))
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid start_date, expected YYYY-MM-DD")
+		}
+		filter.StartDate = &start
+	}
+	if c.Query("end_date") != "" {
+		end, err := time.Parse(dateLayout, c.Query("end_date"))
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid end_date, expected YYYY-MM-DD")
+		}
+		filter.EndDate = &end
+	}
+
+	evidence, err := h.geofenceUseCase.ListEvidence(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get attendance evidence", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get attendance evidence")
+	}
+
+	return utils.SendSuccess(c, "Attendance evidence retrieved successfully", evidence)
+}
+
+func (h *Handler) GetAttendanceEvidenceByID(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid attendance evidence ID")
+	}
+
+	evidence, err := h.geofenceUseCase.GetEvidence(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get attendance evidence")
+	}
+
+	return utils.SendSuccess(c, "Attendance evidence retrieved successfully", evidence)
+}
+
+// GetAttendanceEvidencePhoto serves the photo itself rather than JSON
+func (h *Handler) GetAttendanceEvidencePhoto(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid attendance evidence ID")
+	}
+
+	photo, contentType, err := h.geofenceUseCase.GetEvidencePhoto(c.Context(), id, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get attendance evidence photo")
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+	return c.Send(photo)
+}
+
+func (h *Handler) ReviewAttendanceEvidence(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid attendance evidence ID")
+	}
+
+	var req application.ReviewEvidenceRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	evidence, err := h.geofenceUseCase.ReviewEvidence(c.Context(), id, &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to review attendance evidence")
+	}
+
+	return utils.SendSuccess(c, "Attendance evidence reviewed successfully", evidence)
+}
+
+// Attendance exceptions
+
+func (h *Handler) GetAttendanceExceptions(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.AttendanceExceptionFilter{
+		Type:   c.Query("type"),
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+		}
+		filter.EmployeeID = &id
+	}
+	if c.Query("start_date") != "" {
+		start, err := time.Parse(dateLayout, c.Query("start_date"))
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid start_date, expected YYYY-MM-DD")
+		}
+		filter.StartDate = &start
+	}
+	if c.Query("end_date") != "" {
+		end, err := time.Parse(dateLayout, c.Query("end_date"))
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid end_date, expected YYYY-MM-DD")
+		}
+		filter.EndDate = &end
+	}
+
+	exceptions, err := h.exceptionUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get attendance exceptions", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get attendance exceptions")
+	}
+
+	return utils.SendSuccess(c, "Attendance exceptions retrieved successfully", exceptions)
+}
+
+func (h *Handler) GetAttendanceExceptionByID(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid attendance exception ID")
+	}
+
+	exception, err := h.exceptionUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get attendance exception")
+	}
+
+	return utils.SendSuccess(c, "Attendance exception retrieved successfully", exception)
+}
+
+func (h *Handler) ClaimOvertime(c *fiber.Ctx) error {
+	var req application.OvertimeClaimRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	exception, err := h.exceptionUseCase.ClaimOvertime(c.Context(), &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to claim overtime")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Overtime claimed successfully",
+		Data:    exception,
+	})
+}
+
+func (h *Handler) ScanMissedPunches(c *fiber.Ctx) error {
+	date, err := dateQuery(c, "date", time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid date, expected YYYY-MM-DD")
+	}
+
+	exceptions, err := h.exceptionUseCase.ScanMissedPunches(c.Context(), date)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to scan for missed punches")
+	}
+
+	return utils.SendSuccess(c, "Missed punches scanned successfully", exceptions)
+}
+
+func (h *Handler) JustifyAttendanceException(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid attendance exception ID")
+	}
+
+	var req application.JustifyExceptionRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	exception, err := h.exceptionUseCase.Justify(c.Context(), id, &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to justify attendance exception")
+	}
+
+	return utils.SendSuccess(c, "Attendance exception justified successfully", exception)
+}
+
+func (h *Handler) DecideAttendanceException(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid attendance exception ID")
+	}
+
+	var req application.DecideExceptionRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	exception, err := h.exceptionUseCase.Decide(c.Context(), id, &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to decide attendance exception")
+	}
+
+	return utils.SendSuccess(c, "Attendance exception decided successfully", exception)
+}
+
+// sendUseCaseError maps use case failures onto HTTP status codes
+func (h *Handler) sendUseCaseError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return utils.SendError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, application.ErrInvalidState):
+		return utils.SendError(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, application.ErrNoShift):
+		return utils.SendError(c, fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, application.ErrForbidden):
+		return utils.SendError(c, fiber.StatusForbidden, err.Error())
+	}
+
+	h.logger.Error(message, "error", err)
+	return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+}
+
+func uuidParam(c *fiber.Ctx, name string) (uuid.UUID, error) {
+	return uuid.Parse(c.Params(name))
+}
+
+// currentUserID returns the authenticated user set by the JWT middleware, or
+// nil for service tokens without one
+func currentUserID(c *fiber.Ctx) *uuid.UUID {
+	userID, _ := c.Locals("user_id").(string)
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// currentCaller returns who the request is made by, from the JWT claims
+func currentCaller(c *fiber.Ctx) *application.Caller {
+	email, _ := c.Locals("email").(string)
+	return &application.Caller{
+		UserID: currentUserID(c),
+		Email:  email,
+		IsHR:   middleware.HasRole(c, middleware.RoleHR, middleware.RoleAdmin),
+	}
+}
+
+// pagination reads the page and limit query parameters as limit and offset
+func pagination(c *fiber.Ctx) (int, int) {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	return limit, (page - 1) * limit
+}
+
+// dateQuery parses a YYYY-MM-DD query parameter, falling back to the date of
+// def when it is absent
+func dateQuery(c *fiber.Ctx, name string, def time.Time) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		year, month, day := def.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Parse(dateLayout, value)
+}
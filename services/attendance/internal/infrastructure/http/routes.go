@@ -0,0 +1,111 @@
+package http
+
+import (
+	"yathuerp/shared/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupRoutes(app *fiber.App, handler *Handler, auth *middleware.AuthMiddleware) {
+	// API versioning
+	api := app.Group("/api/v1")
+	attendance := api.Group("/attendance")
+
+	job := auth.JobAuth()
+	hr := auth.RoleBasedAuth(middleware.RoleHR, middleware.RoleAdmin)
+	manager := auth.RoleBasedAuth(middleware.RoleHR, middleware.RoleAdmin, middleware.RoleManager)
+
+	// Time clock
+	attendance.Post("/clock-in", handler.ClockIn)
+	attendance.Post("/clock-out", handler.ClockOut)
+	attendance.Post("/absentees", job, handler.MarkAbsentees)
+
+	// Attendance records
+	attendance.Get("/", handler.GetAttendances)
+	attendance.Get("/records/:id", handler.GetAttendanceByID)
+
+	// Shifts
+	shifts := attendance.Group("/shifts")
+	{
+		shifts.Get("/", handler.GetShifts)
+		shifts.Post("/", handler.CreateShift)
+		shifts.Post("/assignments", handler.AssignShift)
+		shifts.Delete("/assignments/:id", handler.DeleteShiftAssignment)
+		shifts.Get("/:id", handler.GetShiftByID)
+		shifts.Put("/:id", handler.UpdateShift)
+		shifts.Delete("/:id", handler.DeleteShift)
+	}
+
+	attendance.Get("/employees/:employeeId/shifts", handler.GetEmployeeShifts)
+
+	// Terminal punches, pushed by the terminals' service accounts or imported
+	// by HR
+	punches := attendance.Group("/punches")
+	{
+		punches.Post("/", job, handler.PushPunches)
+		punches.Post("/import", job, handler.ImportPunchLog)
+		punches.Get("/imports", handler.GetPunchImports)
+		punches.Get("/imports/:id", handler.GetPunchImportByID)
+		punches.Get("/exceptions", handler.GetPunchExceptions)
+		punches.Post("/exceptions/:id/resolve", hr, handler.ResolvePunchException)
+	}
+
+	// Terminal enrolments
+	devices := attendance.Group("/devices")
+	{
+		devices.Get("/users", handler.GetDeviceUsers)
+		devices.Post("/users", hr, handler.CreateDeviceUser)
+		devices.Delete("/users/:id", hr, handler.DeleteDeviceUser)
+	}
+
+	// Rosters
+	rosters := attendance.Group("/rosters")
+	{
+		rosters.Get("/", handler.GetRoster)
+		rosters.Post("/generate", manager, handler.GenerateRoster)
+		rosters.Get("/patterns", handler.GetRosterPatterns)
+		rosters.Post("/patterns", manager, handler.CreateRosterPattern)
+		rosters.Get("/patterns/:id", handler.GetRosterPatternByID)
+		rosters.Put("/patterns/:id", manager, handler.UpdateRosterPattern)
+		rosters.Delete("/patterns/:id", manager, handler.DeleteRosterPattern)
+		rosters.Get("/assignments", handler.GetRosterAssignments)
+		rosters.Post("/assignments", manager, handler.AssignRoster)
+		rosters.Delete("/assignments/:id", manager, handler.DeleteRosterAssignment)
+		rosters.Get("/swaps", handler.GetShiftSwaps)
+		rosters.Post("/swaps", handler.RequestShiftSwap)
+		rosters.Get("/swaps/:id", handler.GetShiftSwapByID)
+		rosters.Post("/swaps/:id/respond", handler.RespondToShiftSwap)
+		rosters.Post("/swaps/:id/decide", manager, handler.DecideShiftSwap)
+		rosters.Post("/swaps/:id/cancel", handler.CancelShiftSwap)
+	}
+
+	// Geofences for mobile clock-in
+	geofences := attendance.Group("/geofences")
+	{
+		geofences.Get("/", handler.GetGeofences)
+		geofences.Post("/", handler.CreateGeofence)
+		geofences.Get("/:id", handler.GetGeofenceByID)
+		geofences.Put("/:id", handler.UpdateGeofence)
+		geofences.Delete("/:id", handler.DeleteGeofence)
+	}
+
+	// Location and photo evidence captured with punches
+	evidence := attendance.Group("/evidence")
+	{
+		evidence.Get("/", handler.GetAttendanceEvidence)
+		evidence.Get("/:id", handler.GetAttendanceEvidenceByID)
+		evidence.Get("/:id/photo", manager, handler.GetAttendanceEvidencePhoto)
+		evidence.Post("/:id/review", manager, handler.ReviewAttendanceEvidence)
+	}
+
+	// Late arrivals, missed punches and overtime awaiting approval
+	exceptions := attendance.Group("/exceptions")
+	{
+		exceptions.Get("/", handler.GetAttendanceExceptions)
+		exceptions.Post("/overtime", handler.ClaimOvertime)
+		exceptions.Post("/missed-punches", job, handler.ScanMissedPunches)
+		exceptions.Get("/:id", handler.GetAttendanceExceptionByID)
+		exceptions.Post("/:id/justify", handler.JustifyAttendanceException)
+		exceptions.Post("/:id/decide", manager, handler.DecideAttendanceException)
+	}
+}
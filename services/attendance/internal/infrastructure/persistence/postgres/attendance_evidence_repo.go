@@ -0,0 +1,237 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// attendanceEvidenceColumns leaves out the photo, which is only read on its
+// own; has_photo stands in for it
+const attendanceEvidenceColumns = `
+	id, attendance_id, employee_id, kind, captured_at, latitude, longitude,
+	accuracy_meters, branch_id, geofence_id, distance_meters, within_fence,
+	photo_content_type, photo IS NOT NULL, review_status, reviewed_by,
+	reviewed_at, review_comment, created_at, updated_at`
+
+type attendanceEvidenceRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewAttendanceEvidenceRepository(db *pgxpool.Pool, logger utils.Logger) domain.AttendanceEvidenceRepository {
+	return &attendanceEvidenceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *attendanceEvidenceRepository) Create(evidence *domain.AttendanceEvidence) error {
+	query := `
+		INSERT INTO attendance_evidence (
+			id, attendance_id, employee_id, kind, captured_at, latitude, longitude,
+			accuracy_meters, branch_id, geofence_id, distance_meters, within_fence,
+			photo, photo_content_type, review_status, review_comment, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		evidence.ID,
+		evidence.AttendanceID,
+		evidence.EmployeeID,
+		evidence.Kind,
+		evidence.CapturedAt,
+		evidence.Latitude,
+		evidence.Longitude,
+		evidence.AccuracyMeters,
+		evidence.BranchID,
+		evidence.GeofenceID,
+		evidence.DistanceMeters,
+		evidence.WithinFence,
+		evidence.Photo,
+		evidence.PhotoContentType,
+		evidence.ReviewStatus,
+		evidence.ReviewComment,
+		evidence.CreatedAt,
+		evidence.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create attendance evidence", "error", err, "attendance_id", evidence.AttendanceID)
+		return fmt.Errorf("failed to create attendance evidence: %w", err)
+	}
+
+	evidence.HasPhoto = evidence.Photo != nil
+	return nil
+}
+
+func (r *attendanceEvidenceRepository) GetByID(id uuid.UUID) (*domain.AttendanceEvidence, error) {
+	query := `SELECT ` + attendanceEvidenceColumns + ` FROM attendance_evidence WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	evidence, err := scanAttendanceEvidence(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("attendance evidence %v: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get attendance evidence", "error", err, "attendance_evidence_id", id)
+		return nil, fmt.Errorf("failed to get attendance evidence: %w", err)
+	}
+
+	return evidence, nil
+}
+
+func (r *attendanceEvidenceRepository) GetPhoto(id uuid.UUID) ([]byte, string, error) {
+	query := `SELECT photo, photo_content_type FROM attendance_evidence WHERE id = $1 AND photo IS NOT NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var photo []byte
+	var contentType string
+	if err := r.db.QueryRow(ctx, query, id).Scan(&photo, &contentType); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", fmt.Errorf("attendance evidence photo %v: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get attendance evidence photo", "error", err, "attendance_evidence_id", id)
+		return nil, "", fmt.Errorf("failed to get attendance evidence photo: %w", err)
+	}
+
+	return photo, contentType, nil
+}
+
+func (r *attendanceEvidenceRepository) GetAll(filter *domain.AttendanceEvidenceFilter) ([]*domain.AttendanceEvidence, error) {
+	query := `SELECT ` + attendanceEvidenceColumns + ` FROM attendance_evidence WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(" AND employee_id = $%d", argIndex)
+		args = append(args, *filter.EmployeeID)
+		argIndex++
+	}
+
+	if filter.AttendanceID != nil {
+		query += fmt.Sprintf(" AND attendance_id = $%d", argIndex)
+		args = append(args, *filter.AttendanceID)
+		argIndex++
+	}
+
+	if filter.ReviewStatus != "" {
+		query += fmt.Sprintf(" AND review_status = $%d", argIndex)
+		args = append(args, filter.ReviewStatus)
+		argIndex++
+	}
+
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(" AND captured_at::date >= $%d", argIndex)
+		args = append(args, *filter.StartDate)
+		argIndex++
+	}
+
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(" AND captured_at::date <= $%d", argIndex)
+		args = append(args, *filter.EndDate)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY captured_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query attendance evidence", "error", err)
+		return nil, fmt.Errorf("failed to query attendance evidence: %w", err)
+	}
+	defer rows.Close()
+
+	var evidence []*domain.AttendanceEvidence
+	for rows.Next() {
+		item, err := scanAttendanceEvidence(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan attendance evidence row", "error", err)
+			return nil, fmt.Errorf("failed to scan attendance evidence: %w", err)
+		}
+		evidence = append(evidence, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning attendance evidence rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return evidence, nil
+}
+
+func (r *attendanceEvidenceRepository) UpdateReview(evidence *domain.AttendanceEvidence) error {
+	query := `
+		UPDATE attendance_evidence SET
+			review_status = $2, reviewed_by = $3, reviewed_at = $4, review_comment = $5, updated_at = $6
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	evidence.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		evidence.ID,
+		evidence.ReviewStatus,
+		evidence.ReviewedBy,
+		evidence.ReviewedAt,
+		evidence.ReviewComment,
+		evidence.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update attendance evidence review", "error", err, "attendance_evidence_id", evidence.ID)
+		return fmt.Errorf("failed to update attendance evidence review: %w", err)
+	}
+
+	return nil
+}
+
+func scanAttendanceEvidence(row pgx.Row) (*domain.AttendanceEvidence, error) {
+	evidence := &domain.AttendanceEvidence{}
+	err := row.Scan(
+		&evidence.ID,
+		&evidence.AttendanceID,
+		&evidence.EmployeeID,
+		&evidence.Kind,
+		&evidence.CapturedAt,
+		&evidence.Latitude,
+		&evidence.Longitude,
+		&evidence.AccuracyMeters,
+		&evidence.BranchID,
+		&evidence.GeofenceID,
+		&evidence.DistanceMeters,
+		&evidence.WithinFence,
+		&evidence.PhotoContentType,
+		&evidence.HasPhoto,
+		&evidence.ReviewStatus,
+		&evidence.ReviewedBy,
+		&evidence.ReviewedAt,
+		&evidence.ReviewComment,
+		&evidence.CreatedAt,
+		&evidence.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return evidence, nil
+}
@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const attendanceExceptionColumns = `
+	id, attendance_id, employee_id, attendance_date, type, minutes, hours,
+	approved_hours, claimed, details, justification, status, justified_at,
+	decided_by, decided_at, decision_comment, overtime_id, posted_at,
+	created_at, updated_at`
+
+type attendanceExceptionRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewAttendanceExceptionRepository(db *pgxpool.Pool, logger utils.Logger) domain.AttendanceExceptionRepository {
+	return &attendanceExceptionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *attendanceExceptionRepository) Create(exception *domain.AttendanceException) error {
+	query := `
+		INSERT INTO attendance_exceptions (` + attendanceExceptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		exception.ID,
+		exception.AttendanceID,
+		exception.EmployeeID,
+		exception.AttendanceDate,
+		exception.Type,
+		exception.Minutes,
+		exception.Hours,
+		exception.ApprovedHours,
+		exception.Claimed,
+		exception.Details,
+		exception.Justification,
+		exception.Status,
+		exception.JustifiedAt,
+		exception.DecidedBy,
+		exception.DecidedAt,
+		exception.DecisionComment,
+		exception.OvertimeID,
+		exception.PostedAt,
+		exception.CreatedAt,
+		exception.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create attendance exception", "error", err, "attendance_id", exception.AttendanceID)
+		return fmt.Errorf("failed to create attendance exception: %w", err)
+	}
+
+	r.logger.Info("Attendance exception raised",
+		"attendance_exception_id", exception.ID,
+		"attendance_id", exception.AttendanceID,
+		"type", exception.Type)
+	return nil
+}
+
+func (r *attendanceExceptionRepository) GetByID(id uuid.UUID) (*domain.AttendanceException, error) {
+	query := `SELECT ` + attendanceExceptionColumns + ` FROM attendance_exceptions WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	exception, err := scanAttendanceException(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("attendance exception %v: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get attendance exception", "error", err, "attendance_exception_id", id)
+		return nil, fmt.Errorf("failed to get attendance exception: %w", err)
+	}
+
+	return exception, nil
+}
+
+func (r *attendanceExceptionRepository) GetByAttendance(attendanceID uuid.UUID) ([]*domain.AttendanceException, error) {
+	query := `SELECT ` + attendanceExceptionColumns + ` FROM attendance_exceptions
+		WHERE attendance_id = $1 AND deleted = false ORDER BY created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, attendanceID)
+	if err != nil {
+		r.logger.Error("Failed to query attendance exceptions", "error", err, "attendance_id", attendanceID)
+		return nil, fmt.Errorf("failed to query attendance exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	return r.collect(rows)
+}
+
+func (r *attendanceExceptionRepository) GetAll(filter *domain.AttendanceExceptionFilter) ([]*domain.AttendanceException, error) {
+	query := `SELECT ` + attendanceExceptionColumns + ` FROM attendance_exceptions WHERE deleted = false`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(" AND employee_id = $%d", argIndex)
+		args = append(args, *filter.EmployeeID)
+		argIndex++
+	}
+
+	if filter.Type != "" {
+		query += fmt.Sprintf(" AND type = $%d", argIndex)
+		args = append(args, filter.Type)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(" AND attendance_date >= $%d", argIndex)
+		args = append(args, *filter.StartDate)
+		argIndex++
+	}
+
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(" AND attendance_date <= $%d", argIndex)
+		args = append(args, *filter.EndDate)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY attendance_date DESC, created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query attendance exceptions", "error", err)
+		return nil, fmt.Errorf("failed to query attendance exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	return r.collect(rows)
+}
+
+func (r *attendanceExceptionRepository) Update(exception *domain.AttendanceException) error {
+	query := `
+		UPDATE attendance_exceptions SET
+			minutes = $2, hours = $3, approved_hours = $4, claimed = $5, details = $6,
+			justification = $7, status = $8, justified_at = $9, decided_by = $10,
+			decided_at = $11, decision_comment = $12, updated_at = $13
+		WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exception.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		exception.ID,
+		exception.Minutes,
+		exception.Hours,
+		exception.ApprovedHours,
+		exception.Claimed,
+		exception.Details,
+		exception.Justification,
+		exception.Status,
+		exception.JustifiedAt,
+		exception.DecidedBy,
+		exception.DecidedAt,
+		exception.DecisionComment,
+		exception.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update attendance exception", "error", err, "attendance_exception_id", exception.ID)
+		return fmt.Errorf("failed to update attendance exception: %w", err)
+	}
+
+	return nil
+}
+
+func (r *attendanceExceptionRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE attendance_exceptions SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete attendance exception", "error", err, "attendance_exception_id", id)
+		return fmt.Errorf("failed to delete attendance exception: %w", err)
+	}
+
+	return nil
+}
+
+func (r *attendanceExceptionRepository) collect(rows pgx.Rows) ([]*domain.AttendanceException, error) {
+	var exceptions []*domain.AttendanceException
+	for rows.Next() {
+		exception, err := scanAttendanceException(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan attendance exception row", "error", err)
+			return nil, fmt.Errorf("failed to scan attendance exception: %w", err)
+		}
+		exceptions = append(exceptions, exception)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning attendance exception rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return exceptions, nil
+}
+
+func scanAttendanceException(row pgx.Row) (*domain.AttendanceException, error) {
+	exception := &domain.AttendanceException{}
+	err := row.Scan(
+		&exception.ID,
+		&exception.AttendanceID,
+		&exception.EmployeeID,
+		&exception.AttendanceDate,
+		&exception.Type,
+		&exception.Minutes,
+		&exception.Hours,
+		&exception.ApprovedHours,
+		&exception.Claimed,
+		&exception.Details,
+		&exception.Justification,
+		&exception.Status,
+		&exception.JustifiedAt,
+		&exception.DecidedBy,
+		&exception.DecidedAt,
+		&exception.DecisionComment,
+		&exception.OvertimeID,
+		&exception.PostedAt,
+		&exception.CreatedAt,
+		&exception.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return exception, nil
+}
@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const attendanceColumns = `
+	id, employee_id, attendance_date, shift_id, check_in, check_out, work_hours,
+	overtime_hours, status, notes, approved_by, created_at, updated_at`
+
+type attendanceRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewAttendanceRepository(db *pgxpool.Pool, logger utils.Logger) domain.AttendanceRepository {
+	return &attendanceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *attendanceRepository) Create(attendance *domain.Attendance) error {
+	query := `
+		INSERT INTO attendances (` + attendanceColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		attendance.ID,
+		attendance.EmployeeID,
+		attendance.AttendanceDate,
+		attendance.ShiftID,
+		attendance.CheckIn,
+		attendance.CheckOut,
+		attendance.WorkHours,
+		attendance.OvertimeHours,
+		attendance.Status,
+		attendance.Notes,
+		attendance.ApprovedBy,
+		attendance.CreatedAt,
+		attendance.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create attendance", "error", err)
+		return fmt.Errorf("failed to create attendance: %w", err)
+	}
+
+	r.logger.Info("Attendance created successfully", "attendance_id", attendance.ID)
+	return nil
+}
+
+func (r *attendanceRepository) GetByID(id uuid.UUID) (*domain.Attendance, error) {
+	query := `SELECT ` + attendanceColumns + ` FROM attendances WHERE id = $1 AND deleted = false`
+
+	return r.get(query, id)
+}
+
+func (r *attendanceRepository) GetByEmployeeID(employeeID uuid.UUID, startDate, endDate time.Time) ([]*domain.Attendance, error) {
+	query := `
+		SELECT ` + attendanceColumns + `
+		FROM attendances
+		WHERE employee_id = $1 AND attendance_date BETWEEN $2 AND $3 AND deleted = false
+		ORDER BY attendance_date`
+
+	return r.query(query, employeeID, startDate, endDate)
+}
+
+func (r *attendanceRepository) GetAll(filter *domain.AttendanceFilter) ([]*domain.Attendance, error) {
+	query := `SELECT ` + attendanceColumns + ` FROM attendances WHERE deleted = false`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(" AND employee_id = $%d", argIndex)
+		args = append(args, *filter.EmployeeID)
+		argIndex++
+	}
+
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(" AND attendance_date >= $%d", argIndex)
+		args = append(args, *filter.StartDate)
+		argIndex++
+	}
+
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(" AND attendance_date <= $%d", argIndex)
+		args = append(args, *filter.EndDate)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY attendance_date DESC, check_in DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	return r.query(query, args...)
+}
+
+func (r *attendanceRepository) Update(attendance *domain.Attendance) error {
+	query := `
+		UPDATE attendances SET
+			attendance_date = $2, shift_id = $3, check_in = $4, check_out = $5,
+			work_hours = $6, overtime_hours = $7, status = $8, notes = $9,
+			approved_by = $10, updated_at = $11
+		WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	attendance.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		attendance.ID,
+		attendance.AttendanceDate,
+		attendance.ShiftID,
+		attendance.CheckIn,
+		attendance.CheckOut,
+		attendance.WorkHours,
+		attendance.OvertimeHours,
+		attendance.Status,
+		attendance.Notes,
+		attendance.ApprovedBy,
+		attendance.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update attendance", "error", err, "attendance_id", attendance.ID)
+		return fmt.Errorf("failed to update attendance: %w", err)
+	}
+
+	r.logger.Info("Attendance updated successfully", "attendance_id", attendance.ID)
+	return nil
+}
+
+func (r *attendanceRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE attendances SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete attendance", "error", err, "attendance_id", id)
+		return fmt.Errorf("failed to delete attendance: %w", err)
+	}
+
+	r.logger.Info("Attendance deleted successfully", "attendance_id", id)
+	return nil
+}
+
+func (r *attendanceRepository) GetByDate(date time.Time) ([]*domain.Attendance, error) {
+	query := `
+		SELECT ` + attendanceColumns + `
+		FROM attendances
+		WHERE attendance_date = $1 AND deleted = false
+		ORDER BY check_in`
+
+	return r.query(query, date)
+}
+
+func (r *attendanceRepository) GetByEmployeeAndDate(employeeID uuid.UUID, date time.Time) (*domain.Attendance, error) {
+	query := `
+		SELECT ` + attendanceColumns + `
+		FROM attendances
+		WHERE employee_id = $1 AND attendance_date = $2 AND deleted = false`
+
+	return r.get(query, employeeID, date)
+}
+
+// GetOpenByEmployee returns the employee's latest clock-in without a clock-out
+func (r *attendanceRepository) GetOpenByEmployee(employeeID uuid.UUID) (*domain.Attendance, error) {
+	query := `
+		SELECT ` + attendanceColumns + `
+		FROM attendances
+		WHERE employee_id = $1 AND check_in IS NOT NULL AND check_out IS NULL AND deleted = false
+		ORDER BY check_in DESC
+		LIMIT 1`
+
+	return r.get(query, employeeID)
+}
+
+func (r *attendanceRepository) get(query string, args ...interface{}) (*domain.Attendance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	attendance, err := scanAttendance(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("attendance %v: %w", args[0], domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get attendance", "error", err)
+		return nil, fmt.Errorf("failed to get attendance: %w", err)
+	}
+
+	return attendance, nil
+}
+
+func (r *attendanceRepository) query(query string, args ...interface{}) ([]*domain.Attendance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query attendance", "error", err)
+		return nil, fmt.Errorf("failed to query attendance: %w", err)
+	}
+	defer rows.Close()
+
+	var attendances []*domain.Attendance
+	for rows.Next() {
+		attendance, err := scanAttendance(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan attendance row", "error", err)
+			return nil, fmt.Errorf("failed to scan attendance: %w", err)
+		}
+		attendances = append(attendances, attendance)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning attendance rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return attendances, nil
+}
+
+func scanAttendance(row pgx.Row) (*domain.Attendance, error) {
+	attendance := &domain.Attendance{}
+	err := row.Scan(
+		&attendance.ID,
+		&attendance.EmployeeID,
+		&attendance.AttendanceDate,
+		&attendance.ShiftID,
+		&attendance.CheckIn,
+		&attendance.CheckOut,
+		&attendance.WorkHours,
+		&attendance.OvertimeHours,
+		&attendance.Status,
+		&attendance.Notes,
+		&attendance.ApprovedBy,
+		&attendance.CreatedAt,
+		&attendance.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return attendance, nil
+}
@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const deviceUserColumns = `id, device_id, device_user_id, employee_id, created_at, updated_at`
+
+type deviceUserRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewDeviceUserRepository(db *pgxpool.Pool, logger utils.Logger) domain.DeviceUserRepository {
+	return &deviceUserRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *deviceUserRepository) Create(deviceUser *domain.DeviceUser) error {
+	query := `
+		INSERT INTO device_users (` + deviceUserColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		deviceUser.ID,
+		deviceUser.DeviceID,
+		deviceUser.DeviceUserID,
+		deviceUser.EmployeeID,
+		deviceUser.CreatedAt,
+		deviceUser.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create device user", "error", err)
+		return fmt.Errorf("failed to create device user: %w", err)
+	}
+
+	r.logger.Info("Device user created successfully", "device_user_id", deviceUser.ID)
+	return nil
+}
+
+func (r *deviceUserRepository) GetByID(id uuid.UUID) (*domain.DeviceUser, error) {
+	query := `SELECT ` + deviceUserColumns + ` FROM device_users WHERE id = $1 AND deleted = false`
+
+	return r.get(query, id)
+}
+
+func (r *deviceUserRepository) GetAll() ([]*domain.DeviceUser, error) {
+	query := `SELECT ` + deviceUserColumns + ` FROM device_users WHERE deleted = false ORDER BY device_id, device_user_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("Failed to query device users", "error", err)
+		return nil, fmt.Errorf("failed to query device users: %w", err)
+	}
+	defer rows.Close()
+
+	var deviceUsers []*domain.DeviceUser
+	for rows.Next() {
+		deviceUser, err := scanDeviceUser(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan device user row", "error", err)
+			return nil, fmt.Errorf("failed to scan device user: %w", err)
+		}
+		deviceUsers = append(deviceUsers, deviceUser)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning device user rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return deviceUsers, nil
+}
+
+// Resolve prefers a mapping for the specific terminal over one that applies
+// to all terminals
+func (r *deviceUserRepository) Resolve(deviceID, deviceUserID string) (*domain.DeviceUser, error) {
+	query := `
+		SELECT ` + deviceUserColumns + `
+		FROM device_users
+		WHERE device_user_id = $1 AND device_id IN ($2, '') AND deleted = false
+		ORDER BY device_id DESC
+		LIMIT 1`
+
+	return r.get(query, deviceUserID, deviceID)
+}
+
+func (r *deviceUserRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE device_users SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete device user", "error", err, "device_user_id", id)
+		return fmt.Errorf("failed to delete device user: %w", err)
+	}
+
+	r.logger.Info("Device user deleted successfully", "device_user_id", id)
+	return nil
+}
+
+func (r *deviceUserRepository) get(query string, args ...interface{}) (*domain.DeviceUser, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deviceUser, err := scanDeviceUser(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("device user %v: %w", args[0], domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get device user", "error", err)
+		return nil, fmt.Errorf("failed to get device user: %w", err)
+	}
+
+	return deviceUser, nil
+}
+
+func scanDeviceUser(row pgx.Row) (*domain.DeviceUser, error) {
+	deviceUser := &domain.DeviceUser{}
+	err := row.Scan(
+		&deviceUser.ID,
+		&deviceUser.DeviceID,
+		&deviceUser.DeviceUserID,
+		&deviceUser.EmployeeID,
+		&deviceUser.CreatedAt,
+		&deviceUser.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return deviceUser, nil
+}
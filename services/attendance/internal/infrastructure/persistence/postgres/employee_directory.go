@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// selectDirectoryEmployee reads an employee with the branch of their current
+// grade in the HR monolith, matched on email as the monolith's employees are
+// and on name to the organization service's branches
+const selectDirectoryEmployee = `
+	SELECT e.id, e.manager_id, (
+		SELECT b.id
+		FROM tbl_employees te
+		JOIN tbl_employee_grades g ON g.employee_id = te.id AND g.is_current = 1 AND g.deleted = 0
+		JOIN tbl_branches tb ON tb.id = g.branch_id AND tb.deleted = 0
+		JOIN branches b ON LOWER(b.name) = LOWER(tb.name)
+		WHERE LOWER(te.email) = LOWER(e.email) AND te.deleted = 0
+		ORDER BY g.start_date DESC NULLS LAST
+		LIMIT 1
+	)
+	FROM employees e`
+
+// employeeDirectory reads the employees table owned by the employee service
+type employeeDirectory struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewEmployeeDirectory(db *pgxpool.Pool, logger utils.Logger) domain.EmployeeDirectory {
+	return &employeeDirectory{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *employeeDirectory) GetActiveByDepartment(departmentID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT id FROM employees WHERE department_id = $1 AND status = 'active' AND deleted = false ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, departmentID)
+	if err != nil {
+		r.logger.Error("Failed to query department employees", "error", err, "department_id", departmentID)
+		return nil, fmt.Errorf("failed to query department employees: %w", err)
+	}
+	defer rows.Close()
+
+	var employeeIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			r.logger.Error("Failed to scan department employee row", "error", err)
+			return nil, fmt.Errorf("failed to scan department employee: %w", err)
+		}
+		employeeIDs = append(employeeIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning department employee rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return employeeIDs, nil
+}
+
+func (r *employeeDirectory) GetByID(id uuid.UUID) (*domain.DirectoryEmployee, error) {
+	query := selectDirectoryEmployee + ` WHERE e.id = $1 AND e.deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	employee := &domain.DirectoryEmployee{}
+	err := r.db.QueryRow(ctx, query, id).Scan(&employee.ID, &employee.ManagerID, &employee.BranchID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("employee %v: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get employee", "error", err, "employee_id", id)
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+	return employee, nil
+}
+
+func (r *employeeDirectory) GetByEmail(email string) (*domain.DirectoryEmployee, error) {
+	query := selectDirectoryEmployee + ` WHERE LOWER(e.email) = LOWER($1) AND e.deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	employee := &domain.DirectoryEmployee{}
+	err := r.db.QueryRow(ctx, query, email).Scan(&employee.ID, &employee.ManagerID, &employee.BranchID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("employee with email %s: %w", email, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get employee by email", "error", err)
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+	return employee, nil
+}
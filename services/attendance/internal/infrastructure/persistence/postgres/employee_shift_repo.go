@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const employeeShiftColumns = `
+	id, employee_id, shift_id, effective_from, effective_to, created_at, updated_at`
+
+type employeeShiftRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewEmployeeShiftRepository(db *pgxpool.Pool, logger utils.Logger) domain.EmployeeShiftRepository {
+	return &employeeShiftRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *employeeShiftRepository) Create(assignment *domain.EmployeeShift) error {
+	query := `
+		INSERT INTO employee_shifts (` + employeeShiftColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		assignment.ID,
+		assignment.EmployeeID,
+		assignment.ShiftID,
+		assignment.EffectiveFrom,
+		assignment.EffectiveTo,
+		assignment.CreatedAt,
+		assignment.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create shift assignment", "error", err)
+		return fmt.Errorf("failed to create shift assignment: %w", err)
+	}
+
+	r.logger.Info("Shift assignment created successfully", "employee_shift_id", assignment.ID)
+	return nil
+}
+
+func (r *employeeShiftRepository) GetByID(id uuid.UUID) (*domain.EmployeeShift, error) {
+	query := `SELECT ` + employeeShiftColumns + ` FROM employee_shifts WHERE id = $1 AND deleted = false`
+
+	return r.get(query, id)
+}
+
+func (r *employeeShiftRepository) GetByEmployee(employeeID uuid.UUID) ([]*domain.EmployeeShift, error) {
+	query := `
+		SELECT ` + employeeShiftColumns + `
+		FROM employee_shifts
+		WHERE employee_id = $1 AND deleted = false
+		ORDER BY effective_from DESC`
+
+	return r.query(query, employeeID)
+}
+
+// GetForDate returns the assignment covering date; the latest one wins when
+// assignments overlap.
+func (r *employeeShiftRepository) GetForDate(employeeID uuid.UUID, date time.Time) (*domain.EmployeeShift, error) {
+	query := `
+		SELECT ` + employeeShiftColumns + `
+		FROM employee_shifts
+		WHERE employee_id = $1 AND effective_from <= $2
+			AND (effective_to IS NULL OR effective_to >= $2) AND deleted = false
+		ORDER BY effective_from DESC, created_at DESC
+		LIMIT 1`
+
+	return r.get(query, employeeID, date)
+}
+
+// GetActiveOn returns the assignment covering date for every employee
+func (r *employeeShiftRepository) GetActiveOn(date time.Time) ([]*domain.EmployeeShift, error) {
+	query := `
+		SELECT DISTINCT ON (employee_id) ` + employeeShiftColumns + `
+		FROM employee_shifts
+		WHERE effective_from <= $1 AND (effective_to IS NULL OR effective_to >= $1) AND deleted = false
+		ORDER BY employee_id, effective_from DESC, created_at DESC`
+
+	return r.query(query, date)
+}
+
+func (r *employeeShiftRepository) Update(assignment *domain.EmployeeShift) error {
+	query := `
+		UPDATE employee_shifts SET
+			shift_id = $2, effective_from = $3, effective_to = $4, updated_at = $5
+		WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	assignment.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		assignment.ID,
+		assignment.ShiftID,
+		assignment.EffectiveFrom,
+		assignment.EffectiveTo,
+		assignment.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update shift assignment", "error", err, "employee_shift_id", assignment.ID)
+		return fmt.Errorf("failed to update shift assignment: %w", err)
+	}
+
+	r.logger.Info("Shift assignment updated successfully", "employee_shift_id", assignment.ID)
+	return nil
+}
+
+func (r *employeeShiftRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE employee_shifts SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete shift assignment", "error", err, "employee_shift_id", id)
+		return fmt.Errorf("failed to delete shift assignment: %w", err)
+	}
+
+	r.logger.Info("Shift assignment deleted successfully", "employee_shift_id", id)
+	return nil
+}
+
+func (r *employeeShiftRepository) get(query string, args ...interface{}) (*domain.EmployeeShift, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assignment, err := scanEmployeeShift(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("shift assignment %v: %w", args[0], domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get shift assignment", "error", err)
+		return nil, fmt.Errorf("failed to get shift assignment: %w", err)
+	}
+
+	return assignment, nil
+}
+
+func (r *employeeShiftRepository) query(query string, args ...interface{}) ([]*domain.EmployeeShift, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query shift assignments", "error", err)
+		return nil, fmt.Errorf("failed to query shift assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*domain.EmployeeShift
+	for rows.Next() {
+		assignment, err := scanEmployeeShift(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan shift assignment row", "error", err)
+			return nil, fmt.Errorf("failed to scan shift assignment: %w", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning shift assignment rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return assignments, nil
+}
+
+func scanEmployeeShift(row pgx.Row) (*domain.EmployeeShift, error) {
+	assignment := &domain.EmployeeShift{}
+	err := row.Scan(
+		&assignment.ID,
+		&assignment.EmployeeID,
+		&assignment.ShiftID,
+		&assignment.EffectiveFrom,
+		&assignment.EffectiveTo,
+		&assignment.CreatedAt,
+		&assignment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
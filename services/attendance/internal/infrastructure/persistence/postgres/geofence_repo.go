@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const geofenceColumns = `
+	id, branch_id, name, latitude, longitude, radius_meters, polygon, active,
+	created_at, updated_at`
+
+type geofenceRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewGeofenceRepository(db *pgxpool.Pool, logger utils.Logger) domain.GeofenceRepository {
+	return &geofenceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *geofenceRepository) Create(geofence *domain.Geofence) error {
+	query := `
+		INSERT INTO geofences (` + geofenceColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		geofence.ID,
+		geofence.BranchID,
+		geofence.Name,
+		geofence.Latitude,
+		geofence.Longitude,
+		geofence.RadiusMeters,
+		geofence.Polygon,
+		geofence.Active,
+		geofence.CreatedAt,
+		geofence.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create geofence", "error", err)
+		return fmt.Errorf("failed to create geofence: %w", err)
+	}
+
+	r.logger.Info("Geofence created successfully", "geofence_id", geofence.ID, "branch_id", geofence.BranchID)
+	return nil
+}
+
+func (r *geofenceRepository) GetByID(id uuid.UUID) (*domain.Geofence, error) {
+	query := `SELECT ` + geofenceColumns + ` FROM geofences WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	geofence, err := scanGeofence(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("geofence %v: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get geofence", "error", err, "geofence_id", id)
+		return nil, fmt.Errorf("failed to get geofence: %w", err)
+	}
+
+	return geofence, nil
+}
+
+func (r *geofenceRepository) GetAll(branchID *uuid.UUID, activeOnly bool) ([]*domain.Geofence, error) {
+	query := `SELECT ` + geofenceColumns + ` FROM geofences WHERE deleted = false`
+	args := []interface{}{}
+
+	if branchID != nil {
+		query += ` AND branch_id = $1`
+		args = append(args, *branchID)
+	}
+	if activeOnly {
+		query += ` AND active = true`
+	}
+	query += ` ORDER BY branch_id, name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query geofences", "error", err)
+		return nil, fmt.Errorf("failed to query geofences: %w", err)
+	}
+	defer rows.Close()
+
+	var geofences []*domain.Geofence
+	for rows.Next() {
+		geofence, err := scanGeofence(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan geofence row", "error", err)
+			return nil, fmt.Errorf("failed to scan geofence: %w", err)
+		}
+		geofences = append(geofences, geofence)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning geofence rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return geofences, nil
+}
+
+func (r *geofenceRepository) Update(geofence *domain.Geofence) error {
+	query := `
+		UPDATE geofences SET
+			branch_id = $2, name = $3, latitude = $4, longitude = $5,
+			radius_meters = $6, polygon = $7, active = $8, updated_at = $9
+		WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	geofence.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		geofence.ID,
+		geofence.BranchID,
+		geofence.Name,
+		geofence.Latitude,
+		geofence.Longitude,
+		geofence.RadiusMeters,
+		geofence.Polygon,
+		geofence.Active,
+		geofence.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update geofence", "error", err, "geofence_id", geofence.ID)
+		return fmt.Errorf("failed to update geofence: %w", err)
+	}
+
+	r.logger.Info("Geofence updated successfully", "geofence_id", geofence.ID)
+	return nil
+}
+
+func (r *geofenceRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE geofences SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete geofence", "error", err, "geofence_id", id)
+		return fmt.Errorf("failed to delete geofence: %w", err)
+	}
+
+	r.logger.Info("Geofence deleted successfully", "geofence_id", id)
+	return nil
+}
+
+func scanGeofence(row pgx.Row) (*domain.Geofence, error) {
+	geofence := &domain.Geofence{}
+	err := row.Scan(
+		&geofence.ID,
+		&geofence.BranchID,
+		&geofence.Name,
+		&geofence.Latitude,
+		&geofence.Longitude,
+		&geofence.RadiusMeters,
+		&geofence.Polygon,
+		&geofence.Active,
+		&geofence.CreatedAt,
+		&geofence.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return geofence, nil
+}
@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const punchExceptionColumns = `
+	id, import_id, punch_id, employee_id, device_id, device_user_id, punch_time,
+	attendance_date, line, raw, reason, details, resolved, created_at, updated_at`
+
+type punchExceptionRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewPunchExceptionRepository(db *pgxpool.Pool, logger utils.Logger) domain.PunchExceptionRepository {
+	return &punchExceptionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *punchExceptionRepository) Create(exception *domain.PunchException) error {
+	query := `
+		INSERT INTO punch_exceptions (` + punchExceptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		exception.ID,
+		exception.ImportID,
+		exception.PunchID,
+		exception.EmployeeID,
+		exception.DeviceID,
+		exception.DeviceUserID,
+		exception.PunchTime,
+		exception.AttendanceDate,
+		exception.Line,
+		exception.Raw,
+		exception.Reason,
+		exception.Details,
+		exception.Resolved,
+		exception.CreatedAt,
+		exception.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create punch exception", "error", err)
+		return fmt.Errorf("failed to create punch exception: %w", err)
+	}
+
+	return nil
+}
+
+func (r *punchExceptionRepository) GetByID(id uuid.UUID) (*domain.PunchException, error) {
+	query := `SELECT ` + punchExceptionColumns + ` FROM punch_exceptions WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	exception, err := scanPunchException(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("punch exception %v: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get punch exception", "error", err, "punch_exception_id", id)
+		return nil, fmt.Errorf("failed to get punch exception: %w", err)
+	}
+
+	return exception, nil
+}
+
+func (r *punchExceptionRepository) GetAll(filter *domain.PunchExceptionFilter) ([]*domain.PunchException, error) {
+	query := `SELECT ` + punchExceptionColumns + ` FROM punch_exceptions WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.ImportID != nil {
+		query += fmt.Sprintf(" AND import_id = $%d", argIndex)
+		args = append(args, *filter.ImportID)
+		argIndex++
+	}
+
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(" AND employee_id = $%d", argIndex)
+		args = append(args, *filter.EmployeeID)
+		argIndex++
+	}
+
+	if filter.Reason != "" {
+		query += fmt.Sprintf(" AND reason = $%d", argIndex)
+		args = append(args, filter.Reason)
+		argIndex++
+	}
+
+	if filter.Resolved != nil {
+		query += fmt.Sprintf(" AND resolved = $%d", argIndex)
+		args = append(args, *filter.Resolved)
+		argIndex++
+	}
+
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(" AND COALESCE(attendance_date, punch_time::date, created_at::date) >= $%d", argIndex)
+		args = append(args, *filter.StartDate)
+		argIndex++
+	}
+
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(" AND COALESCE(attendance_date, punch_time::date, created_at::date) <= $%d", argIndex)
+		args = append(args, *filter.EndDate)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY created_at DESC, line LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query punch exceptions", "error", err)
+		return nil, fmt.Errorf("failed to query punch exceptions: %w", err)
+	}
+	defer rows.Close()
+
+	var exceptions []*domain.PunchException
+	for rows.Next() {
+		exception, err := scanPunchException(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan punch exception row", "error", err)
+			return nil, fmt.Errorf("failed to scan punch exception: %w", err)
+		}
+		exceptions = append(exceptions, exception)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning punch exception rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return exceptions, nil
+}
+
+func (r *punchExceptionRepository) Update(exception *domain.PunchException) error {
+	query := `UPDATE punch_exceptions SET resolved = $2, details = $3, updated_at = $4 WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exception.UpdatedAt = time.Now()
+	if _, err := r.db.Exec(ctx, query, exception.ID, exception.Resolved, exception.Details, exception.UpdatedAt); err != nil {
+		r.logger.Error("Failed to update punch exception", "error", err, "punch_exception_id", exception.ID)
+		return fmt.Errorf("failed to update punch exception: %w", err)
+	}
+
+	return nil
+}
+
+func scanPunchException(row pgx.Row) (*domain.PunchException, error) {
+	exception := &domain.PunchException{}
+	err := row.Scan(
+		&exception.ID,
+		&exception.ImportID,
+		&exception.PunchID,
+		&exception.EmployeeID,
+		&exception.DeviceID,
+		&exception.DeviceUserID,
+		&exception.PunchTime,
+		&exception.AttendanceDate,
+		&exception.Line,
+		&exception.Raw,
+		&exception.Reason,
+		&exception.Details,
+		&exception.Resolved,
+		&exception.CreatedAt,
+		&exception.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return exception, nil
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const punchImportColumns = `
+	id, source, file_name, device_id, total_lines, imported, duplicates,
+	exceptions, sessions, imported_by, created_at, updated_at`
+
+type punchImportRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewPunchImportRepository(db *pgxpool.Pool, logger utils.Logger) domain.PunchImportRepository {
+	return &punchImportRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *punchImportRepository) Create(punchImport *domain.PunchImport) error {
+	query := `
+		INSERT INTO punch_imports (` + punchImportColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		punchImport.ID,
+		punchImport.Source,
+		punchImport.FileName,
+		punchImport.DeviceID,
+		punchImport.TotalLines,
+		punchImport.Imported,
+		punchImport.Duplicates,
+		punchImport.Exceptions,
+		punchImport.Sessions,
+		punchImport.ImportedBy,
+		punchImport.CreatedAt,
+		punchImport.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create punch import", "error", err)
+		return fmt.Errorf("failed to create punch import: %w", err)
+	}
+
+	r.logger.Info("Punch import created successfully", "punch_import_id", punchImport.ID)
+	return nil
+}
+
+func (r *punchImportRepository) GetByID(id uuid.UUID) (*domain.PunchImport, error) {
+	query := `SELECT ` + punchImportColumns + ` FROM punch_imports WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	punchImport, err := scanPunchImport(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("punch import %v: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get punch import", "error", err, "punch_import_id", id)
+		return nil, fmt.Errorf("failed to get punch import: %w", err)
+	}
+
+	return punchImport, nil
+}
+
+func (r *punchImportRepository) GetAll(limit, offset int) ([]*domain.PunchImport, error) {
+	query := `SELECT ` + punchImportColumns + ` FROM punch_imports ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, limit, offset)
+	if err != nil {
+		r.logger.Error("Failed to query punch imports", "error", err)
+		return nil, fmt.Errorf("failed to query punch imports: %w", err)
+	}
+	defer rows.Close()
+
+	var punchImports []*domain.PunchImport
+	for rows.Next() {
+		punchImport, err := scanPunchImport(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan punch import row", "error", err)
+			return nil, fmt.Errorf("failed to scan punch import: %w", err)
+		}
+		punchImports = append(punchImports, punchImport)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning punch import rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return punchImports, nil
+}
+
+func (r *punchImportRepository) Update(punchImport *domain.PunchImport) error {
+	query := `
+		UPDATE punch_imports SET
+			total_lines = $2, imported = $3, duplicates = $4, exceptions = $5,
+			sessions = $6, updated_at = $7
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	punchImport.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		punchImport.ID,
+		punchImport.TotalLines,
+		punchImport.Imported,
+		punchImport.Duplicates,
+		punchImport.Exceptions,
+		punchImport.Sessions,
+		punchImport.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update punch import", "error", err, "punch_import_id", punchImport.ID)
+		return fmt.Errorf("failed to update punch import: %w", err)
+	}
+
+	return nil
+}
+
+func scanPunchImport(row pgx.Row) (*domain.PunchImport, error) {
+	punchImport := &domain.PunchImport{}
+	err := row.Scan(
+		&punchImport.ID,
+		&punchImport.Source,
+		&punchImport.FileName,
+		&punchImport.DeviceID,
+		&punchImport.TotalLines,
+		&punchImport.Imported,
+		&punchImport.Duplicates,
+		&punchImport.Exceptions,
+		&punchImport.Sessions,
+		&punchImport.ImportedBy,
+		&punchImport.CreatedAt,
+		&punchImport.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return punchImport, nil
+}
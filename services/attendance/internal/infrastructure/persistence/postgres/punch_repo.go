@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const punchColumns = `
+	id, import_id, device_id, device_user_id, employee_id, punch_time, direction,
+	source, attendance_id, status, created_at`
+
+type punchRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewPunchRepository(db *pgxpool.Pool, logger utils.Logger) domain.PunchRepository {
+	return &punchRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *punchRepository) CreateIfNew(punch *domain.Punch) (bool, error) {
+	query := `
+		INSERT INTO punches (` + punchColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (device_id, device_user_id, punch_time) DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, query,
+		punch.ID,
+		punch.ImportID,
+		punch.DeviceID,
+		punch.DeviceUserID,
+		punch.EmployeeID,
+		punch.PunchTime,
+		punch.Direction,
+		punch.Source,
+		punch.AttendanceID,
+		punch.Status,
+		punch.CreatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create punch", "error", err)
+		return false, fmt.Errorf("failed to create punch: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *punchRepository) GetByEmployeeBetween(employeeID uuid.UUID, from, to time.Time) ([]*domain.Punch, error) {
+	query := `
+		SELECT ` + punchColumns + `
+		FROM punches
+		WHERE employee_id = $1 AND punch_time >= $2 AND punch_time < $3
+		ORDER BY punch_time`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, employeeID, from, to)
+	if err != nil {
+		r.logger.Error("Failed to query punches", "error", err)
+		return nil, fmt.Errorf("failed to query punches: %w", err)
+	}
+	defer rows.Close()
+
+	var punches []*domain.Punch
+	for rows.Next() {
+		punch, err := scanPunch(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan punch row", "error", err)
+			return nil, fmt.Errorf("failed to scan punch: %w", err)
+		}
+		punches = append(punches, punch)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning punch rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return punches, nil
+}
+
+func (r *punchRepository) SetResult(ids []uuid.UUID, status string, attendanceID *uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `UPDATE punches SET status = $1, attendance_id = $2 WHERE id = ANY($3)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, status, attendanceID, ids); err != nil {
+		r.logger.Error("Failed to update punches", "error", err)
+		return fmt.Errorf("failed to update punches: %w", err)
+	}
+	return nil
+}
+
+func scanPunch(row pgx.Row) (*domain.Punch, error) {
+	punch := &domain.Punch{}
+	err := row.Scan(
+		&punch.ID,
+		&punch.ImportID,
+		&punch.DeviceID,
+		&punch.DeviceUserID,
+		&punch.EmployeeID,
+		&punch.PunchTime,
+		&punch.Direction,
+		&punch.Source,
+		&punch.AttendanceID,
+		&punch.Status,
+		&punch.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return punch, nil
+}
@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const rosterAssignmentColumns = `
+	id, pattern_id, employee_id, department_id, effective_from, effective_to,
+	anchor_date, created_at, updated_at`
+
+type rosterAssignmentRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewRosterAssignmentRepository(db *pgxpool.Pool, logger utils.Logger) domain.RosterAssignmentRepository {
+	return &rosterAssignmentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *rosterAssignmentRepository) Create(assignment *domain.RosterAssignment) error {
+	query := `
+		INSERT INTO roster_assignments (` + rosterAssignmentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		assignment.ID,
+		assignment.PatternID,
+		assignment.EmployeeID,
+		assignment.DepartmentID,
+		assignment.EffectiveFrom,
+		assignment.EffectiveTo,
+		assignment.AnchorDate,
+		assignment.CreatedAt,
+		assignment.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create roster assignment", "error", err)
+		return fmt.Errorf("failed to create roster assignment: %w", err)
+	}
+
+	r.logger.Info("Roster assignment created successfully", "roster_assignment_id", assignment.ID)
+	return nil
+}
+
+func (r *rosterAssignmentRepository) GetByID(id uuid.UUID) (*domain.RosterAssignment, error) {
+	query := `SELECT ` + rosterAssignmentColumns + ` FROM roster_assignments WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assignment, err := scanRosterAssignment(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("roster assignment %v: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get roster assignment", "error", err, "roster_assignment_id", id)
+		return nil, fmt.Errorf("failed to get roster assignment: %w", err)
+	}
+
+	return assignment, nil
+}
+
+func (r *rosterAssignmentRepository) GetAll() ([]*domain.RosterAssignment, error) {
+	query := `
+		SELECT ` + rosterAssignmentColumns + `
+		FROM roster_assignments
+		WHERE deleted = false
+		ORDER BY effective_from DESC`
+
+	return r.query(query)
+}
+
+func (r *rosterAssignmentRepository) GetActiveBetween(from, to time.Time) ([]*domain.RosterAssignment, error) {
+	query := `
+		SELECT ` + rosterAssignmentColumns + `
+		FROM roster_assignments
+		WHERE effective_from <= $2 AND (effective_to IS NULL OR effective_to >= $1) AND deleted = false
+		ORDER BY effective_from, created_at`
+
+	return r.query(query, from, to)
+}
+
+func (r *rosterAssignmentRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE roster_assignments SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete roster assignment", "error", err, "roster_assignment_id", id)
+		return fmt.Errorf("failed to delete roster assignment: %w", err)
+	}
+
+	r.logger.Info("Roster assignment deleted successfully", "roster_assignment_id", id)
+	return nil
+}
+
+func (r *rosterAssignmentRepository) query(query string, args ...interface{}) ([]*domain.RosterAssignment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query roster assignments", "error", err)
+		return nil, fmt.Errorf("failed to query roster assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*domain.RosterAssignment
+	for rows.Next() {
+		assignment, err := scanRosterAssignment(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan roster assignment row", "error", err)
+			return nil, fmt.Errorf("failed to scan roster assignment: %w", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning roster assignment rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return assignments, nil
+}
+
+func scanRosterAssignment(row pgx.Row) (*domain.RosterAssignment, error) {
+	assignment := &domain.RosterAssignment{}
+	err := row.Scan(
+		&assignment.ID,
+		&assignment.PatternID,
+		&assignment.EmployeeID,
+		&assignment.DepartmentID,
+		&assignment.EffectiveFrom,
+		&assignment.EffectiveTo,
+		&assignment.AnchorDate,
+		&assignment.CreatedAt,
+		&assignment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
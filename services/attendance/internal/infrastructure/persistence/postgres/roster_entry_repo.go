@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const rosterEntryColumns = `
+	id, employee_id, roster_date, shift_id, assignment_id, swap_request_id,
+	source, created_at, updated_at`
+
+type rosterEntryRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewRosterEntryRepository(db *pgxpool.Pool, logger utils.Logger) domain.RosterEntryRepository {
+	return &rosterEntryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *rosterEntryRepository) Upsert(entry *domain.RosterEntry) error {
+	query := `
+		INSERT INTO roster_entries (` + rosterEntryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (employee_id, roster_date) DO UPDATE SET
+			shift_id = EXCLUDED.shift_id,
+			assignment_id = EXCLUDED.assignment_id,
+			swap_request_id = EXCLUDED.swap_request_id,
+			source = EXCLUDED.source,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		entry.ID,
+		entry.EmployeeID,
+		entry.RosterDate,
+		entry.ShiftID,
+		entry.AssignmentID,
+		entry.SwapRequestID,
+		entry.Source,
+		entry.CreatedAt,
+		entry.UpdatedAt,
+	).Scan(&entry.ID, &entry.CreatedAt)
+
+	if err != nil {
+		r.logger.Error("Failed to save roster entry", "error", err)
+		return fmt.Errorf("failed to save roster entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *rosterEntryRepository) GetForDate(employeeID uuid.UUID, date time.Time) (*domain.RosterEntry, error) {
+	query := `SELECT ` + rosterEntryColumns + ` FROM roster_entries WHERE employee_id = $1 AND roster_date = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	entry, err := scanRosterEntry(r.db.QueryRow(ctx, query, employeeID, date))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("roster entry for %v on %s: %w", employeeID, date.Format("2006-01-02"), domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get roster entry", "error", err)
+		return nil, fmt.Errorf("failed to get roster entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (r *rosterEntryRepository) GetByDate(date time.Time) ([]*domain.RosterEntry, error) {
+	query := `SELECT ` + rosterEntryColumns + ` FROM roster_entries WHERE roster_date = $1`
+
+	return r.query(query, date)
+}
+
+func (r *rosterEntryRepository) GetAll(filter *domain.RosterEntryFilter) ([]*domain.RosterEntry, error) {
+	query := `SELECT ` + rosterEntryColumns + ` FROM roster_entries WHERE roster_date BETWEEN $1 AND $2`
+	args := []interface{}{filter.StartDate, filter.EndDate}
+
+	if filter.EmployeeID != nil {
+		query += ` AND employee_id = $3`
+		args = append(args, *filter.EmployeeID)
+	}
+	query += ` ORDER BY employee_id, roster_date`
+
+	return r.query(query, args...)
+}
+
+func (r *rosterEntryRepository) UpdateShifts(entries []*domain.RosterEntry) error {
+	query := `
+		UPDATE roster_entries SET
+			shift_id = $2, swap_request_id = $3, source = $4, updated_at = $5
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin roster update", "error", err)
+		return fmt.Errorf("failed to begin roster update: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, entry := range entries {
+		if _, err := tx.Exec(ctx, query,
+			entry.ID,
+			entry.ShiftID,
+			entry.SwapRequestID,
+			entry.Source,
+			entry.UpdatedAt,
+		); err != nil {
+			r.logger.Error("Failed to update roster entry", "error", err, "roster_entry_id", entry.ID)
+			return fmt.Errorf("failed to update roster entry: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit roster update", "error", err)
+		return fmt.Errorf("failed to commit roster update: %w", err)
+	}
+
+	return nil
+}
+
+func (r *rosterEntryRepository) query(query string, args ...interface{}) ([]*domain.RosterEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query roster entries", "error", err)
+		return nil, fmt.Errorf("failed to query roster entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.RosterEntry
+	for rows.Next() {
+		entry, err := scanRosterEntry(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan roster entry row", "error", err)
+			return nil, fmt.Errorf("failed to scan roster entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning roster entry rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+func scanRosterEntry(row pgx.Row) (*domain.RosterEntry, error) {
+	entry := &domain.RosterEntry{}
+	err := row.Scan(
+		&entry.ID,
+		&entry.EmployeeID,
+		&entry.RosterDate,
+		&entry.ShiftID,
+		&entry.AssignmentID,
+		&entry.SwapRequestID,
+		&entry.Source,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
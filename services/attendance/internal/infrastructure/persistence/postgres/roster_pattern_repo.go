@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const rosterPatternColumns = `id, name, code, description, days, created_at, updated_at`
+
+type rosterPatternRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewRosterPatternRepository(db *pgxpool.Pool, logger utils.Logger) domain.RosterPatternRepository {
+	return &rosterPatternRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *rosterPatternRepository) Create(pattern *domain.RosterPattern) error {
+	query := `
+		INSERT INTO roster_patterns (` + rosterPatternColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		pattern.ID,
+		pattern.Name,
+		pattern.Code,
+		pattern.Description,
+		pattern.Days,
+		pattern.CreatedAt,
+		pattern.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create roster pattern", "error", err)
+		return fmt.Errorf("failed to create roster pattern: %w", err)
+	}
+
+	r.logger.Info("Roster pattern created successfully", "roster_pattern_id", pattern.ID)
+	return nil
+}
+
+func (r *rosterPatternRepository) GetByID(id uuid.UUID) (*domain.RosterPattern, error) {
+	query := `SELECT ` + rosterPatternColumns + ` FROM roster_patterns WHERE id = $1 AND deleted = false`
+
+	return r.get(query, id)
+}
+
+func (r *rosterPatternRepository) GetByCode(code string) (*domain.RosterPattern, error) {
+	query := `SELECT ` + rosterPatternColumns + ` FROM roster_patterns WHERE code = $1 AND deleted = false`
+
+	return r.get(query, code)
+}
+
+func (r *rosterPatternRepository) GetAll() ([]*domain.RosterPattern, error) {
+	query := `SELECT ` + rosterPatternColumns + ` FROM roster_patterns WHERE deleted = false ORDER BY name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("Failed to query roster patterns", "error", err)
+		return nil, fmt.Errorf("failed to query roster patterns: %w", err)
+	}
+	defer rows.Close()
+
+	var patterns []*domain.RosterPattern
+	for rows.Next() {
+		pattern, err := scanRosterPattern(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan roster pattern row", "error", err)
+			return nil, fmt.Errorf("failed to scan roster pattern: %w", err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning roster pattern rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return patterns, nil
+}
+
+func (r *rosterPatternRepository) Update(pattern *domain.RosterPattern) error {
+	query := `
+		UPDATE roster_patterns SET
+			name = $2, code = $3, description = $4, days = $5, updated_at = $6
+		WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pattern.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		pattern.ID,
+		pattern.Name,
+		pattern.Code,
+		pattern.Description,
+		pattern.Days,
+		pattern.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update roster pattern", "error", err, "roster_pattern_id", pattern.ID)
+		return fmt.Errorf("failed to update roster pattern: %w", err)
+	}
+
+	r.logger.Info("Roster pattern updated successfully", "roster_pattern_id", pattern.ID)
+	return nil
+}
+
+func (r *rosterPatternRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE roster_patterns SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete roster pattern", "error", err, "roster_pattern_id", id)
+		return fmt.Errorf("failed to delete roster pattern: %w", err)
+	}
+
+	r.logger.Info("Roster pattern deleted successfully", "roster_pattern_id", id)
+	return nil
+}
+
+func (r *rosterPatternRepository) get(query string, args ...interface{}) (*domain.RosterPattern, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pattern, err := scanRosterPattern(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("roster pattern %v: %w", args[0], domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get roster pattern", "error", err)
+		return nil, fmt.Errorf("failed to get roster pattern: %w", err)
+	}
+
+	return pattern, nil
+}
+
+func scanRosterPattern(row pgx.Row) (*domain.RosterPattern, error) {
+	pattern := &domain.RosterPattern{}
+	err := row.Scan(
+		&pattern.ID,
+		&pattern.Name,
+		&pattern.Code,
+		&pattern.Description,
+		&pattern.Days,
+		&pattern.CreatedAt,
+		&pattern.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pattern, nil
+}
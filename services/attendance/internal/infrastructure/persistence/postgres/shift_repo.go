@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const shiftColumns = `
+	id, name, code, start_time, end_time, break_time, work_hours, description,
+	is_night_shift, grace_minutes, half_day_hours, created_at, updated_at`
+
+type shiftRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewShiftRepository(db *pgxpool.Pool, logger utils.Logger) domain.ShiftRepository {
+	return &shiftRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *shiftRepository) Create(shift *domain.Shift) error {
+	query := `
+		INSERT INTO shifts (` + shiftColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		shift.ID,
+		shift.Name,
+		shift.Code,
+		shift.StartTime,
+		shift.EndTime,
+		shift.BreakTime,
+		shift.WorkHours,
+		shift.Description,
+		shift.IsNightShift,
+		shift.GraceMinutes,
+		shift.HalfDayHours,
+		shift.CreatedAt,
+		shift.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create shift", "error", err)
+		return fmt.Errorf("failed to create shift: %w", err)
+	}
+
+	r.logger.Info("Shift created successfully", "shift_id", shift.ID)
+	return nil
+}
+
+func (r *shiftRepository) GetByID(id uuid.UUID) (*domain.Shift, error) {
+	query := `SELECT ` + shiftColumns + ` FROM shifts WHERE id = $1 AND deleted = false`
+
+	return r.get(query, id)
+}
+
+func (r *shiftRepository) GetByCode(code string) (*domain.Shift, error) {
+	query := `SELECT ` + shiftColumns + ` FROM shifts WHERE code = $1 AND deleted = false`
+
+	return r.get(query, code)
+}
+
+func (r *shiftRepository) GetAll() ([]*domain.Shift, error) {
+	query := `SELECT ` + shiftColumns + ` FROM shifts WHERE deleted = false ORDER BY start_time, name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("Failed to query shifts", "error", err)
+		return nil, fmt.Errorf("failed to query shifts: %w", err)
+	}
+	defer rows.Close()
+
+	var shifts []*domain.Shift
+	for rows.Next() {
+		shift, err := scanShift(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan shift row", "error", err)
+			return nil, fmt.Errorf("failed to scan shift: %w", err)
+		}
+		shifts = append(shifts, shift)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning shift rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return shifts, nil
+}
+
+func (r *shiftRepository) Update(shift *domain.Shift) error {
+	query := `
+		UPDATE shifts SET
+			name = $2, code = $3, start_time = $4, end_time = $5, break_time = $6,
+			work_hours = $7, description = $8, is_night_shift = $9, grace_minutes = $10,
+			half_day_hours = $11, updated_at = $12
+		WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	shift.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		shift.ID,
+		shift.Name,
+		shift.Code,
+		shift.StartTime,
+		shift.EndTime,
+		shift.BreakTime,
+		shift.WorkHours,
+		shift.Description,
+		shift.IsNightShift,
+		shift.GraceMinutes,
+		shift.HalfDayHours,
+		shift.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update shift", "error", err, "shift_id", shift.ID)
+		return fmt.Errorf("failed to update shift: %w", err)
+	}
+
+	r.logger.Info("Shift updated successfully", "shift_id", shift.ID)
+	return nil
+}
+
+func (r *shiftRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE shifts SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete shift", "error", err, "shift_id", id)
+		return fmt.Errorf("failed to delete shift: %w", err)
+	}
+
+	r.logger.Info("Shift deleted successfully", "shift_id", id)
+	return nil
+}
+
+func (r *shiftRepository) get(query string, arg interface{}) (*domain.Shift, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	shift, err := scanShift(r.db.QueryRow(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("shift %v: %w", arg, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get shift", "error", err, "key", arg)
+		return nil, fmt.Errorf("failed to get shift: %w", err)
+	}
+
+	return shift, nil
+}
+
+func scanShift(row pgx.Row) (*domain.Shift, error) {
+	shift := &domain.Shift{}
+	err := row.Scan(
+		&shift.ID,
+		&shift.Name,
+		&shift.Code,
+		&shift.StartTime,
+		&shift.EndTime,
+		&shift.BreakTime,
+		&shift.WorkHours,
+		&shift.Description,
+		&shift.IsNightShift,
+		&shift.GraceMinutes,
+		&shift.HalfDayHours,
+		&shift.CreatedAt,
+		&shift.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
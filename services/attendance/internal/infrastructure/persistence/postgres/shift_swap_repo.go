@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const shiftSwapColumns = `
+	id, requester_id, requester_date, counterpart_id, counterpart_date, reason,
+	status, responded_at, decided_by, decided_at, comment, created_at, updated_at`
+
+type shiftSwapRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewShiftSwapRepository(db *pgxpool.Pool, logger utils.Logger) domain.ShiftSwapRepository {
+	return &shiftSwapRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *shiftSwapRepository) Create(swap *domain.ShiftSwapRequest) error {
+	query := `
+		INSERT INTO shift_swap_requests (` + shiftSwapColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		swap.ID,
+		swap.RequesterID,
+		swap.RequesterDate,
+		swap.CounterpartID,
+		swap.CounterpartDate,
+		swap.Reason,
+		swap.Status,
+		swap.RespondedAt,
+		swap.DecidedBy,
+		swap.DecidedAt,
+		swap.Comment,
+		swap.CreatedAt,
+		swap.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create shift swap request", "error", err)
+		return fmt.Errorf("failed to create shift swap request: %w", err)
+	}
+
+	r.logger.Info("Shift swap request created successfully", "shift_swap_request_id", swap.ID)
+	return nil
+}
+
+func (r *shiftSwapRepository) GetByID(id uuid.UUID) (*domain.ShiftSwapRequest, error) {
+	query := `SELECT ` + shiftSwapColumns + ` FROM shift_swap_requests WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	swap, err := scanShiftSwap(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("shift swap request %v: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get shift swap request", "error", err, "shift_swap_request_id", id)
+		return nil, fmt.Errorf("failed to get shift swap request: %w", err)
+	}
+
+	return swap, nil
+}
+
+func (r *shiftSwapRepository) GetAll(filter *domain.ShiftSwapFilter) ([]*domain.ShiftSwapRequest, error) {
+	query := `SELECT ` + shiftSwapColumns + ` FROM shift_swap_requests WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(" AND (requester_id = $%d OR counterpart_id = $%d)", argIndex, argIndex)
+		args = append(args, *filter.EmployeeID)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query shift swap requests", "error", err)
+		return nil, fmt.Errorf("failed to query shift swap requests: %w", err)
+	}
+	defer rows.Close()
+
+	var swaps []*domain.ShiftSwapRequest
+	for rows.Next() {
+		swap, err := scanShiftSwap(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan shift swap request row", "error", err)
+			return nil, fmt.Errorf("failed to scan shift swap request: %w", err)
+		}
+		swaps = append(swaps, swap)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning shift swap request rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return swaps, nil
+}
+
+func (r *shiftSwapRepository) Update(swap *domain.ShiftSwapRequest) error {
+	query := `
+		UPDATE shift_swap_requests SET
+			status = $2, responded_at = $3, decided_by = $4, decided_at = $5,
+			comment = $6, updated_at = $7
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	swap.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		swap.ID,
+		swap.Status,
+		swap.RespondedAt,
+		swap.DecidedBy,
+		swap.DecidedAt,
+		swap.Comment,
+		swap.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update shift swap request", "error", err, "shift_swap_request_id", swap.ID)
+		return fmt.Errorf("failed to update shift swap request: %w", err)
+	}
+
+	r.logger.Info("Shift swap request updated successfully", "shift_swap_request_id", swap.ID, "status", swap.Status)
+	return nil
+}
+
+func scanShiftSwap(row pgx.Row) (*domain.ShiftSwapRequest, error) {
+	swap := &domain.ShiftSwapRequest{}
+	err := row.Scan(
+		&swap.ID,
+		&swap.RequesterID,
+		&swap.RequesterDate,
+		&swap.CounterpartID,
+		&swap.CounterpartDate,
+		&swap.Reason,
+		&swap.Status,
+		&swap.RespondedAt,
+		&swap.DecidedBy,
+		&swap.DecidedAt,
+		&swap.Comment,
+		&swap.CreatedAt,
+		&swap.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return swap, nil
+}
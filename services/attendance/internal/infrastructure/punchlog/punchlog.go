@@ -0,0 +1,270 @@
+// Package punchlog reads punch exports from fingerprint and card terminals
+package punchlog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"yathuerp/services/attendance/internal/domain"
+)
+
+// Supported log formats
+const (
+	FormatCSV    = domain.PunchSourceCSV
+	FormatZKTeco = domain.PunchSourceZKTeco
+)
+
+var ErrUnknownFormat = errors.New("unknown punch log format")
+
+// timeLayouts are the timestamp layouts seen in terminal exports, most
+// specific first
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+}
+
+// csvColumns maps normalised header names onto the fields they carry
+var csvColumns = map[string]string{
+	"deviceuserid": "user",
+	"userid":       "user",
+	"empno":        "user",
+	"enrollno":     "user",
+	"enrollnumber": "user",
+	"pin":          "user",
+	"acno":         "user",
+	"badgenumber":  "user",
+	"timestamp":    "timestamp",
+	"datetime":     "timestamp",
+	"punchtime":    "timestamp",
+	"checktime":    "timestamp",
+	"date":         "date",
+	"time":         "time",
+	"deviceid":     "device",
+	"device":       "device",
+	"terminal":     "device",
+	"terminalid":   "device",
+	"sn":           "device",
+	"serialnumber": "device",
+	"direction":    "direction",
+	"state":        "direction",
+	"status":       "direction",
+	"checktype":    "direction",
+	"inout":        "direction",
+	"punchstate":   "direction",
+}
+
+// Parse reads a log in the given format. Times without a zone are read in loc.
+func Parse(format string, r io.Reader, loc *time.Location) (*domain.PunchLog, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r, loc)
+	case FormatZKTeco:
+		return ParseZKTeco(r, loc)
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+// ParseCSV reads a CSV export with a header row. It needs a user column and
+// either a combined timestamp or separate date and time columns; device and
+// direction columns are optional.
+func ParseCSV(r io.Reader, loc *time.Location) (*domain.PunchLog, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return &domain.PunchLog{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		if field, ok := csvColumns[normalise(name)]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["user"]; !ok {
+		return nil, fmt.Errorf("no user ID column in header")
+	}
+	_, hasTimestamp := columns["timestamp"]
+	_, hasDate := columns["date"]
+	_, hasTime := columns["time"]
+	if !hasTimestamp && hasTime && !hasDate {
+		// a lone time column carries the full timestamp
+		columns["timestamp"] = columns["time"]
+		hasTimestamp = true
+	}
+	if !hasTimestamp && !(hasDate && hasTime) {
+		return nil, fmt.Errorf("no timestamp or date and time columns in header")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	log := &domain.PunchLog{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("failed to read punch log: %w", err)
+			}
+			log.Lines++
+			log.Rejected = append(log.Rejected, domain.RejectedLine{Line: parseErr.Line, Reason: parseErr.Err.Error()})
+			continue
+		}
+		if isBlank(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		log.Lines++
+		raw := strings.Join(record, ",")
+
+		userID := field(record, "user")
+		if userID == "" {
+			log.Rejected = append(log.Rejected, domain.RejectedLine{Line: line, Raw: raw, Reason: "missing user ID"})
+			continue
+		}
+
+		value := field(record, "timestamp")
+		if !hasTimestamp {
+			value = strings.TrimSpace(field(record, "date") + " " + field(record, "time"))
+		}
+		at, err := parseTime(value, loc)
+		if err != nil {
+			log.Rejected = append(log.Rejected, domain.RejectedLine{Line: line, Raw: raw, Reason: err.Error()})
+			continue
+		}
+
+		log.Punches = append(log.Punches, domain.RawPunch{
+			Line:         line,
+			DeviceID:     field(record, "device"),
+			DeviceUserID: userID,
+			Time:         at,
+			Direction:    Direction(field(record, "direction")),
+			Raw:          raw,
+		})
+	}
+
+	return log, nil
+}
+
+// ParseZKTeco reads a ZKTeco attendance log (attlog.dat), one punch per line:
+// user PIN, date, time, verify mode, state and work code separated by tabs or
+// spaces.
+func ParseZKTeco(r io.Reader, loc *time.Location) (*domain.PunchLog, error) {
+	scanner := bufio.NewScanner(r)
+	log := &domain.PunchLog{}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+		log.Lines++
+
+		fields := strings.Fields(raw)
+		if len(fields) < 3 {
+			log.Rejected = append(log.Rejected, domain.RejectedLine{Line: line, Raw: raw, Reason: "expected user ID, date and time"})
+			continue
+		}
+		at, err := parseTime(fields[1]+" "+fields[2], loc)
+		if err != nil {
+			log.Rejected = append(log.Rejected, domain.RejectedLine{Line: line, Raw: raw, Reason: err.Error()})
+			continue
+		}
+
+		punch := domain.RawPunch{
+			Line:         line,
+			DeviceUserID: fields[0],
+			Time:         at,
+			Raw:          raw,
+		}
+		if len(fields) > 4 {
+			punch.Direction = zktecoState(fields[4])
+		}
+		log.Punches = append(log.Punches, punch)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read line %d: %w", line+1, err)
+	}
+	return log, nil
+}
+
+// Direction reads the punch directions terminals commonly write, e.g. "IN",
+// "C/Out" or "Check-In", and numeric states where 0 is in and 1 is out.
+func Direction(value string) string {
+	switch normalise(value) {
+	case "in", "i", "checkin", "cin", "0":
+		return domain.PunchDirectionIn
+	case "out", "o", "checkout", "cout", "1":
+		return domain.PunchDirectionOut
+	}
+	return domain.PunchDirectionUnknown
+}
+
+// zktecoState maps ZKTeco punch states: check in/out, break out/in and
+// overtime in/out
+func zktecoState(state string) string {
+	switch state {
+	case "0", "3", "4":
+		return domain.PunchDirectionIn
+	case "1", "2", "5":
+		return domain.PunchDirectionOut
+	}
+	return domain.PunchDirectionUnknown
+}
+
+func parseTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid punch time %q", value)
+}
+
+// normalise lower-cases value and drops everything but letters and digits
+func normalise(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
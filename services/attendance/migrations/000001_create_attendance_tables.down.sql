@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS attendances;
+DROP TABLE IF EXISTS employee_shifts;
+DROP TABLE IF EXISTS shifts;
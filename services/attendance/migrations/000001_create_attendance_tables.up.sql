@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS shifts (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name           VARCHAR(255) NOT NULL,
+    code           VARCHAR(50) NOT NULL,
+    start_time     VARCHAR(5) NOT NULL,
+    end_time       VARCHAR(5) NOT NULL,
+    break_time     VARCHAR(5) NOT NULL DEFAULT '',
+    work_hours     NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    description    TEXT NOT NULL DEFAULT '',
+    is_night_shift BOOLEAN NOT NULL DEFAULT false,
+    grace_minutes  INTEGER NOT NULL DEFAULT 0,
+    half_day_hours NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    deleted        BOOLEAN NOT NULL DEFAULT false,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_code ON shifts (code) WHERE deleted = false;
+
+CREATE TABLE IF NOT EXISTS employee_shifts (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id    UUID NOT NULL,
+    shift_id       UUID NOT NULL REFERENCES shifts (id),
+    effective_from DATE NOT NULL,
+    effective_to   DATE,
+    deleted        BOOLEAN NOT NULL DEFAULT false,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_shifts_employee ON employee_shifts (employee_id, effective_from);
+
+CREATE TABLE IF NOT EXISTS attendances (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id     UUID NOT NULL,
+    attendance_date DATE NOT NULL,
+    shift_id        UUID REFERENCES shifts (id),
+    check_in        TIMESTAMPTZ,
+    check_out       TIMESTAMPTZ,
+    work_hours      NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    overtime_hours  NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    status          VARCHAR(20) NOT NULL DEFAULT 'present',
+    notes           TEXT NOT NULL DEFAULT '',
+    approved_by     UUID,
+    deleted         BOOLEAN NOT NULL DEFAULT false,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attendances_employee_date ON attendances (employee_id, attendance_date) WHERE deleted = false;
+CREATE INDEX IF NOT EXISTS idx_attendances_date ON attendances (attendance_date);
+CREATE INDEX IF NOT EXISTS idx_attendances_open ON attendances (employee_id) WHERE check_out IS NULL AND deleted = false;
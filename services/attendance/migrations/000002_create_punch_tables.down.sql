@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS punch_exceptions;
+DROP TABLE IF EXISTS punches;
+DROP TABLE IF EXISTS punch_imports;
+DROP TABLE IF EXISTS device_users;
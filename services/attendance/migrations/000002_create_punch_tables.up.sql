@@ -0,0 +1,64 @@
+CREATE TABLE IF NOT EXISTS device_users (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    device_id      VARCHAR(100) NOT NULL DEFAULT '',
+    device_user_id VARCHAR(100) NOT NULL,
+    employee_id    UUID NOT NULL,
+    deleted        BOOLEAN NOT NULL DEFAULT false,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_device_users_device_user ON device_users (device_id, device_user_id) WHERE deleted = false;
+
+CREATE TABLE IF NOT EXISTS punch_imports (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source      VARCHAR(20) NOT NULL,
+    file_name   VARCHAR(255) NOT NULL DEFAULT '',
+    device_id   VARCHAR(100) NOT NULL DEFAULT '',
+    total_lines INTEGER NOT NULL DEFAULT 0,
+    imported    INTEGER NOT NULL DEFAULT 0,
+    duplicates  INTEGER NOT NULL DEFAULT 0,
+    exceptions  INTEGER NOT NULL DEFAULT 0,
+    sessions    INTEGER NOT NULL DEFAULT 0,
+    imported_by UUID,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS punches (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    import_id      UUID REFERENCES punch_imports (id),
+    device_id      VARCHAR(100) NOT NULL DEFAULT '',
+    device_user_id VARCHAR(100) NOT NULL,
+    employee_id    UUID,
+    punch_time     TIMESTAMPTZ NOT NULL,
+    direction      VARCHAR(10) NOT NULL DEFAULT '',
+    source         VARCHAR(20) NOT NULL,
+    attendance_id  UUID REFERENCES attendances (id),
+    status         VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_punches_unique ON punches (device_id, device_user_id, punch_time);
+CREATE INDEX IF NOT EXISTS idx_punches_employee_time ON punches (employee_id, punch_time);
+
+CREATE TABLE IF NOT EXISTS punch_exceptions (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    import_id       UUID REFERENCES punch_imports (id),
+    punch_id        UUID REFERENCES punches (id),
+    employee_id     UUID,
+    device_id       VARCHAR(100) NOT NULL DEFAULT '',
+    device_user_id  VARCHAR(100) NOT NULL DEFAULT '',
+    punch_time      TIMESTAMPTZ,
+    attendance_date DATE,
+    line            INTEGER NOT NULL DEFAULT 0,
+    raw             TEXT NOT NULL DEFAULT '',
+    reason          VARCHAR(30) NOT NULL,
+    details         TEXT NOT NULL DEFAULT '',
+    resolved        BOOLEAN NOT NULL DEFAULT false,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_punch_exceptions_import ON punch_exceptions (import_id);
+CREATE INDEX IF NOT EXISTS idx_punch_exceptions_open ON punch_exceptions (created_at) WHERE resolved = false;
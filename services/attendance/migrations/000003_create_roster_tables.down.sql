@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS roster_entries;
+DROP TABLE IF EXISTS shift_swap_requests;
+DROP TABLE IF EXISTS roster_assignments;
+DROP TABLE IF EXISTS roster_patterns;
@@ -0,0 +1,62 @@
+CREATE TABLE IF NOT EXISTS roster_patterns (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(255) NOT NULL,
+    code        VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    days        JSONB NOT NULL DEFAULT '[]',
+    deleted     BOOLEAN NOT NULL DEFAULT false,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roster_patterns_code ON roster_patterns (code) WHERE deleted = false;
+
+CREATE TABLE IF NOT EXISTS roster_assignments (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pattern_id     UUID NOT NULL REFERENCES roster_patterns (id),
+    employee_id    UUID,
+    department_id  UUID,
+    effective_from DATE NOT NULL,
+    effective_to   DATE,
+    anchor_date    DATE NOT NULL,
+    deleted        BOOLEAN NOT NULL DEFAULT false,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((employee_id IS NULL) <> (department_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_roster_assignments_effective ON roster_assignments (effective_from, effective_to);
+
+CREATE TABLE IF NOT EXISTS shift_swap_requests (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    requester_id     UUID NOT NULL,
+    requester_date   DATE NOT NULL,
+    counterpart_id   UUID NOT NULL,
+    counterpart_date DATE NOT NULL,
+    reason           TEXT NOT NULL DEFAULT '',
+    status           VARCHAR(20) NOT NULL DEFAULT 'pending',
+    responded_at     TIMESTAMPTZ,
+    decided_by       UUID,
+    decided_at       TIMESTAMPTZ,
+    comment          TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_swap_requests_requester ON shift_swap_requests (requester_id);
+CREATE INDEX IF NOT EXISTS idx_shift_swap_requests_counterpart ON shift_swap_requests (counterpart_id);
+
+CREATE TABLE IF NOT EXISTS roster_entries (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id     UUID NOT NULL,
+    roster_date     DATE NOT NULL,
+    shift_id        UUID REFERENCES shifts (id),
+    assignment_id   UUID REFERENCES roster_assignments (id),
+    swap_request_id UUID REFERENCES shift_swap_requests (id),
+    source          VARCHAR(20) NOT NULL DEFAULT 'generated',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roster_entries_employee_date ON roster_entries (employee_id, roster_date);
+CREATE INDEX IF NOT EXISTS idx_roster_entries_date ON roster_entries (roster_date);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS attendance_evidence;
+DROP TABLE IF EXISTS geofences;
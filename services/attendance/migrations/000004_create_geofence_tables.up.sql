@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS geofences (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id     UUID NOT NULL,
+    name          VARCHAR(255) NOT NULL,
+    latitude      DOUBLE PRECISION NOT NULL,
+    longitude     DOUBLE PRECISION NOT NULL,
+    radius_meters DOUBLE PRECISION NOT NULL DEFAULT 0,
+    polygon       JSONB NOT NULL DEFAULT '[]',
+    active        BOOLEAN NOT NULL DEFAULT true,
+    deleted       BOOLEAN NOT NULL DEFAULT false,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_geofences_branch ON geofences (branch_id) WHERE deleted = false;
+
+CREATE TABLE IF NOT EXISTS attendance_evidence (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    attendance_id      UUID NOT NULL REFERENCES attendances (id),
+    employee_id        UUID NOT NULL,
+    kind               VARCHAR(20) NOT NULL,
+    captured_at        TIMESTAMPTZ NOT NULL,
+    latitude           DOUBLE PRECISION,
+    longitude          DOUBLE PRECISION,
+    accuracy_meters    DOUBLE PRECISION,
+    branch_id          UUID,
+    geofence_id        UUID REFERENCES geofences (id),
+    distance_meters    DOUBLE PRECISION,
+    within_fence       BOOLEAN,
+    photo              BYTEA,
+    photo_content_type VARCHAR(50) NOT NULL DEFAULT '',
+    review_status      VARCHAR(20) NOT NULL DEFAULT 'not_required',
+    reviewed_by        UUID,
+    reviewed_at        TIMESTAMPTZ,
+    review_comment     TEXT NOT NULL DEFAULT '',
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_evidence_attendance ON attendance_evidence (attendance_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_evidence_employee ON attendance_evidence (employee_id, captured_at);
+CREATE INDEX IF NOT EXISTS idx_attendance_evidence_pending ON attendance_evidence (captured_at) WHERE review_status = 'pending';
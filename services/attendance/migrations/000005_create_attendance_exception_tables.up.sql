@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS attendance_exceptions (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    attendance_id    UUID NOT NULL REFERENCES attendances (id),
+    employee_id      UUID NOT NULL,
+    attendance_date  DATE NOT NULL,
+    type             VARCHAR(20) NOT NULL,
+    minutes          INTEGER NOT NULL DEFAULT 0,
+    hours            NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    approved_hours   NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    claimed          BOOLEAN NOT NULL DEFAULT false,
+    details          TEXT NOT NULL DEFAULT '',
+    justification    TEXT NOT NULL DEFAULT '',
+    status           VARCHAR(20) NOT NULL DEFAULT 'open',
+    justified_at     TIMESTAMPTZ,
+    decided_by       UUID,
+    decided_at       TIMESTAMPTZ,
+    decision_comment TEXT NOT NULL DEFAULT '',
+    overtime_id      UUID,
+    posted_at        TIMESTAMPTZ,
+    deleted          BOOLEAN NOT NULL DEFAULT false,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attendance_exceptions_attendance_type ON attendance_exceptions (attendance_id, type) WHERE deleted = false;
+CREATE INDEX IF NOT EXISTS idx_attendance_exceptions_employee ON attendance_exceptions (employee_id, attendance_date);
+CREATE INDEX IF NOT EXISTS idx_attendance_exceptions_pending ON attendance_exceptions (attendance_date) WHERE status IN ('open', 'justified') AND deleted = false;
+CREATE INDEX IF NOT EXISTS idx_attendance_exceptions_unposted ON attendance_exceptions (attendance_date) WHERE type = 'overtime' AND status = 'approved' AND overtime_id IS NULL AND deleted = false;
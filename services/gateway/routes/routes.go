@@ -82,6 +82,18 @@ func Setup(app *fiber.App, cfg *config.Config) {
 		return nil
 	})
 
+	// 360 feedback is answered with the reviewer's token rather than a JWT,
+	// so it is proxied ahead of the protected performance routes
+	feedback := api.Group("/performance/feedback")
+	feedback.All("/*", func(c *fiber.Ctx) error {
+		url := cfg.Services["performance"] + c.Path() + "?" + c.QueryString()
+		if err := proxy.Do(c, url); err != nil {
+			return err
+		}
+		c.Response().Header.Del(fiber.HeaderXForwardedFor)
+		return nil
+	})
+
 	// Performance Management microservice routes (protected)
 	performance := api.Group("/performance")
 	performance.Use(middleware.JWTAuth())
@@ -13,9 +13,13 @@ import (
 	"yathuerp/shared/logger"
 	"yathuerp/shared/middleware"
 
+	"yathuerp/services/loan/internal/application"
+	"yathuerp/services/loan/internal/infrastructure/http"
+	"yathuerp/services/loan/internal/infrastructure/persistence/postgres"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	fiberlogger "github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
@@ -46,7 +50,7 @@ func main() {
 
 	// Global middleware
 	app.Use(recover.New())
-	app.Use(logger.New())
+	app.Use(fiberlogger.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowMethods: "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
@@ -66,7 +70,29 @@ func main() {
 	})
 
 	// Setup routes
-	setupRoutes(app, db)
+	pool := db.GetPool()
+	serviceLogger := logger.ServiceLogger{}
+
+	authMiddleware := middleware.NewAuthMiddleware(serviceLogger)
+	app.Use(authMiddleware.JWTAuth(cfg.JWTSecret))
+
+	loanTypeRepo := postgres.NewLoanTypeRepository(pool, serviceLogger)
+	applicationRepo := postgres.NewLoanApplicationRepository(pool, serviceLogger)
+	paymentRepo := postgres.NewLoanPaymentRepository(pool, serviceLogger)
+	guarantorRepo := postgres.NewLoanGuarantorRepository(pool, serviceLogger)
+	employeeDirectory := postgres.NewEmployeeDirectory(pool, serviceLogger)
+	reportRepo := postgres.NewLoanReportRepository(pool, serviceLogger)
+
+	loanTypeUseCase := application.NewLoanTypeUseCase(loanTypeRepo, serviceLogger)
+	applicationUseCase := application.NewLoanApplicationUseCase(applicationRepo, loanTypeRepo, paymentRepo, guarantorRepo, employeeDirectory, serviceLogger)
+	guarantorUseCase := application.NewLoanGuarantorUseCase(guarantorRepo, applicationRepo, serviceLogger)
+	reportUseCase := application.NewLoanReportUseCase(reportRepo, paymentRepo, serviceLogger)
+
+	http.SetupRoutes(app,
+		http.NewHandler(loanTypeUseCase, applicationUseCase, guarantorUseCase, serviceLogger),
+		http.NewReportHandler(reportUseCase, serviceLogger),
+		authMiddleware,
+	)
 
 	// Graceful shutdown
 	go func() {
@@ -88,15 +114,3 @@ func main() {
 	logger.Info("Loan service starting on port %s", cfg.Port)
 	log.Fatal(app.Listen(":" + cfg.Port))
 }
-
-func setupRoutes(app *fiber.App, db interface{}) {
-	// TODO: Setup routes from internal/infrastructure/http
-	// This will be implemented in next step
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message":    "YathuERP Loan Service is running",
-			"service":    "loan",
-			"go_version": "1.22",
-		})
-	})
-}
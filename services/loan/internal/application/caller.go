@@ -0,0 +1,41 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"yathuerp/services/loan/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ErrForbidden is returned when the caller may not act on a loan application
+var ErrForbidden = errors.New("not allowed")
+
+// Caller is the authenticated user a request is made by, taken from the JWT
+// rather than the request body
+type Caller struct {
+	UserID *uuid.UUID
+	Email  string
+	// ManagesLoans is set for HR and finance, who run the loan book
+	ManagesLoans bool
+}
+
+// callerEmployee finds the employee the caller signs in as. It returns nil
+// for an HR or finance account that is not an employee.
+func callerEmployee(directory domain.EmployeeDirectory, caller *Caller) (*uuid.UUID, error) {
+	if caller == nil || caller.Email == "" {
+		return nil, fmt.Errorf("%w: the request is not made by a user", ErrForbidden)
+	}
+	employeeID, err := directory.GetByEmail(caller.Email)
+	if errors.Is(err, domain.ErrNotFound) {
+		if caller.ManagesLoans {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: your account is not linked to an employee", ErrForbidden)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &employeeID, nil
+}
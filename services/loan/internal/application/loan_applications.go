@@ -0,0 +1,590 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidState is returned when an operation is not allowed for the
+// current status of a loan application
+var ErrInvalidState = errors.New("invalid loan application state")
+
+type LoanApplicationUseCase struct {
+	applicationRepo domain.LoanApplicationRepository
+	loanTypeRepo    domain.LoanTypeRepository
+	paymentRepo     domain.LoanPaymentRepository
+	guarantorRepo   domain.LoanGuarantorRepository
+	directory       domain.EmployeeDirectory
+	logger          utils.Logger
+}
+
+func NewLoanApplicationUseCase(
+	applicationRepo domain.LoanApplicationRepository,
+	loanTypeRepo domain.LoanTypeRepository,
+	paymentRepo domain.LoanPaymentRepository,
+	guarantorRepo domain.LoanGuarantorRepository,
+	directory domain.EmployeeDirectory,
+	logger utils.Logger,
+) *LoanApplicationUseCase {
+	return &LoanApplicationUseCase{
+		applicationRepo: applicationRepo,
+		loanTypeRepo:    loanTypeRepo,
+		paymentRepo:     paymentRepo,
+		guarantorRepo:   guarantorRepo,
+		directory:       directory,
+		logger:          logger,
+	}
+}
+
+// SubmitLoanApplicationRequest is an application by the calling employee.
+// InterestRate overrides the loan type's default and may only be set by HR or
+// finance.
+type SubmitLoanApplicationRequest struct {
+	LoanTypeID   string   `json:"loan_type_id" validate:"required,uuid"`
+	Amount       float64  `json:"amount" validate:"required"`
+	TermMonths   int      `json:"term_months" validate:"required"`
+	InterestRate *float64 `json:"interest_rate"`
+	Purpose      string   `json:"purpose"`
+	Attachments  string   `json:"attachments"`
+}
+
+type LoanDecisionRequest struct {
+	ApproverID uuid.UUID `json:"-"`
+	Notes      string    `json:"notes"`
+}
+
+type DisburseLoanRequest struct {
+	DisbursementDate string `json:"disbursement_date"`
+	FirstDueDate     string `json:"first_due_date"`
+}
+
+type RecordRepaymentRequest struct {
+	Amount          float64 `json:"amount" validate:"required"`
+	PaymentDate     string  `json:"payment_date"`
+	PaymentMethod   string  `json:"payment_method"`
+	ReferenceNumber string  `json:"reference_number"`
+	Notes           string  `json:"notes"`
+}
+
+// Submit validates the caller's application against its loan type and stores
+// it as pending
+func (uc *LoanApplicationUseCase) Submit(ctx context.Context, req *SubmitLoanApplicationRequest, caller *Caller) (*domain.LoanApplication, error) {
+	employee, err := callerEmployee(uc.directory, caller)
+	if err != nil {
+		return nil, err
+	}
+	if employee == nil {
+		return nil, fmt.Errorf("%w: your account is not linked to an employee", ErrForbidden)
+	}
+	employeeID := *employee
+
+	loanTypeID, err := uuid.Parse(req.LoanTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loan type ID")
+	}
+
+	loanType, err := uc.loanTypeRepo.GetByID(loanTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	interestRate, err := requestedInterestRate(loanType, req, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.validateAgainstLoanType(employeeID, loanType, req.Amount, req.TermMonths, interestRate); err != nil {
+		return nil, fmt.Errorf("business validation failed: %w", err)
+	}
+
+	now := time.Now()
+	application := &domain.LoanApplication{
+		ID:             uuid.New(),
+		EmployeeID:     employeeID,
+		LoanTypeID:     loanTypeID,
+		Amount:         req.Amount,
+		InterestRate:   interestRate,
+		TermMonths:     req.TermMonths,
+		MonthlyPayment: monthlyInstallment(req.Amount, interestRate, req.TermMonths),
+		Purpose:        req.Purpose,
+		Status:         domain.LoanStatusPending,
+		Attachments:    attachmentsOrDefault(req.Attachments),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := uc.applicationRepo.Create(application); err != nil {
+		uc.logger.Error("Failed to create loan application", "error", err)
+		return nil, fmt.Errorf("failed to create loan application: %w", err)
+	}
+
+	uc.logger.Info("Loan application submitted", "loan_application_id", application.ID, "employee_id", employeeID)
+	return application, nil
+}
+
+// Update changes the terms of an application that is still pending
+func (uc *LoanApplicationUseCase) Update(ctx context.Context, id uuid.UUID, req *SubmitLoanApplicationRequest, caller *Caller) (*domain.LoanApplication, error) {
+	application, err := uc.applicationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkApplicant(application, caller); err != nil {
+		return nil, err
+	}
+	if application.Status != domain.LoanStatusPending {
+		return nil, fmt.Errorf("%w: only pending applications can be edited", ErrInvalidState)
+	}
+
+	loanTypeID, err := uuid.Parse(req.LoanTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loan type ID")
+	}
+	loanType, err := uc.loanTypeRepo.GetByID(loanTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	interestRate, err := requestedInterestRate(loanType, req, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.validateLoanTerms(loanType, req.Amount, req.TermMonths, interestRate); err != nil {
+		return nil, fmt.Errorf("business validation failed: %w", err)
+	}
+
+	application.LoanTypeID = loanTypeID
+	application.Amount = req.Amount
+	application.InterestRate = interestRate
+	application.TermMonths = req.TermMonths
+	application.MonthlyPayment = monthlyInstallment(req.Amount, interestRate, req.TermMonths)
+	application.Purpose = req.Purpose
+	application.Attachments = attachmentsOrDefault(req.Attachments)
+
+	if err := uc.applicationRepo.Update(application); err != nil {
+		return nil, err
+	}
+
+	return application, nil
+}
+
+func (uc *LoanApplicationUseCase) Get(ctx context.Context, id uuid.UUID) (*domain.LoanApplication, error) {
+	return uc.applicationRepo.GetByID(id)
+}
+
+func (uc *LoanApplicationUseCase) List(ctx context.Context, filter *domain.LoanApplicationFilter) ([]*domain.LoanApplication, error) {
+	return uc.applicationRepo.GetAll(filter)
+}
+
+func (uc *LoanApplicationUseCase) ListByEmployee(ctx context.Context, employeeID uuid.UUID) ([]*domain.LoanApplication, error) {
+	return uc.applicationRepo.GetByEmployeeID(employeeID)
+}
+
+func (uc *LoanApplicationUseCase) ListPending(ctx context.Context) ([]*domain.LoanApplication, error) {
+	return uc.applicationRepo.GetPendingApplications()
+}
+
+// Delete removes an application that has not been decided yet
+func (uc *LoanApplicationUseCase) Delete(ctx context.Context, id uuid.UUID, caller *Caller) error {
+	application, err := uc.applicationRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkApplicant(application, caller); err != nil {
+		return err
+	}
+	if application.Status != domain.LoanStatusPending && application.Status != domain.LoanStatusRejected {
+		return fmt.Errorf("%w: only pending or rejected applications can be deleted", ErrInvalidState)
+	}
+	return uc.applicationRepo.Delete(id)
+}
+
+// Approve approves a pending application once any required guarantors have approved
+func (uc *LoanApplicationUseCase) Approve(ctx context.Context, id uuid.UUID, req *LoanDecisionRequest, caller *Caller) (*domain.LoanApplication, error) {
+	application, err := uc.applicationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkDecider(application, caller); err != nil {
+		return nil, err
+	}
+	if application.Status != domain.LoanStatusPending {
+		return nil, fmt.Errorf("%w: application is %s", ErrInvalidState, application.Status)
+	}
+
+	loanType, err := uc.loanTypeRepo.GetByID(application.LoanTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if loanType.RequiresGuarantor {
+		guarantors, err := uc.guarantorRepo.GetByLoanApplicationID(application.ID)
+		if err != nil {
+			return nil, err
+		}
+		approved := false
+		for _, guarantor := range guarantors {
+			if guarantor.IsApproved {
+				approved = true
+				break
+			}
+		}
+		if !approved {
+			return nil, fmt.Errorf("%w: loan type %s requires an approved guarantor", ErrInvalidState, loanType.Code)
+		}
+	}
+
+	if err := uc.checkActiveLoanLimit(application.EmployeeID, loanType); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+
+	now := time.Now()
+	application.Status = domain.LoanStatusApproved
+	application.ApproverID = &req.ApproverID
+	application.ApprovalDate = &now
+	application.ApprovalNotes = req.Notes
+
+	if err := uc.applicationRepo.Update(application); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Loan application approved", "loan_application_id", application.ID, "approver_id", req.ApproverID)
+	return application, nil
+}
+
+func (uc *LoanApplicationUseCase) Reject(ctx context.Context, id uuid.UUID, req *LoanDecisionRequest, caller *Caller) (*domain.LoanApplication, error) {
+	application, err := uc.applicationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkDecider(application, caller); err != nil {
+		return nil, err
+	}
+	if application.Status != domain.LoanStatusPending && application.Status != domain.LoanStatusApproved {
+		return nil, fmt.Errorf("%w: application is %s", ErrInvalidState, application.Status)
+	}
+	if strings.TrimSpace(req.Notes) == "" {
+		return nil, fmt.Errorf("a rejection reason is required")
+	}
+
+	now := time.Now()
+	application.Status = domain.LoanStatusRejected
+	application.ApproverID = &req.ApproverID
+	application.ApprovalDate = &now
+	application.ApprovalNotes = req.Notes
+
+	if err := uc.applicationRepo.Update(application); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Loan application rejected", "loan_application_id", application.ID, "approver_id", req.ApproverID)
+	return application, nil
+}
+
+// Disburse marks an approved loan as paid out and generates its repayment schedule
+func (uc *LoanApplicationUseCase) Disburse(ctx context.Context, id uuid.UUID, req *DisburseLoanRequest) (*domain.LoanApplication, []*domain.LoanPayment, error) {
+	application, err := uc.applicationRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if application.Status != domain.LoanStatusApproved {
+		return nil, nil, fmt.Errorf("%w: only approved applications can be disbursed", ErrInvalidState)
+	}
+
+	disbursementDate := time.Now()
+	if req.DisbursementDate != "" {
+		t, err := utils.ParseTime(req.DisbursementDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid disbursement date")
+		}
+		disbursementDate = *t
+	}
+
+	firstDueDate := disbursementDate.AddDate(0, 1, 0)
+	if req.FirstDueDate != "" {
+		t, err := utils.ParseTime(req.FirstDueDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid first due date")
+		}
+		firstDueDate = *t
+	}
+	if firstDueDate.Before(disbursementDate) {
+		return nil, nil, fmt.Errorf("first due date cannot be before the disbursement date")
+	}
+
+	schedule := buildRepaymentSchedule(application, firstDueDate)
+	application.Status = domain.LoanStatusDisbursed
+	application.DisbursementDate = &disbursementDate
+	if err := uc.applicationRepo.Disburse(application, schedule); err != nil {
+		return nil, nil, err
+	}
+
+	uc.logger.Info("Loan disbursed", "loan_application_id", application.ID, "installments", len(schedule))
+	return application, schedule, nil
+}
+
+func (uc *LoanApplicationUseCase) Payments(ctx context.Context, id uuid.UUID) ([]*domain.LoanPayment, error) {
+	if _, err := uc.applicationRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return uc.paymentRepo.GetByLoanApplicationID(id)
+}
+
+// RecordRepayment allocates a repayment to the oldest outstanding installments
+// and closes the loan once every installment is settled. A repayment larger
+// than the outstanding balance is rejected.
+func (uc *LoanApplicationUseCase) RecordRepayment(ctx context.Context, id uuid.UUID, req *RecordRepaymentRequest) (*domain.LoanApplication, []*domain.LoanPayment, error) {
+	if req.Amount <= 0 {
+		return nil, nil, fmt.Errorf("repayment amount must be positive")
+	}
+
+	application, err := uc.applicationRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if application.Status != domain.LoanStatusDisbursed && application.Status != domain.LoanStatusDefaulted {
+		return nil, nil, fmt.Errorf("%w: repayments can only be recorded against disbursed loans", ErrInvalidState)
+	}
+
+	paymentDate := time.Now()
+	if req.PaymentDate != "" {
+		t, err := utils.ParseTime(req.PaymentDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid payment date")
+		}
+		paymentDate = *t
+	}
+
+	payments, err := uc.paymentRepo.GetByLoanApplicationID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outstanding := 0.0
+	for _, payment := range payments {
+		outstanding += math.Max(payment.AmountDue-payment.AmountPaid, 0)
+	}
+	outstanding = roundAmount(outstanding)
+	if roundAmount(req.Amount) > outstanding {
+		return nil, nil, fmt.Errorf("repayment of %.2f exceeds the outstanding balance of %.2f", req.Amount, outstanding)
+	}
+
+	now := time.Now()
+	remaining := roundAmount(req.Amount)
+	var updated []*domain.LoanPayment
+	var repayments []*domain.LoanRepayment
+	for _, payment := range payments {
+		if remaining <= 0 {
+			break
+		}
+		owed := roundAmount(payment.AmountDue - payment.AmountPaid)
+		if owed <= 0 {
+			continue
+		}
+
+		applied := math.Min(owed, remaining)
+		payment.AmountPaid = roundAmount(payment.AmountPaid + applied)
+		payment.PaymentDate = &paymentDate
+		payment.PaymentMethod = req.PaymentMethod
+		payment.ReferenceNumber = req.ReferenceNumber
+		if req.Notes != "" {
+			payment.Notes = req.Notes
+		}
+		if payment.AmountPaid >= payment.AmountDue {
+			payment.Status = domain.PaymentStatusPaid
+		} else {
+			payment.Status = domain.PaymentStatusPartial
+		}
+
+		updated = append(updated, payment)
+		repayments = append(repayments, &domain.LoanRepayment{
+			ID:            uuid.New(),
+			LoanPaymentID: payment.ID,
+			Amount:        applied,
+			PaidAt:        paymentDate,
+			CreatedAt:     now,
+		})
+		remaining = roundAmount(remaining - applied)
+	}
+
+	if allInstallmentsPaid(payments) {
+		application.Status = domain.LoanStatusPaid
+		application.CompletionDate = &paymentDate
+	}
+	if err := uc.applicationRepo.RecordRepayment(application, updated, repayments); err != nil {
+		return nil, nil, err
+	}
+
+	uc.logger.Info("Loan repayment recorded", "loan_application_id", id, "amount", req.Amount)
+	return application, updated, nil
+}
+
+// checkApplicant makes sure the caller is the applicant, or HR or finance
+func (uc *LoanApplicationUseCase) checkApplicant(application *domain.LoanApplication, caller *Caller) error {
+	if caller != nil && caller.ManagesLoans {
+		return nil
+	}
+	employee, err := callerEmployee(uc.directory, caller)
+	if err != nil {
+		return err
+	}
+	if employee == nil || *employee != application.EmployeeID {
+		return fmt.Errorf("%w: only the applicant can change their loan application", ErrForbidden)
+	}
+	return nil
+}
+
+// checkDecider makes sure the caller is not deciding their own application.
+// The routes already limit decisions to HR and finance.
+func (uc *LoanApplicationUseCase) checkDecider(application *domain.LoanApplication, caller *Caller) error {
+	employee, err := callerEmployee(uc.directory, caller)
+	if err != nil {
+		return err
+	}
+	if employee != nil && *employee == application.EmployeeID {
+		return fmt.Errorf("%w: you cannot decide your own loan application", ErrForbidden)
+	}
+	return nil
+}
+
+// requestedInterestRate is the loan type's default rate unless HR or finance
+// set one
+func requestedInterestRate(loanType *domain.LoanType, req *SubmitLoanApplicationRequest, caller *Caller) (float64, error) {
+	if req.InterestRate == nil {
+		return loanType.DefaultInterestRate, nil
+	}
+	if caller == nil || !caller.ManagesLoans {
+		return 0, fmt.Errorf("%w: only HR or finance can set the interest rate", ErrForbidden)
+	}
+	return *req.InterestRate, nil
+}
+
+func (uc *LoanApplicationUseCase) validateAgainstLoanType(employeeID uuid.UUID, loanType *domain.LoanType, amount float64, termMonths int, interestRate float64) error {
+	if err := uc.validateLoanTerms(loanType, amount, termMonths, interestRate); err != nil {
+		return err
+	}
+	return uc.checkActiveLoanLimit(employeeID, loanType)
+}
+
+func (uc *LoanApplicationUseCase) validateLoanTerms(loanType *domain.LoanType, amount float64, termMonths int, interestRate float64) error {
+	if !loanType.IsActive {
+		return fmt.Errorf("loan type %s is not active", loanType.Code)
+	}
+	if amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	if amount < loanType.MinAmount {
+		return fmt.Errorf("amount is below the minimum of %.2f for %s", loanType.MinAmount, loanType.Code)
+	}
+	if loanType.MaxAmount > 0 && amount > loanType.MaxAmount {
+		return fmt.Errorf("amount exceeds the maximum of %.2f for %s", loanType.MaxAmount, loanType.Code)
+	}
+	if termMonths < loanType.MinTermMonths || (loanType.MaxTermMonths > 0 && termMonths > loanType.MaxTermMonths) {
+		return fmt.Errorf("term must be between %d and %d months for %s", loanType.MinTermMonths, loanType.MaxTermMonths, loanType.Code)
+	}
+	if interestRate < 0 {
+		return fmt.Errorf("interest rate cannot be negative")
+	}
+	return nil
+}
+
+func (uc *LoanApplicationUseCase) checkActiveLoanLimit(employeeID uuid.UUID, loanType *domain.LoanType) error {
+	if loanType.MaxActiveLoans <= 0 {
+		return nil
+	}
+
+	active, err := uc.applicationRepo.GetActiveLoans(employeeID)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, loan := range active {
+		if loan.LoanTypeID == loanType.ID {
+			count++
+		}
+	}
+	if count >= loanType.MaxActiveLoans {
+		return fmt.Errorf("employee already has %d active %s loan(s)", count, loanType.Code)
+	}
+	return nil
+}
+
+// monthlyInstallment returns the fixed amortised installment for an annual
+// interest rate given as a percentage
+func monthlyInstallment(principal, annualRate float64, termMonths int) float64 {
+	if termMonths <= 0 {
+		return 0
+	}
+	rate := annualRate / 100 / 12
+	if rate == 0 {
+		return roundAmount(principal / float64(termMonths))
+	}
+	return roundAmount(principal * rate / (1 - math.Pow(1+rate, -float64(termMonths))))
+}
+
+// buildRepaymentSchedule splits a loan into monthly installments starting at
+// firstDueDate; the final installment absorbs any rounding difference
+func buildRepaymentSchedule(application *domain.LoanApplication, firstDueDate time.Time) []*domain.LoanPayment {
+	rate := application.InterestRate / 100 / 12
+	balance := application.Amount
+	now := time.Now()
+
+	schedule := make([]*domain.LoanPayment, 0, application.TermMonths)
+	for i := 1; i <= application.TermMonths; i++ {
+		interest := roundAmount(balance * rate)
+		principal := roundAmount(application.MonthlyPayment - interest)
+		if i == application.TermMonths || principal > balance {
+			principal = roundAmount(balance)
+		}
+		balance = roundAmount(balance - principal)
+
+		schedule = append(schedule, &domain.LoanPayment{
+			ID:                uuid.New(),
+			LoanApplicationID: application.ID,
+			PaymentNumber:     i,
+			DueDate:           firstDueDate.AddDate(0, i-1, 0),
+			AmountDue:         roundAmount(principal + interest),
+			InterestAmount:    interest,
+			PrincipalAmount:   principal,
+			BalanceAmount:     balance,
+			Status:            domain.PaymentStatusPending,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		})
+	}
+
+	return schedule
+}
+
+func allInstallmentsPaid(payments []*domain.LoanPayment) bool {
+	if len(payments) == 0 {
+		return false
+	}
+	for _, payment := range payments {
+		if payment.AmountPaid < payment.AmountDue {
+			return false
+		}
+	}
+	return true
+}
+
+func attachmentsOrDefault(attachments string) string {
+	if strings.TrimSpace(attachments) == "" {
+		return "[]"
+	}
+	return attachments
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
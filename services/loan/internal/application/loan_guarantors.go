@@ -0,0 +1,162 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+type LoanGuarantorUseCase struct {
+	guarantorRepo   domain.LoanGuarantorRepository
+	applicationRepo domain.LoanApplicationRepository
+	logger          utils.Logger
+}
+
+func NewLoanGuarantorUseCase(
+	guarantorRepo domain.LoanGuarantorRepository,
+	applicationRepo domain.LoanApplicationRepository,
+	logger utils.Logger,
+) *LoanGuarantorUseCase {
+	return &LoanGuarantorUseCase{
+		guarantorRepo:   guarantorRepo,
+		applicationRepo: applicationRepo,
+		logger:          logger,
+	}
+}
+
+type LoanGuarantorRequest struct {
+	GuarantorName    string `json:"guarantor_name" validate:"required"`
+	GuarantorEmail   string `json:"guarantor_email"`
+	GuarantorPhone   string `json:"guarantor_phone"`
+	GuarantorAddress string `json:"guarantor_address"`
+	GuarantorID      string `json:"guarantor_id"`
+	Relationship     string `json:"relationship"`
+}
+
+type GuarantorDecisionRequest struct {
+	Approved bool   `json:"approved"`
+	Notes    string `json:"notes"`
+}
+
+// Add registers a guarantor against an application that is still pending
+func (uc *LoanGuarantorUseCase) Add(ctx context.Context, loanApplicationID uuid.UUID, req *LoanGuarantorRequest) (*domain.LoanGuarantor, error) {
+	application, err := uc.applicationRepo.GetByID(loanApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.Status != domain.LoanStatusPending {
+		return nil, fmt.Errorf("%w: guarantors can only be added to pending applications", ErrInvalidState)
+	}
+	if strings.TrimSpace(req.GuarantorName) == "" {
+		return nil, fmt.Errorf("guarantor name is required")
+	}
+
+	now := time.Now()
+	guarantor := &domain.LoanGuarantor{
+		ID:                uuid.New(),
+		LoanApplicationID: loanApplicationID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if err := applyGuarantorRequest(guarantor, req, application.EmployeeID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.guarantorRepo.Create(guarantor); err != nil {
+		return nil, err
+	}
+
+	return guarantor, nil
+}
+
+func (uc *LoanGuarantorUseCase) List(ctx context.Context, loanApplicationID uuid.UUID) ([]*domain.LoanGuarantor, error) {
+	if _, err := uc.applicationRepo.GetByID(loanApplicationID); err != nil {
+		return nil, err
+	}
+	return uc.guarantorRepo.GetByLoanApplicationID(loanApplicationID)
+}
+
+func (uc *LoanGuarantorUseCase) Update(ctx context.Context, id uuid.UUID, req *LoanGuarantorRequest) (*domain.LoanGuarantor, error) {
+	guarantor, err := uc.guarantorRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	application, err := uc.applicationRepo.GetByID(guarantor.LoanApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.Status != domain.LoanStatusPending {
+		return nil, fmt.Errorf("%w: guarantors can only be changed on pending applications", ErrInvalidState)
+	}
+
+	if err := applyGuarantorRequest(guarantor, req, application.EmployeeID); err != nil {
+		return nil, err
+	}
+	if err := uc.guarantorRepo.Update(guarantor); err != nil {
+		return nil, err
+	}
+
+	return guarantor, nil
+}
+
+// Decide records the guarantor's acceptance or refusal
+func (uc *LoanGuarantorUseCase) Decide(ctx context.Context, id uuid.UUID, req *GuarantorDecisionRequest) (*domain.LoanGuarantor, error) {
+	guarantor, err := uc.guarantorRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	guarantor.IsApproved = req.Approved
+	guarantor.ApprovalDate = &now
+	guarantor.ApprovalNotes = req.Notes
+
+	if err := uc.guarantorRepo.Update(guarantor); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Loan guarantor decision recorded", "guarantor_id", id, "approved", req.Approved)
+	return guarantor, nil
+}
+
+func (uc *LoanGuarantorUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	guarantor, err := uc.guarantorRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	application, err := uc.applicationRepo.GetByID(guarantor.LoanApplicationID)
+	if err != nil {
+		return err
+	}
+	if application.Status != domain.LoanStatusPending {
+		return fmt.Errorf("%w: guarantors can only be removed from pending applications", ErrInvalidState)
+	}
+	return uc.guarantorRepo.Delete(id)
+}
+
+func applyGuarantorRequest(guarantor *domain.LoanGuarantor, req *LoanGuarantorRequest, borrowerID uuid.UUID) error {
+	guarantor.GuarantorName = strings.TrimSpace(req.GuarantorName)
+	guarantor.GuarantorEmail = req.GuarantorEmail
+	guarantor.GuarantorPhone = req.GuarantorPhone
+	guarantor.GuarantorAddress = req.GuarantorAddress
+	guarantor.Relationship = req.Relationship
+	guarantor.GuarantorID = nil
+
+	if req.GuarantorID != "" {
+		id, err := uuid.Parse(req.GuarantorID)
+		if err != nil {
+			return fmt.Errorf("invalid guarantor ID")
+		}
+		if id == borrowerID {
+			return fmt.Errorf("an employee cannot guarantee their own loan")
+		}
+		guarantor.GuarantorID = &id
+	}
+	return nil
+}
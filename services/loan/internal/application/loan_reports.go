@@ -0,0 +1,159 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+// DefaultDefaulterDays is how long an installment must be overdue before the
+// loan is reported as a defaulter
+const DefaultDefaulterDays = 90
+
+// arrearsBuckets are the ageing bands used by the arrears report
+var arrearsBuckets = []domain.ArrearsBucket{
+	{Label: "1-30 days", MinDays: 1, MaxDays: 30},
+	{Label: "31-60 days", MinDays: 31, MaxDays: 60},
+	{Label: "61-90 days", MinDays: 61, MaxDays: 90},
+	{Label: "90+ days", MinDays: 91},
+}
+
+type LoanReportUseCase struct {
+	reportRepo  domain.LoanReportRepository
+	paymentRepo domain.LoanPaymentRepository
+	logger      utils.Logger
+}
+
+func NewLoanReportUseCase(
+	reportRepo domain.LoanReportRepository,
+	paymentRepo domain.LoanPaymentRepository,
+	logger utils.Logger,
+) *LoanReportUseCase {
+	return &LoanReportUseCase{
+		reportRepo:  reportRepo,
+		paymentRepo: paymentRepo,
+		logger:      logger,
+	}
+}
+
+// OutstandingBalances returns the loan book at asOf grouped by "loan_type" or "department"
+func (uc *LoanReportUseCase) OutstandingBalances(ctx context.Context, groupBy string, asOf time.Time) ([]*domain.OutstandingBalance, error) {
+	switch groupBy {
+	case "", "loan_type":
+		return uc.reportRepo.GetOutstandingByLoanType(endOfDay(asOf))
+	case "department":
+		return uc.reportRepo.GetOutstandingByDepartment(endOfDay(asOf))
+	default:
+		return nil, fmt.Errorf("invalid group_by %q: expected loan_type or department", groupBy)
+	}
+}
+
+// Arrears returns overdue installments at asOf aged into 30/60/90+ day buckets
+func (uc *LoanReportUseCase) Arrears(ctx context.Context, asOf time.Time) (*domain.ArrearsReport, error) {
+	items, err := uc.reportRepo.GetArrears(endOfDay(asOf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load arrears: %w", err)
+	}
+
+	return buildArrearsReport(asOf, items), nil
+}
+
+// Disbursements returns amounts disbursed per loan type for each period in [startDate, endDate]
+func (uc *LoanReportUseCase) Disbursements(ctx context.Context, startDate, endDate time.Time, period string) ([]*domain.DisbursementSummary, error) {
+	if period == "" {
+		period = domain.ReportPeriodMonth
+	}
+	if period != domain.ReportPeriodMonth && period != domain.ReportPeriodQuarter && period != domain.ReportPeriodYear {
+		return nil, fmt.Errorf("invalid period %q: expected month, quarter or year", period)
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+
+	return uc.reportRepo.GetDisbursements(startDate, endDate.AddDate(0, 0, 1), period)
+}
+
+// Defaulters returns defaulted loans and loans with an installment overdue for
+// at least minDaysOverdue days
+func (uc *LoanReportUseCase) Defaulters(ctx context.Context, asOf time.Time, minDaysOverdue int) ([]*domain.Defaulter, error) {
+	if minDaysOverdue <= 0 {
+		minDaysOverdue = DefaultDefaulterDays
+	}
+
+	defaulters, err := uc.reportRepo.GetDefaulters(endOfDay(asOf), minDaysOverdue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load defaulters: %w", err)
+	}
+
+	for _, defaulter := range defaulters {
+		if defaulter.OldestDueDate != nil {
+			defaulter.DaysOverdue = daysBetween(*defaulter.OldestDueDate, asOf)
+		}
+	}
+
+	return defaulters, nil
+}
+
+// OverduePayments returns all unpaid installments past their due date
+func (uc *LoanReportUseCase) OverduePayments(ctx context.Context) ([]*domain.LoanPayment, error) {
+	return uc.paymentRepo.GetOverduePayments()
+}
+
+// UpcomingPayments returns unpaid installments falling due in the next days
+func (uc *LoanReportUseCase) UpcomingPayments(ctx context.Context, days int) ([]*domain.LoanPayment, error) {
+	if days <= 0 {
+		return nil, fmt.Errorf("days must be positive")
+	}
+	return uc.paymentRepo.GetUpcomingPayments(days)
+}
+
+func buildArrearsReport(asOf time.Time, items []*domain.ArrearsItem) *domain.ArrearsReport {
+	report := &domain.ArrearsReport{
+		AsOf:    asOf,
+		Buckets: make([]domain.ArrearsBucket, len(arrearsBuckets)),
+		Items:   items,
+	}
+	copy(report.Buckets, arrearsBuckets)
+
+	loansPerBucket := make([]map[uuid.UUID]bool, len(arrearsBuckets))
+	for i := range loansPerBucket {
+		loansPerBucket[i] = make(map[uuid.UUID]bool)
+	}
+
+	for _, item := range items {
+		for i := range report.Buckets {
+			bucket := &report.Buckets[i]
+			if item.DaysOverdue < bucket.MinDays || (bucket.MaxDays > 0 && item.DaysOverdue > bucket.MaxDays) {
+				continue
+			}
+			bucket.Installments++
+			bucket.Amount += item.AmountOutstanding
+			loansPerBucket[i][item.LoanApplicationID] = true
+			break
+		}
+		report.TotalArrears += item.AmountOutstanding
+	}
+
+	for i := range report.Buckets {
+		report.Buckets[i].Loans = len(loansPerBucket[i])
+	}
+
+	return report
+}
+
+// endOfDay is the as-of time of every report, so that repayments and
+// disbursements made on the as-of date count
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+func daysBetween(from, to time.Time) int {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
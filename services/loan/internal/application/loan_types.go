@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+type LoanTypeUseCase struct {
+	loanTypeRepo domain.LoanTypeRepository
+	logger       utils.Logger
+}
+
+func NewLoanTypeUseCase(
+	loanTypeRepo domain.LoanTypeRepository,
+	logger utils.Logger,
+) *LoanTypeUseCase {
+	return &LoanTypeUseCase{
+		loanTypeRepo: loanTypeRepo,
+		logger:       logger,
+	}
+}
+
+type LoanTypeRequest struct {
+	Name                string  `json:"name" validate:"required"`
+	Code                string  `json:"code" validate:"required"`
+	Description         string  `json:"description"`
+	MinAmount           float64 `json:"min_amount" validate:"min=0"`
+	MaxAmount           float64 `json:"max_amount" validate:"min=0"`
+	DefaultInterestRate float64 `json:"default_interest_rate" validate:"min=0"`
+	MinTermMonths       int     `json:"min_term_months" validate:"min=1"`
+	MaxTermMonths       int     `json:"max_term_months" validate:"min=1"`
+	RequiresGuarantor   bool    `json:"requires_guarantor"`
+	MaxActiveLoans      int     `json:"max_active_loans" validate:"min=0"`
+	EligibilityCriteria string  `json:"eligibility_criteria"`
+	IsActive            *bool   `json:"is_active"`
+}
+
+func (uc *LoanTypeUseCase) Create(ctx context.Context, req *LoanTypeRequest) (*domain.LoanType, error) {
+	if err := validateLoanTypeRequest(req); err != nil {
+		return nil, fmt.Errorf("business validation failed: %w", err)
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if existing, err := uc.loanTypeRepo.GetByCode(code); err == nil && existing != nil {
+		return nil, fmt.Errorf("loan type with code %s already exists", code)
+	} else if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	loanType := &domain.LoanType{
+		ID:        uuid.New(),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyLoanTypeRequest(loanType, req)
+	loanType.Code = code
+
+	if err := uc.loanTypeRepo.Create(loanType); err != nil {
+		return nil, err
+	}
+
+	return loanType, nil
+}
+
+func (uc *LoanTypeUseCase) Update(ctx context.Context, id uuid.UUID, req *LoanTypeRequest) (*domain.LoanType, error) {
+	if err := validateLoanTypeRequest(req); err != nil {
+		return nil, fmt.Errorf("business validation failed: %w", err)
+	}
+
+	loanType, err := uc.loanTypeRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if code != loanType.Code {
+		if existing, err := uc.loanTypeRepo.GetByCode(code); err == nil && existing != nil {
+			return nil, fmt.Errorf("loan type with code %s already exists", code)
+		}
+	}
+
+	applyLoanTypeRequest(loanType, req)
+	loanType.Code = code
+
+	if err := uc.loanTypeRepo.Update(loanType); err != nil {
+		return nil, err
+	}
+
+	return loanType, nil
+}
+
+func (uc *LoanTypeUseCase) Get(ctx context.Context, id uuid.UUID) (*domain.LoanType, error) {
+	return uc.loanTypeRepo.GetByID(id)
+}
+
+// List returns all loan types, or only the active ones when activeOnly is set
+func (uc *LoanTypeUseCase) List(ctx context.Context, activeOnly bool) ([]*domain.LoanType, error) {
+	if activeOnly {
+		return uc.loanTypeRepo.GetActive()
+	}
+	return uc.loanTypeRepo.GetAll()
+}
+
+func (uc *LoanTypeUseCase) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := uc.loanTypeRepo.GetByID(id); err != nil {
+		return err
+	}
+	return uc.loanTypeRepo.Delete(id)
+}
+
+func validateLoanTypeRequest(req *LoanTypeRequest) error {
+	if strings.TrimSpace(req.Name) == "" || strings.TrimSpace(req.Code) == "" {
+		return fmt.Errorf("name and code are required")
+	}
+	if req.MaxAmount > 0 && req.MinAmount > req.MaxAmount {
+		return fmt.Errorf("min amount cannot exceed max amount")
+	}
+	if req.MinTermMonths > req.MaxTermMonths {
+		return fmt.Errorf("min term cannot exceed max term")
+	}
+	return nil
+}
+
+func applyLoanTypeRequest(loanType *domain.LoanType, req *LoanTypeRequest) {
+	loanType.Name = strings.TrimSpace(req.Name)
+	loanType.Description = req.Description
+	loanType.MinAmount = req.MinAmount
+	loanType.MaxAmount = req.MaxAmount
+	loanType.DefaultInterestRate = req.DefaultInterestRate
+	loanType.MinTermMonths = req.MinTermMonths
+	loanType.MaxTermMonths = req.MaxTermMonths
+	loanType.RequiresGuarantor = req.RequiresGuarantor
+	loanType.MaxActiveLoans = req.MaxActiveLoans
+	loanType.EligibilityCriteria = req.EligibilityCriteria
+	if req.IsActive != nil {
+		loanType.IsActive = *req.IsActive
+	}
+}
@@ -27,6 +27,24 @@ type LoanApplication struct {
 	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// Loan application statuses
+const (
+	LoanStatusPending   = "pending"
+	LoanStatusApproved  = "approved"
+	LoanStatusRejected  = "rejected"
+	LoanStatusDisbursed = "disbursed"
+	LoanStatusPaid      = "paid"
+	LoanStatusDefaulted = "defaulted"
+)
+
+// Loan payment statuses
+const (
+	PaymentStatusPending = "pending"
+	PaymentStatusPaid    = "paid"
+	PaymentStatusOverdue = "overdue"
+	PaymentStatusPartial = "partial"
+)
+
 // LoanType represents types of loans available
 type LoanType struct {
 	ID                  uuid.UUID `json:"id" db:"id"`
@@ -66,6 +84,16 @@ type LoanPayment struct {
 	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// LoanRepayment is the part of a repayment allocated to one installment.
+// Reports sum them to tell what had been repaid at a given time.
+type LoanRepayment struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	LoanPaymentID uuid.UUID `json:"loan_payment_id" db:"loan_payment_id"`
+	Amount        float64   `json:"amount" db:"amount"`
+	PaidAt        time.Time `json:"paid_at" db:"paid_at"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
 // LoanGuarantor represents loan guarantor information
 type LoanGuarantor struct {
 	ID                uuid.UUID  `json:"id" db:"id"`
@@ -93,6 +121,13 @@ type LoanApplicationRepository interface {
 	Update(application *LoanApplication) error
 	Delete(id uuid.UUID) error
 	GetActiveLoans(employeeID uuid.UUID) ([]*LoanApplication, error)
+	// Disburse saves the disbursed application and creates its repayment
+	// schedule in one transaction
+	Disburse(application *LoanApplication, schedule []*LoanPayment) error
+	// RecordRepayment saves the installments a repayment was allocated to,
+	// the allocations themselves, and the application once it is paid off,
+	// in one transaction
+	RecordRepayment(application *LoanApplication, payments []*LoanPayment, repayments []*LoanRepayment) error
 }
 
 type LoanTypeRepository interface {
@@ -123,6 +158,12 @@ type LoanGuarantorRepository interface {
 	Delete(id uuid.UUID) error
 }
 
+// EmployeeDirectory reads the employee records shared by the services
+type EmployeeDirectory interface {
+	// GetByEmail finds the employee a user signs in as
+	GetByEmail(email string) (uuid.UUID, error)
+}
+
 // Filters
 type LoanApplicationFilter struct {
 	EmployeeID *uuid.UUID
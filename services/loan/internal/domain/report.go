@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned by repositories when a record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// Report periods accepted for disbursement summaries
+const (
+	ReportPeriodMonth   = "month"
+	ReportPeriodQuarter = "quarter"
+	ReportPeriodYear    = "year"
+)
+
+// OutstandingBalance represents the loan book balance for a loan type or department
+type OutstandingBalance struct {
+	GroupID            *uuid.UUID `json:"group_id" db:"group_id"`
+	GroupName          string     `json:"group_name" db:"group_name"`
+	ActiveLoans        int        `json:"active_loans" db:"active_loans"`
+	PrincipalIssued    float64    `json:"principal_issued" db:"principal_issued"`
+	AmountRepaid       float64    `json:"amount_repaid" db:"amount_repaid"`
+	OutstandingBalance float64    `json:"outstanding_balance" db:"outstanding_balance"`
+}
+
+// ArrearsItem represents a single overdue installment
+type ArrearsItem struct {
+	PaymentID         uuid.UUID `json:"payment_id" db:"payment_id"`
+	LoanApplicationID uuid.UUID `json:"loan_application_id" db:"loan_application_id"`
+	EmployeeID        uuid.UUID `json:"employee_id" db:"employee_id"`
+	EmployeeName      string    `json:"employee_name" db:"employee_name"`
+	DepartmentName    string    `json:"department_name" db:"department_name"`
+	LoanTypeName      string    `json:"loan_type_name" db:"loan_type_name"`
+	PaymentNumber     int       `json:"payment_number" db:"payment_number"`
+	DueDate           time.Time `json:"due_date" db:"due_date"`
+	AmountOutstanding float64   `json:"amount_outstanding" db:"amount_outstanding"`
+	DaysOverdue       int       `json:"days_overdue" db:"days_overdue"`
+}
+
+// ArrearsBucket groups overdue installments by age. MaxDays of 0 means open-ended.
+type ArrearsBucket struct {
+	Label        string  `json:"label"`
+	MinDays      int     `json:"min_days"`
+	MaxDays      int     `json:"max_days"`
+	Installments int     `json:"installments"`
+	Loans        int     `json:"loans"`
+	Amount       float64 `json:"amount"`
+}
+
+// ArrearsReport represents the arrears ageing analysis at a point in time
+type ArrearsReport struct {
+	AsOf         time.Time       `json:"as_of"`
+	TotalArrears float64         `json:"total_arrears"`
+	Buckets      []ArrearsBucket `json:"buckets"`
+	Items        []*ArrearsItem  `json:"items"`
+}
+
+// DisbursementSummary represents loans disbursed for a loan type within a period
+type DisbursementSummary struct {
+	PeriodStart  time.Time `json:"period_start" db:"period_start"`
+	LoanTypeID   uuid.UUID `json:"loan_type_id" db:"loan_type_id"`
+	LoanTypeName string    `json:"loan_type_name" db:"loan_type_name"`
+	Loans        int       `json:"loans" db:"loans"`
+	Amount       float64   `json:"amount" db:"amount"`
+}
+
+// Defaulter represents a loan that is defaulted or seriously in arrears
+type Defaulter struct {
+	LoanApplicationID  uuid.UUID  `json:"loan_application_id" db:"loan_application_id"`
+	EmployeeID         uuid.UUID  `json:"employee_id" db:"employee_id"`
+	EmployeeName       string     `json:"employee_name" db:"employee_name"`
+	DepartmentName     string     `json:"department_name" db:"department_name"`
+	LoanTypeName       string     `json:"loan_type_name" db:"loan_type_name"`
+	LoanStatus         string     `json:"loan_status" db:"loan_status"`
+	Amount             float64    `json:"amount" db:"amount"`
+	OutstandingBalance float64    `json:"outstanding_balance" db:"outstanding_balance"`
+	ArrearsAmount      float64    `json:"arrears_amount" db:"arrears_amount"`
+	MissedInstallments int        `json:"missed_installments" db:"missed_installments"`
+	OldestDueDate      *time.Time `json:"oldest_due_date" db:"oldest_due_date"`
+	DaysOverdue        int        `json:"days_overdue" db:"days_overdue"`
+}
+
+// LoanReportRepository provides the aggregate queries behind loan reporting
+type LoanReportRepository interface {
+	GetOutstandingByLoanType(asOf time.Time) ([]*OutstandingBalance, error)
+	GetOutstandingByDepartment(asOf time.Time) ([]*OutstandingBalance, error)
+	GetArrears(asOf time.Time) ([]*ArrearsItem, error)
+	GetDisbursements(startDate, endDate time.Time, period string) ([]*DisbursementSummary, error)
+	GetDefaulters(asOf time.Time, minDaysOverdue int) ([]*Defaulter, error)
+}
@@ -0,0 +1,488 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"yathuerp/services/loan/internal/application"
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/middleware"
+	"yathuerp/shared/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	loanTypeUseCase        *application.LoanTypeUseCase
+	loanApplicationUseCase *application.LoanApplicationUseCase
+	loanGuarantorUseCase   *application.LoanGuarantorUseCase
+	logger                 utils.Logger
+}
+
+func NewHandler(
+	loanTypeUseCase *application.LoanTypeUseCase,
+	loanApplicationUseCase *application.LoanApplicationUseCase,
+	loanGuarantorUseCase *application.LoanGuarantorUseCase,
+	logger utils.Logger,
+) *Handler {
+	return &Handler{
+		loanTypeUseCase:        loanTypeUseCase,
+		loanApplicationUseCase: loanApplicationUseCase,
+		loanGuarantorUseCase:   loanGuarantorUseCase,
+		logger:                 logger,
+	}
+}
+
+// Loan types
+
+func (h *Handler) GetLoanTypes(c *fiber.Ctx) error {
+	loanTypes, err := h.loanTypeUseCase.List(c.Context(), c.QueryBool("active"))
+	if err != nil {
+		h.logger.Error("Failed to get loan types", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get loan types")
+	}
+
+	return utils.SendSuccess(c, "Loan types retrieved successfully", loanTypes)
+}
+
+func (h *Handler) GetLoanTypeByID(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan type ID")
+	}
+
+	loanType, err := h.loanTypeUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get loan type")
+	}
+
+	return utils.SendSuccess(c, "Loan type retrieved successfully", loanType)
+}
+
+func (h *Handler) CreateLoanType(c *fiber.Ctx) error {
+	var req application.LoanTypeRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	loanType, err := h.loanTypeUseCase.Create(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create loan type")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Loan type created successfully",
+		Data:    loanType,
+	})
+}
+
+func (h *Handler) UpdateLoanType(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan type ID")
+	}
+
+	var req application.LoanTypeRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	loanType, err := h.loanTypeUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update loan type")
+	}
+
+	return utils.SendSuccess(c, "Loan type updated successfully", loanType)
+}
+
+func (h *Handler) DeleteLoanType(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan type ID")
+	}
+
+	if err := h.loanTypeUseCase.Delete(c.Context(), id); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete loan type")
+	}
+
+	return utils.SendSuccess(c, "Loan type deleted successfully", nil)
+}
+
+// Loan applications
+
+func (h *Handler) GetLoanApplications(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	filter := &domain.LoanApplicationFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	}
+
+	if value := c.Query("employee_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+		}
+		filter.EmployeeID = &id
+	}
+
+	if value := c.Query("loan_type_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan type ID")
+		}
+		filter.LoanTypeID = &id
+	}
+
+	if value := c.Query("from"); value != "" {
+		from, err := utils.ParseTime(value)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid from date")
+		}
+		filter.StartDate = from
+	}
+
+	if value := c.Query("to"); value != "" {
+		to, err := utils.ParseTime(value)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid to date")
+		}
+		end := to.AddDate(0, 0, 1)
+		filter.EndDate = &end
+	}
+
+	applications, err := h.loanApplicationUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get loan applications", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get loan applications")
+	}
+
+	return utils.SendSuccess(c, "Loan applications retrieved successfully", applications)
+}
+
+func (h *Handler) GetPendingLoanApplications(c *fiber.Ctx) error {
+	applications, err := h.loanApplicationUseCase.ListPending(c.Context())
+	if err != nil {
+		h.logger.Error("Failed to get pending loan applications", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get pending loan applications")
+	}
+
+	return utils.SendSuccess(c, "Pending loan applications retrieved successfully", applications)
+}
+
+func (h *Handler) GetEmployeeLoanApplications(c *fiber.Ctx) error {
+	employeeID, err := uuidParam(c, "employeeId")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+
+	applications, err := h.loanApplicationUseCase.ListByEmployee(c.Context(), employeeID)
+	if err != nil {
+		h.logger.Error("Failed to get employee loan applications", "error", err, "employee_id", employeeID)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get loan applications")
+	}
+
+	return utils.SendSuccess(c, "Loan applications retrieved successfully", applications)
+}
+
+func (h *Handler) GetLoanApplicationByID(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan application ID")
+	}
+
+	loanApplication, err := h.loanApplicationUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get loan application")
+	}
+
+	return utils.SendSuccess(c, "Loan application retrieved successfully", loanApplication)
+}
+
+func (h *Handler) SubmitLoanApplication(c *fiber.Ctx) error {
+	var req application.SubmitLoanApplicationRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	loanApplication, err := h.loanApplicationUseCase.Submit(c.Context(), &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to submit loan application")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Loan application submitted successfully",
+		Data:    loanApplication,
+	})
+}
+
+func (h *Handler) UpdateLoanApplication(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan application ID")
+	}
+
+	var req application.SubmitLoanApplicationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	loanApplication, err := h.loanApplicationUseCase.Update(c.Context(), id, &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update loan application")
+	}
+
+	return utils.SendSuccess(c, "Loan application updated successfully", loanApplication)
+}
+
+func (h *Handler) DeleteLoanApplication(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan application ID")
+	}
+
+	if err := h.loanApplicationUseCase.Delete(c.Context(), id, currentCaller(c)); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete loan application")
+	}
+
+	return utils.SendSuccess(c, "Loan application deleted successfully", nil)
+}
+
+func (h *Handler) ApproveLoanApplication(c *fiber.Ctx) error {
+	return h.decideLoanApplication(c, h.loanApplicationUseCase.Approve, "Loan application approved successfully")
+}
+
+func (h *Handler) RejectLoanApplication(c *fiber.Ctx) error {
+	return h.decideLoanApplication(c, h.loanApplicationUseCase.Reject, "Loan application rejected successfully")
+}
+
+func (h *Handler) DisburseLoan(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan application ID")
+	}
+
+	var req application.DisburseLoanRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	loanApplication, schedule, err := h.loanApplicationUseCase.Disburse(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to disburse loan")
+	}
+
+	return utils.SendSuccess(c, "Loan disbursed successfully", fiber.Map{
+		"application": loanApplication,
+		"schedule":    schedule,
+	})
+}
+
+func (h *Handler) GetLoanPayments(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan application ID")
+	}
+
+	payments, err := h.loanApplicationUseCase.Payments(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get loan payments")
+	}
+
+	return utils.SendSuccess(c, "Loan payments retrieved successfully", payments)
+}
+
+func (h *Handler) RecordRepayment(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan application ID")
+	}
+
+	var req application.RecordRepaymentRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	loanApplication, payments, err := h.loanApplicationUseCase.RecordRepayment(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to record repayment")
+	}
+
+	return utils.SendSuccess(c, "Repayment recorded successfully", fiber.Map{
+		"application": loanApplication,
+		"payments":    payments,
+	})
+}
+
+// Guarantors
+
+func (h *Handler) GetLoanGuarantors(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan application ID")
+	}
+
+	guarantors, err := h.loanGuarantorUseCase.List(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get loan guarantors")
+	}
+
+	return utils.SendSuccess(c, "Loan guarantors retrieved successfully", guarantors)
+}
+
+func (h *Handler) AddLoanGuarantor(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan application ID")
+	}
+
+	var req application.LoanGuarantorRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	guarantor, err := h.loanGuarantorUseCase.Add(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to add loan guarantor")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Loan guarantor added successfully",
+		Data:    guarantor,
+	})
+}
+
+func (h *Handler) UpdateLoanGuarantor(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid guarantor ID")
+	}
+
+	var req application.LoanGuarantorRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	guarantor, err := h.loanGuarantorUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update loan guarantor")
+	}
+
+	return utils.SendSuccess(c, "Loan guarantor updated successfully", guarantor)
+}
+
+func (h *Handler) DecideLoanGuarantor(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid guarantor ID")
+	}
+
+	var req application.GuarantorDecisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	guarantor, err := h.loanGuarantorUseCase.Decide(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to record guarantor decision")
+	}
+
+	return utils.SendSuccess(c, "Guarantor decision recorded successfully", guarantor)
+}
+
+func (h *Handler) DeleteLoanGuarantor(c *fiber.Ctx) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid guarantor ID")
+	}
+
+	if err := h.loanGuarantorUseCase.Delete(c.Context(), id); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete loan guarantor")
+	}
+
+	return utils.SendSuccess(c, "Loan guarantor deleted successfully", nil)
+}
+
+func (h *Handler) decideLoanApplication(
+	c *fiber.Ctx,
+	decide func(context.Context, uuid.UUID, *application.LoanDecisionRequest, *application.Caller) (*domain.LoanApplication, error),
+	message string,
+) error {
+	id, err := uuidParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid loan application ID")
+	}
+
+	approverID, err := currentUserID(c)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req application.LoanDecisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+	req.ApproverID = approverID
+
+	loanApplication, err := decide(c.Context(), id, &req, currentCaller(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to process loan application")
+	}
+
+	return utils.SendSuccess(c, message, loanApplication)
+}
+
+// sendUseCaseError maps use case failures onto HTTP status codes
+func (h *Handler) sendUseCaseError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return utils.SendError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, application.ErrInvalidState):
+		return utils.SendError(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, application.ErrForbidden):
+		return utils.SendError(c, fiber.StatusForbidden, err.Error())
+	}
+
+	h.logger.Error(message, "error", err)
+	return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+}
+
+func uuidParam(c *fiber.Ctx, name string) (uuid.UUID, error) {
+	return uuid.Parse(c.Params(name))
+}
+
+// currentUserID returns the authenticated user set by the JWT middleware
+func currentUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	userID, _ := c.Locals("user_id").(string)
+	return uuid.Parse(userID)
+}
+
+// currentCaller returns the user the request is made by
+func currentCaller(c *fiber.Ctx) *application.Caller {
+	email, _ := c.Locals("email").(string)
+	caller := &application.Caller{
+		Email:        email,
+		ManagesLoans: middleware.HasRole(c, loanManagerRoles...),
+	}
+	if userID, err := currentUserID(c); err == nil {
+		caller.UserID = &userID
+	}
+	return caller
+}
@@ -0,0 +1,219 @@
+package http
+
+import (
+	"strconv"
+	"time"
+
+	"yathuerp/services/loan/internal/application"
+	"yathuerp/shared/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportHandler struct {
+	reportUseCase *application.LoanReportUseCase
+	logger        utils.Logger
+}
+
+func NewReportHandler(
+	reportUseCase *application.LoanReportUseCase,
+	logger utils.Logger,
+) *ReportHandler {
+	return &ReportHandler{
+		reportUseCase: reportUseCase,
+		logger:        logger,
+	}
+}
+
+// GetOutstandingBalances handles GET /reports/outstanding?group_by=loan_type|department&as_of=YYYY-MM-DD
+func (h *ReportHandler) GetOutstandingBalances(c *fiber.Ctx) error {
+	asOf, err := dateQuery(c, "as_of", time.Now())
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+	groupBy := c.Query("group_by", "loan_type")
+
+	balances, err := h.reportUseCase.OutstandingBalances(c.Context(), groupBy, asOf)
+	if err != nil {
+		h.logger.Error("Failed to build outstanding balances report", "error", err)
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if wantsCSV(c) {
+		records := make([][]string, 0, len(balances))
+		for _, b := range balances {
+			records = append(records, []string{
+				b.GroupName,
+				strconv.Itoa(b.ActiveLoans),
+				formatAmount(b.PrincipalIssued),
+				formatAmount(b.AmountRepaid),
+				formatAmount(b.OutstandingBalance),
+			})
+		}
+		return sendCSV(c, "loan-outstanding-"+groupBy+"-"+asOf.Format("2006-01-02")+".csv",
+			[]string{groupBy, "active_loans", "principal_issued", "amount_repaid", "outstanding_balance"},
+			records)
+	}
+
+	return utils.SendSuccess(c, "Outstanding balances retrieved successfully", balances)
+}
+
+// GetArrears handles GET /reports/arrears?as_of=YYYY-MM-DD
+func (h *ReportHandler) GetArrears(c *fiber.Ctx) error {
+	asOf, err := dateQuery(c, "as_of", time.Now())
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	report, err := h.reportUseCase.Arrears(c.Context(), asOf)
+	if err != nil {
+		h.logger.Error("Failed to build arrears report", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to build arrears report")
+	}
+
+	if wantsCSV(c) {
+		records := make([][]string, 0, len(report.Items))
+		for _, item := range report.Items {
+			records = append(records, []string{
+				item.EmployeeID.String(),
+				item.EmployeeName,
+				item.DepartmentName,
+				item.LoanApplicationID.String(),
+				item.LoanTypeName,
+				strconv.Itoa(item.PaymentNumber),
+				item.DueDate.Format("2006-01-02"),
+				strconv.Itoa(item.DaysOverdue),
+				formatAmount(item.AmountOutstanding),
+			})
+		}
+		return sendCSV(c, "loan-arrears-"+asOf.Format("2006-01-02")+".csv",
+			[]string{"employee_id", "employee_name", "department", "loan_application_id", "loan_type",
+				"payment_number", "due_date", "days_overdue", "amount_outstanding"},
+			records)
+	}
+
+	return utils.SendSuccess(c, "Arrears report retrieved successfully", report)
+}
+
+// GetDisbursements handles GET /reports/disbursements?from=YYYY-MM-DD&to=YYYY-MM-DD&period=month|quarter|year
+func (h *ReportHandler) GetDisbursements(c *fiber.Ctx) error {
+	now := time.Now()
+	from, err := dateQuery(c, "from", time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()))
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+	to, err := dateQuery(c, "to", now)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+	period := c.Query("period", "month")
+
+	summaries, err := h.reportUseCase.Disbursements(c.Context(), from, to, period)
+	if err != nil {
+		h.logger.Error("Failed to build disbursements report", "error", err)
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if wantsCSV(c) {
+		records := make([][]string, 0, len(summaries))
+		for _, s := range summaries {
+			records = append(records, []string{
+				s.PeriodStart.Format("2006-01-02"),
+				s.LoanTypeName,
+				strconv.Itoa(s.Loans),
+				formatAmount(s.Amount),
+			})
+		}
+		return sendCSV(c, "loan-disbursements-"+from.Format("2006-01-02")+"-"+to.Format("2006-01-02")+".csv",
+			[]string{"period_start", "loan_type", "loans", "amount"},
+			records)
+	}
+
+	return utils.SendSuccess(c, "Disbursements retrieved successfully", summaries)
+}
+
+// GetDefaulters handles GET /reports/defaulters?as_of=YYYY-MM-DD&min_days_overdue=90
+func (h *ReportHandler) GetDefaulters(c *fiber.Ctx) error {
+	asOf, err := dateQuery(c, "as_of", time.Now())
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+	minDays, _ := strconv.Atoi(c.Query("min_days_overdue", strconv.Itoa(application.DefaultDefaulterDays)))
+
+	defaulters, err := h.reportUseCase.Defaulters(c.Context(), asOf, minDays)
+	if err != nil {
+		h.logger.Error("Failed to build defaulters report", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to build defaulters report")
+	}
+
+	if wantsCSV(c) {
+		records := make([][]string, 0, len(defaulters))
+		for _, d := range defaulters {
+			oldestDue := ""
+			if d.OldestDueDate != nil {
+				oldestDue = d.OldestDueDate.Format("2006-01-02")
+			}
+			records = append(records, []string{
+				d.EmployeeID.String(),
+				d.EmployeeName,
+				d.DepartmentName,
+				d.LoanApplicationID.String(),
+				d.LoanTypeName,
+				d.LoanStatus,
+				formatAmount(d.Amount),
+				formatAmount(d.OutstandingBalance),
+				formatAmount(d.ArrearsAmount),
+				strconv.Itoa(d.MissedInstallments),
+				oldestDue,
+				strconv.Itoa(d.DaysOverdue),
+			})
+		}
+		return sendCSV(c, "loan-defaulters-"+asOf.Format("2006-01-02")+".csv",
+			[]string{"employee_id", "employee_name", "department", "loan_application_id", "loan_type", "loan_status",
+				"amount", "outstanding_balance", "arrears_amount", "missed_installments", "oldest_due_date", "days_overdue"},
+			records)
+	}
+
+	return utils.SendSuccess(c, "Defaulters retrieved successfully", defaulters)
+}
+
+// GetOverduePayments handles GET /payments/overdue
+func (h *ReportHandler) GetOverduePayments(c *fiber.Ctx) error {
+	payments, err := h.reportUseCase.OverduePayments(c.Context())
+	if err != nil {
+		h.logger.Error("Failed to get overdue payments", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get overdue payments")
+	}
+
+	return utils.SendSuccess(c, "Overdue payments retrieved successfully", payments)
+}
+
+// GetUpcomingPayments handles GET /payments/upcoming?days=30
+func (h *ReportHandler) GetUpcomingPayments(c *fiber.Ctx) error {
+	days, err := strconv.Atoi(c.Query("days", "30"))
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid days")
+	}
+
+	payments, err := h.reportUseCase.UpcomingPayments(c.Context(), days)
+	if err != nil {
+		h.logger.Error("Failed to get upcoming payments", "error", err)
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SendSuccess(c, "Upcoming payments retrieved successfully", payments)
+}
+
+// dateQuery parses a YYYY-MM-DD query parameter, falling back to def when absent
+func dateQuery(c *fiber.Ctx, key string, def time.Time) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return def, nil
+	}
+
+	t, err := utils.ParseTime(value)
+	if err != nil {
+		return time.Time{}, fiber.NewError(fiber.StatusBadRequest, "Invalid "+key+": expected YYYY-MM-DD")
+	}
+	return *t, nil
+}
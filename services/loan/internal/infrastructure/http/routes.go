@@ -0,0 +1,72 @@
+package http
+
+import (
+	"yathuerp/shared/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// loanManagerRoles run the loan book: they keep the loan types, decide and
+// disburse applications, collect repayments and read the reports
+var loanManagerRoles = []string{middleware.RoleHR, middleware.RoleFinance, middleware.RoleAdmin}
+
+func SetupRoutes(app *fiber.App, handler *Handler, reportHandler *ReportHandler, auth *middleware.AuthMiddleware) {
+	// API versioning
+	api := app.Group("/api/v1")
+	loans := api.Group("/loans")
+	manager := auth.RoleBasedAuth(loanManagerRoles...)
+
+	// Loan types
+	loanTypes := loans.Group("/types")
+	{
+		loanTypes.Get("/", handler.GetLoanTypes)
+		loanTypes.Post("/", manager, handler.CreateLoanType)
+		loanTypes.Get("/:id", handler.GetLoanTypeByID)
+		loanTypes.Put("/:id", manager, handler.UpdateLoanType)
+		loanTypes.Delete("/:id", manager, handler.DeleteLoanType)
+	}
+
+	// Loan applications
+	applications := loans.Group("/applications")
+	{
+		applications.Get("/", handler.GetLoanApplications)
+		applications.Post("/", handler.SubmitLoanApplication)
+		applications.Get("/pending", handler.GetPendingLoanApplications)
+		applications.Get("/:id", handler.GetLoanApplicationByID)
+		applications.Put("/:id", handler.UpdateLoanApplication)
+		applications.Delete("/:id", handler.DeleteLoanApplication)
+		applications.Post("/:id/approve", manager, handler.ApproveLoanApplication)
+		applications.Post("/:id/reject", manager, handler.RejectLoanApplication)
+		applications.Post("/:id/disburse", manager, handler.DisburseLoan)
+		applications.Get("/:id/payments", handler.GetLoanPayments)
+		applications.Post("/:id/payments", manager, handler.RecordRepayment)
+		applications.Get("/:id/guarantors", handler.GetLoanGuarantors)
+		applications.Post("/:id/guarantors", handler.AddLoanGuarantor)
+	}
+
+	// Guarantors
+	guarantors := loans.Group("/guarantors")
+	{
+		guarantors.Put("/:id", handler.UpdateLoanGuarantor)
+		guarantors.Delete("/:id", handler.DeleteLoanGuarantor)
+		guarantors.Post("/:id/decision", handler.DecideLoanGuarantor)
+	}
+
+	loans.Get("/employees/:employeeId/applications", handler.GetEmployeeLoanApplications)
+
+	// Loan book reporting
+	reports := loans.Group("/reports", manager)
+	{
+		reports.Get("/outstanding", reportHandler.GetOutstandingBalances)
+		reports.Get("/arrears", reportHandler.GetArrears)
+		reports.Get("/disbursements", reportHandler.GetDisbursements)
+		reports.Get("/defaulters", reportHandler.GetDefaulters)
+	}
+
+	// Installment schedules
+	payments := loans.Group("/payments", manager)
+	{
+		payments.Get("/overdue", reportHandler.GetOverduePayments)
+		payments.Get("/upcoming", reportHandler.GetUpcomingPayments)
+	}
+}
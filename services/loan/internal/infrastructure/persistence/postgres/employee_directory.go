@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// employeeDirectory reads the employees table owned by the employee service
+type employeeDirectory struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewEmployeeDirectory(db *pgxpool.Pool, logger utils.Logger) domain.EmployeeDirectory {
+	return &employeeDirectory{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *employeeDirectory) GetByEmail(email string) (uuid.UUID, error) {
+	query := `SELECT id FROM employees WHERE LOWER(email) = LOWER($1) AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var id uuid.UUID
+	if err := r.db.QueryRow(ctx, query, email).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, fmt.Errorf("employee with email %s: %w", email, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get employee by email", "error", err)
+		return uuid.Nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,371 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const loanApplicationColumns = `
+	id, employee_id, loan_type_id, amount, interest_rate, term_months,
+	monthly_payment, purpose, status, approver_id, approval_date,
+	disbursement_date, completion_date, approval_notes, attachments,
+	created_at, updated_at`
+
+type loanApplicationRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewLoanApplicationRepository(db *pgxpool.Pool, logger utils.Logger) domain.LoanApplicationRepository {
+	return &loanApplicationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *loanApplicationRepository) Create(application *domain.LoanApplication) error {
+	query := `
+		INSERT INTO loan_applications (` + loanApplicationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		application.ID,
+		application.EmployeeID,
+		application.LoanTypeID,
+		application.Amount,
+		application.InterestRate,
+		application.TermMonths,
+		application.MonthlyPayment,
+		application.Purpose,
+		application.Status,
+		application.ApproverID,
+		application.ApprovalDate,
+		application.DisbursementDate,
+		application.CompletionDate,
+		application.ApprovalNotes,
+		application.Attachments,
+		application.CreatedAt,
+		application.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create loan application", "error", err)
+		return fmt.Errorf("failed to create loan application: %w", err)
+	}
+
+	r.logger.Info("Loan application created successfully", "loan_application_id", application.ID)
+	return nil
+}
+
+func (r *loanApplicationRepository) GetByID(id uuid.UUID) (*domain.LoanApplication, error) {
+	query := `SELECT ` + loanApplicationColumns + ` FROM loan_applications WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	application, err := scanLoanApplication(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("loan application %s: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get loan application by ID", "error", err, "loan_application_id", id)
+		return nil, fmt.Errorf("failed to get loan application: %w", err)
+	}
+
+	return application, nil
+}
+
+func (r *loanApplicationRepository) GetByEmployeeID(employeeID uuid.UUID) ([]*domain.LoanApplication, error) {
+	query := `
+		SELECT ` + loanApplicationColumns + `
+		FROM loan_applications
+		WHERE employee_id = $1 AND deleted = false
+		ORDER BY created_at DESC`
+
+	return r.query(query, employeeID)
+}
+
+func (r *loanApplicationRepository) GetPendingApplications() ([]*domain.LoanApplication, error) {
+	query := `
+		SELECT ` + loanApplicationColumns + `
+		FROM loan_applications
+		WHERE status = 'pending' AND deleted = false
+		ORDER BY created_at`
+
+	return r.query(query)
+}
+
+func (r *loanApplicationRepository) GetAll(filter *domain.LoanApplicationFilter) ([]*domain.LoanApplication, error) {
+	query := `SELECT ` + loanApplicationColumns + ` FROM loan_applications WHERE deleted = false`
+
+	args := []interface{}{}
+	argIndex := 1
+
+	// Add filters
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(" AND employee_id = $%d", argIndex)
+		args = append(args, *filter.EmployeeID)
+		argIndex++
+	}
+
+	if filter.LoanTypeID != nil {
+		query += fmt.Sprintf(" AND loan_type_id = $%d", argIndex)
+		args = append(args, *filter.LoanTypeID)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	if filter.StartDate != nil {
+		query += fmt.Sprintf(" AND created_at >= $%d", argIndex)
+		args = append(args, *filter.StartDate)
+		argIndex++
+	}
+
+	if filter.EndDate != nil {
+		query += fmt.Sprintf(" AND created_at < $%d", argIndex)
+		args = append(args, *filter.EndDate)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	return r.query(query, args...)
+}
+
+func (r *loanApplicationRepository) Update(application *domain.LoanApplication) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	application.UpdatedAt = time.Now()
+	if _, err := updateLoanApplication(ctx, r.db, application, ""); err != nil {
+		r.logger.Error("Failed to update loan application", "error", err, "loan_application_id", application.ID)
+		return err
+	}
+
+	r.logger.Info("Loan application updated successfully", "loan_application_id", application.ID)
+	return nil
+}
+
+// Disburse saves the disbursed application and creates its repayment
+// schedule in one transaction. It fails if the application is no longer
+// approved, e.g. when it was disbursed concurrently.
+func (r *loanApplicationRepository) Disburse(application *domain.LoanApplication, schedule []*domain.LoanPayment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	application.UpdatedAt = time.Now()
+	tag, err := updateLoanApplication(ctx, tx, application, domain.LoanStatusApproved)
+	if err != nil {
+		r.logger.Error("Failed to disburse loan application", "error", err, "loan_application_id", application.ID)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("the loan application is no longer approved, reload and try again")
+	}
+
+	for _, payment := range schedule {
+		if err := insertLoanPayment(ctx, tx, payment); err != nil {
+			r.logger.Error("Failed to create repayment schedule", "error", err, "loan_application_id", application.ID)
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit loan disbursement: %w", err)
+	}
+
+	r.logger.Info("Loan application disbursed successfully", "loan_application_id", application.ID)
+	return nil
+}
+
+// RecordRepayment saves the installments a repayment was allocated to, the
+// allocations, and the application when it is settled, in one transaction.
+// It fails if any of the installments changed since they were read.
+func (r *loanApplicationRepository) RecordRepayment(application *domain.LoanApplication, payments []*domain.LoanPayment, repayments []*domain.LoanRepayment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	for _, payment := range payments {
+		readAt := payment.UpdatedAt
+		payment.UpdatedAt = now
+		tag, err := updateLoanPayment(ctx, tx, payment, &readAt)
+		if err != nil {
+			r.logger.Error("Failed to record loan repayment", "error", err, "payment_id", payment.ID)
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("the loan's installments have changed, reload and try again")
+		}
+	}
+	for _, repayment := range repayments {
+		if err := insertLoanRepayment(ctx, tx, repayment); err != nil {
+			r.logger.Error("Failed to record loan repayment", "error", err, "payment_id", repayment.LoanPaymentID)
+			return err
+		}
+	}
+
+	if application.Status == domain.LoanStatusPaid {
+		application.UpdatedAt = now
+		if _, err := updateLoanApplication(ctx, tx, application, ""); err != nil {
+			r.logger.Error("Failed to close loan application", "error", err, "loan_application_id", application.ID)
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit loan repayment: %w", err)
+	}
+
+	r.logger.Info("Loan repayment recorded successfully", "loan_application_id", application.ID)
+	return nil
+}
+
+func (r *loanApplicationRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE loan_applications SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete loan application", "error", err, "loan_application_id", id)
+		return fmt.Errorf("failed to delete loan application: %w", err)
+	}
+
+	r.logger.Info("Loan application deleted successfully", "loan_application_id", id)
+	return nil
+}
+
+// GetActiveLoans returns approved or disbursed loans that have not been repaid
+func (r *loanApplicationRepository) GetActiveLoans(employeeID uuid.UUID) ([]*domain.LoanApplication, error) {
+	query := `
+		SELECT ` + loanApplicationColumns + `
+		FROM loan_applications
+		WHERE employee_id = $1 AND status IN ('approved', 'disbursed') AND deleted = false
+		ORDER BY created_at DESC`
+
+	return r.query(query, employeeID)
+}
+
+func (r *loanApplicationRepository) query(query string, args ...interface{}) ([]*domain.LoanApplication, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query loan applications", "error", err)
+		return nil, fmt.Errorf("failed to query loan applications: %w", err)
+	}
+	defer rows.Close()
+
+	var applications []*domain.LoanApplication
+	for rows.Next() {
+		application, err := scanLoanApplication(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan loan application row", "error", err)
+			return nil, fmt.Errorf("failed to scan loan application: %w", err)
+		}
+		applications = append(applications, application)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning loan application rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return applications, nil
+}
+
+// updateLoanApplication saves the application. With fromStatus set, the row is
+// only updated while it is still in that status.
+func updateLoanApplication(ctx context.Context, db execer, application *domain.LoanApplication, fromStatus string) (pgconn.CommandTag, error) {
+	query := `
+		UPDATE loan_applications SET
+			loan_type_id = $2, amount = $3, interest_rate = $4, term_months = $5,
+			monthly_payment = $6, purpose = $7, status = $8, approver_id = $9,
+			approval_date = $10, disbursement_date = $11, completion_date = $12,
+			approval_notes = $13, attachments = $14, updated_at = $15
+		WHERE id = $1 AND deleted = false AND ($16::text = '' OR status = $16)`
+
+	tag, err := db.Exec(ctx, query,
+		application.ID,
+		application.LoanTypeID,
+		application.Amount,
+		application.InterestRate,
+		application.TermMonths,
+		application.MonthlyPayment,
+		application.Purpose,
+		application.Status,
+		application.ApproverID,
+		application.ApprovalDate,
+		application.DisbursementDate,
+		application.CompletionDate,
+		application.ApprovalNotes,
+		application.Attachments,
+		application.UpdatedAt,
+		fromStatus,
+	)
+	if err != nil {
+		return tag, fmt.Errorf("failed to update loan application: %w", err)
+	}
+	return tag, nil
+}
+
+func scanLoanApplication(row pgx.Row) (*domain.LoanApplication, error) {
+	application := &domain.LoanApplication{}
+	err := row.Scan(
+		&application.ID,
+		&application.EmployeeID,
+		&application.LoanTypeID,
+		&application.Amount,
+		&application.InterestRate,
+		&application.TermMonths,
+		&application.MonthlyPayment,
+		&application.Purpose,
+		&application.Status,
+		&application.ApproverID,
+		&application.ApprovalDate,
+		&application.DisbursementDate,
+		&application.CompletionDate,
+		&application.ApprovalNotes,
+		&application.Attachments,
+		&application.CreatedAt,
+		&application.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return application, nil
+}
@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const loanGuarantorColumns = `
+	id, loan_application_id, guarantor_name, guarantor_email, guarantor_phone,
+	guarantor_address, guarantor_id, relationship, is_approved, approval_date,
+	approval_notes, created_at, updated_at`
+
+type loanGuarantorRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewLoanGuarantorRepository(db *pgxpool.Pool, logger utils.Logger) domain.LoanGuarantorRepository {
+	return &loanGuarantorRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *loanGuarantorRepository) Create(guarantor *domain.LoanGuarantor) error {
+	query := `
+		INSERT INTO loan_guarantors (` + loanGuarantorColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		guarantor.ID,
+		guarantor.LoanApplicationID,
+		guarantor.GuarantorName,
+		guarantor.GuarantorEmail,
+		guarantor.GuarantorPhone,
+		guarantor.GuarantorAddress,
+		guarantor.GuarantorID,
+		guarantor.Relationship,
+		guarantor.IsApproved,
+		guarantor.ApprovalDate,
+		guarantor.ApprovalNotes,
+		guarantor.CreatedAt,
+		guarantor.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create loan guarantor", "error", err)
+		return fmt.Errorf("failed to create loan guarantor: %w", err)
+	}
+
+	return nil
+}
+
+func (r *loanGuarantorRepository) GetByID(id uuid.UUID) (*domain.LoanGuarantor, error) {
+	query := `SELECT ` + loanGuarantorColumns + ` FROM loan_guarantors WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	guarantor, err := scanLoanGuarantor(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("loan guarantor %s: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get loan guarantor by ID", "error", err, "guarantor_id", id)
+		return nil, fmt.Errorf("failed to get loan guarantor: %w", err)
+	}
+
+	return guarantor, nil
+}
+
+func (r *loanGuarantorRepository) GetByLoanApplicationID(loanApplicationID uuid.UUID) ([]*domain.LoanGuarantor, error) {
+	query := `
+		SELECT ` + loanGuarantorColumns + `
+		FROM loan_guarantors
+		WHERE loan_application_id = $1 AND deleted = false
+		ORDER BY created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, loanApplicationID)
+	if err != nil {
+		r.logger.Error("Failed to query loan guarantors", "error", err, "loan_application_id", loanApplicationID)
+		return nil, fmt.Errorf("failed to query loan guarantors: %w", err)
+	}
+	defer rows.Close()
+
+	var guarantors []*domain.LoanGuarantor
+	for rows.Next() {
+		guarantor, err := scanLoanGuarantor(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan loan guarantor row", "error", err)
+			return nil, fmt.Errorf("failed to scan loan guarantor: %w", err)
+		}
+		guarantors = append(guarantors, guarantor)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning loan guarantor rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return guarantors, nil
+}
+
+func (r *loanGuarantorRepository) Update(guarantor *domain.LoanGuarantor) error {
+	query := `
+		UPDATE loan_guarantors SET
+			guarantor_name = $2, guarantor_email = $3, guarantor_phone = $4,
+			guarantor_address = $5, guarantor_id = $6, relationship = $7,
+			is_approved = $8, approval_date = $9, approval_notes = $10, updated_at = $11
+		WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	guarantor.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		guarantor.ID,
+		guarantor.GuarantorName,
+		guarantor.GuarantorEmail,
+		guarantor.GuarantorPhone,
+		guarantor.GuarantorAddress,
+		guarantor.GuarantorID,
+		guarantor.Relationship,
+		guarantor.IsApproved,
+		guarantor.ApprovalDate,
+		guarantor.ApprovalNotes,
+		guarantor.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update loan guarantor", "error", err, "guarantor_id", guarantor.ID)
+		return fmt.Errorf("failed to update loan guarantor: %w", err)
+	}
+
+	return nil
+}
+
+func (r *loanGuarantorRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE loan_guarantors SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete loan guarantor", "error", err, "guarantor_id", id)
+		return fmt.Errorf("failed to delete loan guarantor: %w", err)
+	}
+
+	return nil
+}
+
+func scanLoanGuarantor(row pgx.Row) (*domain.LoanGuarantor, error) {
+	guarantor := &domain.LoanGuarantor{}
+	err := row.Scan(
+		&guarantor.ID,
+		&guarantor.LoanApplicationID,
+		&guarantor.GuarantorName,
+		&guarantor.GuarantorEmail,
+		&guarantor.GuarantorPhone,
+		&guarantor.GuarantorAddress,
+		&guarantor.GuarantorID,
+		&guarantor.Relationship,
+		&guarantor.IsApproved,
+		&guarantor.ApprovalDate,
+		&guarantor.ApprovalNotes,
+		&guarantor.CreatedAt,
+		&guarantor.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return guarantor, nil
+}
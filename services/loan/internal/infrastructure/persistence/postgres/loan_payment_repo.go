@@ -0,0 +1,270 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const loanPaymentColumns = `
+	id, loan_application_id, payment_number, due_date, payment_date,
+	amount_due, amount_paid, interest_amount, principal_amount, balance_amount,
+	status, payment_method, reference_number, notes, created_at, updated_at`
+
+type loanPaymentRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewLoanPaymentRepository(db *pgxpool.Pool, logger utils.Logger) domain.LoanPaymentRepository {
+	return &loanPaymentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *loanPaymentRepository) Create(payment *domain.LoanPayment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := insertLoanPayment(ctx, r.db, payment); err != nil {
+		r.logger.Error("Failed to create loan payment", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *loanPaymentRepository) GetByID(id uuid.UUID) (*domain.LoanPayment, error) {
+	query := `SELECT ` + loanPaymentColumns + ` FROM loan_payments WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	payment, err := scanLoanPayment(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("loan payment %s: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get loan payment by ID", "error", err, "payment_id", id)
+		return nil, fmt.Errorf("failed to get loan payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+func (r *loanPaymentRepository) GetByLoanApplicationID(loanApplicationID uuid.UUID) ([]*domain.LoanPayment, error) {
+	query := `
+		SELECT ` + loanPaymentColumns + `
+		FROM loan_payments
+		WHERE loan_application_id = $1 AND deleted = false
+		ORDER BY payment_number`
+
+	return r.query(query, loanApplicationID)
+}
+
+func (r *loanPaymentRepository) Update(payment *domain.LoanPayment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	payment.UpdatedAt = time.Now()
+	if _, err := updateLoanPayment(ctx, r.db, payment, nil); err != nil {
+		r.logger.Error("Failed to update loan payment", "error", err, "payment_id", payment.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (r *loanPaymentRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE loan_payments SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete loan payment", "error", err, "payment_id", id)
+		return fmt.Errorf("failed to delete loan payment: %w", err)
+	}
+
+	return nil
+}
+
+// GetOverduePayments returns unpaid installments whose due date has passed on
+// loans that are still being repaid
+func (r *loanPaymentRepository) GetOverduePayments() ([]*domain.LoanPayment, error) {
+	query := `
+		SELECT ` + prefixColumns("lp", loanPaymentColumns) + `
+		FROM loan_payments lp
+		JOIN loan_applications la ON la.id = lp.loan_application_id AND la.deleted = false
+		WHERE lp.deleted = false
+			AND lp.amount_paid < lp.amount_due
+			AND lp.due_date < CURRENT_DATE
+			AND la.status IN ('disbursed', 'defaulted')
+		ORDER BY lp.due_date`
+
+	return r.query(query)
+}
+
+// GetUpcomingPayments returns unpaid installments falling due within the next
+// given number of days
+func (r *loanPaymentRepository) GetUpcomingPayments(days int) ([]*domain.LoanPayment, error) {
+	query := `
+		SELECT ` + prefixColumns("lp", loanPaymentColumns) + `
+		FROM loan_payments lp
+		JOIN loan_applications la ON la.id = lp.loan_application_id AND la.deleted = false
+		WHERE lp.deleted = false
+			AND lp.amount_paid < lp.amount_due
+			AND lp.due_date >= CURRENT_DATE
+			AND lp.due_date <= CURRENT_DATE + $1::int
+			AND la.status IN ('disbursed', 'defaulted')
+		ORDER BY lp.due_date`
+
+	return r.query(query, days)
+}
+
+func (r *loanPaymentRepository) query(query string, args ...interface{}) ([]*domain.LoanPayment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query loan payments", "error", err)
+		return nil, fmt.Errorf("failed to query loan payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*domain.LoanPayment
+	for rows.Next() {
+		payment, err := scanLoanPayment(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan loan payment row", "error", err)
+			return nil, fmt.Errorf("failed to scan loan payment: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning loan payment rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return payments, nil
+}
+
+func insertLoanPayment(ctx context.Context, db execer, payment *domain.LoanPayment) error {
+	query := `
+		INSERT INTO loan_payments (` + loanPaymentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+
+	_, err := db.Exec(ctx, query,
+		payment.ID,
+		payment.LoanApplicationID,
+		payment.PaymentNumber,
+		payment.DueDate,
+		payment.PaymentDate,
+		payment.AmountDue,
+		payment.AmountPaid,
+		payment.InterestAmount,
+		payment.PrincipalAmount,
+		payment.BalanceAmount,
+		payment.Status,
+		payment.PaymentMethod,
+		payment.ReferenceNumber,
+		payment.Notes,
+		payment.CreatedAt,
+		payment.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create loan payment: %w", err)
+	}
+	return nil
+}
+
+func insertLoanRepayment(ctx context.Context, db execer, repayment *domain.LoanRepayment) error {
+	query := `
+		INSERT INTO loan_repayments (id, loan_payment_id, amount, paid_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := db.Exec(ctx, query,
+		repayment.ID,
+		repayment.LoanPaymentID,
+		repayment.Amount,
+		repayment.PaidAt,
+		repayment.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create loan repayment: %w", err)
+	}
+	return nil
+}
+
+// updateLoanPayment saves the payment. With readAt set, the row is only
+// updated if it has not changed since it was read at that time.
+func updateLoanPayment(ctx context.Context, db execer, payment *domain.LoanPayment, readAt *time.Time) (pgconn.CommandTag, error) {
+	query := `
+		UPDATE loan_payments SET
+			payment_number = $2, due_date = $3, payment_date = $4,
+			amount_due = $5, amount_paid = $6, interest_amount = $7,
+			principal_amount = $8, balance_amount = $9, status = $10,
+			payment_method = $11, reference_number = $12, notes = $13,
+			updated_at = $14
+		WHERE id = $1 AND deleted = false AND ($15::timestamptz IS NULL OR updated_at = $15)`
+
+	tag, err := db.Exec(ctx, query,
+		payment.ID,
+		payment.PaymentNumber,
+		payment.DueDate,
+		payment.PaymentDate,
+		payment.AmountDue,
+		payment.AmountPaid,
+		payment.InterestAmount,
+		payment.PrincipalAmount,
+		payment.BalanceAmount,
+		payment.Status,
+		payment.PaymentMethod,
+		payment.ReferenceNumber,
+		payment.Notes,
+		payment.UpdatedAt,
+		readAt,
+	)
+	if err != nil {
+		return tag, fmt.Errorf("failed to update loan payment: %w", err)
+	}
+	return tag, nil
+}
+
+func scanLoanPayment(row pgx.Row) (*domain.LoanPayment, error) {
+	payment := &domain.LoanPayment{}
+	err := row.Scan(
+		&payment.ID,
+		&payment.LoanApplicationID,
+		&payment.PaymentNumber,
+		&payment.DueDate,
+		&payment.PaymentDate,
+		&payment.AmountDue,
+		&payment.AmountPaid,
+		&payment.InterestAmount,
+		&payment.PrincipalAmount,
+		&payment.BalanceAmount,
+		&payment.Status,
+		&payment.PaymentMethod,
+		&payment.ReferenceNumber,
+		&payment.Notes,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
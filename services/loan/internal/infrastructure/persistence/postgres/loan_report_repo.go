@@ -0,0 +1,293 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// installmentsCTE lists every installment with the repayments made against
+// it up to the as-of time ($1), so all the reports age the book the same way
+const installmentsCTE = `
+	WITH installments AS (
+		SELECT
+			lp.id, lp.loan_application_id, lp.payment_number, lp.due_date, lp.amount_due,
+			COALESCE(SUM(r.amount) FILTER (WHERE r.paid_at <= $1), 0) AS amount_paid
+		FROM loan_payments lp
+		LEFT JOIN loan_repayments r ON r.loan_payment_id = lp.id
+		WHERE lp.deleted = false
+		GROUP BY lp.id
+	)`
+
+// loanBalancesCTE computes, per disbursed loan, the total scheduled and the
+// amount repaid up to the as-of time ($1)
+const loanBalancesCTE = installmentsCTE + `,
+	loan_balances AS (
+		SELECT
+			la.id, la.employee_id, la.loan_type_id, la.amount,
+			COALESCE(SUM(lp.amount_due), 0) AS total_due,
+			COALESCE(SUM(lp.amount_paid), 0) AS total_paid
+		FROM loan_applications la
+		LEFT JOIN installments lp ON lp.loan_application_id = la.id
+		WHERE la.deleted = false
+			AND la.status IN ('disbursed', 'paid', 'defaulted')
+			AND la.disbursement_date <= $1
+		GROUP BY la.id
+	)`
+
+type loanReportRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewLoanReportRepository(db *pgxpool.Pool, logger utils.Logger) domain.LoanReportRepository {
+	return &loanReportRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *loanReportRepository) GetOutstandingByLoanType(asOf time.Time) ([]*domain.OutstandingBalance, error) {
+	query := loanBalancesCTE + `
+		SELECT
+			lt.id, lt.name,
+			COUNT(*) FILTER (WHERE lb.total_due - lb.total_paid > 0),
+			COALESCE(SUM(lb.amount), 0),
+			COALESCE(SUM(lb.total_paid), 0),
+			COALESCE(SUM(lb.total_due - lb.total_paid), 0)
+		FROM loan_balances lb
+		JOIN loan_types lt ON lt.id = lb.loan_type_id
+		GROUP BY lt.id, lt.name
+		ORDER BY lt.name`
+
+	return r.queryOutstanding(query, asOf)
+}
+
+func (r *loanReportRepository) GetOutstandingByDepartment(asOf time.Time) ([]*domain.OutstandingBalance, error) {
+	query := loanBalancesCTE + `
+		SELECT
+			d.id, COALESCE(d.name, 'Unassigned'),
+			COUNT(*) FILTER (WHERE lb.total_due - lb.total_paid > 0),
+			COALESCE(SUM(lb.amount), 0),
+			COALESCE(SUM(lb.total_paid), 0),
+			COALESCE(SUM(lb.total_due - lb.total_paid), 0)
+		FROM loan_balances lb
+		LEFT JOIN employees e ON e.id = lb.employee_id
+		LEFT JOIN departments d ON d.id = e.department_id
+		GROUP BY d.id, d.name
+		ORDER BY COALESCE(d.name, 'Unassigned')`
+
+	return r.queryOutstanding(query, asOf)
+}
+
+// GetArrears lists the installments overdue at asOf, counting only what had
+// been repaid by then
+func (r *loanReportRepository) GetArrears(asOf time.Time) ([]*domain.ArrearsItem, error) {
+	query := installmentsCTE + `
+		SELECT
+			lp.id, lp.loan_application_id, la.employee_id,
+			COALESCE(e.first_name || ' ' || e.last_name, ''),
+			COALESCE(d.name, 'Unassigned'),
+			lt.name, lp.payment_number, lp.due_date,
+			lp.amount_due - lp.amount_paid,
+			$1::date - lp.due_date::date
+		FROM installments lp
+		JOIN loan_applications la ON la.id = lp.loan_application_id AND la.deleted = false
+		JOIN loan_types lt ON lt.id = la.loan_type_id
+		LEFT JOIN employees e ON e.id = la.employee_id
+		LEFT JOIN departments d ON d.id = e.department_id
+		WHERE lp.amount_paid < lp.amount_due
+			AND lp.due_date < $1::date
+			AND la.status IN ('disbursed', 'paid', 'defaulted')
+			AND la.disbursement_date <= $1
+		ORDER BY lp.due_date, la.employee_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, asOf)
+	if err != nil {
+		r.logger.Error("Failed to query loan arrears", "error", err)
+		return nil, fmt.Errorf("failed to query loan arrears: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*domain.ArrearsItem
+	for rows.Next() {
+		item := &domain.ArrearsItem{}
+		if err := rows.Scan(
+			&item.PaymentID,
+			&item.LoanApplicationID,
+			&item.EmployeeID,
+			&item.EmployeeName,
+			&item.DepartmentName,
+			&item.LoanTypeName,
+			&item.PaymentNumber,
+			&item.DueDate,
+			&item.AmountOutstanding,
+			&item.DaysOverdue,
+		); err != nil {
+			r.logger.Error("Failed to scan arrears row", "error", err)
+			return nil, fmt.Errorf("failed to scan arrears row: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *loanReportRepository) GetDisbursements(startDate, endDate time.Time, period string) ([]*domain.DisbursementSummary, error) {
+	query := `
+		SELECT
+			date_trunc($3, la.disbursement_date) AS period_start,
+			lt.id, lt.name, COUNT(*), COALESCE(SUM(la.amount), 0)
+		FROM loan_applications la
+		JOIN loan_types lt ON lt.id = la.loan_type_id
+		WHERE la.deleted = false
+			AND la.disbursement_date >= $1
+			AND la.disbursement_date < $2
+		GROUP BY period_start, lt.id, lt.name
+		ORDER BY period_start, lt.name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, startDate, endDate, period)
+	if err != nil {
+		r.logger.Error("Failed to query loan disbursements", "error", err)
+		return nil, fmt.Errorf("failed to query loan disbursements: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*domain.DisbursementSummary
+	for rows.Next() {
+		summary := &domain.DisbursementSummary{}
+		if err := rows.Scan(
+			&summary.PeriodStart,
+			&summary.LoanTypeID,
+			&summary.LoanTypeName,
+			&summary.Loans,
+			&summary.Amount,
+		); err != nil {
+			r.logger.Error("Failed to scan disbursement row", "error", err)
+			return nil, fmt.Errorf("failed to scan disbursement row: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// GetDefaulters lists the loans that were defaulted or overdue by at least
+// minDaysOverdue at asOf, counting only what had been repaid by then
+func (r *loanReportRepository) GetDefaulters(asOf time.Time, minDaysOverdue int) ([]*domain.Defaulter, error) {
+	query := installmentsCTE + `
+		SELECT
+			la.id, la.employee_id,
+			COALESCE(e.first_name || ' ' || e.last_name, ''),
+			COALESCE(d.name, 'Unassigned'),
+			lt.name, la.status, la.amount,
+			COALESCE(SUM(lp.amount_due - lp.amount_paid), 0),
+			COALESCE(SUM(lp.amount_due - lp.amount_paid) FILTER (WHERE lp.due_date < $1::date), 0),
+			COUNT(lp.id) FILTER (WHERE lp.due_date < $1::date),
+			MIN(lp.due_date) FILTER (WHERE lp.due_date < $1::date)
+		FROM loan_applications la
+		JOIN loan_types lt ON lt.id = la.loan_type_id
+		LEFT JOIN installments lp ON lp.loan_application_id = la.id
+			AND lp.amount_paid < lp.amount_due
+		LEFT JOIN employees e ON e.id = la.employee_id
+		LEFT JOIN departments d ON d.id = e.department_id
+		WHERE la.deleted = false
+			AND la.status IN ('disbursed', 'paid', 'defaulted')
+			AND la.disbursement_date <= $1
+		GROUP BY la.id, e.first_name, e.last_name, d.name, lt.name
+		HAVING la.status = 'defaulted'
+			OR MIN(lp.due_date) FILTER (WHERE lp.due_date < $1::date) <= $1::date - $2::int
+		ORDER BY 9 DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, asOf, minDaysOverdue)
+	if err != nil {
+		r.logger.Error("Failed to query loan defaulters", "error", err)
+		return nil, fmt.Errorf("failed to query loan defaulters: %w", err)
+	}
+	defer rows.Close()
+
+	var defaulters []*domain.Defaulter
+	for rows.Next() {
+		defaulter := &domain.Defaulter{}
+		if err := rows.Scan(
+			&defaulter.LoanApplicationID,
+			&defaulter.EmployeeID,
+			&defaulter.EmployeeName,
+			&defaulter.DepartmentName,
+			&defaulter.LoanTypeName,
+			&defaulter.LoanStatus,
+			&defaulter.Amount,
+			&defaulter.OutstandingBalance,
+			&defaulter.ArrearsAmount,
+			&defaulter.MissedInstallments,
+			&defaulter.OldestDueDate,
+		); err != nil {
+			r.logger.Error("Failed to scan defaulter row", "error", err)
+			return nil, fmt.Errorf("failed to scan defaulter row: %w", err)
+		}
+		defaulters = append(defaulters, defaulter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return defaulters, nil
+}
+
+func (r *loanReportRepository) queryOutstanding(query string, asOf time.Time) ([]*domain.OutstandingBalance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, asOf)
+	if err != nil {
+		r.logger.Error("Failed to query outstanding loan balances", "error", err)
+		return nil, fmt.Errorf("failed to query outstanding balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []*domain.OutstandingBalance
+	for rows.Next() {
+		balance := &domain.OutstandingBalance{}
+		if err := rows.Scan(
+			&balance.GroupID,
+			&balance.GroupName,
+			&balance.ActiveLoans,
+			&balance.PrincipalIssued,
+			&balance.AmountRepaid,
+			&balance.OutstandingBalance,
+		); err != nil {
+			r.logger.Error("Failed to scan outstanding balance row", "error", err)
+			return nil, fmt.Errorf("failed to scan outstanding balance row: %w", err)
+		}
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return balances, nil
+}
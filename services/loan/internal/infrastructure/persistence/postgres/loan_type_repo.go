@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/loan/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const loanTypeColumns = `
+	id, name, code, description, min_amount, max_amount, default_interest_rate,
+	min_term_months, max_term_months, requires_guarantor, max_active_loans,
+	eligibility_criteria, is_active, created_at, updated_at`
+
+type loanTypeRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewLoanTypeRepository(db *pgxpool.Pool, logger utils.Logger) domain.LoanTypeRepository {
+	return &loanTypeRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *loanTypeRepository) Create(loanType *domain.LoanType) error {
+	query := `
+		INSERT INTO loan_types (` + loanTypeColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.db.Exec(ctx, query,
+		loanType.ID,
+		loanType.Name,
+		loanType.Code,
+		loanType.Description,
+		loanType.MinAmount,
+		loanType.MaxAmount,
+		loanType.DefaultInterestRate,
+		loanType.MinTermMonths,
+		loanType.MaxTermMonths,
+		loanType.RequiresGuarantor,
+		loanType.MaxActiveLoans,
+		loanType.EligibilityCriteria,
+		loanType.IsActive,
+		loanType.CreatedAt,
+		loanType.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create loan type", "error", err)
+		return fmt.Errorf("failed to create loan type: %w", err)
+	}
+
+	r.logger.Info("Loan type created successfully", "loan_type_id", loanType.ID)
+	return nil
+}
+
+func (r *loanTypeRepository) GetByID(id uuid.UUID) (*domain.LoanType, error) {
+	query := `SELECT ` + loanTypeColumns + ` FROM loan_types WHERE id = $1 AND deleted = false`
+
+	return r.get(query, id)
+}
+
+func (r *loanTypeRepository) GetByCode(code string) (*domain.LoanType, error) {
+	query := `SELECT ` + loanTypeColumns + ` FROM loan_types WHERE code = $1 AND deleted = false`
+
+	return r.get(query, code)
+}
+
+func (r *loanTypeRepository) GetAll() ([]*domain.LoanType, error) {
+	query := `SELECT ` + loanTypeColumns + ` FROM loan_types WHERE deleted = false ORDER BY name`
+
+	return r.query(query)
+}
+
+func (r *loanTypeRepository) GetActive() ([]*domain.LoanType, error) {
+	query := `SELECT ` + loanTypeColumns + ` FROM loan_types WHERE is_active = true AND deleted = false ORDER BY name`
+
+	return r.query(query)
+}
+
+func (r *loanTypeRepository) Update(loanType *domain.LoanType) error {
+	query := `
+		UPDATE loan_types SET
+			name = $2, code = $3, description = $4, min_amount = $5, max_amount = $6,
+			default_interest_rate = $7, min_term_months = $8, max_term_months = $9,
+			requires_guarantor = $10, max_active_loans = $11, eligibility_criteria = $12,
+			is_active = $13, updated_at = $14
+		WHERE id = $1 AND deleted = false`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	loanType.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		loanType.ID,
+		loanType.Name,
+		loanType.Code,
+		loanType.Description,
+		loanType.MinAmount,
+		loanType.MaxAmount,
+		loanType.DefaultInterestRate,
+		loanType.MinTermMonths,
+		loanType.MaxTermMonths,
+		loanType.RequiresGuarantor,
+		loanType.MaxActiveLoans,
+		loanType.EligibilityCriteria,
+		loanType.IsActive,
+		loanType.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update loan type", "error", err, "loan_type_id", loanType.ID)
+		return fmt.Errorf("failed to update loan type: %w", err)
+	}
+
+	r.logger.Info("Loan type updated successfully", "loan_type_id", loanType.ID)
+	return nil
+}
+
+func (r *loanTypeRepository) Delete(id uuid.UUID) error {
+	query := `UPDATE loan_types SET deleted = true, updated_at = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, time.Now(), id); err != nil {
+		r.logger.Error("Failed to delete loan type", "error", err, "loan_type_id", id)
+		return fmt.Errorf("failed to delete loan type: %w", err)
+	}
+
+	r.logger.Info("Loan type deleted successfully", "loan_type_id", id)
+	return nil
+}
+
+func (r *loanTypeRepository) get(query string, arg interface{}) (*domain.LoanType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	loanType, err := scanLoanType(r.db.QueryRow(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("loan type %v: %w", arg, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get loan type", "error", err, "key", arg)
+		return nil, fmt.Errorf("failed to get loan type: %w", err)
+	}
+
+	return loanType, nil
+}
+
+func (r *loanTypeRepository) query(query string, args ...interface{}) ([]*domain.LoanType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query loan types", "error", err)
+		return nil, fmt.Errorf("failed to query loan types: %w", err)
+	}
+	defer rows.Close()
+
+	var loanTypes []*domain.LoanType
+	for rows.Next() {
+		loanType, err := scanLoanType(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan loan type row", "error", err)
+			return nil, fmt.Errorf("failed to scan loan type: %w", err)
+		}
+		loanTypes = append(loanTypes, loanType)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning loan type rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return loanTypes, nil
+}
+
+func scanLoanType(row pgx.Row) (*domain.LoanType, error) {
+	loanType := &domain.LoanType{}
+	err := row.Scan(
+		&loanType.ID,
+		&loanType.Name,
+		&loanType.Code,
+		&loanType.Description,
+		&loanType.MinAmount,
+		&loanType.MaxAmount,
+		&loanType.DefaultInterestRate,
+		&loanType.MinTermMonths,
+		&loanType.MaxTermMonths,
+		&loanType.RequiresGuarantor,
+		&loanType.MaxActiveLoans,
+		&loanType.EligibilityCriteria,
+		&loanType.IsActive,
+		&loanType.CreatedAt,
+		&loanType.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return loanType, nil
+}
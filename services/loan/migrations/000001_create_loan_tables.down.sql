@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS loan_guarantors;
+DROP TABLE IF EXISTS loan_payments;
+DROP TABLE IF EXISTS loan_applications;
+DROP TABLE IF EXISTS loan_types;
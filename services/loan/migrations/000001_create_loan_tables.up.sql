@@ -0,0 +1,87 @@
+CREATE TABLE IF NOT EXISTS loan_types (
+    id                    UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                  VARCHAR(255) NOT NULL,
+    code                  VARCHAR(50) NOT NULL,
+    description           TEXT NOT NULL DEFAULT '',
+    min_amount            NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    max_amount            NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    default_interest_rate NUMERIC(7, 4) NOT NULL DEFAULT 0,
+    min_term_months       INTEGER NOT NULL DEFAULT 1,
+    max_term_months       INTEGER NOT NULL DEFAULT 12,
+    requires_guarantor    BOOLEAN NOT NULL DEFAULT false,
+    max_active_loans      INTEGER NOT NULL DEFAULT 0,
+    eligibility_criteria  TEXT NOT NULL DEFAULT '',
+    is_active             BOOLEAN NOT NULL DEFAULT true,
+    deleted               BOOLEAN NOT NULL DEFAULT false,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loan_types_code ON loan_types (code) WHERE deleted = false;
+
+CREATE TABLE IF NOT EXISTS loan_applications (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id       UUID NOT NULL,
+    loan_type_id      UUID NOT NULL REFERENCES loan_types (id),
+    amount            NUMERIC(15, 2) NOT NULL,
+    interest_rate     NUMERIC(7, 4) NOT NULL DEFAULT 0,
+    term_months       INTEGER NOT NULL,
+    monthly_payment   NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    purpose           TEXT NOT NULL DEFAULT '',
+    status            VARCHAR(20) NOT NULL DEFAULT 'pending',
+    approver_id       UUID,
+    approval_date     TIMESTAMPTZ,
+    disbursement_date TIMESTAMPTZ,
+    completion_date   TIMESTAMPTZ,
+    approval_notes    TEXT NOT NULL DEFAULT '',
+    attachments       TEXT NOT NULL DEFAULT '[]',
+    deleted           BOOLEAN NOT NULL DEFAULT false,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_loan_applications_employee ON loan_applications (employee_id);
+CREATE INDEX IF NOT EXISTS idx_loan_applications_status ON loan_applications (status);
+
+CREATE TABLE IF NOT EXISTS loan_payments (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    loan_application_id UUID NOT NULL REFERENCES loan_applications (id),
+    payment_number      INTEGER NOT NULL,
+    due_date            DATE NOT NULL,
+    payment_date        TIMESTAMPTZ,
+    amount_due          NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    amount_paid         NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    interest_amount     NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    principal_amount    NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    balance_amount      NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    status              VARCHAR(20) NOT NULL DEFAULT 'pending',
+    payment_method      VARCHAR(50) NOT NULL DEFAULT '',
+    reference_number    VARCHAR(100) NOT NULL DEFAULT '',
+    notes               TEXT NOT NULL DEFAULT '',
+    deleted             BOOLEAN NOT NULL DEFAULT false,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loan_payments_schedule
+    ON loan_payments (loan_application_id, payment_number) WHERE deleted = false;
+CREATE INDEX IF NOT EXISTS idx_loan_payments_due_date ON loan_payments (due_date);
+
+CREATE TABLE IF NOT EXISTS loan_guarantors (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    loan_application_id UUID NOT NULL REFERENCES loan_applications (id),
+    guarantor_name      VARCHAR(255) NOT NULL,
+    guarantor_email     VARCHAR(255) NOT NULL DEFAULT '',
+    guarantor_phone     VARCHAR(50) NOT NULL DEFAULT '',
+    guarantor_address   TEXT NOT NULL DEFAULT '',
+    guarantor_id        UUID,
+    relationship        VARCHAR(100) NOT NULL DEFAULT '',
+    is_approved         BOOLEAN NOT NULL DEFAULT false,
+    approval_date       TIMESTAMPTZ,
+    approval_notes      TEXT NOT NULL DEFAULT '',
+    deleted             BOOLEAN NOT NULL DEFAULT false,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_loan_guarantors_application ON loan_guarantors (loan_application_id);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS loan_repayments (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    loan_payment_id UUID NOT NULL REFERENCES loan_payments (id),
+    amount          NUMERIC(15, 2) NOT NULL,
+    paid_at         TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_loan_repayments_payment ON loan_repayments (loan_payment_id, paid_at);
+
+-- installments paid before the ledger existed are carried over as one
+-- repayment on their last payment date
+INSERT INTO loan_repayments (loan_payment_id, amount, paid_at)
+SELECT id, amount_paid, COALESCE(payment_date, updated_at)
+FROM loan_payments
+WHERE amount_paid > 0 AND deleted = false;
@@ -13,9 +13,14 @@ import (
 	"yathuerp/shared/logger"
 	"yathuerp/shared/middleware"
 
+	"yathuerp/services/performance/internal/application"
+	"yathuerp/services/performance/internal/infrastructure/http"
+	"yathuerp/services/performance/internal/infrastructure/mail"
+	"yathuerp/services/performance/internal/infrastructure/persistence/postgres"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	fiberlogger "github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
@@ -46,7 +51,7 @@ func main() {
 
 	// Global middleware
 	app.Use(recover.New())
-	app.Use(logger.New())
+	app.Use(fiberlogger.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowMethods: "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
@@ -66,7 +71,62 @@ func main() {
 	})
 
 	// Setup routes
-	setupRoutes(app, db)
+	pool := db.GetPool()
+	serviceLogger := logger.ServiceLogger{}
+
+	cycleRepo := postgres.NewCycleRepository(pool, serviceLogger)
+	appraisalRepo := postgres.NewAppraisalRepository(pool, serviceLogger)
+	orgDirectory := postgres.NewOrgDirectory(pool, serviceLogger)
+	goalRepo := postgres.NewGoalRepository(pool, serviceLogger)
+	competencyRepo := postgres.NewCompetencyRepository(pool, serviceLogger)
+	competencyRatingRepo := postgres.NewCompetencyRatingRepository(pool, serviceLogger)
+	ratingScaleRepo := postgres.NewRatingScaleRepository(pool, serviceLogger)
+	kpiRepo := postgres.NewKPIRepository(pool, serviceLogger)
+	kpiMeasurementRepo := postgres.NewKPIMeasurementRepository(pool, serviceLogger)
+	nominationRepo := postgres.NewFeedbackNominationRepository(pool, serviceLogger)
+	feedbackResponseRepo := postgres.NewFeedbackResponseRepository(pool, serviceLogger)
+	calibrationSessionRepo := postgres.NewCalibrationSessionRepository(pool, serviceLogger)
+	calibrationAdjustmentRepo := postgres.NewCalibrationAdjustmentRepository(pool, serviceLogger)
+	pipRepo := postgres.NewPIPRepository(pool, serviceLogger)
+	pipCheckInRepo := postgres.NewPIPCheckInRepository(pool, serviceLogger)
+	trainingRepo := postgres.NewTrainingRepository(pool, serviceLogger)
+	trainingEnrolmentRepo := postgres.NewTrainingEnrolmentRepository(pool, serviceLogger)
+	trainingBudgetRepo := postgres.NewTrainingBudgetRepository(pool, serviceLogger)
+
+	cycleUseCase := application.NewCycleUseCase(cycleRepo, appraisalRepo, orgDirectory, serviceLogger)
+	goalUseCase := application.NewGoalUseCase(goalRepo, cycleRepo, kpiRepo, kpiMeasurementRepo, serviceLogger)
+	competencyUseCase := application.NewCompetencyUseCase(competencyRepo, serviceLogger)
+	scoringUseCase := application.NewScoringUseCase(
+		appraisalRepo, cycleRepo, goalRepo, competencyRepo, competencyRatingRepo, ratingScaleRepo,
+		nominationRepo, feedbackResponseRepo, orgDirectory, serviceLogger,
+	)
+	kpiUseCase := application.NewKPIUseCase(kpiRepo, goalRepo, kpiMeasurementRepo, serviceLogger)
+	feedbackUseCase := application.NewFeedbackUseCase(
+		appraisalRepo, cycleRepo, nominationRepo, feedbackResponseRepo, competencyRepo, orgDirectory,
+		mail.NewFeedbackMailer(cfg.SMTP, serviceLogger), serviceLogger,
+	)
+	signOffUseCase := application.NewSignOffUseCase(appraisalRepo, cycleRepo, orgDirectory, serviceLogger)
+	calibrationUseCase := application.NewCalibrationUseCase(
+		calibrationSessionRepo, calibrationAdjustmentRepo, appraisalRepo, cycleRepo, ratingScaleRepo, orgDirectory, serviceLogger,
+	)
+	pipUseCase := application.NewPIPUseCase(pipRepo, pipCheckInRepo, appraisalRepo, orgDirectory, serviceLogger)
+	trainingUseCase := application.NewTrainingUseCase(
+		trainingRepo, trainingEnrolmentRepo, trainingBudgetRepo, competencyRepo, goalRepo, cycleRepo, orgDirectory, serviceLogger,
+	)
+
+	handler := http.NewHandler(
+		cycleUseCase, goalUseCase, competencyUseCase, scoringUseCase, kpiUseCase, feedbackUseCase,
+		signOffUseCase, calibrationUseCase, pipUseCase, trainingUseCase, serviceLogger,
+	)
+
+	// feedback reviewers answer with their token, so those routes come
+	// before the JWT middleware
+	http.SetupPublicRoutes(app, handler)
+
+	authMiddleware := middleware.NewAuthMiddleware(serviceLogger)
+	app.Use(authMiddleware.JWTAuth(cfg.JWTSecret))
+
+	http.SetupRoutes(app, handler, authMiddleware)
 
 	// Graceful shutdown
 	go func() {
@@ -88,15 +148,3 @@ func main() {
 	logger.Info("Performance service starting on port %s", cfg.Port)
 	log.Fatal(app.Listen(":" + cfg.Port))
 }
-
-func setupRoutes(app *fiber.App, db interface{}) {
-	// TODO: Setup routes from internal/infrastructure/http
-	// This will be implemented in next step
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message":    "YathuERP Performance Service is running",
-			"service":    "performance",
-			"go_version": "1.22",
-		})
-	})
-}
@@ -0,0 +1,390 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+)
+
+// CalibrationUseCase lets HR review the spread of ratings in a cycle, move
+// ratings with a recorded justification, and hold the result to optional
+// forced-distribution limits
+type CalibrationUseCase struct {
+	sessionRepo    domain.CalibrationSessionRepository
+	adjustmentRepo domain.CalibrationAdjustmentRepository
+	appraisalRepo  domain.PerformanceAppraisalRepository
+	cycleRepo      domain.PerformanceCycleRepository
+	scaleRepo      domain.RatingScaleRepository
+	orgDirectory   domain.OrgDirectory
+	logger         utils.Logger
+}
+
+func NewCalibrationUseCase(
+	sessionRepo domain.CalibrationSessionRepository,
+	adjustmentRepo domain.CalibrationAdjustmentRepository,
+	appraisalRepo domain.PerformanceAppraisalRepository,
+	cycleRepo domain.PerformanceCycleRepository,
+	scaleRepo domain.RatingScaleRepository,
+	orgDirectory domain.OrgDirectory,
+	logger utils.Logger,
+) *CalibrationUseCase {
+	return &CalibrationUseCase{
+		sessionRepo:    sessionRepo,
+		adjustmentRepo: adjustmentRepo,
+		appraisalRepo:  appraisalRepo,
+		cycleRepo:      cycleRepo,
+		scaleRepo:      scaleRepo,
+		orgDirectory:   orgDirectory,
+		logger:         logger,
+	}
+}
+
+type DistributionLimitRequest struct {
+	Rating     string  `json:"rating" validate:"required"`
+	MaxPercent float64 `json:"max_percent"`
+}
+
+type CalibrationSessionRequest struct {
+	CycleID      int                        `json:"cycle_id" validate:"required"`
+	DepartmentID *int                       `json:"department_id"` // all departments when empty
+	Name         string                     `json:"name" validate:"required"`
+	Notes        string                     `json:"notes"`
+	Limits       []DistributionLimitRequest `json:"limits" validate:"dive"`
+}
+
+type CalibrationAdjustmentRequest struct {
+	AppraisalID   int    `json:"appraisal_id" validate:"required"`
+	Rating        string `json:"rating" validate:"required"`
+	Justification string `json:"justification" validate:"required"`
+}
+
+// Create opens a calibration session on a launched cycle. There is at most
+// one open session for a cycle and department at a time.
+func (uc *CalibrationUseCase) Create(ctx context.Context, req *CalibrationSessionRequest, createdBy *int) (*domain.CalibrationSession, error) {
+	cycle, err := uc.cycleRepo.GetByID(req.CycleID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.Status == domain.CycleStatusDraft || cycle.Status == domain.CycleStatusClosed {
+		return nil, fmt.Errorf("%w: cycle %s is %s", ErrInvalidState, cycle.Name, cycle.Status)
+	}
+
+	open, err := uc.sessionRepo.GetAll(&domain.CalibrationSessionFilter{
+		CycleID: &cycle.ID,
+		Status:  domain.CalibrationStatusOpen,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range open {
+		sameScope := (other.DepartmentID == nil && req.DepartmentID == nil) ||
+			(other.DepartmentID != nil && req.DepartmentID != nil && *other.DepartmentID == *req.DepartmentID)
+		if sameScope {
+			return nil, fmt.Errorf("%w: calibration session %s is already open for this department", ErrInvalidState, other.Name)
+		}
+	}
+
+	session := &domain.CalibrationSession{
+		CycleID:      cycle.ID,
+		DepartmentID: req.DepartmentID,
+		Status:       domain.CalibrationStatusOpen,
+		CreatedBy:    createdBy,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if err := uc.apply(session, req); err != nil {
+		return nil, err
+	}
+	if err := uc.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Calibration session opened", "session_id", session.ID, "cycle_id", cycle.ID)
+	return session, nil
+}
+
+// Update changes the name, notes and limits of an open session. Its cycle and
+// department are fixed.
+func (uc *CalibrationUseCase) Update(ctx context.Context, id int, req *CalibrationSessionRequest) (*domain.CalibrationSession, error) {
+	session, err := uc.openSession(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.CycleID != session.CycleID || !sameDepartment(req.DepartmentID, session.DepartmentID) {
+		return nil, fmt.Errorf("the cycle and department of a calibration session cannot be changed")
+	}
+
+	if err := uc.apply(session, req); err != nil {
+		return nil, err
+	}
+	session.UpdatedAt = time.Now()
+	if err := uc.sessionRepo.Update(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (uc *CalibrationUseCase) Get(ctx context.Context, id int) (*domain.CalibrationSession, error) {
+	return uc.sessionRepo.GetByID(id)
+}
+
+func (uc *CalibrationUseCase) List(ctx context.Context, filter *domain.CalibrationSessionFilter) ([]*domain.CalibrationSession, error) {
+	return uc.sessionRepo.GetAll(filter)
+}
+
+// Distribution shows how the ratings in the session are spread against its
+// limits
+func (uc *CalibrationUseCase) Distribution(ctx context.Context, id int) (*domain.RatingDistribution, error) {
+	session, err := uc.sessionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return uc.distribution(session)
+}
+
+// Adjust moves an appraisal's rating within an open session. Only scored
+// appraisals nobody has signed can be adjusted, and the reason is kept with
+// the change.
+func (uc *CalibrationUseCase) Adjust(ctx context.Context, id int, req *CalibrationAdjustmentRequest, adjustedBy *int) (*domain.CalibrationAdjustment, error) {
+	session, err := uc.openSession(id)
+	if err != nil {
+		return nil, err
+	}
+	justification := strings.TrimSpace(req.Justification)
+	if justification == "" {
+		return nil, fmt.Errorf("justification is required")
+	}
+
+	appraisal, err := uc.appraisalRepo.GetByID(req.AppraisalID)
+	if err != nil {
+		return nil, err
+	}
+	inSession, err := uc.inSession(session, appraisal)
+	if err != nil {
+		return nil, err
+	}
+	if !inSession {
+		return nil, fmt.Errorf("appraisal %d is not part of calibration session %s", appraisal.ID, session.Name)
+	}
+	if appraisal.Status == domain.AppraisalStatusCompleted || appraisal.EmployeeSignedAt != nil || appraisal.ManagerSignedAt != nil {
+		return nil, fmt.Errorf("%w: appraisal %d has been signed", ErrInvalidState, appraisal.ID)
+	}
+	if appraisal.OverallRating == "" {
+		return nil, fmt.Errorf("%w: appraisal %d has not been scored", ErrInvalidState, appraisal.ID)
+	}
+	if appraisal.CalibrationSessionID != nil && *appraisal.CalibrationSessionID != session.ID {
+		return nil, fmt.Errorf("%w: appraisal %d was calibrated in another session", ErrInvalidState, appraisal.ID)
+	}
+
+	scale, err := uc.scaleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	rating := ""
+	for _, band := range scale {
+		if strings.EqualFold(band.Rating, strings.TrimSpace(req.Rating)) {
+			rating = band.Rating
+		}
+	}
+	if rating == "" {
+		return nil, fmt.Errorf("rating %q is not on the rating scale", req.Rating)
+	}
+	if rating == appraisal.OverallRating {
+		return nil, fmt.Errorf("appraisal %d is already rated %s", appraisal.ID, rating)
+	}
+
+	adjustment := &domain.CalibrationAdjustment{
+		SessionID:      session.ID,
+		AppraisalID:    appraisal.ID,
+		PreviousRating: appraisal.OverallRating,
+		NewRating:      rating,
+		Justification:  justification,
+		AdjustedBy:     adjustedBy,
+		CreatedAt:      time.Now(),
+	}
+	if err := uc.adjustmentRepo.Create(adjustment); err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+func (uc *CalibrationUseCase) Adjustments(ctx context.Context, id int) ([]*domain.CalibrationAdjustment, error) {
+	if _, err := uc.sessionRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return uc.adjustmentRepo.GetBySession(id)
+}
+
+// Finalize closes the session once its ratings are within the limits and
+// locks the ratings it covered, so that re-scoring cannot undo the
+// calibration
+func (uc *CalibrationUseCase) Finalize(ctx context.Context, id int) (*domain.CalibrationSession, error) {
+	session, err := uc.openSession(id)
+	if err != nil {
+		return nil, err
+	}
+	appraisals, err := uc.sessionAppraisals(session)
+	if err != nil {
+		return nil, err
+	}
+	distribution, err := uc.distributionOf(session, appraisals)
+	if err != nil {
+		return nil, err
+	}
+	if !distribution.WithinLimits {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidState, strings.Join(distribution.Violations(), "; "))
+	}
+
+	var rated []int
+	for _, appraisal := range appraisals {
+		if appraisal.OverallRating != "" {
+			rated = append(rated, appraisal.ID)
+		}
+	}
+
+	now := time.Now()
+	session.Status = domain.CalibrationStatusFinalized
+	session.FinalizedAt = &now
+	session.UpdatedAt = now
+	if err := uc.sessionRepo.Finalize(session, rated); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Calibration session finalized", "session_id", session.ID, "rated", distribution.Rated)
+	return session, nil
+}
+
+func (uc *CalibrationUseCase) apply(session *domain.CalibrationSession, req *CalibrationSessionRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	limits := make([]domain.DistributionLimit, 0, len(req.Limits))
+	for _, limit := range req.Limits {
+		limits = append(limits, domain.DistributionLimit{
+			Rating:     strings.TrimSpace(limit.Rating),
+			MaxPercent: limit.MaxPercent,
+		})
+	}
+	if len(limits) > 0 {
+		scale, err := uc.scaleRepo.GetAll()
+		if err != nil {
+			return err
+		}
+		if err := domain.ValidateLimits(limits, scale); err != nil {
+			return err
+		}
+		// store the ratings as the scale spells them
+		for i := range limits {
+			for _, band := range scale {
+				if strings.EqualFold(band.Rating, limits[i].Rating) {
+					limits[i].Rating = band.Rating
+				}
+			}
+		}
+	}
+
+	session.Name = name
+	session.Notes = req.Notes
+	session.Limits = limits
+	return nil
+}
+
+func (uc *CalibrationUseCase) distribution(session *domain.CalibrationSession) (*domain.RatingDistribution, error) {
+	appraisals, err := uc.sessionAppraisals(session)
+	if err != nil {
+		return nil, err
+	}
+	return uc.distributionOf(session, appraisals)
+}
+
+// sessionAppraisals returns the appraisals of the session's cycle and
+// department
+func (uc *CalibrationUseCase) sessionAppraisals(session *domain.CalibrationSession) ([]*domain.PerformanceAppraisal, error) {
+	appraisals, err := uc.appraisalRepo.GetByCycle(session.CycleID)
+	if err != nil {
+		return nil, err
+	}
+	if session.DepartmentID == nil {
+		return appraisals, nil
+	}
+
+	departments, err := uc.departments()
+	if err != nil {
+		return nil, err
+	}
+	var inDepartment []*domain.PerformanceAppraisal
+	for _, appraisal := range appraisals {
+		if session.Covers(departments[appraisal.EmployeeID]) {
+			inDepartment = append(inDepartment, appraisal)
+		}
+	}
+	return inDepartment, nil
+}
+
+func (uc *CalibrationUseCase) distributionOf(session *domain.CalibrationSession, appraisals []*domain.PerformanceAppraisal) (*domain.RatingDistribution, error) {
+	scale, err := uc.scaleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return domain.Distribution(session.ID, appraisals, scale, session.Limits), nil
+}
+
+// inSession reports whether the appraisal falls in the session's cycle and
+// department
+func (uc *CalibrationUseCase) inSession(session *domain.CalibrationSession, appraisal *domain.PerformanceAppraisal) (bool, error) {
+	if appraisal.CycleID != session.CycleID {
+		return false, nil
+	}
+	if session.DepartmentID == nil {
+		return true, nil
+	}
+	departments, err := uc.departments()
+	if err != nil {
+		return false, err
+	}
+	return session.Covers(departments[appraisal.EmployeeID]), nil
+}
+
+// departments maps employees to their current department
+func (uc *CalibrationUseCase) departments() (map[int]*int, error) {
+	employees, err := uc.orgDirectory.Employees()
+	if err != nil {
+		return nil, err
+	}
+	departments := make(map[int]*int, len(employees))
+	for _, employee := range employees {
+		departments[employee.EmployeeID] = employee.DepartmentID
+	}
+	return departments, nil
+}
+
+// openSession loads a session whose ratings can still be calibrated
+func (uc *CalibrationUseCase) openSession(id int) (*domain.CalibrationSession, error) {
+	session, err := uc.sessionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != domain.CalibrationStatusOpen {
+		return nil, fmt.Errorf("%w: calibration session %s is %s", ErrInvalidState, session.Name, session.Status)
+	}
+	cycle, err := uc.cycleRepo.GetByID(session.CycleID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.Status == domain.CycleStatusClosed {
+		return nil, fmt.Errorf("%w: cycle %s is closed", ErrInvalidState, cycle.Name)
+	}
+	return session, nil
+}
+
+func sameDepartment(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,101 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+)
+
+type CompetencyUseCase struct {
+	competencyRepo domain.PerformanceCompetencyRepository
+	logger         utils.Logger
+}
+
+func NewCompetencyUseCase(competencyRepo domain.PerformanceCompetencyRepository, logger utils.Logger) *CompetencyUseCase {
+	return &CompetencyUseCase{
+		competencyRepo: competencyRepo,
+		logger:         logger,
+	}
+}
+
+type CompetencyRequest struct {
+	ParentID             *int    `json:"parent_id"`
+	Title                string  `json:"title" validate:"required"`
+	Description          string  `json:"description"`
+	CompetencyLevel      string  `json:"competency_level" validate:"required"`
+	BehavioralIndicators string  `json:"behavioral_indicators"`
+	Weight               float64 `json:"weight"`
+	IsActive             *bool   `json:"is_active"`
+}
+
+func (uc *CompetencyUseCase) Create(ctx context.Context, req *CompetencyRequest) (*domain.PerformanceCompetency, error) {
+	competency := &domain.PerformanceCompetency{
+		IsActive:  1,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := uc.apply(competency, req); err != nil {
+		return nil, err
+	}
+
+	if err := uc.competencyRepo.Create(competency); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Competency created", "competency_id", competency.ID)
+	return competency, nil
+}
+
+func (uc *CompetencyUseCase) Update(ctx context.Context, id int, req *CompetencyRequest) (*domain.PerformanceCompetency, error) {
+	competency, err := uc.competencyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.ParentID != nil && *req.ParentID == id {
+		return nil, fmt.Errorf("a competency cannot be its own parent")
+	}
+	if err := uc.apply(competency, req); err != nil {
+		return nil, err
+	}
+
+	if err := uc.competencyRepo.Update(competency); err != nil {
+		return nil, err
+	}
+	return competency, nil
+}
+
+func (uc *CompetencyUseCase) Get(ctx context.Context, id int) (*domain.PerformanceCompetency, error) {
+	return uc.competencyRepo.GetByID(id)
+}
+
+func (uc *CompetencyUseCase) List(ctx context.Context, filter *domain.PerformanceCompetencyFilter) ([]*domain.PerformanceCompetency, error) {
+	return uc.competencyRepo.GetAll(filter)
+}
+
+func (uc *CompetencyUseCase) apply(competency *domain.PerformanceCompetency, req *CompetencyRequest) error {
+	if req.Weight < 0 || req.Weight > 100 {
+		return fmt.Errorf("weight must be between 0 and 100")
+	}
+	if req.ParentID != nil {
+		if _, err := uc.competencyRepo.GetByID(*req.ParentID); err != nil {
+			return fmt.Errorf("parent competency %d: %w", *req.ParentID, err)
+		}
+	}
+
+	competency.ParentID = req.ParentID
+	competency.Title = req.Title
+	competency.Description = req.Description
+	competency.CompetencyLevel = req.CompetencyLevel
+	competency.BehavioralIndicators = req.BehavioralIndicators
+	competency.Weight = req.Weight
+	if req.IsActive != nil {
+		competency.IsActive = 0
+		if *req.IsActive {
+			competency.IsActive = 1
+		}
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+)
+
+const dateLayout = "2006-01-02"
+
+// New cycles split the overall score 70/30 between goals and competencies,
+// leave 360 feedback out of it and show feedback once three reviewers have
+// answered, unless told otherwise
+const (
+	defaultGoalWeight       = 70
+	defaultCompetencyWeight = 30
+	defaultMinRespondents   = 3
+)
+
+var (
+	// ErrInvalidState is returned when a cycle or appraisal is not in a status
+	// that allows the change, e.g. launching a cycle twice
+	ErrInvalidState = errors.New("invalid performance state")
+
+	// ErrNoManager is returned when no active manager can be found above an
+	// employee in the org hierarchy
+	ErrNoManager = errors.New("no active manager in the org hierarchy, set one with PUT /api/v1/employees/:employeeId/manager")
+
+	// ErrForbidden is returned when the caller is not the person a change is
+	// reserved for, e.g. signing an appraisal on someone else's behalf
+	ErrForbidden = errors.New("not allowed")
+)
+
+type CycleUseCase struct {
+	cycleRepo     domain.PerformanceCycleRepository
+	appraisalRepo domain.PerformanceAppraisalRepository
+	orgDirectory  domain.OrgDirectory
+	logger        utils.Logger
+}
+
+func NewCycleUseCase(
+	cycleRepo domain.PerformanceCycleRepository,
+	appraisalRepo domain.PerformanceAppraisalRepository,
+	orgDirectory domain.OrgDirectory,
+	logger utils.Logger,
+) *CycleUseCase {
+	return &CycleUseCase{
+		cycleRepo:     cycleRepo,
+		appraisalRepo: appraisalRepo,
+		orgDirectory:  orgDirectory,
+		logger:        logger,
+	}
+}
+
+type CycleRequest struct {
+	Name                string   `json:"name" validate:"required"`
+	Description         string   `json:"description"`
+	StartDate           string   `json:"start_date" validate:"required"`
+	EndDate             string   `json:"end_date" validate:"required"`
+	EvaluationStartDate *string  `json:"evaluation_start_date"` // defaults to end_date
+	EvaluationEndDate   string   `json:"evaluation_end_date" validate:"required"`
+	GoalWeight          *float64 `json:"goal_weight"`
+	CompetencyWeight    *float64 `json:"competency_weight"`
+	FeedbackWeight      *float64 `json:"feedback_weight"`
+	MinRespondents      *int     `json:"feedback_min_respondents"`
+}
+
+// SkippedEmployee is an active employee left without an appraisal, with the
+// reason
+type SkippedEmployee struct {
+	EmployeeID int    `json:"employee_id"`
+	Reason     string `json:"reason"`
+}
+
+// AppraisalGeneration reports the appraisals a launch or a later top-up
+// created
+type AppraisalGeneration struct {
+	Cycle   *domain.PerformanceCycle       `json:"cycle"`
+	Created []*domain.PerformanceAppraisal `json:"created"`
+	Skipped []SkippedEmployee              `json:"skipped"`
+}
+
+func (uc *CycleUseCase) Create(ctx context.Context, req *CycleRequest, createdBy *int) (*domain.PerformanceCycle, error) {
+	cycle := &domain.PerformanceCycle{
+		Status:           domain.CycleStatusDraft,
+		GoalWeight:       defaultGoalWeight,
+		CompetencyWeight: defaultCompetencyWeight,
+		MinRespondents:   defaultMinRespondents,
+		CreatedBy:        createdBy,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	if err := applyCycleRequest(cycle, req); err != nil {
+		return nil, err
+	}
+
+	if err := uc.cycleRepo.Create(cycle); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Performance cycle created", "cycle_id", cycle.ID)
+	return cycle, nil
+}
+
+// Update changes a cycle that has not been launched yet
+func (uc *CycleUseCase) Update(ctx context.Context, id int, req *CycleRequest) (*domain.PerformanceCycle, error) {
+	cycle, err := uc.cycleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.Status != domain.CycleStatusDraft {
+		return nil, fmt.Errorf("%w: only draft cycles can be changed, cycle is %s", ErrInvalidState, cycle.Status)
+	}
+	if err := applyCycleRequest(cycle, req); err != nil {
+		return nil, err
+	}
+
+	if err := uc.cycleRepo.Update(cycle); err != nil {
+		return nil, err
+	}
+	return cycle, nil
+}
+
+func (uc *CycleUseCase) Get(ctx context.Context, id int) (*domain.PerformanceCycle, error) {
+	return uc.cycleRepo.GetByID(id)
+}
+
+func (uc *CycleUseCase) List(ctx context.Context, filter *domain.PerformanceCycleFilter) ([]*domain.PerformanceCycle, error) {
+	return uc.cycleRepo.GetAll(filter)
+}
+
+// Delete removes a cycle that has not been launched yet
+func (uc *CycleUseCase) Delete(ctx context.Context, id int) error {
+	cycle, err := uc.cycleRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if cycle.Status != domain.CycleStatusDraft {
+		return fmt.Errorf("%w: only draft cycles can be deleted, cycle is %s", ErrInvalidState, cycle.Status)
+	}
+	return uc.cycleRepo.Delete(id)
+}
+
+// Launch opens a draft cycle and creates an appraisal for every active
+// employee. A cycle launched after its evaluation has started goes straight
+// to evaluation; one whose evaluation has already ended cannot be launched.
+func (uc *CycleUseCase) Launch(ctx context.Context, id int) (*AppraisalGeneration, error) {
+	cycle, err := uc.cycleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.Status != domain.CycleStatusDraft {
+		return nil, fmt.Errorf("%w: cycle has already been launched", ErrInvalidState)
+	}
+
+	// StatusOn keeps drafts as they are, so work it out as if launched
+	now := time.Now()
+	cycle.Status = domain.CycleStatusActive
+	cycle.Status = cycle.StatusOn(now)
+	if cycle.Status == domain.CycleStatusClosed {
+		return nil, fmt.Errorf("%w: the cycle's evaluation ended on %s", ErrInvalidState, cycle.EvaluationEndDate.Format(dateLayout))
+	}
+
+	generation, err := uc.generate(cycle)
+	if err != nil {
+		return nil, err
+	}
+
+	cycle.LaunchedAt = &now
+	if err := uc.cycleRepo.Update(cycle); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Performance cycle launched",
+		"cycle_id", cycle.ID,
+		"status", cycle.Status,
+		"appraisals", len(generation.Created),
+		"skipped", len(generation.Skipped))
+	return generation, nil
+}
+
+// GenerateAppraisals tops up a launched cycle with appraisals for employees
+// who have joined, or gained a manager, since it was launched
+func (uc *CycleUseCase) GenerateAppraisals(ctx context.Context, id int) (*AppraisalGeneration, error) {
+	cycle, err := uc.cycleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.Status != domain.CycleStatusActive && cycle.Status != domain.CycleStatusEvaluation {
+		return nil, fmt.Errorf("%w: appraisals can only be generated for a launched cycle, cycle is %s", ErrInvalidState, cycle.Status)
+	}
+
+	return uc.generate(cycle)
+}
+
+// SyncStatuses moves every launched cycle to the status its evaluation dates
+// give on date and returns the cycles that changed.
+func (uc *CycleUseCase) SyncStatuses(ctx context.Context, date time.Time) ([]*domain.PerformanceCycle, error) {
+	cycles, err := uc.cycleRepo.GetLaunched()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []*domain.PerformanceCycle{}
+	for _, cycle := range cycles {
+		status := cycle.StatusOn(date)
+		if status == cycle.Status {
+			continue
+		}
+
+		uc.logger.Info("Performance cycle status changed", "cycle_id", cycle.ID, "from", cycle.Status, "to", status)
+		cycle.Status = status
+		if err := uc.cycleRepo.Update(cycle); err != nil {
+			return nil, err
+		}
+		changed = append(changed, cycle)
+	}
+
+	return changed, nil
+}
+
+// Progress reports how many of a cycle's appraisals are completed, overall and
+// per manager
+func (uc *CycleUseCase) Progress(ctx context.Context, id int) (*domain.CycleProgress, error) {
+	cycle, err := uc.cycleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	byStatus, err := uc.appraisalRepo.CountByStatus(id)
+	if err != nil {
+		return nil, err
+	}
+	managers, err := uc.appraisalRepo.ProgressByManager(id)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &domain.CycleProgress{
+		CycleID:   cycle.ID,
+		Status:    cycle.Status,
+		ByStatus:  byStatus,
+		Completed: byStatus[domain.AppraisalStatusCompleted],
+		Managers:  managers,
+	}
+	for _, count := range byStatus {
+		progress.Appraisals += count
+	}
+	progress.CompletionRate = completionRate(progress.Completed, progress.Appraisals)
+	for i := range progress.Managers {
+		progress.Managers[i].CompletionRate = completionRate(progress.Managers[i].Completed, progress.Managers[i].Appraisals)
+	}
+
+	progress.DaysRemaining = cycle.DaysRemaining(time.Now())
+
+	return progress, nil
+}
+
+func (uc *CycleUseCase) ListAppraisals(ctx context.Context, filter *domain.PerformanceAppraisalFilter) ([]*domain.PerformanceAppraisal, error) {
+	return uc.appraisalRepo.GetAll(filter)
+}
+
+func (uc *CycleUseCase) GetAppraisal(ctx context.Context, id int) (*domain.PerformanceAppraisal, error) {
+	return uc.appraisalRepo.GetByID(id)
+}
+
+// generate creates a draft appraisal for each active employee in the cycle
+// who has none yet, reviewed by the manager resolved from the hierarchy
+func (uc *CycleUseCase) generate(cycle *domain.PerformanceCycle) (*AppraisalGeneration, error) {
+	employees, err := uc.orgDirectory.Employees()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]domain.OrgEmployee, len(employees))
+	for _, employee := range employees {
+		byID[employee.EmployeeID] = employee
+	}
+
+	now := time.Now()
+	generation := &AppraisalGeneration{
+		Cycle:   cycle,
+		Created: []*domain.PerformanceAppraisal{},
+		Skipped: []SkippedEmployee{},
+	}
+	var appraisals []*domain.PerformanceAppraisal
+	for _, employee := range employees {
+		if !employee.Active {
+			continue
+		}
+		managerID, err := resolveManager(employee, byID)
+		if err != nil {
+			generation.Skipped = append(generation.Skipped, SkippedEmployee{
+				EmployeeID: employee.EmployeeID,
+				Reason:     err.Error(),
+			})
+			continue
+		}
+		appraisals = append(appraisals, &domain.PerformanceAppraisal{
+			CycleID:    cycle.ID,
+			EmployeeID: employee.EmployeeID,
+			ManagerID:  managerID,
+			Status:     domain.AppraisalStatusDraft,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+
+	if len(appraisals) > 0 {
+		created, err := uc.appraisalRepo.CreateMissing(appraisals)
+		if err != nil {
+			return nil, err
+		}
+		generation.Created = append(generation.Created, created...)
+	}
+
+	uc.logger.Info("Appraisals generated",
+		"cycle_id", cycle.ID,
+		"created", len(generation.Created),
+		"skipped", len(generation.Skipped))
+	return generation, nil
+}
+
+// resolveManager walks up the hierarchy from the employee to the first
+// manager who is still active, so a leaver's reports go to the leaver's own
+// manager. Loops in the hierarchy are treated as having no manager.
+func resolveManager(employee domain.OrgEmployee, byID map[int]domain.OrgEmployee) (int, error) {
+	visited := map[int]bool{employee.EmployeeID: true}
+	current := employee
+	for current.ManagerID != nil {
+		managerID := *current.ManagerID
+		if visited[managerID] {
+			break
+		}
+		visited[managerID] = true
+
+		manager, ok := byID[managerID]
+		if !ok {
+			break
+		}
+		if manager.Active {
+			return managerID, nil
+		}
+		current = manager
+	}
+	return 0, ErrNoManager
+}
+
+func applyCycleRequest(cycle *domain.PerformanceCycle, req *CycleRequest) error {
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return fmt.Errorf("invalid start_date, expected YYYY-MM-DD")
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		return fmt.Errorf("invalid end_date, expected YYYY-MM-DD")
+	}
+	evaluationEnd, err := time.Parse(dateLayout, req.EvaluationEndDate)
+	if err != nil {
+		return fmt.Errorf("invalid evaluation_end_date, expected YYYY-MM-DD")
+	}
+	var evaluationStart *time.Time
+	if req.EvaluationStartDate != nil && *req.EvaluationStartDate != "" {
+		date, err := time.Parse(dateLayout, *req.EvaluationStartDate)
+		if err != nil {
+			return fmt.Errorf("invalid evaluation_start_date, expected YYYY-MM-DD")
+		}
+		evaluationStart = &date
+	}
+
+	cycle.Name = req.Name
+	cycle.Description = req.Description
+	cycle.StartDate = start
+	cycle.EndDate = end
+	cycle.EvaluationStartDate = evaluationStart
+	cycle.EvaluationEndDate = evaluationEnd
+	if req.GoalWeight != nil {
+		cycle.GoalWeight = *req.GoalWeight
+	}
+	if req.CompetencyWeight != nil {
+		cycle.CompetencyWeight = *req.CompetencyWeight
+	}
+	if req.FeedbackWeight != nil {
+		cycle.FeedbackWeight = *req.FeedbackWeight
+	}
+	if req.MinRespondents != nil {
+		cycle.MinRespondents = *req.MinRespondents
+	}
+	return cycle.Validate()
+}
+
+func completionRate(completed, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(completed)/float64(total)*10000) / 100
+}
@@ -0,0 +1,421 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+)
+
+// questionnaireLimit caps the competencies put to reviewers
+const questionnaireLimit = 500
+
+type FeedbackUseCase struct {
+	appraisalRepo  domain.PerformanceAppraisalRepository
+	cycleRepo      domain.PerformanceCycleRepository
+	nominationRepo domain.FeedbackNominationRepository
+	responseRepo   domain.FeedbackResponseRepository
+	competencyRepo domain.PerformanceCompetencyRepository
+	orgDirectory   domain.OrgDirectory
+	mailer         domain.FeedbackMailer
+	logger         utils.Logger
+}
+
+func NewFeedbackUseCase(
+	appraisalRepo domain.PerformanceAppraisalRepository,
+	cycleRepo domain.PerformanceCycleRepository,
+	nominationRepo domain.FeedbackNominationRepository,
+	responseRepo domain.FeedbackResponseRepository,
+	competencyRepo domain.PerformanceCompetencyRepository,
+	orgDirectory domain.OrgDirectory,
+	mailer domain.FeedbackMailer,
+	logger utils.Logger,
+) *FeedbackUseCase {
+	return &FeedbackUseCase{
+		appraisalRepo:  appraisalRepo,
+		cycleRepo:      cycleRepo,
+		nominationRepo: nominationRepo,
+		responseRepo:   responseRepo,
+		competencyRepo: competencyRepo,
+		orgDirectory:   orgDirectory,
+		mailer:         mailer,
+		logger:         logger,
+	}
+}
+
+type NominationRequest struct {
+	ReviewerType       string `json:"reviewer_type" validate:"required,oneof=peer subordinate external"`
+	ReviewerEmployeeID *int   `json:"reviewer_employee_id"`                      // peers and subordinates
+	ReviewerName       string `json:"reviewer_name"`                             // external reviewers
+	ReviewerEmail      string `json:"reviewer_email" validate:"omitempty,email"` // defaults to an employee's own email
+}
+
+// FeedbackQuestionnaire is what a reviewer is asked to rate
+type FeedbackQuestionnaire struct {
+	AppraisalID  int                             `json:"appraisal_id"`
+	CycleName    string                          `json:"cycle_name"`
+	ReviewerType string                          `json:"reviewer_type"`
+	Status       string                          `json:"status"`
+	Competencies []*domain.PerformanceCompetency `json:"competencies"`
+}
+
+type FeedbackAnswerRequest struct {
+	CompetencyID int    `json:"competency_id" validate:"required"`
+	Rating       int    `json:"rating" validate:"required"`
+	Comments     string `json:"comments"`
+}
+
+type FeedbackSubmission struct {
+	Answers []FeedbackAnswerRequest `json:"answers" validate:"required,min=1,dive"`
+}
+
+// Nominate asks a peer, subordinate or external reviewer for feedback on an
+// appraisal and mails them the link they answer with. Only HR or the
+// appraisal's manager can nominate. The employee and their manager cannot
+// review the appraisal, a subordinate must report to the employee, and nobody
+// is asked twice.
+func (uc *FeedbackUseCase) Nominate(ctx context.Context, appraisalID int, req *NominationRequest, nominatedBy *int, isHR bool) (*domain.FeedbackNomination, error) {
+	appraisal, _, err := uc.openAppraisal(appraisalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkNominator(appraisal, nominatedBy, isHR); err != nil {
+		return nil, err
+	}
+
+	nomination := &domain.FeedbackNomination{
+		AppraisalID:        appraisal.ID,
+		ReviewerType:       req.ReviewerType,
+		ReviewerEmployeeID: req.ReviewerEmployeeID,
+		ReviewerName:       strings.TrimSpace(req.ReviewerName),
+		ReviewerEmail:      strings.TrimSpace(req.ReviewerEmail),
+		Status:             domain.NominationStatusPending,
+		NominatedBy:        nominatedBy,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+	if err := nomination.Validate(); err != nil {
+		return nil, err
+	}
+	if nomination.ReviewerEmployeeID != nil {
+		if err := uc.checkReviewer(appraisal, nomination); err != nil {
+			return nil, err
+		}
+		if nomination.ReviewerEmail == "" {
+			email, err := uc.orgDirectory.EmployeeEmail(*nomination.ReviewerEmployeeID)
+			if err != nil {
+				return nil, err
+			}
+			if email == "" {
+				return nil, fmt.Errorf("employee %d has no email to send the invitation to", *nomination.ReviewerEmployeeID)
+			}
+			nomination.ReviewerEmail = email
+		}
+	}
+
+	existing, err := uc.nominationRepo.GetByAppraisal(appraisal.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range existing {
+		sameEmployee := nomination.ReviewerEmployeeID != nil && other.ReviewerEmployeeID != nil &&
+			*nomination.ReviewerEmployeeID == *other.ReviewerEmployeeID
+		sameEmail := nomination.ReviewerEmployeeID == nil && other.ReviewerEmployeeID == nil &&
+			strings.EqualFold(nomination.ReviewerEmail, other.ReviewerEmail)
+		if sameEmployee || sameEmail {
+			return nil, fmt.Errorf("%w: this reviewer has already been nominated", ErrInvalidState)
+		}
+	}
+
+	token, err := newFeedbackToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate feedback token: %w", err)
+	}
+	nomination.TokenHash = hashFeedbackToken(token)
+	if err := uc.nominationRepo.Create(nomination); err != nil {
+		return nil, err
+	}
+	if err := uc.mailer.SendFeedbackInvitation(nomination.ReviewerEmail, nomination.ReviewerName, token); err != nil {
+		// a reviewer who never got the link could not answer
+		if err := uc.nominationRepo.Delete(nomination.ID); err != nil {
+			uc.logger.Error("Failed to remove unsent feedback nomination", "error", err, "nomination_id", nomination.ID)
+		}
+		return nil, fmt.Errorf("failed to send the feedback invitation: %w", err)
+	}
+
+	uc.logger.Info("Feedback reviewer nominated",
+		"appraisal_id", appraisal.ID,
+		"nomination_id", nomination.ID,
+		"reviewer_type", nomination.ReviewerType)
+	return nomination, nil
+}
+
+func (uc *FeedbackUseCase) ListNominations(ctx context.Context, appraisalID int) ([]*domain.FeedbackNomination, error) {
+	if _, err := uc.appraisalRepo.GetByID(appraisalID); err != nil {
+		return nil, err
+	}
+	return uc.nominationRepo.GetByAppraisal(appraisalID)
+}
+
+// WithdrawNomination removes a nomination the reviewer has not answered yet.
+// Like nominating, only HR or the appraisal's manager can withdraw one.
+func (uc *FeedbackUseCase) WithdrawNomination(ctx context.Context, appraisalID, nominationID int, userID *int, isHR bool) error {
+	appraisal, err := uc.appraisalRepo.GetByID(appraisalID)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkNominator(appraisal, userID, isHR); err != nil {
+		return err
+	}
+	nomination, err := uc.nominationRepo.GetByID(nominationID)
+	if err != nil {
+		return err
+	}
+	if nomination.AppraisalID != appraisalID {
+		return fmt.Errorf("feedback nomination %d: %w", nominationID, domain.ErrNotFound)
+	}
+	if nomination.Status != domain.NominationStatusPending {
+		return fmt.Errorf("%w: the reviewer has already responded", ErrInvalidState)
+	}
+	return uc.nominationRepo.Delete(nominationID)
+}
+
+// Questionnaire returns the competencies the reviewer holding token is asked
+// to rate
+func (uc *FeedbackUseCase) Questionnaire(ctx context.Context, token string) (*FeedbackQuestionnaire, error) {
+	nomination, err := uc.nominationRepo.GetByTokenHash(hashFeedbackToken(token))
+	if err != nil {
+		return nil, err
+	}
+	appraisal, err := uc.appraisalRepo.GetByID(nomination.AppraisalID)
+	if err != nil {
+		return nil, err
+	}
+	cycle, err := uc.cycleRepo.GetByID(appraisal.CycleID)
+	if err != nil {
+		return nil, err
+	}
+	competencies, err := uc.competencyRepo.GetAll(&domain.PerformanceCompetencyFilter{
+		ActiveOnly: true,
+		Limit:      questionnaireLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &FeedbackQuestionnaire{
+		AppraisalID:  appraisal.ID,
+		CycleName:    cycle.Name,
+		ReviewerType: nomination.ReviewerType,
+		Status:       nomination.Status,
+		Competencies: competencies,
+	}, nil
+}
+
+// Submit records the answers of the reviewer holding token. Each active
+// competency can be rated once; reviewers may leave out those they cannot
+// judge.
+func (uc *FeedbackUseCase) Submit(ctx context.Context, token string, req *FeedbackSubmission) error {
+	nomination, err := uc.pendingNomination(token)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	responses := make([]*domain.FeedbackResponse, 0, len(req.Answers))
+	answered := map[int]bool{}
+	for _, answer := range req.Answers {
+		if answered[answer.CompetencyID] {
+			return fmt.Errorf("competency %d is rated more than once", answer.CompetencyID)
+		}
+		answered[answer.CompetencyID] = true
+		if err := domain.ValidateRating(answer.Rating); err != nil {
+			return err
+		}
+		competency, err := uc.competencyRepo.GetByID(answer.CompetencyID)
+		if err != nil {
+			return err
+		}
+		if competency.IsActive != 1 {
+			return fmt.Errorf("competency %s is no longer in use", competency.Title)
+		}
+		responses = append(responses, &domain.FeedbackResponse{
+			NominationID: nomination.ID,
+			CompetencyID: competency.ID,
+			Rating:       answer.Rating,
+			Comments:     strings.TrimSpace(answer.Comments),
+			CreatedAt:    now,
+		})
+	}
+
+	nomination.RespondedAt = &now
+	return uc.responseRepo.Submit(nomination, responses)
+}
+
+// Decline records that the reviewer holding token will not give feedback
+func (uc *FeedbackUseCase) Decline(ctx context.Context, token string) error {
+	nomination, err := uc.pendingNomination(token)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	nomination.Status = domain.NominationStatusDeclined
+	nomination.RespondedAt = &now
+	return uc.nominationRepo.Update(nomination)
+}
+
+// Summary aggregates the appraisal's feedback within the cycle's anonymity
+// threshold
+func (uc *FeedbackUseCase) Summary(ctx context.Context, appraisalID int) (*domain.FeedbackSummary, error) {
+	appraisal, err := uc.appraisalRepo.GetByID(appraisalID)
+	if err != nil {
+		return nil, err
+	}
+	cycle, err := uc.cycleRepo.GetByID(appraisal.CycleID)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeFeedback(uc.nominationRepo, uc.responseRepo, uc.competencyRepo, appraisal, cycle)
+}
+
+// checkNominator makes sure the calling user is HR or the appraisal's manager,
+// and not the employee being appraised
+func (uc *FeedbackUseCase) checkNominator(appraisal *domain.PerformanceAppraisal, userID *int, isHR bool) error {
+	if userID == nil {
+		return fmt.Errorf("%w: reviewers must be nominated by a user", ErrForbidden)
+	}
+	nominator, err := uc.orgDirectory.EmployeeOfUser(*userID)
+	if err != nil {
+		return err
+	}
+	if nominator != nil && *nominator == appraisal.EmployeeID {
+		return fmt.Errorf("%w: you cannot choose the reviewers of your own appraisal", ErrForbidden)
+	}
+	if isHR {
+		return nil
+	}
+	if nominator == nil || *nominator != appraisal.ManagerID {
+		return fmt.Errorf("%w: only HR or the appraisal's manager can nominate reviewers", ErrForbidden)
+	}
+	return nil
+}
+
+// checkReviewer makes sure an internal reviewer is a current employee other
+// than the employee and their manager, and that a subordinate reports to the
+// employee
+func (uc *FeedbackUseCase) checkReviewer(appraisal *domain.PerformanceAppraisal, nomination *domain.FeedbackNomination) error {
+	reviewerID := *nomination.ReviewerEmployeeID
+	if reviewerID == appraisal.EmployeeID {
+		return fmt.Errorf("employees cannot review their own appraisal")
+	}
+	if reviewerID == appraisal.ManagerID {
+		return fmt.Errorf("the appraising manager gives their view on the appraisal itself")
+	}
+
+	employees, err := uc.orgDirectory.Employees()
+	if err != nil {
+		return err
+	}
+	for _, employee := range employees {
+		if employee.EmployeeID != reviewerID {
+			continue
+		}
+		if !employee.Active {
+			return fmt.Errorf("employee %d has left", reviewerID)
+		}
+		reportsToEmployee := employee.ManagerID != nil && *employee.ManagerID == appraisal.EmployeeID
+		if nomination.ReviewerType == domain.FeedbackReviewerSubordinate && !reportsToEmployee {
+			return fmt.Errorf("employee %d does not report to the appraised employee", reviewerID)
+		}
+		return nil
+	}
+	return fmt.Errorf("employee %d not found", reviewerID)
+}
+
+// pendingNomination loads the nomination for token while it can still be
+// answered
+func (uc *FeedbackUseCase) pendingNomination(token string) (*domain.FeedbackNomination, error) {
+	nomination, err := uc.nominationRepo.GetByTokenHash(hashFeedbackToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if nomination.Status != domain.NominationStatusPending {
+		return nil, fmt.Errorf("%w: feedback has already been %s", ErrInvalidState, nomination.Status)
+	}
+	if _, _, err := uc.openAppraisal(nomination.AppraisalID); err != nil {
+		return nil, err
+	}
+	return nomination, nil
+}
+
+// openAppraisal loads an appraisal still collecting feedback: it is not
+// completed and its cycle is not closed
+func (uc *FeedbackUseCase) openAppraisal(id int) (*domain.PerformanceAppraisal, *domain.PerformanceCycle, error) {
+	appraisal, err := uc.appraisalRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if appraisal.Status == domain.AppraisalStatusCompleted {
+		return nil, nil, fmt.Errorf("%w: appraisal is completed", ErrInvalidState)
+	}
+	cycle, err := uc.cycleRepo.GetByID(appraisal.CycleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cycle.Status == domain.CycleStatusClosed {
+		return nil, nil, fmt.Errorf("%w: cycle %s is closed", ErrInvalidState, cycle.Name)
+	}
+	return appraisal, cycle, nil
+}
+
+// summarizeFeedback aggregates an appraisal's submitted feedback for display
+// and scoring
+func summarizeFeedback(
+	nominationRepo domain.FeedbackNominationRepository,
+	responseRepo domain.FeedbackResponseRepository,
+	competencyRepo domain.PerformanceCompetencyRepository,
+	appraisal *domain.PerformanceAppraisal,
+	cycle *domain.PerformanceCycle,
+) (*domain.FeedbackSummary, error) {
+	nominations, err := nominationRepo.GetByAppraisal(appraisal.ID)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := responseRepo.GetAnswers(appraisal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	competencies := map[int]*domain.PerformanceCompetency{}
+	for _, answer := range answers {
+		if _, ok := competencies[answer.CompetencyID]; ok {
+			continue
+		}
+		competency, err := competencyRepo.GetByID(answer.CompetencyID)
+		if err != nil {
+			return nil, err
+		}
+		competencies[answer.CompetencyID] = competency
+	}
+
+	return domain.AggregateFeedback(appraisal.ID, cycle.MinRespondents, nominations, answers, competencies), nil
+}
+
+func newFeedbackToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashFeedbackToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,363 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+)
+
+type GoalUseCase struct {
+	goalRepo        domain.PerformanceGoalRepository
+	cycleRepo       domain.PerformanceCycleRepository
+	kpiRepo         domain.PerformanceKPIRepository
+	measurementRepo domain.KPIMeasurementRepository
+	logger          utils.Logger
+}
+
+func NewGoalUseCase(
+	goalRepo domain.PerformanceGoalRepository,
+	cycleRepo domain.PerformanceCycleRepository,
+	kpiRepo domain.PerformanceKPIRepository,
+	measurementRepo domain.KPIMeasurementRepository,
+	logger utils.Logger,
+) *GoalUseCase {
+	return &GoalUseCase{
+		goalRepo:        goalRepo,
+		cycleRepo:       cycleRepo,
+		kpiRepo:         kpiRepo,
+		measurementRepo: measurementRepo,
+		logger:          logger,
+	}
+}
+
+type GoalRequest struct {
+	EmployeeID    int      `json:"employee_id" validate:"required"`
+	CycleID       *int     `json:"cycle_id"`
+	KpiID         *int     `json:"kpi_id"`
+	Title         string   `json:"title" validate:"required"`
+	Description   string   `json:"description"`
+	TargetValue   *float64 `json:"target_value"` // derived from the KPI's formula when left out
+	BaselineValue *float64 `json:"baseline_value"`
+	CurrentValue  *float64 `json:"current_value"` // ignored once the goal has measurements
+	Weight        float64  `json:"weight"`
+	Progress      float64  `json:"progress"` // used when there is no target to measure against
+	DueDate       string   `json:"due_date" validate:"required"`
+	StartDate     *string  `json:"start_date"`
+	Status        string   `json:"status" validate:"omitempty,oneof=active completed cancelled"`
+	IsSmartGoal   bool     `json:"is_smart_goal"`
+}
+
+type MeasurementRequest struct {
+	MeasuredOn string  `json:"measured_on" validate:"required"`
+	Value      float64 `json:"value"`
+	Notes      string  `json:"notes"`
+}
+
+type GoalTrend struct {
+	Goal     *domain.PerformanceGoal `json:"goal"`
+	Interval string                  `json:"interval"`
+	Points   []domain.TrendPoint     `json:"points"`
+}
+
+func (uc *GoalUseCase) Create(ctx context.Context, req *GoalRequest) (*domain.PerformanceGoal, error) {
+	goal := &domain.PerformanceGoal{
+		EmployeeID: req.EmployeeID,
+		Status:     domain.GoalStatusActive,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := uc.apply(goal, req); err != nil {
+		return nil, err
+	}
+
+	if err := uc.goalRepo.Create(goal); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Goal created", "goal_id", goal.ID, "employee_id", goal.EmployeeID)
+	return goal, nil
+}
+
+// Update changes a goal while its cycle is still open. The employee cannot
+// be changed.
+func (uc *GoalUseCase) Update(ctx context.Context, id int, req *GoalRequest) (*domain.PerformanceGoal, error) {
+	goal, err := uc.goalRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.EmployeeID != goal.EmployeeID {
+		return nil, fmt.Errorf("a goal cannot be moved to another employee")
+	}
+	if err := uc.checkCycleOpen(goal.CycleID); err != nil {
+		return nil, err
+	}
+	if err := uc.apply(goal, req); err != nil {
+		return nil, err
+	}
+
+	if err := uc.goalRepo.Update(goal); err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+func (uc *GoalUseCase) Get(ctx context.Context, id int) (*domain.PerformanceGoal, error) {
+	return uc.goalRepo.GetByID(id)
+}
+
+func (uc *GoalUseCase) List(ctx context.Context, filter *domain.PerformanceGoalFilter) ([]*domain.PerformanceGoal, error) {
+	return uc.goalRepo.GetAll(filter)
+}
+
+// Delete removes a goal while its cycle is still open
+func (uc *GoalUseCase) Delete(ctx context.Context, id int) error {
+	goal, err := uc.goalRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkCycleOpen(goal.CycleID); err != nil {
+		return err
+	}
+	return uc.goalRepo.Delete(id)
+}
+
+// RecordMeasurement adds a KPI reading to the goal and recalculates its
+// current value and progress
+func (uc *GoalUseCase) RecordMeasurement(ctx context.Context, goalID int, req *MeasurementRequest, recordedBy *int) (*domain.KPIMeasurement, error) {
+	measuredOn, err := time.Parse(dateLayout, req.MeasuredOn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid measured_on, expected YYYY-MM-DD")
+	}
+	goal, kpi, err := uc.measurableGoal(goalID)
+	if err != nil {
+		return nil, err
+	}
+	if kpi != nil {
+		if err := kpi.ValidateValue(req.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	measurement := &domain.KPIMeasurement{
+		GoalID:     goal.ID,
+		MeasuredOn: measuredOn,
+		Value:      req.Value,
+		Notes:      req.Notes,
+		RecordedBy: recordedBy,
+		CreatedAt:  time.Now(),
+	}
+	if err := uc.measurementRepo.Create(measurement); err != nil {
+		return nil, err
+	}
+	if err := refreshGoal(uc.goalRepo, uc.measurementRepo, goal, kpi); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("KPI measurement recorded", "goal_id", goal.ID, "measurement_id", measurement.ID)
+	return measurement, nil
+}
+
+func (uc *GoalUseCase) GetMeasurements(ctx context.Context, goalID int) ([]*domain.KPIMeasurement, error) {
+	if _, err := uc.goalRepo.GetByID(goalID); err != nil {
+		return nil, err
+	}
+	return uc.measurementRepo.GetByGoal(goalID)
+}
+
+// DeleteMeasurement removes a reading and recalculates the goal
+func (uc *GoalUseCase) DeleteMeasurement(ctx context.Context, goalID, measurementID int) error {
+	measurement, err := uc.measurementRepo.GetByID(measurementID)
+	if err != nil {
+		return err
+	}
+	if measurement.GoalID != goalID {
+		return fmt.Errorf("KPI measurement %d: %w", measurementID, domain.ErrNotFound)
+	}
+	goal, kpi, err := uc.measurableGoal(goalID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.measurementRepo.Delete(measurementID); err != nil {
+		return err
+	}
+	return refreshGoal(uc.goalRepo, uc.measurementRepo, goal, kpi)
+}
+
+// Trend follows the goal's measurements week by week or month by month
+func (uc *GoalUseCase) Trend(ctx context.Context, goalID int, interval string) (*GoalTrend, error) {
+	goal, err := uc.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, err
+	}
+	kpi, err := uc.goalKPI(goal)
+	if err != nil {
+		return nil, err
+	}
+	measurements, err := uc.measurementRepo.GetByGoal(goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregation := domain.KPIAggregationLatest
+	if kpi != nil {
+		aggregation = kpi.Aggregation
+	}
+	return &GoalTrend{
+		Goal:     goal,
+		Interval: interval,
+		Points:   domain.GoalTrend(goal, aggregation, interval, measurements),
+	}, nil
+}
+
+// measurableGoal loads a goal that can still take measurements, with its
+// KPI when it has one
+func (uc *GoalUseCase) measurableGoal(goalID int) (*domain.PerformanceGoal, *domain.PerformanceKPI, error) {
+	goal, err := uc.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if goal.Status == domain.GoalStatusCancelled {
+		return nil, nil, fmt.Errorf("%w: goal %s is cancelled", ErrInvalidState, goal.Title)
+	}
+	if err := uc.checkCycleOpen(goal.CycleID); err != nil {
+		return nil, nil, err
+	}
+	kpi, err := uc.goalKPI(goal)
+	if err != nil {
+		return nil, nil, err
+	}
+	return goal, kpi, nil
+}
+
+func (uc *GoalUseCase) goalKPI(goal *domain.PerformanceGoal) (*domain.PerformanceKPI, error) {
+	if goal.KpiID == nil {
+		return nil, nil
+	}
+	return uc.kpiRepo.GetByID(*goal.KpiID)
+}
+
+// apply copies the request onto the goal and validates it. A goal tracked
+// against a KPI with a formula takes its target from the formula unless one
+// is given, and once measured its current value comes from the measurements.
+// Progress follows the measured achievement whenever the goal has a target
+// and a current value, and the employee's goals in a cycle may not weigh
+// more than 100%.
+func (uc *GoalUseCase) apply(goal *domain.PerformanceGoal, req *GoalRequest) error {
+	due, err := time.Parse(dateLayout, req.DueDate)
+	if err != nil {
+		return fmt.Errorf("invalid due_date, expected YYYY-MM-DD")
+	}
+	var start *time.Time
+	if req.StartDate != nil && *req.StartDate != "" {
+		date, err := time.Parse(dateLayout, *req.StartDate)
+		if err != nil {
+			return fmt.Errorf("invalid start_date, expected YYYY-MM-DD")
+		}
+		start = &date
+	}
+	if err := uc.checkCycleOpen(req.CycleID); err != nil {
+		return err
+	}
+	target := req.TargetValue
+	if req.KpiID != nil {
+		kpi, err := uc.kpiRepo.GetByID(*req.KpiID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("KPI %d not found", *req.KpiID)
+		}
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			value, ok, err := kpi.Target(req.BaselineValue)
+			if err != nil {
+				return fmt.Errorf("cannot derive the target from KPI %s: %w", kpi.Title, err)
+			}
+			if ok {
+				target = &value
+			}
+		}
+	}
+	current := req.CurrentValue
+	if goal.ID != 0 {
+		measurements, err := uc.measurementRepo.GetByGoal(goal.ID)
+		if err != nil {
+			return err
+		}
+		if len(measurements) > 0 {
+			current = goal.CurrentValue
+		}
+	}
+
+	goal.CycleID = req.CycleID
+	goal.KpiID = req.KpiID
+	goal.Title = req.Title
+	goal.Description = req.Description
+	goal.TargetValue = target
+	goal.BaselineValue = req.BaselineValue
+	goal.CurrentValue = current
+	goal.Weight = req.Weight
+	goal.Progress = req.Progress
+	goal.DueDate = due
+	goal.StartDate = start
+	if req.Status != "" {
+		goal.Status = req.Status
+	}
+	goal.IsSmartGoal = 0
+	if req.IsSmartGoal {
+		goal.IsSmartGoal = 1
+	}
+	if err := goal.Validate(); err != nil {
+		return err
+	}
+	if goal.TargetValue != nil && goal.CurrentValue != nil {
+		goal.Progress = goal.Achievement()
+	}
+
+	return uc.checkWeights(goal)
+}
+
+// checkWeights keeps the employee's goals in the cycle within 100%. Whether
+// they reach exactly 100% is checked when the appraisal is scored.
+func (uc *GoalUseCase) checkWeights(goal *domain.PerformanceGoal) error {
+	if goal.CycleID == nil || goal.Status == domain.GoalStatusCancelled {
+		return nil
+	}
+
+	goals, err := uc.goalRepo.GetForAppraisal(goal.EmployeeID, *goal.CycleID)
+	if err != nil {
+		return err
+	}
+	total := goal.Weight
+	for _, other := range goals {
+		if other.ID != goal.ID {
+			total += other.Weight
+		}
+	}
+	if total > 100+domain.WeightTolerance {
+		return fmt.Errorf("the employee's goals in this cycle would weigh %.2f%%, more than 100%%", total)
+	}
+	return nil
+}
+
+// checkCycleOpen rejects changes to goals in a closed cycle
+func (uc *GoalUseCase) checkCycleOpen(cycleID *int) error {
+	if cycleID == nil {
+		return nil
+	}
+	cycle, err := uc.cycleRepo.GetByID(*cycleID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("performance cycle %d not found", *cycleID)
+	}
+	if err != nil {
+		return err
+	}
+	if cycle.Status == domain.CycleStatusClosed {
+		return fmt.Errorf("%w: cycle %s is closed", ErrInvalidState, cycle.Name)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+)
+
+type KPIUseCase struct {
+	kpiRepo         domain.PerformanceKPIRepository
+	goalRepo        domain.PerformanceGoalRepository
+	measurementRepo domain.KPIMeasurementRepository
+	logger          utils.Logger
+}
+
+func NewKPIUseCase(
+	kpiRepo domain.PerformanceKPIRepository,
+	goalRepo domain.PerformanceGoalRepository,
+	measurementRepo domain.KPIMeasurementRepository,
+	logger utils.Logger,
+) *KPIUseCase {
+	return &KPIUseCase{
+		kpiRepo:         kpiRepo,
+		goalRepo:        goalRepo,
+		measurementRepo: measurementRepo,
+		logger:          logger,
+	}
+}
+
+type KPIRequest struct {
+	DepartmentID             *int   `json:"department_id"`
+	Title                    string `json:"title" validate:"required"`
+	Description              string `json:"description"`
+	Unit                     string `json:"unit" validate:"required"`
+	DataType                 string `json:"data_type"`   // defaults to numeric
+	Aggregation              string `json:"aggregation"` // defaults to latest
+	TargetCalculationFormula string `json:"target_calculation_formula"`
+	IsActive                 *bool  `json:"is_active"`
+}
+
+// KPITrendPoint averages the KPI's goals over one period
+type KPITrendPoint struct {
+	Period          string  `json:"period"`
+	Goals           int     `json:"goals"` // goals measured in the period
+	Measurements    int     `json:"measurements"`
+	AverageValue    float64 `json:"average_value"`
+	AverageProgress float64 `json:"average_progress"` // over every goal measured so far
+}
+
+type KPITrend struct {
+	KPI      *domain.PerformanceKPI `json:"kpi"`
+	Interval string                 `json:"interval"`
+	Points   []KPITrendPoint        `json:"points"`
+}
+
+func (uc *KPIUseCase) Create(ctx context.Context, req *KPIRequest, createdBy *int) (*domain.PerformanceKPI, error) {
+	kpi := &domain.PerformanceKPI{
+		DataType:    domain.KPIDataTypeNumeric,
+		Aggregation: domain.KPIAggregationLatest,
+		IsActive:    1,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	applyKPIRequest(kpi, req)
+	if err := kpi.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.kpiRepo.Create(kpi); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("KPI created", "kpi_id", kpi.ID)
+	return kpi, nil
+}
+
+// Update changes the KPI. Targets already derived from the formula stay as
+// agreed, but a new aggregation recalculates its goals' current values.
+func (uc *KPIUseCase) Update(ctx context.Context, id int, req *KPIRequest) (*domain.PerformanceKPI, error) {
+	kpi, err := uc.kpiRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	aggregation := kpi.Aggregation
+	applyKPIRequest(kpi, req)
+	if err := kpi.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.kpiRepo.Update(kpi); err != nil {
+		return nil, err
+	}
+
+	if kpi.Aggregation != aggregation {
+		goals, err := uc.goalRepo.GetByKPI(kpi.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, goal := range goals {
+			if err := refreshGoal(uc.goalRepo, uc.measurementRepo, goal, kpi); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return kpi, nil
+}
+
+func (uc *KPIUseCase) Get(ctx context.Context, id int) (*domain.PerformanceKPI, error) {
+	return uc.kpiRepo.GetByID(id)
+}
+
+func (uc *KPIUseCase) List(ctx context.Context, filter *domain.PerformanceKPIFilter) ([]*domain.PerformanceKPI, error) {
+	return uc.kpiRepo.GetAll(filter)
+}
+
+// EvaluateTarget previews the target the KPI's formula gives for a baseline
+func (uc *KPIUseCase) EvaluateTarget(ctx context.Context, id int, baseline *float64) (float64, error) {
+	kpi, err := uc.kpiRepo.GetByID(id)
+	if err != nil {
+		return 0, err
+	}
+	target, ok, err := kpi.Target(baseline)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("KPI %s has no target formula", kpi.Title)
+	}
+	return target, nil
+}
+
+// Trend follows every goal tracked against the KPI, optionally within one
+// cycle, and averages them period by period
+func (uc *KPIUseCase) Trend(ctx context.Context, id int, cycleID *int, interval string) (*KPITrend, error) {
+	kpi, err := uc.kpiRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	goals, err := uc.goalRepo.GetByKPI(id, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	measurements, err := uc.measurementRepo.GetByKPI(id, cycleID)
+	if err != nil {
+		return nil, err
+	}
+
+	byGoal := map[int][]*domain.KPIMeasurement{}
+	for _, measurement := range measurements {
+		byGoal[measurement.GoalID] = append(byGoal[measurement.GoalID], measurement)
+	}
+
+	type periodTotals struct {
+		goals, measurements int
+		value               float64
+	}
+	totals := map[string]*periodTotals{}
+	progressByGoal := map[int][]domain.TrendPoint{}
+	for _, goal := range goals {
+		points := domain.GoalTrend(goal, kpi.Aggregation, interval, byGoal[goal.ID])
+		progressByGoal[goal.ID] = points
+		for _, point := range points {
+			if totals[point.Period] == nil {
+				totals[point.Period] = &periodTotals{}
+			}
+			totals[point.Period].goals++
+			totals[point.Period].measurements += point.Measurements
+			totals[point.Period].value += point.Value
+		}
+	}
+
+	periods := make([]string, 0, len(totals))
+	for period := range totals {
+		periods = append(periods, period)
+	}
+	sort.Strings(periods)
+
+	trend := &KPITrend{KPI: kpi, Interval: interval, Points: []KPITrendPoint{}}
+	for _, period := range periods {
+		point := KPITrendPoint{
+			Period:       period,
+			Goals:        totals[period].goals,
+			Measurements: totals[period].measurements,
+			AverageValue: roundTwo(totals[period].value / float64(totals[period].goals)),
+		}
+
+		// each goal counts with its latest progress up to the period
+		progress, measured := 0.0, 0
+		for _, points := range progressByGoal {
+			latest := -1
+			for i, goalPoint := range points {
+				if goalPoint.Period <= period {
+					latest = i
+				}
+			}
+			if latest >= 0 {
+				progress += points[latest].Progress
+				measured++
+			}
+		}
+		if measured > 0 {
+			point.AverageProgress = roundTwo(progress / float64(measured))
+		}
+		trend.Points = append(trend.Points, point)
+	}
+
+	return trend, nil
+}
+
+// refreshGoal recalculates the goal's current value from its measurements
+// and, when it has a target, its progress
+func refreshGoal(goalRepo domain.PerformanceGoalRepository, measurementRepo domain.KPIMeasurementRepository, goal *domain.PerformanceGoal, kpi *domain.PerformanceKPI) error {
+	measurements, err := measurementRepo.GetByGoal(goal.ID)
+	if err != nil {
+		return err
+	}
+
+	aggregation := domain.KPIAggregationLatest
+	if kpi != nil {
+		aggregation = kpi.Aggregation
+	}
+	goal.CurrentValue = nil
+	if value, ok := domain.Aggregate(aggregation, measurements); ok {
+		goal.CurrentValue = &value
+	}
+	if goal.TargetValue != nil && goal.CurrentValue != nil {
+		goal.Progress = goal.Achievement()
+	}
+
+	return goalRepo.Update(goal)
+}
+
+func applyKPIRequest(kpi *domain.PerformanceKPI, req *KPIRequest) {
+	kpi.DepartmentID = req.DepartmentID
+	kpi.Title = req.Title
+	kpi.Description = req.Description
+	kpi.Unit = req.Unit
+	if req.DataType != "" {
+		kpi.DataType = req.DataType
+	}
+	if req.Aggregation != "" {
+		kpi.Aggregation = req.Aggregation
+	}
+	kpi.TargetCalculationFormula = req.TargetCalculationFormula
+	if req.IsActive != nil {
+		kpi.IsActive = 0
+		if *req.IsActive {
+			kpi.IsActive = 1
+		}
+	}
+}
+
+func roundTwo(value float64) float64 {
+	return math.Round(value*100) / 100
+}
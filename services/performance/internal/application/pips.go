@@ -0,0 +1,493 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+)
+
+// defaultPIPReminderDays is how long before the end of a plan its reminder
+// goes out unless the plan says otherwise
+const defaultPIPReminderDays = 7
+
+type PIPUseCase struct {
+	pipRepo       domain.PerformancePIPRepository
+	checkInRepo   domain.PIPCheckInRepository
+	appraisalRepo domain.PerformanceAppraisalRepository
+	orgDirectory  domain.OrgDirectory
+	logger        utils.Logger
+}
+
+func NewPIPUseCase(
+	pipRepo domain.PerformancePIPRepository,
+	checkInRepo domain.PIPCheckInRepository,
+	appraisalRepo domain.PerformanceAppraisalRepository,
+	orgDirectory domain.OrgDirectory,
+	logger utils.Logger,
+) *PIPUseCase {
+	return &PIPUseCase{
+		pipRepo:       pipRepo,
+		checkInRepo:   checkInRepo,
+		appraisalRepo: appraisalRepo,
+		orgDirectory:  orgDirectory,
+		logger:        logger,
+	}
+}
+
+type PIPRequest struct {
+	EmployeeID          int    `json:"employee_id" validate:"required"`
+	AppraisalID         *int   `json:"appraisal_id"`
+	Reason              string `json:"reason" validate:"required"`
+	Objectives          string `json:"objectives" validate:"required"`
+	SuccessCriteria     string `json:"success_criteria" validate:"required"`
+	StartDate           string `json:"start_date" validate:"required"`
+	EndDate             string `json:"end_date" validate:"required"`
+	ReminderDays        *int   `json:"reminder_days"`
+	CheckInIntervalDays int    `json:"check_in_interval_days"` // schedules check-ins when the plan is created
+}
+
+// PIPUpdateRequest changes the content of a running plan. Its employee and
+// dates are fixed; the end date only moves by extending the plan.
+type PIPUpdateRequest struct {
+	AppraisalID     *int   `json:"appraisal_id"`
+	Reason          string `json:"reason" validate:"required"`
+	Objectives      string `json:"objectives" validate:"required"`
+	SuccessCriteria string `json:"success_criteria" validate:"required"`
+	ReminderDays    *int   `json:"reminder_days"`
+}
+
+type CheckInScheduleRequest struct {
+	ScheduledDate string `json:"scheduled_date" validate:"required"`
+}
+
+type CheckInRequest struct {
+	HeldDate       string `json:"held_date"` // today when left out
+	Notes          string `json:"notes" validate:"required"`
+	ProgressRating int    `json:"progress_rating" validate:"required"`
+}
+
+type PIPOutcomeRequest struct {
+	Outcome    string `json:"outcome" validate:"required,oneof=extended completed terminated"`
+	Notes      string `json:"notes"`
+	NewEndDate string `json:"new_end_date"` // required to extend the plan
+	RecordExit bool   `json:"record_exit"`  // records the termination in tbl_employee_trash
+	ExitDate   string `json:"exit_date"`    // today when left out
+}
+
+// PIPDetail is a plan with its check-ins
+type PIPDetail struct {
+	*domain.PerformancePIP
+	CheckIns []*domain.PIPCheckIn `json:"check_ins"`
+}
+
+// PIPReminder tells the employee's manager a plan is about to end
+type PIPReminder struct {
+	PIP             *domain.PerformancePIP `json:"pip"`
+	ManagerID       *int                   `json:"manager_id"`
+	DaysLeft        int                    `json:"days_left"`
+	PendingCheckIns int                    `json:"pending_check_ins"`
+}
+
+// Create puts an active employee on a plan, optionally linked to the
+// appraisal that led to it, and schedules its check-ins. An employee has at
+// most one active plan.
+func (uc *PIPUseCase) Create(ctx context.Context, req *PIPRequest, createdBy *int) (*PIPDetail, error) {
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_date, expected YYYY-MM-DD")
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end_date, expected YYYY-MM-DD")
+	}
+	if req.CheckInIntervalDays < 0 {
+		return nil, fmt.Errorf("check_in_interval_days cannot be negative")
+	}
+	if _, err := uc.activeEmployee(req.EmployeeID); err != nil {
+		return nil, err
+	}
+
+	active, err := uc.pipRepo.GetAll(&domain.PerformancePIPFilter{
+		EmployeeID: &req.EmployeeID,
+		Status:     domain.PIPStatusActive,
+		Limit:      1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(active) > 0 {
+		return nil, fmt.Errorf("%w: employee %d is already on PIP %d", ErrInvalidState, req.EmployeeID, active[0].ID)
+	}
+
+	now := time.Now()
+	pip := &domain.PerformancePIP{
+		EmployeeID:   req.EmployeeID,
+		StartDate:    start,
+		EndDate:      end,
+		ReminderDays: defaultPIPReminderDays,
+		Status:       domain.PIPStatusActive,
+		CreatedBy:    createdBy,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := uc.apply(pip, req.AppraisalID, req.Reason, req.Objectives, req.SuccessCriteria, req.ReminderDays); err != nil {
+		return nil, err
+	}
+
+	checkIns := []*domain.PIPCheckIn{}
+	for _, date := range domain.ScheduleCheckIns(start, end, req.CheckInIntervalDays) {
+		checkIns = append(checkIns, &domain.PIPCheckIn{
+			ScheduledDate: date,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+	if err := uc.pipRepo.Create(pip, checkIns); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Employee placed on PIP", "pip_id", pip.ID, "employee_id", pip.EmployeeID)
+	return &PIPDetail{PerformancePIP: pip, CheckIns: checkIns}, nil
+}
+
+func (uc *PIPUseCase) Update(ctx context.Context, id int, req *PIPUpdateRequest) (*domain.PerformancePIP, error) {
+	pip, err := uc.activePIP(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.apply(pip, req.AppraisalID, req.Reason, req.Objectives, req.SuccessCriteria, req.ReminderDays); err != nil {
+		return nil, err
+	}
+	if err := uc.pipRepo.Update(pip); err != nil {
+		return nil, err
+	}
+	return pip, nil
+}
+
+func (uc *PIPUseCase) Get(ctx context.Context, id int) (*PIPDetail, error) {
+	pip, err := uc.pipRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	checkIns, err := uc.checkInRepo.GetByPIP(id)
+	if err != nil {
+		return nil, err
+	}
+	return &PIPDetail{PerformancePIP: pip, CheckIns: checkIns}, nil
+}
+
+func (uc *PIPUseCase) List(ctx context.Context, filter *domain.PerformancePIPFilter) ([]*domain.PerformancePIP, error) {
+	return uc.pipRepo.GetAll(filter)
+}
+
+// ScheduleCheckIn adds a check-in within the plan's dates
+func (uc *PIPUseCase) ScheduleCheckIn(ctx context.Context, pipID int, req *CheckInScheduleRequest) (*domain.PIPCheckIn, error) {
+	date, err := time.Parse(dateLayout, req.ScheduledDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scheduled_date, expected YYYY-MM-DD")
+	}
+	pip, err := uc.activePIP(pipID)
+	if err != nil {
+		return nil, err
+	}
+	if date.Before(pip.StartDate) || date.After(pip.EndDate) {
+		return nil, fmt.Errorf("scheduled_date must fall between the plan's start_date and end_date")
+	}
+
+	checkIn := &domain.PIPCheckIn{
+		PIPID:         pip.ID,
+		ScheduledDate: date,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := uc.checkInRepo.Create(checkIn); err != nil {
+		return nil, err
+	}
+	return checkIn, nil
+}
+
+func (uc *PIPUseCase) CheckIns(ctx context.Context, pipID int) ([]*domain.PIPCheckIn, error) {
+	if _, err := uc.pipRepo.GetByID(pipID); err != nil {
+		return nil, err
+	}
+	return uc.checkInRepo.GetByPIP(pipID)
+}
+
+// RecordCheckIn records that a check-in was held, with its notes and a
+// progress rating. It can be corrected while the plan is running.
+func (uc *PIPUseCase) RecordCheckIn(ctx context.Context, pipID, checkInID int, req *CheckInRequest, recordedBy *int) (*domain.PIPCheckIn, error) {
+	held := currentDate()
+	if req.HeldDate != "" {
+		date, err := time.Parse(dateLayout, req.HeldDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid held_date, expected YYYY-MM-DD")
+		}
+		held = date
+	}
+	if held.After(currentDate()) {
+		return nil, fmt.Errorf("held_date cannot be in the future")
+	}
+	if err := domain.ValidateRating(req.ProgressRating); err != nil {
+		return nil, fmt.Errorf("progress_rating: %w", err)
+	}
+	if strings.TrimSpace(req.Notes) == "" {
+		return nil, fmt.Errorf("notes are required")
+	}
+
+	pip, err := uc.activePIP(pipID)
+	if err != nil {
+		return nil, err
+	}
+	checkIn, err := uc.planCheckIn(pip.ID, checkInID)
+	if err != nil {
+		return nil, err
+	}
+	if held.Before(pip.StartDate) {
+		return nil, fmt.Errorf("held_date cannot be before the plan's start_date")
+	}
+
+	rating := req.ProgressRating
+	checkIn.HeldDate = &held
+	checkIn.Notes = strings.TrimSpace(req.Notes)
+	checkIn.ProgressRating = &rating
+	checkIn.RecordedBy = recordedBy
+	if err := uc.checkInRepo.Update(checkIn); err != nil {
+		return nil, err
+	}
+	return checkIn, nil
+}
+
+// CancelCheckIn removes a check-in that has not been held
+func (uc *PIPUseCase) CancelCheckIn(ctx context.Context, pipID, checkInID int) error {
+	pip, err := uc.activePIP(pipID)
+	if err != nil {
+		return err
+	}
+	checkIn, err := uc.planCheckIn(pip.ID, checkInID)
+	if err != nil {
+		return err
+	}
+	if checkIn.HeldDate != nil {
+		return fmt.Errorf("%w: the check-in has been held", ErrInvalidState)
+	}
+	return uc.checkInRepo.Delete(checkIn.ID)
+}
+
+// RecordOutcome ends or extends a plan. Extending moves the end date and
+// re-arms the reminder; completing or terminating closes the plan. A
+// termination can record the employee's exit at the same time. Nobody can
+// record the outcome of their own plan.
+func (uc *PIPUseCase) RecordOutcome(ctx context.Context, id int, req *PIPOutcomeRequest, recordedBy *int) (*domain.PerformancePIP, error) {
+	pip, err := uc.activePIP(id)
+	if err != nil {
+		return nil, err
+	}
+	if recordedBy == nil {
+		return nil, fmt.Errorf("%w: PIP outcomes must be recorded by a user", ErrForbidden)
+	}
+	recorder, err := uc.orgDirectory.EmployeeOfUser(*recordedBy)
+	if err != nil {
+		return nil, err
+	}
+	if recorder != nil && *recorder == pip.EmployeeID {
+		return nil, fmt.Errorf("%w: you cannot record the outcome of your own plan", ErrForbidden)
+	}
+	if req.RecordExit && req.Outcome != domain.PIPOutcomeTerminated {
+		return nil, fmt.Errorf("an exit can only be recorded when the plan ends in termination")
+	}
+
+	today := currentDate()
+	var exit *domain.EmployeeExit
+	switch req.Outcome {
+	case domain.PIPOutcomeExtended:
+		if req.NewEndDate == "" {
+			return nil, fmt.Errorf("new_end_date is required to extend the plan")
+		}
+		end, err := time.Parse(dateLayout, req.NewEndDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid new_end_date, expected YYYY-MM-DD")
+		}
+		if !end.After(pip.EndDate) {
+			return nil, fmt.Errorf("new_end_date must be after the current end_date")
+		}
+		if pip.OriginalEndDate == nil {
+			original := pip.EndDate
+			pip.OriginalEndDate = &original
+		}
+		pip.EndDate = end
+		pip.ReminderSentAt = nil
+	case domain.PIPOutcomeCompleted, domain.PIPOutcomeTerminated:
+		pip.Status = domain.PIPStatusClosed
+	}
+	if req.RecordExit {
+		exitDate := today
+		if req.ExitDate != "" {
+			if exitDate, err = time.Parse(dateLayout, req.ExitDate); err != nil {
+				return nil, fmt.Errorf("invalid exit_date, expected YYYY-MM-DD")
+			}
+		}
+		if _, err := uc.activeEmployee(pip.EmployeeID); err != nil {
+			return nil, err
+		}
+		reason := fmt.Sprintf("Terminated following performance improvement plan %d", pip.ID)
+		if notes := strings.TrimSpace(req.Notes); notes != "" {
+			reason += ": " + notes
+		}
+		exit = &domain.EmployeeExit{
+			EmployeeID: pip.EmployeeID,
+			Action:     domain.EmployeeExitTerminated,
+			Reason:     reason,
+			Date:       exitDate,
+			CreatedBy:  recordedBy,
+		}
+	}
+
+	pip.Outcome = req.Outcome
+	pip.OutcomeNotes = strings.TrimSpace(req.Notes)
+	pip.OutcomeDate = &today
+	if err := uc.pipRepo.RecordOutcome(pip, exit); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("PIP outcome recorded",
+		"pip_id", pip.ID,
+		"employee_id", pip.EmployeeID,
+		"outcome", pip.Outcome,
+		"exit_recorded", exit != nil)
+	return pip, nil
+}
+
+// Reminders returns the plans whose end-of-plan reminder falls due on date,
+// addressed to the employee's current manager, and marks them reminded so
+// each end date is only reminded once.
+func (uc *PIPUseCase) Reminders(ctx context.Context, date time.Time) ([]*PIPReminder, error) {
+	pips, err := uc.pipRepo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := []*PIPReminder{}
+	var byID map[int]domain.OrgEmployee
+	for _, pip := range pips {
+		if !pip.ReminderDue(date) {
+			continue
+		}
+		if byID == nil {
+			employees, err := uc.orgDirectory.Employees()
+			if err != nil {
+				return nil, err
+			}
+			byID = make(map[int]domain.OrgEmployee, len(employees))
+			for _, employee := range employees {
+				byID[employee.EmployeeID] = employee
+			}
+		}
+		checkIns, err := uc.checkInRepo.GetByPIP(pip.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		reminder := &PIPReminder{PIP: pip, DaysLeft: pip.DaysLeft(date)}
+		if employee, ok := byID[pip.EmployeeID]; ok {
+			if managerID, err := resolveManager(employee, byID); err == nil {
+				reminder.ManagerID = &managerID
+			}
+		}
+		for _, checkIn := range checkIns {
+			if checkIn.HeldDate == nil {
+				reminder.PendingCheckIns++
+			}
+		}
+
+		now := time.Now()
+		pip.ReminderSentAt = &now
+		if err := uc.pipRepo.Update(pip); err != nil {
+			return nil, err
+		}
+
+		uc.logger.Info("PIP reminder due",
+			"pip_id", pip.ID,
+			"employee_id", pip.EmployeeID,
+			"days_left", reminder.DaysLeft)
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
+
+// apply sets the plan's content, checking a linked appraisal belongs to the
+// plan's employee
+func (uc *PIPUseCase) apply(pip *domain.PerformancePIP, appraisalID *int, reason, objectives, successCriteria string, reminderDays *int) error {
+	if appraisalID != nil {
+		appraisal, err := uc.appraisalRepo.GetByID(*appraisalID)
+		if err != nil {
+			return err
+		}
+		if appraisal.EmployeeID != pip.EmployeeID {
+			return fmt.Errorf("appraisal %d is not the employee's appraisal", appraisal.ID)
+		}
+	}
+
+	pip.AppraisalID = appraisalID
+	pip.Reason = strings.TrimSpace(reason)
+	pip.Objectives = strings.TrimSpace(objectives)
+	pip.SuccessCriteria = strings.TrimSpace(successCriteria)
+	if reminderDays != nil && *reminderDays != pip.ReminderDays {
+		pip.ReminderDays = *reminderDays
+		pip.ReminderSentAt = nil
+	}
+	return pip.Validate()
+}
+
+// activeEmployee looks the employee up in the hierarchy and makes sure they
+// have not left
+func (uc *PIPUseCase) activeEmployee(employeeID int) (domain.OrgEmployee, error) {
+	employees, err := uc.orgDirectory.Employees()
+	if err != nil {
+		return domain.OrgEmployee{}, err
+	}
+	for _, employee := range employees {
+		if employee.EmployeeID != employeeID {
+			continue
+		}
+		if !employee.Active {
+			return domain.OrgEmployee{}, fmt.Errorf("%w: employee %d has left", ErrInvalidState, employeeID)
+		}
+		return employee, nil
+	}
+	return domain.OrgEmployee{}, fmt.Errorf("employee %d not found", employeeID)
+}
+
+// activePIP loads a plan that is still running
+func (uc *PIPUseCase) activePIP(id int) (*domain.PerformancePIP, error) {
+	pip, err := uc.pipRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if pip.Status != domain.PIPStatusActive {
+		return nil, fmt.Errorf("%w: PIP %d is %s", ErrInvalidState, pip.ID, pip.Outcome)
+	}
+	return pip, nil
+}
+
+// planCheckIn loads a check-in belonging to the plan
+func (uc *PIPUseCase) planCheckIn(pipID, checkInID int) (*domain.PIPCheckIn, error) {
+	checkIn, err := uc.checkInRepo.GetByID(checkInID)
+	if err != nil {
+		return nil, err
+	}
+	if checkIn.PIPID != pipID {
+		return nil, fmt.Errorf("PIP check-in %d: %w", checkInID, domain.ErrNotFound)
+	}
+	return checkIn, nil
+}
+
+func currentDate() time.Time {
+	year, month, day := time.Now().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
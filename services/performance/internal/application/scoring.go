@@ -0,0 +1,264 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+)
+
+type ScoringUseCase struct {
+	appraisalRepo  domain.PerformanceAppraisalRepository
+	cycleRepo      domain.PerformanceCycleRepository
+	goalRepo       domain.PerformanceGoalRepository
+	competencyRepo domain.PerformanceCompetencyRepository
+	ratingRepo     domain.CompetencyRatingRepository
+	scaleRepo      domain.RatingScaleRepository
+	nominationRepo domain.FeedbackNominationRepository
+	responseRepo   domain.FeedbackResponseRepository
+	orgDirectory   domain.OrgDirectory
+	logger         utils.Logger
+}
+
+func NewScoringUseCase(
+	appraisalRepo domain.PerformanceAppraisalRepository,
+	cycleRepo domain.PerformanceCycleRepository,
+	goalRepo domain.PerformanceGoalRepository,
+	competencyRepo domain.PerformanceCompetencyRepository,
+	ratingRepo domain.CompetencyRatingRepository,
+	scaleRepo domain.RatingScaleRepository,
+	nominationRepo domain.FeedbackNominationRepository,
+	responseRepo domain.FeedbackResponseRepository,
+	orgDirectory domain.OrgDirectory,
+	logger utils.Logger,
+) *ScoringUseCase {
+	return &ScoringUseCase{
+		appraisalRepo:  appraisalRepo,
+		cycleRepo:      cycleRepo,
+		goalRepo:       goalRepo,
+		competencyRepo: competencyRepo,
+		ratingRepo:     ratingRepo,
+		scaleRepo:      scaleRepo,
+		nominationRepo: nominationRepo,
+		responseRepo:   responseRepo,
+		orgDirectory:   orgDirectory,
+		logger:         logger,
+	}
+}
+
+type CompetencyRatingRequest struct {
+	CompetencyID int    `json:"competency_id" validate:"required"`
+	Rating       int    `json:"rating" validate:"required"`
+	Comments     string `json:"comments"`
+}
+
+type RatingBandRequest struct {
+	MinScore    float64 `json:"min_score"`
+	Rating      string  `json:"rating" validate:"required"`
+	Description string  `json:"description"`
+}
+
+type RatingScaleRequest struct {
+	Bands []RatingBandRequest `json:"bands" validate:"required,min=1,dive"`
+}
+
+// RateCompetency records the manager's rating of a competency on an
+// appraisal, replacing any earlier rating of it. The first rating moves a
+// draft appraisal to in progress.
+func (uc *ScoringUseCase) RateCompetency(ctx context.Context, appraisalID int, req *CompetencyRatingRequest, userID *int) (*domain.CompetencyRating, error) {
+	appraisal, _, err := uc.editableAppraisal(appraisalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkRater(userID, appraisal); err != nil {
+		return nil, err
+	}
+	if err := domain.ValidateRating(req.Rating); err != nil {
+		return nil, err
+	}
+	competency, err := uc.competencyRepo.GetByID(req.CompetencyID)
+	if err != nil {
+		return nil, err
+	}
+	if competency.IsActive != 1 {
+		return nil, fmt.Errorf("competency %s is no longer in use", competency.Title)
+	}
+
+	rating := &domain.CompetencyRating{
+		AppraisalID:  appraisal.ID,
+		CompetencyID: competency.ID,
+		Title:        competency.Title,
+		Weight:       competency.Weight,
+		Rating:       req.Rating,
+		Comments:     req.Comments,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if err := uc.ratingRepo.Save(rating); err != nil {
+		return nil, err
+	}
+
+	if appraisal.Status == domain.AppraisalStatusDraft {
+		appraisal.Status = domain.AppraisalStatusInProgress
+		if err := uc.appraisalRepo.Update(appraisal); err != nil {
+			return nil, err
+		}
+	}
+
+	return rating, nil
+}
+
+func (uc *ScoringUseCase) GetCompetencyRatings(ctx context.Context, appraisalID int) ([]*domain.CompetencyRating, error) {
+	if _, err := uc.appraisalRepo.GetByID(appraisalID); err != nil {
+		return nil, err
+	}
+	return uc.ratingRepo.GetByAppraisal(appraisalID)
+}
+
+// DeleteCompetencyRating takes a competency off an appraisal, e.g. one rated
+// by mistake
+func (uc *ScoringUseCase) DeleteCompetencyRating(ctx context.Context, appraisalID, competencyID int, userID *int) error {
+	appraisal, _, err := uc.editableAppraisal(appraisalID)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkRater(userID, appraisal); err != nil {
+		return err
+	}
+	return uc.ratingRepo.Delete(appraisalID, competencyID)
+}
+
+// Preview scores the appraisal from its current goals, ratings and feedback
+// without storing the result
+func (uc *ScoringUseCase) Preview(ctx context.Context, appraisalID int) (*domain.ScoreBreakdown, error) {
+	appraisal, err := uc.appraisalRepo.GetByID(appraisalID)
+	if err != nil {
+		return nil, err
+	}
+	cycle, err := uc.cycleRepo.GetByID(appraisal.CycleID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.score(appraisal, cycle)
+}
+
+// Score stores the appraisal's overall score and rating, for the manager
+// once they have rated it
+func (uc *ScoringUseCase) Score(ctx context.Context, appraisalID int, userID *int) (*domain.ScoreBreakdown, error) {
+	appraisal, cycle, err := uc.editableAppraisal(appraisalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkRater(userID, appraisal); err != nil {
+		return nil, err
+	}
+	breakdown, err := uc.score(appraisal, cycle)
+	if err != nil {
+		return nil, err
+	}
+
+	overall := breakdown.OverallScore
+	appraisal.OverallScore = &overall
+	appraisal.OverallRating = breakdown.OverallRating
+	if appraisal.Status == domain.AppraisalStatusDraft {
+		appraisal.Status = domain.AppraisalStatusInProgress
+	}
+	if err := uc.appraisalRepo.Update(appraisal); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Appraisal scored",
+		"appraisal_id", appraisal.ID,
+		"overall_score", overall,
+		"overall_rating", breakdown.OverallRating)
+	return breakdown, nil
+}
+
+func (uc *ScoringUseCase) GetRatingScale(ctx context.Context) ([]domain.RatingBand, error) {
+	return uc.scaleRepo.GetAll()
+}
+
+// SetRatingScale replaces the rating scale. Scores already stored keep the
+// rating they were given.
+func (uc *ScoringUseCase) SetRatingScale(ctx context.Context, req *RatingScaleRequest) ([]domain.RatingBand, error) {
+	now := time.Now()
+	scale := make([]domain.RatingBand, 0, len(req.Bands))
+	for _, band := range req.Bands {
+		scale = append(scale, domain.RatingBand{
+			MinScore:    band.MinScore,
+			Rating:      band.Rating,
+			Description: band.Description,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+	if err := domain.ValidateRatingScale(scale); err != nil {
+		return nil, err
+	}
+
+	if err := uc.scaleRepo.Replace(scale); err != nil {
+		return nil, err
+	}
+	return uc.scaleRepo.GetAll()
+}
+
+func (uc *ScoringUseCase) score(appraisal *domain.PerformanceAppraisal, cycle *domain.PerformanceCycle) (*domain.ScoreBreakdown, error) {
+	goals, err := uc.goalRepo.GetForAppraisal(appraisal.EmployeeID, appraisal.CycleID)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := uc.ratingRepo.GetByAppraisal(appraisal.ID)
+	if err != nil {
+		return nil, err
+	}
+	feedback, err := summarizeFeedback(uc.nominationRepo, uc.responseRepo, uc.competencyRepo, appraisal, cycle)
+	if err != nil {
+		return nil, err
+	}
+	scale, err := uc.scaleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.ScoreAppraisal(appraisal, cycle, goals, ratings, feedback, scale)
+}
+
+// checkRater makes sure the calling user is the appraisal's manager
+func (uc *ScoringUseCase) checkRater(userID *int, appraisal *domain.PerformanceAppraisal) error {
+	if userID == nil {
+		return fmt.Errorf("%w: appraisals must be rated by a user", ErrForbidden)
+	}
+	rater, err := uc.orgDirectory.EmployeeOfUser(*userID)
+	if err != nil {
+		return err
+	}
+	if rater == nil || *rater != appraisal.ManagerID {
+		return fmt.Errorf("%w: only the appraisal's manager can rate it", ErrForbidden)
+	}
+	return nil
+}
+
+// editableAppraisal loads an appraisal that can still be rated: it has not
+// been submitted for sign-off or calibrated, and its cycle is not closed
+func (uc *ScoringUseCase) editableAppraisal(id int) (*domain.PerformanceAppraisal, *domain.PerformanceCycle, error) {
+	appraisal, err := uc.appraisalRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if appraisal.Status == domain.AppraisalStatusSubmitted || appraisal.Status == domain.AppraisalStatusCompleted {
+		return nil, nil, fmt.Errorf("%w: appraisal is %s", ErrInvalidState, appraisal.Status)
+	}
+	if appraisal.CalibrationSessionID != nil {
+		return nil, nil, fmt.Errorf("%w: the appraisal's rating has been calibrated", ErrInvalidState)
+	}
+	cycle, err := uc.cycleRepo.GetByID(appraisal.CycleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cycle.Status == domain.CycleStatusClosed {
+		return nil, nil, fmt.Errorf("%w: cycle %s is closed", ErrInvalidState, cycle.Name)
+	}
+	return appraisal, cycle, nil
+}
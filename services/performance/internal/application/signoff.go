@@ -0,0 +1,243 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+)
+
+// SignOffUseCase takes a scored appraisal through review and signatures.
+// The manager submits it, which freezes its ratings, the discussion with the
+// employee is recorded, and once both have signed the appraisal is completed
+// and locked.
+type SignOffUseCase struct {
+	appraisalRepo domain.PerformanceAppraisalRepository
+	cycleRepo     domain.PerformanceCycleRepository
+	orgDirectory  domain.OrgDirectory
+	logger        utils.Logger
+}
+
+func NewSignOffUseCase(
+	appraisalRepo domain.PerformanceAppraisalRepository,
+	cycleRepo domain.PerformanceCycleRepository,
+	orgDirectory domain.OrgDirectory,
+	logger utils.Logger,
+) *SignOffUseCase {
+	return &SignOffUseCase{
+		appraisalRepo: appraisalRepo,
+		cycleRepo:     cycleRepo,
+		orgDirectory:  orgDirectory,
+		logger:        logger,
+	}
+}
+
+type AppraisalReviewRequest struct {
+	ManagerComments string `json:"manager_comments"`
+	NextPeriodGoals string `json:"next_period_goals"`
+	DevelopmentPlan string `json:"development_plan"`
+}
+
+type DiscussionRequest struct {
+	DiscussionDate string `json:"discussion_date" validate:"required"`
+}
+
+type EmployeeSignRequest struct {
+	EmployeeComments string `json:"employee_comments"`
+}
+
+// UpdateReview saves the manager's written review while the appraisal is
+// still being prepared
+func (uc *SignOffUseCase) UpdateReview(ctx context.Context, id int, req *AppraisalReviewRequest) (*domain.PerformanceAppraisal, error) {
+	appraisal, err := uc.appraisalIn(id, domain.AppraisalStatusDraft, domain.AppraisalStatusInProgress)
+	if err != nil {
+		return nil, err
+	}
+
+	appraisal.ManagerComments = req.ManagerComments
+	appraisal.NextPeriodGoals = req.NextPeriodGoals
+	appraisal.DevelopmentPlan = req.DevelopmentPlan
+	if appraisal.Status == domain.AppraisalStatusDraft {
+		appraisal.Status = domain.AppraisalStatusInProgress
+	}
+	if err := uc.appraisalRepo.Update(appraisal); err != nil {
+		return nil, err
+	}
+	return appraisal, nil
+}
+
+// Submit hands a scored appraisal over for discussion and signatures
+func (uc *SignOffUseCase) Submit(ctx context.Context, id int) (*domain.PerformanceAppraisal, error) {
+	appraisal, err := uc.appraisalIn(id, domain.AppraisalStatusDraft, domain.AppraisalStatusInProgress)
+	if err != nil {
+		return nil, err
+	}
+	if appraisal.OverallScore == nil || appraisal.OverallRating == "" {
+		return nil, fmt.Errorf("score the appraisal before submitting it")
+	}
+
+	appraisal.Status = domain.AppraisalStatusSubmitted
+	if err := uc.appraisalRepo.Update(appraisal); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Appraisal submitted", "appraisal_id", appraisal.ID)
+	return appraisal, nil
+}
+
+// RecordDiscussion records when the manager and employee went through the
+// appraisal. It can be moved until someone has signed.
+func (uc *SignOffUseCase) RecordDiscussion(ctx context.Context, id int, req *DiscussionRequest) (*domain.PerformanceAppraisal, error) {
+	date, err := time.Parse(dateLayout, req.DiscussionDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discussion_date, expected YYYY-MM-DD")
+	}
+	appraisal, err := uc.appraisalIn(id, domain.AppraisalStatusSubmitted)
+	if err != nil {
+		return nil, err
+	}
+	if appraisal.EmployeeSignedAt != nil || appraisal.ManagerSignedAt != nil {
+		return nil, fmt.Errorf("%w: the appraisal has been signed", ErrInvalidState)
+	}
+
+	appraisal.DiscussionDate = &date
+	if err := uc.appraisalRepo.Update(appraisal); err != nil {
+		return nil, err
+	}
+	return appraisal, nil
+}
+
+// SignAsEmployee records the employee's signature and their comments. Only
+// the appraised employee can sign.
+func (uc *SignOffUseCase) SignAsEmployee(ctx context.Context, id int, req *EmployeeSignRequest, userID *int) (*domain.PerformanceAppraisal, error) {
+	appraisal, err := uc.signable(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkSigner(userID, appraisal.EmployeeID); err != nil {
+		return nil, err
+	}
+	if appraisal.EmployeeSignedAt != nil {
+		return nil, fmt.Errorf("%w: the employee has already signed", ErrInvalidState)
+	}
+
+	now := time.Now()
+	appraisal.EmployeeSignedAt = &now
+	if req.EmployeeComments != "" {
+		appraisal.EmployeeComments = req.EmployeeComments
+	}
+	return uc.saveSignature(appraisal)
+}
+
+// SignAsManager records the manager's signature. Only the appraisal's
+// manager can sign.
+func (uc *SignOffUseCase) SignAsManager(ctx context.Context, id int, userID *int) (*domain.PerformanceAppraisal, error) {
+	appraisal, err := uc.signable(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkSigner(userID, appraisal.ManagerID); err != nil {
+		return nil, err
+	}
+	if appraisal.ManagerSignedAt != nil {
+		return nil, fmt.Errorf("%w: the manager has already signed", ErrInvalidState)
+	}
+
+	now := time.Now()
+	appraisal.ManagerSignedAt = &now
+	return uc.saveSignature(appraisal)
+}
+
+// Reopen sends a submitted appraisal back to the manager, e.g. when the
+// employee disputes it. Any signature given so far is withdrawn; a calibrated
+// rating stays locked.
+func (uc *SignOffUseCase) Reopen(ctx context.Context, id int) (*domain.PerformanceAppraisal, error) {
+	appraisal, err := uc.appraisalIn(id, domain.AppraisalStatusSubmitted)
+	if err != nil {
+		return nil, err
+	}
+
+	appraisal.Status = domain.AppraisalStatusInProgress
+	appraisal.EmployeeSignedAt = nil
+	appraisal.ManagerSignedAt = nil
+	if err := uc.appraisalRepo.Update(appraisal); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Appraisal reopened", "appraisal_id", appraisal.ID)
+	return appraisal, nil
+}
+
+// saveSignature completes the appraisal once both have signed
+func (uc *SignOffUseCase) saveSignature(appraisal *domain.PerformanceAppraisal) (*domain.PerformanceAppraisal, error) {
+	if appraisal.EmployeeSignedAt != nil && appraisal.ManagerSignedAt != nil {
+		appraisal.Status = domain.AppraisalStatusCompleted
+	}
+	if err := uc.appraisalRepo.Update(appraisal); err != nil {
+		return nil, err
+	}
+
+	if appraisal.Status == domain.AppraisalStatusCompleted {
+		uc.logger.Info("Appraisal signed off", "appraisal_id", appraisal.ID)
+	}
+	return appraisal, nil
+}
+
+// checkSigner makes sure the calling user is the employee who must sign
+func (uc *SignOffUseCase) checkSigner(userID *int, employeeID int) error {
+	if userID == nil {
+		return fmt.Errorf("%w: appraisals must be signed by a user", ErrForbidden)
+	}
+	signer, err := uc.orgDirectory.EmployeeOfUser(*userID)
+	if err != nil {
+		return err
+	}
+	if signer == nil || *signer != employeeID {
+		return fmt.Errorf("%w: you cannot sign this appraisal on someone else's behalf", ErrForbidden)
+	}
+	return nil
+}
+
+// signable loads a submitted appraisal whose discussion has taken place
+func (uc *SignOffUseCase) signable(id int) (*domain.PerformanceAppraisal, error) {
+	appraisal, err := uc.appraisalIn(id, domain.AppraisalStatusSubmitted)
+	if err != nil {
+		return nil, err
+	}
+	if appraisal.DiscussionDate == nil {
+		return nil, fmt.Errorf("%w: record the appraisal discussion before signing", ErrInvalidState)
+	}
+	if appraisal.DiscussionDate.After(time.Now()) {
+		return nil, fmt.Errorf("%w: the appraisal discussion has not taken place yet", ErrInvalidState)
+	}
+	return appraisal, nil
+}
+
+// appraisalIn loads an appraisal in one of the given statuses whose cycle is
+// not closed. Completed appraisals are locked.
+func (uc *SignOffUseCase) appraisalIn(id int, statuses ...string) (*domain.PerformanceAppraisal, error) {
+	appraisal, err := uc.appraisalRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	allowed := false
+	for _, status := range statuses {
+		if appraisal.Status == status {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: appraisal is %s", ErrInvalidState, appraisal.Status)
+	}
+
+	cycle, err := uc.cycleRepo.GetByID(appraisal.CycleID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.Status == domain.CycleStatusClosed {
+		return nil, fmt.Errorf("%w: cycle %s is closed", ErrInvalidState, cycle.Name)
+	}
+	return appraisal, nil
+}
@@ -0,0 +1,557 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+)
+
+// defaultNeedThreshold is the highest competency rating treated as a
+// training need unless the request says otherwise
+const defaultNeedThreshold = 2
+
+type TrainingUseCase struct {
+	trainingRepo   domain.PerformanceTrainingRepository
+	enrolmentRepo  domain.TrainingEnrolmentRepository
+	budgetRepo     domain.TrainingBudgetRepository
+	competencyRepo domain.PerformanceCompetencyRepository
+	goalRepo       domain.PerformanceGoalRepository
+	cycleRepo      domain.PerformanceCycleRepository
+	orgDirectory   domain.OrgDirectory
+	logger         utils.Logger
+}
+
+func NewTrainingUseCase(
+	trainingRepo domain.PerformanceTrainingRepository,
+	enrolmentRepo domain.TrainingEnrolmentRepository,
+	budgetRepo domain.TrainingBudgetRepository,
+	competencyRepo domain.PerformanceCompetencyRepository,
+	goalRepo domain.PerformanceGoalRepository,
+	cycleRepo domain.PerformanceCycleRepository,
+	orgDirectory domain.OrgDirectory,
+	logger utils.Logger,
+) *TrainingUseCase {
+	return &TrainingUseCase{
+		trainingRepo:   trainingRepo,
+		enrolmentRepo:  enrolmentRepo,
+		budgetRepo:     budgetRepo,
+		competencyRepo: competencyRepo,
+		goalRepo:       goalRepo,
+		cycleRepo:      cycleRepo,
+		orgDirectory:   orgDirectory,
+		logger:         logger,
+	}
+}
+
+type TrainingRequest struct {
+	Title                string   `json:"title" validate:"required"`
+	Description          string   `json:"description"`
+	TrainingType         string   `json:"training_type"`
+	AssignedToEmployeeID *int     `json:"assigned_to_employee_id"` // enrolled when the training is created
+	LinkedGoalID         *int     `json:"linked_goal_id"`
+	LinkedCompetencyID   *int     `json:"linked_competency_id"`
+	StartDate            string   `json:"start_date"`
+	EndDate              string   `json:"end_date"`
+	Cost                 *float64 `json:"cost"` // per participant
+	Hours                *float64 `json:"hours"`
+	Provider             string   `json:"provider"`
+}
+
+type TrainingCompleteRequest struct {
+	CompletionDate string `json:"completion_date"` // today when left out
+}
+
+type EnrolmentRequest struct {
+	EmployeeID int      `json:"employee_id" validate:"required"`
+	Cost       *float64 `json:"cost"` // the training's cost when left out
+}
+
+type EnrolmentCompleteRequest struct {
+	CompletedAt string   `json:"completed_at"` // today when left out
+	Hours       *float64 `json:"hours"`        // the training's hours when left out
+}
+
+type TrainingBudgetRequest struct {
+	DepartmentID int     `json:"department_id" validate:"required"`
+	Year         int     `json:"year" validate:"required"`
+	Amount       float64 `json:"amount"`
+}
+
+// TrainingDetail is a training with its enrolments
+type TrainingDetail struct {
+	*domain.PerformanceTraining
+	Enrolments []*domain.TrainingEnrolment `json:"enrolments"`
+}
+
+// Create adds a training. A training assigned to an employee enrols them
+// straight away.
+func (uc *TrainingUseCase) Create(ctx context.Context, req *TrainingRequest, createdBy *int) (*TrainingDetail, error) {
+	training := &domain.PerformanceTraining{
+		Status:    domain.TrainingStatusActive,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := uc.apply(training, req); err != nil {
+		return nil, err
+	}
+
+	var employee domain.OrgEmployee
+	if training.AssignedToEmployeeID != nil {
+		var err error
+		if employee, err = uc.activeEmployee(*training.AssignedToEmployeeID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.trainingRepo.Create(training); err != nil {
+		return nil, err
+	}
+
+	enrolments := []*domain.TrainingEnrolment{}
+	if training.AssignedToEmployeeID != nil {
+		enrolment := newEnrolment(training, employee, nil, createdBy)
+		if err := uc.enrolmentRepo.Create(enrolment); err != nil {
+			return nil, err
+		}
+		enrolments = append(enrolments, enrolment)
+	}
+
+	uc.logger.Info("Training created", "training_id", training.ID, "title", training.Title)
+	return &TrainingDetail{PerformanceTraining: training, Enrolments: enrolments}, nil
+}
+
+// Update changes a training that has not been closed. It cannot be assigned
+// to an employee while others are enrolled on it.
+func (uc *TrainingUseCase) Update(ctx context.Context, id int, req *TrainingRequest) (*domain.PerformanceTraining, error) {
+	training, err := uc.activeTraining(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.apply(training, req); err != nil {
+		return nil, err
+	}
+
+	if training.AssignedToEmployeeID != nil {
+		enrolments, err := uc.enrolmentRepo.GetByTraining(training.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, enrolment := range enrolments {
+			if enrolment.Status != domain.EnrolmentStatusCancelled && enrolment.EmployeeID != *training.AssignedToEmployeeID {
+				return nil, fmt.Errorf("%w: employee %d is enrolled on the training", ErrInvalidState, enrolment.EmployeeID)
+			}
+		}
+	}
+
+	if err := uc.trainingRepo.Update(training); err != nil {
+		return nil, err
+	}
+	return training, nil
+}
+
+func (uc *TrainingUseCase) Get(ctx context.Context, id int) (*TrainingDetail, error) {
+	training, err := uc.trainingRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	enrolments, err := uc.enrolmentRepo.GetByTraining(id)
+	if err != nil {
+		return nil, err
+	}
+	return &TrainingDetail{PerformanceTraining: training, Enrolments: enrolments}, nil
+}
+
+func (uc *TrainingUseCase) List(ctx context.Context, filter *domain.PerformanceTrainingFilter) ([]*domain.PerformanceTraining, error) {
+	return uc.trainingRepo.GetAll(filter)
+}
+
+// Complete closes the training as held, completing everyone still enrolled
+func (uc *TrainingUseCase) Complete(ctx context.Context, id int, req *TrainingCompleteRequest) (*domain.PerformanceTraining, error) {
+	completed := currentDate()
+	if req.CompletionDate != "" {
+		date, err := time.Parse(dateLayout, req.CompletionDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid completion_date, expected YYYY-MM-DD")
+		}
+		completed = date
+	}
+	training, err := uc.activeTraining(id)
+	if err != nil {
+		return nil, err
+	}
+	if training.StartDate != nil && completed.Before(*training.StartDate) {
+		return nil, fmt.Errorf("completion_date cannot be before the training's start_date")
+	}
+
+	training.Status = domain.TrainingStatusCompleted
+	training.Completed = 1
+	training.CompletionDate = &completed
+	if err := uc.trainingRepo.Close(training); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Training completed", "training_id", training.ID)
+	return training, nil
+}
+
+// Cancel closes the training without it being held, cancelling everyone
+// still enrolled so their places no longer count against the budget
+func (uc *TrainingUseCase) Cancel(ctx context.Context, id int) (*domain.PerformanceTraining, error) {
+	training, err := uc.activeTraining(id)
+	if err != nil {
+		return nil, err
+	}
+
+	training.Status = domain.TrainingStatusCancelled
+	if err := uc.trainingRepo.Close(training); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Training cancelled", "training_id", training.ID)
+	return training, nil
+}
+
+// Enrol places an active employee on the training, charged to their current
+// department. An employee holds one place per training, and a training
+// assigned to an employee is only open to them.
+func (uc *TrainingUseCase) Enrol(ctx context.Context, trainingID int, req *EnrolmentRequest, enrolledBy *int) (*domain.TrainingEnrolment, error) {
+	if req.Cost != nil && *req.Cost < 0 {
+		return nil, fmt.Errorf("cost cannot be negative")
+	}
+	training, err := uc.activeTraining(trainingID)
+	if err != nil {
+		return nil, err
+	}
+	if training.AssignedToEmployeeID != nil && *training.AssignedToEmployeeID != req.EmployeeID {
+		return nil, fmt.Errorf("%w: training %d is assigned to employee %d", ErrInvalidState, training.ID, *training.AssignedToEmployeeID)
+	}
+	employee, err := uc.activeEmployee(req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolments, err := uc.enrolmentRepo.GetByTraining(training.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, enrolment := range enrolments {
+		if enrolment.EmployeeID == req.EmployeeID && enrolment.Status != domain.EnrolmentStatusCancelled {
+			return nil, fmt.Errorf("%w: employee %d is already enrolled on the training", ErrInvalidState, req.EmployeeID)
+		}
+	}
+
+	enrolment := newEnrolment(training, employee, req.Cost, enrolledBy)
+	if err := uc.enrolmentRepo.Create(enrolment); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Employee enrolled on training",
+		"training_id", training.ID,
+		"employee_id", enrolment.EmployeeID,
+		"cost", enrolment.Cost)
+	return enrolment, nil
+}
+
+func (uc *TrainingUseCase) Enrolments(ctx context.Context, trainingID int) ([]*domain.TrainingEnrolment, error) {
+	if _, err := uc.trainingRepo.GetByID(trainingID); err != nil {
+		return nil, err
+	}
+	return uc.enrolmentRepo.GetByTraining(trainingID)
+}
+
+// CompleteEnrolment records that one participant has completed the
+// training, crediting them with its hours unless told otherwise
+func (uc *TrainingUseCase) CompleteEnrolment(ctx context.Context, trainingID, enrolmentID int, req *EnrolmentCompleteRequest) (*domain.TrainingEnrolment, error) {
+	completed := currentDate()
+	if req.CompletedAt != "" {
+		date, err := time.Parse(dateLayout, req.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid completed_at, expected YYYY-MM-DD")
+		}
+		completed = date
+	}
+	if completed.After(currentDate()) {
+		return nil, fmt.Errorf("completed_at cannot be in the future")
+	}
+	if req.Hours != nil && *req.Hours < 0 {
+		return nil, fmt.Errorf("hours cannot be negative")
+	}
+
+	training, err := uc.activeTraining(trainingID)
+	if err != nil {
+		return nil, err
+	}
+	enrolment, err := uc.openEnrolment(training.ID, enrolmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolment.Hours = 0
+	if req.Hours != nil {
+		enrolment.Hours = *req.Hours
+	} else if training.Hours != nil {
+		enrolment.Hours = *training.Hours
+	}
+	enrolment.Status = domain.EnrolmentStatusCompleted
+	enrolment.CompletedAt = &completed
+	if err := uc.enrolmentRepo.Update(enrolment); err != nil {
+		return nil, err
+	}
+	return enrolment, nil
+}
+
+// CancelEnrolment gives up a place that has not been completed
+func (uc *TrainingUseCase) CancelEnrolment(ctx context.Context, trainingID, enrolmentID int) (*domain.TrainingEnrolment, error) {
+	training, err := uc.activeTraining(trainingID)
+	if err != nil {
+		return nil, err
+	}
+	enrolment, err := uc.openEnrolment(training.ID, enrolmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolment.Status = domain.EnrolmentStatusCancelled
+	if err := uc.enrolmentRepo.Update(enrolment); err != nil {
+		return nil, err
+	}
+	return enrolment, nil
+}
+
+// Needs lists the competencies employees were rated at or below maxRating
+// on in the cycle, lowest first, with whether they are already enrolled on
+// training for them. A department narrows it to that department's current
+// employees.
+func (uc *TrainingUseCase) Needs(ctx context.Context, cycleID int, maxRating *int, departmentID *int) ([]*domain.TrainingNeed, error) {
+	threshold := defaultNeedThreshold
+	if maxRating != nil {
+		if err := domain.ValidateRating(*maxRating); err != nil {
+			return nil, fmt.Errorf("max_rating: %w", err)
+		}
+		threshold = *maxRating
+	}
+	if _, err := uc.cycleRepo.GetByID(cycleID); err != nil {
+		return nil, err
+	}
+
+	needs, err := uc.trainingRepo.GetNeeds(cycleID, threshold)
+	if err != nil {
+		return nil, err
+	}
+	departments, err := uc.departments()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []*domain.TrainingNeed{}
+	for _, need := range needs {
+		need.DepartmentID = departments[need.EmployeeID]
+		if departmentID != nil && !sameDepartment(need.DepartmentID, departmentID) {
+			continue
+		}
+		filtered = append(filtered, need)
+	}
+	return filtered, nil
+}
+
+// SetBudget sets a department's training budget for the year, replacing
+// any budget it already has
+func (uc *TrainingUseCase) SetBudget(ctx context.Context, req *TrainingBudgetRequest) (*domain.TrainingBudget, error) {
+	if req.Amount < 0 {
+		return nil, fmt.Errorf("amount cannot be negative")
+	}
+	if req.Year < 1 {
+		return nil, fmt.Errorf("year is required")
+	}
+
+	budget := &domain.TrainingBudget{
+		DepartmentID: req.DepartmentID,
+		Year:         req.Year,
+		Amount:       roundTwo(req.Amount),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if err := uc.budgetRepo.Save(budget); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+func (uc *TrainingUseCase) Budgets(ctx context.Context, year int) ([]*domain.TrainingBudget, error) {
+	return uc.budgetRepo.GetByYear(year)
+}
+
+// BudgetReport sets each department's training spend for the year against
+// its budget, optionally for one department
+func (uc *TrainingUseCase) BudgetReport(ctx context.Context, year int, departmentID *int) ([]domain.DepartmentTrainingSpend, error) {
+	budgets, err := uc.budgetRepo.GetByYear(year)
+	if err != nil {
+		return nil, err
+	}
+	enrolments, err := uc.enrolmentRepo.GetByYear(year)
+	if err != nil {
+		return nil, err
+	}
+
+	report := domain.BudgetReport(budgets, enrolments)
+	if departmentID == nil {
+		return report, nil
+	}
+	filtered := []domain.DepartmentTrainingSpend{}
+	for _, spend := range report {
+		if sameDepartment(spend.DepartmentID, departmentID) {
+			filtered = append(filtered, spend)
+		}
+	}
+	return filtered, nil
+}
+
+// EmployeeReport totals training hours and spend per employee for the year,
+// optionally for one department or one employee
+func (uc *TrainingUseCase) EmployeeReport(ctx context.Context, year int, departmentID, employeeID *int) ([]domain.EmployeeTrainingSummary, error) {
+	enrolments, err := uc.enrolmentRepo.GetByYear(year)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := []*domain.TrainingEnrolment{}
+	for _, enrolment := range enrolments {
+		if departmentID != nil && !sameDepartment(enrolment.DepartmentID, departmentID) {
+			continue
+		}
+		if employeeID != nil && enrolment.EmployeeID != *employeeID {
+			continue
+		}
+		selected = append(selected, enrolment)
+	}
+	return domain.SummarizeTraining(selected), nil
+}
+
+// apply sets the training's details, checking the goal and competency it is
+// linked to exist. A linked goal must be the assigned employee's own.
+func (uc *TrainingUseCase) apply(training *domain.PerformanceTraining, req *TrainingRequest) error {
+	var start, end *time.Time
+	if req.StartDate != "" {
+		date, err := time.Parse(dateLayout, req.StartDate)
+		if err != nil {
+			return fmt.Errorf("invalid start_date, expected YYYY-MM-DD")
+		}
+		start = &date
+	}
+	if req.EndDate != "" {
+		date, err := time.Parse(dateLayout, req.EndDate)
+		if err != nil {
+			return fmt.Errorf("invalid end_date, expected YYYY-MM-DD")
+		}
+		end = &date
+	}
+
+	if req.LinkedGoalID != nil {
+		goal, err := uc.goalRepo.GetByID(*req.LinkedGoalID)
+		if err != nil {
+			return err
+		}
+		if req.AssignedToEmployeeID != nil && goal.EmployeeID != *req.AssignedToEmployeeID {
+			return fmt.Errorf("goal %d is not the assigned employee's goal", goal.ID)
+		}
+	}
+	if req.LinkedCompetencyID != nil {
+		if _, err := uc.competencyRepo.GetByID(*req.LinkedCompetencyID); err != nil {
+			return err
+		}
+	}
+
+	training.Title = strings.TrimSpace(req.Title)
+	training.Description = req.Description
+	training.TrainingType = req.TrainingType
+	training.AssignedToEmployeeID = req.AssignedToEmployeeID
+	training.LinkedGoalID = req.LinkedGoalID
+	training.LinkedCompetencyID = req.LinkedCompetencyID
+	training.StartDate = start
+	training.EndDate = end
+	training.Cost = req.Cost
+	training.Hours = req.Hours
+	training.Provider = req.Provider
+	return training.Validate()
+}
+
+// activeTraining loads a training that has not been closed
+func (uc *TrainingUseCase) activeTraining(id int) (*domain.PerformanceTraining, error) {
+	training, err := uc.trainingRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if training.Status != domain.TrainingStatusActive {
+		return nil, fmt.Errorf("%w: training %d is %s", ErrInvalidState, training.ID, training.Status)
+	}
+	return training, nil
+}
+
+// openEnrolment loads an enrolment on the training that is still enrolled
+func (uc *TrainingUseCase) openEnrolment(trainingID, enrolmentID int) (*domain.TrainingEnrolment, error) {
+	enrolment, err := uc.enrolmentRepo.GetByID(enrolmentID)
+	if err != nil {
+		return nil, err
+	}
+	if enrolment.TrainingID != trainingID {
+		return nil, fmt.Errorf("training enrolment %d: %w", enrolmentID, domain.ErrNotFound)
+	}
+	if enrolment.Status != domain.EnrolmentStatusEnrolled {
+		return nil, fmt.Errorf("%w: enrolment %d is %s", ErrInvalidState, enrolment.ID, enrolment.Status)
+	}
+	return enrolment, nil
+}
+
+// activeEmployee looks the employee up in the hierarchy and makes sure they
+// have not left
+func (uc *TrainingUseCase) activeEmployee(employeeID int) (domain.OrgEmployee, error) {
+	employees, err := uc.orgDirectory.Employees()
+	if err != nil {
+		return domain.OrgEmployee{}, err
+	}
+	for _, employee := range employees {
+		if employee.EmployeeID != employeeID {
+			continue
+		}
+		if !employee.Active {
+			return domain.OrgEmployee{}, fmt.Errorf("%w: employee %d has left", ErrInvalidState, employeeID)
+		}
+		return employee, nil
+	}
+	return domain.OrgEmployee{}, fmt.Errorf("employee %d not found", employeeID)
+}
+
+// departments maps each employee to their current department
+func (uc *TrainingUseCase) departments() (map[int]*int, error) {
+	employees, err := uc.orgDirectory.Employees()
+	if err != nil {
+		return nil, err
+	}
+	departments := make(map[int]*int, len(employees))
+	for _, employee := range employees {
+		departments[employee.EmployeeID] = employee.DepartmentID
+	}
+	return departments, nil
+}
+
+func newEnrolment(training *domain.PerformanceTraining, employee domain.OrgEmployee, cost *float64, enrolledBy *int) *domain.TrainingEnrolment {
+	enrolment := &domain.TrainingEnrolment{
+		TrainingID:   training.ID,
+		EmployeeID:   employee.EmployeeID,
+		DepartmentID: employee.DepartmentID,
+		Status:       domain.EnrolmentStatusEnrolled,
+		EnrolledBy:   enrolledBy,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if cost != nil {
+		enrolment.Cost = roundTwo(*cost)
+	} else if training.Cost != nil {
+		enrolment.Cost = roundTwo(*training.Cost)
+	}
+	return enrolment
+}
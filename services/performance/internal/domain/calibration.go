@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Calibration session statuses. Ratings can be adjusted while a session is
+// open; finalizing it checks the forced-distribution limits.
+const (
+	CalibrationStatusOpen      = "open"
+	CalibrationStatusFinalized = "finalized"
+)
+
+// CalibrationSession is HR's review of the ratings given in a cycle, for one
+// department or, without DepartmentID, the whole organisation. Stored in
+// tbl_pf_calibration_sessions with its limits in tbl_pf_calibration_limits.
+type CalibrationSession struct {
+	ID           int                 `json:"id" db:"id"`
+	CycleID      int                 `json:"cycle_id" db:"cycle_id"`
+	DepartmentID *int                `json:"department_id" db:"department_id"`
+	Name         string              `json:"name" db:"name"`
+	Notes        string              `json:"notes" db:"notes"`
+	Status       string              `json:"status" db:"status"` // open, finalized
+	Limits       []DistributionLimit `json:"limits"`
+	CreatedBy    *int                `json:"created_by" db:"created_by"`
+	FinalizedAt  *time.Time          `json:"finalized_at" db:"finalized_at"`
+	CreatedAt    time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// Covers reports whether an employee in departmentID falls in the session
+func (s *CalibrationSession) Covers(departmentID *int) bool {
+	if s.DepartmentID == nil {
+		return true
+	}
+	return departmentID != nil && *departmentID == *s.DepartmentID
+}
+
+// DistributionLimit caps the share of rated appraisals that may get a rating
+type DistributionLimit struct {
+	Rating     string  `json:"rating" db:"rating"`
+	MaxPercent float64 `json:"max_percent" db:"max_percent"`
+}
+
+// CalibrationAdjustment records a rating changed during calibration, stored
+// in tbl_pf_calibration_adjustments
+type CalibrationAdjustment struct {
+	ID             int       `json:"id" db:"id"`
+	SessionID      int       `json:"session_id" db:"session_id"`
+	AppraisalID    int       `json:"appraisal_id" db:"appraisal_id"`
+	PreviousRating string    `json:"previous_rating" db:"previous_rating"`
+	NewRating      string    `json:"new_rating" db:"new_rating"`
+	Justification  string    `json:"justification" db:"justification"`
+	AdjustedBy     *int      `json:"adjusted_by" db:"adjusted_by"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// DistributionBand is how many appraisals got one rating
+type DistributionBand struct {
+	Rating     string   `json:"rating"`
+	MinScore   *float64 `json:"min_score"` // nil for ratings no longer on the scale
+	Count      int      `json:"count"`
+	Percent    float64  `json:"percent"`
+	MaxPercent *float64 `json:"max_percent"`
+	OverLimit  bool     `json:"over_limit"`
+}
+
+// RatingDistribution is the spread of ratings across the appraisals in a
+// calibration session. Percentages are of the rated appraisals.
+type RatingDistribution struct {
+	SessionID    int                `json:"session_id"`
+	Appraisals   int                `json:"appraisals"`
+	Rated        int                `json:"rated"`
+	Unrated      int                `json:"unrated"`
+	Bands        []DistributionBand `json:"bands"`
+	WithinLimits bool               `json:"within_limits"`
+}
+
+// Violations describes the bands over their limit
+func (d *RatingDistribution) Violations() []string {
+	var violations []string
+	for _, band := range d.Bands {
+		if band.OverLimit {
+			violations = append(violations, fmt.Sprintf("%s has %.2f%% of ratings, the limit is %.2f%%", band.Rating, band.Percent, *band.MaxPercent))
+		}
+	}
+	return violations
+}
+
+// Distribution counts the appraisals' ratings band by band, highest band
+// first, and checks them against the limits
+func Distribution(sessionID int, appraisals []*PerformanceAppraisal, scale []RatingBand, limits []DistributionLimit) *RatingDistribution {
+	distribution := &RatingDistribution{
+		SessionID:    sessionID,
+		Appraisals:   len(appraisals),
+		Bands:        []DistributionBand{},
+		WithinLimits: true,
+	}
+
+	bands := make([]RatingBand, len(scale))
+	copy(bands, scale)
+	sort.Slice(bands, func(i, j int) bool { return bands[i].MinScore > bands[j].MinScore })
+
+	index := map[string]int{}
+	for _, band := range bands {
+		minScore := band.MinScore
+		index[strings.ToLower(band.Rating)] = len(distribution.Bands)
+		distribution.Bands = append(distribution.Bands, DistributionBand{Rating: band.Rating, MinScore: &minScore})
+	}
+	for _, appraisal := range appraisals {
+		if appraisal.OverallRating == "" {
+			distribution.Unrated++
+			continue
+		}
+		distribution.Rated++
+		key := strings.ToLower(appraisal.OverallRating)
+		i, ok := index[key]
+		if !ok {
+			i = len(distribution.Bands)
+			index[key] = i
+			distribution.Bands = append(distribution.Bands, DistributionBand{Rating: appraisal.OverallRating})
+		}
+		distribution.Bands[i].Count++
+	}
+
+	for _, limit := range limits {
+		i, ok := index[strings.ToLower(limit.Rating)]
+		if !ok {
+			continue
+		}
+		maxPercent := limit.MaxPercent
+		distribution.Bands[i].MaxPercent = &maxPercent
+	}
+	for i := range distribution.Bands {
+		band := &distribution.Bands[i]
+		if distribution.Rated > 0 {
+			band.Percent = roundScore(float64(band.Count) / float64(distribution.Rated) * 100)
+		}
+		if band.MaxPercent != nil && band.Percent > *band.MaxPercent+WeightTolerance {
+			band.OverLimit = true
+			distribution.WithinLimits = false
+		}
+	}
+	return distribution
+}
+
+// ValidateLimits checks each limit names a rating on the scale once and that
+// the limits leave room for every appraisal
+func ValidateLimits(limits []DistributionLimit, scale []RatingBand) error {
+	if len(limits) == 0 {
+		return nil
+	}
+	ratings := map[string]bool{}
+	for _, band := range scale {
+		ratings[strings.ToLower(band.Rating)] = true
+	}
+
+	seen := map[string]bool{}
+	total := 0.0
+	for _, limit := range limits {
+		key := strings.ToLower(strings.TrimSpace(limit.Rating))
+		if !ratings[key] {
+			return fmt.Errorf("rating %q is not on the rating scale", limit.Rating)
+		}
+		if seen[key] {
+			return fmt.Errorf("rating %q has more than one limit", limit.Rating)
+		}
+		if limit.MaxPercent < 0 || limit.MaxPercent > 100 {
+			return fmt.Errorf("max_percent must be between 0 and 100")
+		}
+		seen[key] = true
+		total += limit.MaxPercent
+	}
+	if len(seen) == len(ratings) && total < 100-WeightTolerance {
+		return fmt.Errorf("the limits add up to %.2f%%, leaving no rating for some appraisals", total)
+	}
+	return nil
+}
+
+type CalibrationSessionRepository interface {
+	// Create stores the session with its limits
+	Create(session *CalibrationSession) error
+	GetByID(id int) (*CalibrationSession, error)
+	GetAll(filter *CalibrationSessionFilter) ([]*CalibrationSession, error)
+	// Update saves the session and replaces its limits
+	Update(session *CalibrationSession) error
+	// Finalize saves the finalized session and locks the ratings of the
+	// appraisals it covered in one transaction
+	Finalize(session *CalibrationSession, appraisalIDs []int) error
+}
+
+type CalibrationAdjustmentRepository interface {
+	// Create stores the adjustment and gives the appraisal its new rating,
+	// marked as calibrated by the session, in one transaction
+	Create(adjustment *CalibrationAdjustment) error
+	GetBySession(sessionID int) ([]*CalibrationAdjustment, error)
+}
+
+type CalibrationSessionFilter struct {
+	CycleID      *int
+	DepartmentID *int
+	Status       string
+	Limit        int
+	Offset       int
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// CompetencyRatingMax is the top of the 1 to 5 scale competencies are rated on
+const CompetencyRatingMax = 5
+
+// PerformanceCompetency is a behaviour or skill appraisals rate, stored in
+// tbl_pf_competencies. Weight is its share of the competency score in percent.
+type PerformanceCompetency struct {
+	ID                   int       `json:"id" db:"id"`
+	ParentID             *int      `json:"parent_id" db:"parent_id"`
+	Title                string    `json:"title" db:"title"`
+	Description          string    `json:"description" db:"description"`
+	CompetencyLevel      string    `json:"competency_level" db:"competency_level"`
+	BehavioralIndicators string    `json:"behavioral_indicators" db:"behavioral_indicators"`
+	Weight               float64   `json:"weight" db:"weight"`
+	IsActive             int       `json:"is_active" db:"is_active"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CompetencyRating is a manager's rating of one competency on an appraisal,
+// stored in tbl_pf_competency_ratings. Title and Weight are read from the
+// competency.
+type CompetencyRating struct {
+	ID           int       `json:"id" db:"id"`
+	AppraisalID  int       `json:"appraisal_id" db:"appraisal_id"`
+	CompetencyID int       `json:"competency_id" db:"competency_id"`
+	Title        string    `json:"title" db:"title"`
+	Weight       float64   `json:"weight" db:"weight"`
+	Rating       int       `json:"rating" db:"rating"`
+	Comments     string    `json:"comments" db:"comments"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ValidateRating checks a rating is on the competency scale
+func ValidateRating(rating int) error {
+	if rating < 1 || rating > CompetencyRatingMax {
+		return fmt.Errorf("rating must be between 1 and %d", CompetencyRatingMax)
+	}
+	return nil
+}
+
+type PerformanceCompetencyRepository interface {
+	Create(competency *PerformanceCompetency) error
+	GetByID(id int) (*PerformanceCompetency, error)
+	GetAll(filter *PerformanceCompetencyFilter) ([]*PerformanceCompetency, error)
+	Update(competency *PerformanceCompetency) error
+}
+
+type CompetencyRatingRepository interface {
+	// Save creates the rating or replaces the appraisal's existing rating of
+	// the same competency
+	Save(rating *CompetencyRating) error
+	GetByAppraisal(appraisalID int) ([]*CompetencyRating, error)
+	Delete(appraisalID, competencyID int) error
+}
+
+type PerformanceCompetencyFilter struct {
+	Level      string
+	ActiveOnly bool
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,223 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Cycle statuses. A cycle is drafted, launched to create its appraisals, and
+// then moves to evaluation and closed as its evaluation dates pass.
+const (
+	CycleStatusDraft      = "draft"
+	CycleStatusActive     = "active"
+	CycleStatusEvaluation = "evaluation"
+	CycleStatusClosed     = "closed"
+)
+
+// Appraisal statuses
+const (
+	AppraisalStatusDraft      = "draft"
+	AppraisalStatusInProgress = "in_progress"
+	AppraisalStatusSubmitted  = "submitted"
+	AppraisalStatusCompleted  = "completed"
+)
+
+// ErrNotFound is returned by repositories when a record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// PerformanceCycle is a review period, stored in tbl_pf_cycles. Objectives
+// run from StartDate to EndDate; managers evaluate between
+// EvaluationStartDate, or EndDate when it is not set, and EvaluationEndDate.
+// 360 feedback on its appraisals is only shown or scored once
+// MinRespondents reviewers have answered.
+type PerformanceCycle struct {
+	ID                  int        `json:"id" db:"id"`
+	Name                string     `json:"name" db:"name"`
+	Description         string     `json:"description" db:"description"`
+	StartDate           time.Time  `json:"start_date" db:"start_date"`
+	EndDate             time.Time  `json:"end_date" db:"end_date"`
+	EvaluationStartDate *time.Time `json:"evaluation_start_date" db:"evaluation_start_date"`
+	EvaluationEndDate   time.Time  `json:"evaluation_end_date" db:"evaluation_end_date"`
+	Status              string     `json:"status" db:"status"`                       // draft, active, evaluation, closed
+	GoalWeight          float64    `json:"goal_weight" db:"goal_weight"`             // share of the overall score from goals, in percent
+	CompetencyWeight    float64    `json:"competency_weight" db:"competency_weight"` // share from competency ratings, in percent
+	FeedbackWeight      float64    `json:"feedback_weight" db:"feedback_weight"`     // share from 360 feedback, in percent
+	MinRespondents      int        `json:"feedback_min_respondents" db:"feedback_min_respondents"`
+	LaunchedAt          *time.Time `json:"launched_at" db:"launched_at"`
+	CreatedBy           *int       `json:"created_by" db:"created_by"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// EvaluationStart is the first day of the evaluation period
+func (c *PerformanceCycle) EvaluationStart() time.Time {
+	if c.EvaluationStartDate != nil {
+		return *c.EvaluationStartDate
+	}
+	return c.EndDate
+}
+
+// Validate checks the cycle's dates are in order, its section weights add up
+// to 100% and feedback needs at least one respondent
+func (c *PerformanceCycle) Validate() error {
+	if c.EndDate.Before(c.StartDate) {
+		return fmt.Errorf("end_date cannot be before start_date")
+	}
+	if c.EvaluationStart().Before(c.StartDate) {
+		return fmt.Errorf("evaluation_start_date cannot be before start_date")
+	}
+	if c.EvaluationEndDate.Before(c.EvaluationStart()) {
+		return fmt.Errorf("evaluation_end_date cannot be before the evaluation start")
+	}
+	if c.GoalWeight < 0 || c.CompetencyWeight < 0 || c.FeedbackWeight < 0 {
+		return fmt.Errorf("goal_weight, competency_weight and feedback_weight cannot be negative")
+	}
+	weights := []float64{c.GoalWeight, c.CompetencyWeight, c.FeedbackWeight}
+	if err := ValidateWeights("goal_weight, competency_weight and feedback_weight", weights); err != nil {
+		return err
+	}
+	if c.MinRespondents < 1 {
+		return fmt.Errorf("feedback_min_respondents must be at least 1")
+	}
+	return nil
+}
+
+// StatusOn is the status a launched cycle should have on date: active until
+// evaluation starts, in evaluation until EvaluationEndDate has passed, then
+// closed. Draft cycles stay draft until launched.
+func (c *PerformanceCycle) StatusOn(date time.Time) string {
+	if c.Status == CycleStatusDraft {
+		return CycleStatusDraft
+	}
+	day := truncateDay(date)
+	switch {
+	case day.After(truncateDay(c.EvaluationEndDate)):
+		return CycleStatusClosed
+	case !day.Before(truncateDay(c.EvaluationStart())):
+		return CycleStatusEvaluation
+	}
+	return CycleStatusActive
+}
+
+// DaysRemaining is the number of days from date until the evaluation ends,
+// or zero once it has
+func (c *PerformanceCycle) DaysRemaining(date time.Time) int {
+	days := int(truncateDay(c.EvaluationEndDate).Sub(truncateDay(date)).Hours() / 24)
+	if days < 0 || c.Status == CycleStatusClosed {
+		return 0
+	}
+	return days
+}
+
+// PerformanceAppraisal is one employee's review in a cycle, stored in
+// tbl_pf_appraisals
+type PerformanceAppraisal struct {
+	ID               int        `json:"id" db:"id"`
+	CycleID          int        `json:"cycle_id" db:"cycle_id"`
+	EmployeeID       int        `json:"employee_id" db:"employee_id"`
+	ManagerID        int        `json:"manager_id" db:"manager_id"`
+	Status           string     `json:"status" db:"status"` // draft, in_progress, submitted, completed
+	OverallScore     *float64   `json:"overall_score" db:"overall_score"`
+	OverallRating    string     `json:"overall_rating" db:"overall_rating"`
+	EmployeeComments string     `json:"employee_comments" db:"employee_comments"`
+	ManagerComments  string     `json:"manager_comments" db:"manager_comments"`
+	EmployeeSignedAt *time.Time `json:"employee_signed_at" db:"employee_signed_at"`
+	ManagerSignedAt  *time.Time `json:"manager_signed_at" db:"manager_signed_at"`
+	DiscussionDate   *time.Time `json:"discussion_date" db:"discussion_date"`
+	NextPeriodGoals  string     `json:"next_period_goals" db:"next_period_goals"`
+	DevelopmentPlan  string     `json:"development_plan" db:"development_plan"`
+	// CalibrationSessionID is the session that adjusted or finalized the
+	// rating; the appraisal can no longer be re-scored once it is set
+	CalibrationSessionID *int      `json:"calibration_session_id" db:"calibration_session_id"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OrgEmployee is an employee's place in the org hierarchy, from their current
+// grade. Active is false once they have left.
+type OrgEmployee struct {
+	EmployeeID   int
+	ManagerID    *int
+	DepartmentID *int
+	Active       bool
+}
+
+// CycleProgress summarises how far a cycle's appraisals have got
+type CycleProgress struct {
+	CycleID        int               `json:"cycle_id"`
+	Status         string            `json:"status"`
+	Appraisals     int               `json:"appraisals"`
+	ByStatus       map[string]int    `json:"by_status"`
+	Completed      int               `json:"completed"`
+	CompletionRate float64           `json:"completion_rate"`
+	DaysRemaining  int               `json:"days_remaining"` // until the evaluation ends
+	Managers       []ManagerProgress `json:"managers"`
+}
+
+// ManagerProgress is one manager's share of a cycle's appraisals
+type ManagerProgress struct {
+	ManagerID      int     `json:"manager_id"`
+	Name           string  `json:"name"`
+	Appraisals     int     `json:"appraisals"`
+	Completed      int     `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+type PerformanceCycleRepository interface {
+	Create(cycle *PerformanceCycle) error
+	GetByID(id int) (*PerformanceCycle, error)
+	GetAll(filter *PerformanceCycleFilter) ([]*PerformanceCycle, error)
+	// GetLaunched returns the cycles that are not draft or closed
+	GetLaunched() ([]*PerformanceCycle, error)
+	Update(cycle *PerformanceCycle) error
+	Delete(id int) error
+}
+
+type PerformanceAppraisalRepository interface {
+	// CreateMissing stores the appraisals whose employee has none in the
+	// cycle yet and returns those it created
+	CreateMissing(appraisals []*PerformanceAppraisal) ([]*PerformanceAppraisal, error)
+	GetByID(id int) (*PerformanceAppraisal, error)
+	GetAll(filter *PerformanceAppraisalFilter) ([]*PerformanceAppraisal, error)
+	// GetByCycle returns every appraisal in the cycle
+	GetByCycle(cycleID int) ([]*PerformanceAppraisal, error)
+	CountByStatus(cycleID int) (map[string]int, error)
+	ProgressByManager(cycleID int) ([]ManagerProgress, error)
+	Update(appraisal *PerformanceAppraisal) error
+}
+
+// OrgDirectory reads the employee hierarchy kept on tbl_employee_grades. HR
+// sets an employee's manager through the monolith's
+// PUT /api/v1/employees/:employeeId/manager.
+type OrgDirectory interface {
+	// Employees returns every employee on a current grade, including those
+	// who have since left, so managers can be traced past them
+	Employees() ([]OrgEmployee, error)
+	// EmployeeOfUser returns the employee linked to a monolith user account,
+	// or nil when the account has none
+	EmployeeOfUser(userID int) (*int, error)
+	// EmployeeEmail returns the employee's email, empty when none is on file
+	EmployeeEmail(employeeID int) (string, error)
+}
+
+// Filters
+type PerformanceCycleFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+type PerformanceAppraisalFilter struct {
+	CycleID    *int
+	EmployeeID *int
+	ManagerID  *int
+	Status     string
+	Limit      int
+	Offset     int
+}
+
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,288 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Feedback reviewer types. The manager's own view is the appraisal itself,
+// so 360 feedback comes from everyone else around the employee.
+const (
+	FeedbackReviewerPeer        = "peer"
+	FeedbackReviewerSubordinate = "subordinate"
+	FeedbackReviewerExternal    = "external"
+)
+
+// FeedbackReviewerPooled is the group that reviewer types too small to show
+// on their own are pooled into
+const FeedbackReviewerPooled = "other"
+
+// Nomination statuses
+const (
+	NominationStatusPending   = "pending"
+	NominationStatusSubmitted = "submitted"
+	NominationStatusDeclined  = "declined"
+)
+
+// FeedbackNomination asks one reviewer for feedback on an appraisal, stored
+// in tbl_pf_feedback_nominations. Internal reviewers are employees; external
+// ones are known by name and email. Reviewers answer with the token they
+// were sent, of which only the hash is kept, so answers are never linked
+// back to who gave them.
+type FeedbackNomination struct {
+	ID                 int        `json:"id" db:"id"`
+	AppraisalID        int        `json:"appraisal_id" db:"appraisal_id"`
+	ReviewerType       string     `json:"reviewer_type" db:"reviewer_type"` // peer, subordinate, external
+	ReviewerEmployeeID *int       `json:"reviewer_employee_id" db:"reviewer_employee_id"`
+	ReviewerName       string     `json:"reviewer_name" db:"reviewer_name"`
+	ReviewerEmail      string     `json:"reviewer_email" db:"reviewer_email"`
+	TokenHash          string     `json:"-" db:"token_hash"`
+	Status             string     `json:"status" db:"status"` // pending, submitted, declined
+	NominatedBy        *int       `json:"nominated_by" db:"nominated_by"`
+	RespondedAt        *time.Time `json:"responded_at" db:"responded_at"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks the reviewer matches the reviewer type
+func (n *FeedbackNomination) Validate() error {
+	switch n.ReviewerType {
+	case FeedbackReviewerPeer, FeedbackReviewerSubordinate:
+		if n.ReviewerEmployeeID == nil {
+			return fmt.Errorf("a %s reviewer needs reviewer_employee_id", n.ReviewerType)
+		}
+	case FeedbackReviewerExternal:
+		if n.ReviewerEmployeeID != nil {
+			return fmt.Errorf("an external reviewer cannot be an employee")
+		}
+		if strings.TrimSpace(n.ReviewerName) == "" || strings.TrimSpace(n.ReviewerEmail) == "" {
+			return fmt.Errorf("an external reviewer needs reviewer_name and reviewer_email")
+		}
+	default:
+		return fmt.Errorf("reviewer_type must be peer, subordinate or external")
+	}
+	return nil
+}
+
+// FeedbackMailer sends reviewers the link they answer with. The token is
+// only ever sent to the reviewer, never returned by the API.
+type FeedbackMailer interface {
+	SendFeedbackInvitation(email, name, token string) error
+}
+
+// FeedbackResponse is a reviewer's rating of one competency, stored in
+// tbl_pf_feedback_responses on the same 1 to 5 scale managers use
+type FeedbackResponse struct {
+	ID           int       `json:"id" db:"id"`
+	NominationID int       `json:"nomination_id" db:"nomination_id"`
+	CompetencyID int       `json:"competency_id" db:"competency_id"`
+	Rating       int       `json:"rating" db:"rating"`
+	Comments     string    `json:"comments" db:"comments"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// FeedbackAnswer is a submitted response stripped of its reviewer, as
+// aggregation sees it
+type FeedbackAnswer struct {
+	ReviewerType string
+	CompetencyID int
+	Rating       int
+	Comments     string
+}
+
+// FeedbackGroup is the feedback from one reviewer type. Groups with fewer
+// respondents than the cycle requires are pooled and not shown on their own.
+type FeedbackGroup struct {
+	ReviewerType  string   `json:"reviewer_type"`
+	Nominated     int      `json:"nominated"`
+	Respondents   int      `json:"respondents"`
+	Released      bool     `json:"released"`
+	AverageRating *float64 `json:"average_rating"`
+}
+
+// FeedbackCompetency is the released feedback on one competency
+type FeedbackCompetency struct {
+	CompetencyID  int     `json:"competency_id"`
+	Title         string  `json:"title"`
+	Weight        float64 `json:"weight"`
+	Ratings       int     `json:"ratings"`
+	AverageRating float64 `json:"average_rating"`
+	Score         float64 `json:"score"` // out of 100
+}
+
+// FeedbackSummary is an appraisal's 360 feedback as the employee and manager
+// see it. Score is out of 100 and nil until enough reviewers have answered.
+type FeedbackSummary struct {
+	AppraisalID    int                  `json:"appraisal_id"`
+	MinRespondents int                  `json:"min_respondents"`
+	Nominated      int                  `json:"nominated"`
+	Respondents    int                  `json:"respondents"`
+	Released       bool                 `json:"released"`
+	Score          *float64             `json:"score"`
+	Groups         []FeedbackGroup      `json:"groups"`
+	Competencies   []FeedbackCompetency `json:"competencies"`
+	Comments       []string             `json:"comments"`
+}
+
+// AggregateFeedback summarises the submitted answers while protecting the
+// reviewers. A reviewer type is shown when at least minRespondents of its
+// reviewers answered; smaller types are pooled into one "other" group shown
+// under the same rule, and anything still below it is left out entirely so
+// it cannot be worked out from the totals. Competencies are only shown with
+// at least minRespondents ratings, and comments are sorted so their order
+// gives nothing away. The score is the weighted average of the shown
+// competencies on the 1 to 5 scale, or their plain average when none of them
+// carries weight.
+func AggregateFeedback(appraisalID, minRespondents int, nominations []*FeedbackNomination, answers []FeedbackAnswer, competencies map[int]*PerformanceCompetency) *FeedbackSummary {
+	summary := &FeedbackSummary{
+		AppraisalID:    appraisalID,
+		MinRespondents: minRespondents,
+		Groups:         []FeedbackGroup{},
+		Competencies:   []FeedbackCompetency{},
+		Comments:       []string{},
+	}
+
+	nominated := map[string]int{}
+	respondents := map[string]int{}
+	for _, nomination := range nominations {
+		if nomination.Status == NominationStatusDeclined {
+			continue
+		}
+		summary.Nominated++
+		nominated[nomination.ReviewerType]++
+		if nomination.Status == NominationStatusSubmitted {
+			respondents[nomination.ReviewerType]++
+		}
+	}
+
+	// decide which reviewer types are shown, pooling the small ones
+	released := map[string]string{}
+	pooled := FeedbackGroup{ReviewerType: FeedbackReviewerPooled}
+	var small []string
+	for _, reviewerType := range []string{FeedbackReviewerPeer, FeedbackReviewerSubordinate, FeedbackReviewerExternal} {
+		if nominated[reviewerType] == 0 {
+			continue
+		}
+		group := FeedbackGroup{
+			ReviewerType: reviewerType,
+			Nominated:    nominated[reviewerType],
+			Respondents:  respondents[reviewerType],
+		}
+		if group.Respondents >= minRespondents {
+			group.Released = true
+			released[reviewerType] = reviewerType
+		} else if group.Respondents > 0 {
+			pooled.Nominated += group.Nominated
+			pooled.Respondents += group.Respondents
+			small = append(small, reviewerType)
+		}
+		summary.Groups = append(summary.Groups, group)
+	}
+	if len(small) > 1 && pooled.Respondents >= minRespondents {
+		pooled.Released = true
+		for _, reviewerType := range small {
+			released[reviewerType] = FeedbackReviewerPooled
+		}
+		summary.Groups = append(summary.Groups, pooled)
+	}
+
+	type totals struct{ ratings, sum int }
+	byGroup := map[string]*totals{}
+	byCompetency := map[int]*totals{}
+	var comments []string
+	for _, answer := range answers {
+		group, ok := released[answer.ReviewerType]
+		if !ok {
+			continue
+		}
+		if byGroup[group] == nil {
+			byGroup[group] = &totals{}
+		}
+		byGroup[group].ratings++
+		byGroup[group].sum += answer.Rating
+		if byCompetency[answer.CompetencyID] == nil {
+			byCompetency[answer.CompetencyID] = &totals{}
+		}
+		byCompetency[answer.CompetencyID].ratings++
+		byCompetency[answer.CompetencyID].sum += answer.Rating
+		if comment := strings.TrimSpace(answer.Comments); comment != "" {
+			comments = append(comments, comment)
+		}
+	}
+
+	for i := range summary.Groups {
+		group := &summary.Groups[i]
+		if !group.Released {
+			continue
+		}
+		summary.Respondents += group.Respondents
+		if t := byGroup[group.ReviewerType]; t != nil {
+			average := roundScore(float64(t.sum) / float64(t.ratings))
+			group.AverageRating = &average
+		}
+	}
+	if summary.Respondents == 0 {
+		return summary
+	}
+
+	score, weights := 0.0, 0.0
+	for competencyID, t := range byCompetency {
+		if t.ratings < minRespondents {
+			continue
+		}
+		average := float64(t.sum) / float64(t.ratings)
+		item := FeedbackCompetency{
+			CompetencyID:  competencyID,
+			Ratings:       t.ratings,
+			AverageRating: roundScore(average),
+			Score:         roundScore(average / CompetencyRatingMax * 100),
+		}
+		if competency := competencies[competencyID]; competency != nil {
+			item.Title = competency.Title
+			item.Weight = competency.Weight
+		}
+		summary.Competencies = append(summary.Competencies, item)
+		score += item.Score * item.Weight
+		weights += item.Weight
+	}
+	if len(summary.Competencies) == 0 {
+		return summary
+	}
+	sort.Slice(summary.Competencies, func(i, j int) bool {
+		return summary.Competencies[i].CompetencyID < summary.Competencies[j].CompetencyID
+	})
+
+	if weights == 0 {
+		for _, item := range summary.Competencies {
+			score += item.Score
+		}
+		weights = float64(len(summary.Competencies))
+	}
+	overall := roundScore(score / weights)
+	summary.Score = &overall
+	summary.Released = true
+
+	sort.Strings(comments)
+	summary.Comments = append(summary.Comments, comments...)
+	return summary
+}
+
+type FeedbackNominationRepository interface {
+	Create(nomination *FeedbackNomination) error
+	GetByID(id int) (*FeedbackNomination, error)
+	GetByTokenHash(tokenHash string) (*FeedbackNomination, error)
+	GetByAppraisal(appraisalID int) ([]*FeedbackNomination, error)
+	Update(nomination *FeedbackNomination) error
+	Delete(id int) error
+}
+
+type FeedbackResponseRepository interface {
+	// Submit stores the reviewer's responses and marks the nomination
+	// submitted in one transaction
+	Submit(nomination *FeedbackNomination, responses []*FeedbackResponse) error
+	// GetAnswers returns the submitted responses on the appraisal without
+	// their reviewers
+	GetAnswers(appraisalID int) ([]FeedbackAnswer, error)
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EvaluateFormula computes a KPI target formula such as "baseline * 1.1" or
+// "(baseline + 20) / 2". Formulas use numbers, the variables passed in,
+// + - * / and parentheses; a formula that is just a number is a fixed target.
+func EvaluateFormula(formula string, variables map[string]*float64) (float64, error) {
+	parser := &formulaParser{input: formula, variables: variables}
+	parser.next()
+	value, err := parser.expression()
+	if err != nil {
+		return 0, err
+	}
+	if parser.token != "" {
+		return 0, fmt.Errorf("unexpected %q in formula", parser.token)
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("formula does not give a number")
+	}
+	return value, nil
+}
+
+// formulaParser is a recursive descent parser over the grammar
+//
+//	expression = term { ("+" | "-") term }
+//	term       = factor { ("*" | "/") factor }
+//	factor     = number | variable | "(" expression ")" | "-" factor
+type formulaParser struct {
+	input     string
+	pos       int
+	token     string
+	variables map[string]*float64
+}
+
+func (p *formulaParser) next() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	if p.pos >= len(p.input) {
+		p.token = ""
+		return
+	}
+
+	start := p.pos
+	switch ch := rune(p.input[p.pos]); {
+	case unicode.IsDigit(ch) || ch == '.':
+		for p.pos < len(p.input) && (unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+	case unicode.IsLetter(ch) || ch == '_':
+		for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '_') {
+			p.pos++
+		}
+	default:
+		p.pos++
+	}
+	p.token = p.input[start:p.pos]
+}
+
+func (p *formulaParser) expression() (float64, error) {
+	value, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for p.token == "+" || p.token == "-" {
+		op := p.token
+		p.next()
+		right, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			value += right
+		} else {
+			value -= right
+		}
+	}
+	return value, nil
+}
+
+func (p *formulaParser) term() (float64, error) {
+	value, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+	for p.token == "*" || p.token == "/" {
+		op := p.token
+		p.next()
+		right, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+		if op == "*" {
+			value *= right
+			continue
+		}
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero in formula")
+		}
+		value /= right
+	}
+	return value, nil
+}
+
+func (p *formulaParser) factor() (float64, error) {
+	token := p.token
+	switch {
+	case token == "":
+		return 0, fmt.Errorf("formula ends unexpectedly")
+	case token == "-":
+		p.next()
+		value, err := p.factor()
+		return -value, err
+	case token == "(":
+		p.next()
+		value, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		if p.token != ")" {
+			return 0, fmt.Errorf("missing ) in formula")
+		}
+		p.next()
+		return value, nil
+	case unicode.IsDigit(rune(token[0])) || token[0] == '.':
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q in formula", token)
+		}
+		p.next()
+		return value, nil
+	case unicode.IsLetter(rune(token[0])) || token[0] == '_':
+		value, ok := p.variables[strings.ToLower(token)]
+		if !ok {
+			return 0, fmt.Errorf("unknown variable %q in formula", token)
+		}
+		if value == nil {
+			return 0, fmt.Errorf("formula needs a %s value", strings.ToLower(token))
+		}
+		p.next()
+		return *value, nil
+	}
+	return 0, fmt.Errorf("unexpected %q in formula", token)
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Goal statuses. Cancelled goals are kept for the record but no longer count
+// towards the appraisal.
+const (
+	GoalStatusActive    = "active"
+	GoalStatusCompleted = "completed"
+	GoalStatusCancelled = "cancelled"
+)
+
+// PerformanceGoal is an objective an employee works towards during a cycle,
+// stored in tbl_pf_goals. Weight is its share of the cycle's goal score in
+// percent.
+type PerformanceGoal struct {
+	ID            int        `json:"id" db:"id"`
+	EmployeeID    int        `json:"employee_id" db:"employee_id"`
+	CycleID       *int       `json:"cycle_id" db:"cycle_id"`
+	KpiID         *int       `json:"kpi_id" db:"kpi_id"`
+	Title         string     `json:"title" db:"title"`
+	Description   string     `json:"description" db:"description"`
+	TargetValue   *float64   `json:"target_value" db:"target_value"`
+	BaselineValue *float64   `json:"baseline_value" db:"baseline_value"`
+	CurrentValue  *float64   `json:"current_value" db:"current_value"`
+	Weight        float64    `json:"weight" db:"weight"`
+	Progress      float64    `json:"progress" db:"progress"` // percent achieved
+	DueDate       time.Time  `json:"due_date" db:"due_date"`
+	StartDate     *time.Time `json:"start_date" db:"start_date"`
+	Status        string     `json:"status" db:"status"` // active, completed, cancelled
+	IsSmartGoal   int        `json:"is_smart_goal" db:"is_smart_goal"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Achievement is how much of the goal has been achieved, in percent. It is
+// measured from BaselineValue, or zero, towards TargetValue, so goals that
+// reduce a value work the same way as goals that grow one. Goals without a
+// target or current value fall back to the recorded Progress. Achievement is
+// capped at 100.
+func (g *PerformanceGoal) Achievement() float64 {
+	if g.TargetValue == nil || g.CurrentValue == nil {
+		return clampPercent(g.Progress)
+	}
+
+	baseline := 0.0
+	if g.BaselineValue != nil {
+		baseline = *g.BaselineValue
+	}
+	span := *g.TargetValue - baseline
+	if span == 0 {
+		if *g.CurrentValue == *g.TargetValue {
+			return 100
+		}
+		return 0
+	}
+	return clampPercent((*g.CurrentValue - baseline) / span * 100)
+}
+
+// Validate checks the goal's weight and dates
+func (g *PerformanceGoal) Validate() error {
+	if g.Weight < 0 || g.Weight > 100 {
+		return fmt.Errorf("weight must be between 0 and 100")
+	}
+	if g.Progress < 0 || g.Progress > 100 {
+		return fmt.Errorf("progress must be between 0 and 100")
+	}
+	if g.StartDate != nil && g.DueDate.Before(*g.StartDate) {
+		return fmt.Errorf("due_date cannot be before start_date")
+	}
+	return nil
+}
+
+type PerformanceGoalRepository interface {
+	Create(goal *PerformanceGoal) error
+	GetByID(id int) (*PerformanceGoal, error)
+	GetAll(filter *PerformanceGoalFilter) ([]*PerformanceGoal, error)
+	// GetForAppraisal returns the employee's goals in the cycle that have not
+	// been cancelled
+	GetForAppraisal(employeeID, cycleID int) ([]*PerformanceGoal, error)
+	// GetByKPI returns the goals tracked against the KPI that have not been
+	// cancelled, optionally within one cycle
+	GetByKPI(kpiID int, cycleID *int) ([]*PerformanceGoal, error)
+	Update(goal *PerformanceGoal) error
+	Delete(id int) error
+}
+
+type PerformanceGoalFilter struct {
+	EmployeeID *int
+	CycleID    *int
+	Status     string
+	Limit      int
+	Offset     int
+}
+
+func clampPercent(value float64) float64 {
+	return math.Max(0, math.Min(100, value))
+}
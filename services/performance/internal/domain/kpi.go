@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// KPI data types. Percentages are kept between 0 and 100 and booleans are
+// recorded as 0 or 1.
+const (
+	KPIDataTypeNumeric    = "numeric"
+	KPIDataTypePercentage = "percentage"
+	KPIDataTypeCurrency   = "currency"
+	KPIDataTypeBoolean    = "boolean"
+)
+
+// KPI aggregations decide how a goal's measurements make up its current
+// value: the latest reading, the running total or the average reading.
+const (
+	KPIAggregationLatest  = "latest"
+	KPIAggregationSum     = "sum"
+	KPIAggregationAverage = "average"
+)
+
+// Trend intervals
+const (
+	TrendIntervalWeek  = "week"
+	TrendIntervalMonth = "month"
+)
+
+// FormulaBaseline is the variable target formulas use for a goal's baseline
+const FormulaBaseline = "baseline"
+
+// PerformanceKPI is a measure goals can be tracked against, stored in
+// tbl_pf_kpis. TargetCalculationFormula derives a goal's target from its
+// baseline.
+type PerformanceKPI struct {
+	ID                       int       `json:"id" db:"id"`
+	DepartmentID             *int      `json:"department_id" db:"department_id"`
+	Title                    string    `json:"title" db:"title"`
+	Description              string    `json:"description" db:"description"`
+	Unit                     string    `json:"unit" db:"unit"`
+	DataType                 string    `json:"data_type" db:"data_type"`     // numeric, percentage, currency, boolean
+	Aggregation              string    `json:"aggregation" db:"aggregation"` // latest, sum, average
+	TargetCalculationFormula string    `json:"target_calculation_formula" db:"target_calculation_formula"`
+	IsActive                 int       `json:"is_active" db:"is_active"`
+	CreatedBy                *int      `json:"created_by" db:"created_by"`
+	CreatedAt                time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt                time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks the KPI's data type, aggregation and formula
+func (k *PerformanceKPI) Validate() error {
+	switch k.DataType {
+	case KPIDataTypeNumeric, KPIDataTypePercentage, KPIDataTypeCurrency, KPIDataTypeBoolean:
+	default:
+		return fmt.Errorf("data_type must be numeric, percentage, currency or boolean")
+	}
+	switch k.Aggregation {
+	case KPIAggregationLatest, KPIAggregationSum, KPIAggregationAverage:
+	default:
+		return fmt.Errorf("aggregation must be latest, sum or average")
+	}
+	if k.TargetCalculationFormula != "" {
+		sample := 1.0
+		if _, err := EvaluateFormula(k.TargetCalculationFormula, map[string]*float64{FormulaBaseline: &sample}); err != nil {
+			return fmt.Errorf("invalid target_calculation_formula: %w", err)
+		}
+	}
+	return nil
+}
+
+// Target evaluates the target formula for a baseline, rounded to two
+// decimals. ok is false when the KPI has no formula.
+func (k *PerformanceKPI) Target(baseline *float64) (target float64, ok bool, err error) {
+	if k.TargetCalculationFormula == "" {
+		return 0, false, nil
+	}
+	target, err = EvaluateFormula(k.TargetCalculationFormula, map[string]*float64{FormulaBaseline: baseline})
+	if err != nil {
+		return 0, false, err
+	}
+	return roundScore(target), true, nil
+}
+
+// ValidateValue checks a measurement fits the KPI's data type
+func (k *PerformanceKPI) ValidateValue(value float64) error {
+	switch k.DataType {
+	case KPIDataTypePercentage:
+		if value < 0 || value > 100 {
+			return fmt.Errorf("a percentage must be between 0 and 100")
+		}
+	case KPIDataTypeBoolean:
+		if value != 0 && value != 1 {
+			return fmt.Errorf("a yes/no measurement must be 0 or 1")
+		}
+	}
+	return nil
+}
+
+// KPIMeasurement is one reading towards a goal, stored in
+// tbl_pf_kpi_measurements
+type KPIMeasurement struct {
+	ID         int       `json:"id" db:"id"`
+	GoalID     int       `json:"goal_id" db:"goal_id"`
+	MeasuredOn time.Time `json:"measured_on" db:"measured_on"`
+	Value      float64   `json:"value" db:"value"`
+	Notes      string    `json:"notes" db:"notes"`
+	RecordedBy *int      `json:"recorded_by" db:"recorded_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// Aggregate combines measurements, ordered by date, into one value. ok is
+// false when there are none.
+func Aggregate(aggregation string, measurements []*KPIMeasurement) (value float64, ok bool) {
+	if len(measurements) == 0 {
+		return 0, false
+	}
+	switch aggregation {
+	case KPIAggregationSum, KPIAggregationAverage:
+		for _, measurement := range measurements {
+			value += measurement.Value
+		}
+		if aggregation == KPIAggregationAverage {
+			value /= float64(len(measurements))
+		}
+		return value, true
+	}
+	return measurements[len(measurements)-1].Value, true
+}
+
+// TrendPoint is a goal's or KPI's position at the end of one period.
+// Value aggregates the period's own measurements and Current everything
+// measured up to then.
+type TrendPoint struct {
+	Period       string  `json:"period"` // first day of the week or month
+	Measurements int     `json:"measurements"`
+	Value        float64 `json:"value"`
+	Current      float64 `json:"current"`
+	Progress     float64 `json:"progress"`
+}
+
+// GoalTrend groups a goal's measurements by week or month and tracks its
+// current value and progress period by period
+func GoalTrend(goal *PerformanceGoal, aggregation, interval string, measurements []*KPIMeasurement) []TrendPoint {
+	sorted := make([]*KPIMeasurement, len(measurements))
+	copy(sorted, measurements)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].MeasuredOn.Before(sorted[j].MeasuredOn) })
+
+	points := []TrendPoint{}
+	for start := 0; start < len(sorted); {
+		period := PeriodStart(sorted[start].MeasuredOn, interval)
+		end := start
+		for end < len(sorted) && PeriodStart(sorted[end].MeasuredOn, interval).Equal(period) {
+			end++
+		}
+
+		value, _ := Aggregate(aggregation, sorted[start:end])
+		current, _ := Aggregate(aggregation, sorted[:end])
+		snapshot := *goal
+		snapshot.CurrentValue = &current
+		points = append(points, TrendPoint{
+			Period:       period.Format("2006-01-02"),
+			Measurements: end - start,
+			Value:        roundScore(value),
+			Current:      roundScore(current),
+			Progress:     roundScore(snapshot.Achievement()),
+		})
+		start = end
+	}
+	return points
+}
+
+// PeriodStart is the Monday of date's week or the first of its month
+func PeriodStart(date time.Time, interval string) time.Time {
+	day := truncateDay(date)
+	if interval == TrendIntervalWeek {
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	}
+	return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+type PerformanceKPIRepository interface {
+	Create(kpi *PerformanceKPI) error
+	GetByID(id int) (*PerformanceKPI, error)
+	GetAll(filter *PerformanceKPIFilter) ([]*PerformanceKPI, error)
+	Update(kpi *PerformanceKPI) error
+}
+
+type KPIMeasurementRepository interface {
+	Create(measurement *KPIMeasurement) error
+	GetByID(id int) (*KPIMeasurement, error)
+	// GetByGoal returns the goal's measurements, oldest first
+	GetByGoal(goalID int) ([]*KPIMeasurement, error)
+	// GetByKPI returns the measurements of every goal tracked against the
+	// KPI, optionally within one cycle, oldest first
+	GetByKPI(kpiID int, cycleID *int) ([]*KPIMeasurement, error)
+	Delete(id int) error
+}
+
+type PerformanceKPIFilter struct {
+	DepartmentID *int
+	ActiveOnly   bool
+	Limit        int
+	Offset       int
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PIP statuses. An extended plan stays active with a later end date; a plan
+// is closed once it is completed or ends in termination.
+const (
+	PIPStatusActive = "active"
+	PIPStatusClosed = "closed"
+)
+
+// PIP outcomes
+const (
+	PIPOutcomeExtended   = "extended"
+	PIPOutcomeCompleted  = "completed"
+	PIPOutcomeTerminated = "terminated"
+)
+
+// EmployeeExitTerminated is the tbl_employee_trash action recorded when a
+// plan ends in termination
+const EmployeeExitTerminated = "terminated"
+
+// PerformancePIP is a performance improvement plan, stored in tbl_pf_pips.
+// AppraisalID links the plan to the appraisal that led to it. A reminder is
+// due ReminderDays before EndDate and is sent once per end date.
+type PerformancePIP struct {
+	ID              int        `json:"id" db:"id"`
+	EmployeeID      int        `json:"employee_id" db:"employee_id"`
+	AppraisalID     *int       `json:"appraisal_id" db:"appraisal_id"`
+	Reason          string     `json:"reason" db:"reason"`
+	Objectives      string     `json:"objectives" db:"objectives"`
+	SuccessCriteria string     `json:"success_criteria" db:"success_criteria"`
+	StartDate       time.Time  `json:"start_date" db:"start_date"`
+	EndDate         time.Time  `json:"end_date" db:"end_date"`
+	OriginalEndDate *time.Time `json:"original_end_date" db:"original_end_date"` // set on the first extension
+	ReminderDays    int        `json:"reminder_days" db:"reminder_days"`
+	ReminderSentAt  *time.Time `json:"reminder_sent_at" db:"reminder_sent_at"`
+	Status          string     `json:"status" db:"status"`   // active, closed
+	Outcome         string     `json:"outcome" db:"outcome"` // extended, completed, terminated
+	OutcomeNotes    string     `json:"outcome_notes" db:"outcome_notes"`
+	OutcomeDate     *time.Time `json:"outcome_date" db:"outcome_date"`
+	CreatedBy       *int       `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks the plan's content and dates
+func (p *PerformancePIP) Validate() error {
+	if strings.TrimSpace(p.Reason) == "" {
+		return fmt.Errorf("reason is required")
+	}
+	if strings.TrimSpace(p.Objectives) == "" {
+		return fmt.Errorf("objectives are required")
+	}
+	if strings.TrimSpace(p.SuccessCriteria) == "" {
+		return fmt.Errorf("success_criteria is required")
+	}
+	if !p.EndDate.After(p.StartDate) {
+		return fmt.Errorf("end_date must be after start_date")
+	}
+	if p.ReminderDays < 0 {
+		return fmt.Errorf("reminder_days cannot be negative")
+	}
+	return nil
+}
+
+// DaysLeft is the number of days from date to the end of the plan, negative
+// once it has passed
+func (p *PerformancePIP) DaysLeft(date time.Time) int {
+	return int(truncateDay(p.EndDate).Sub(truncateDay(date)).Hours() / 24)
+}
+
+// ReminderDue reports whether the end-of-plan reminder should go out on
+// date. Plans already past their end date without an outcome are reminded
+// too.
+func (p *PerformancePIP) ReminderDue(date time.Time) bool {
+	return p.Status == PIPStatusActive && p.ReminderSentAt == nil && p.DaysLeft(date) <= p.ReminderDays
+}
+
+// PIPCheckIn is a scheduled review of progress on a plan, stored in
+// tbl_pf_pip_checkins. It is held once HeldDate is recorded, with notes and a
+// progress rating on the 1 to 5 competency scale.
+type PIPCheckIn struct {
+	ID             int        `json:"id" db:"id"`
+	PIPID          int        `json:"pip_id" db:"pip_id"`
+	ScheduledDate  time.Time  `json:"scheduled_date" db:"scheduled_date"`
+	HeldDate       *time.Time `json:"held_date" db:"held_date"`
+	Notes          string     `json:"notes" db:"notes"`
+	ProgressRating *int       `json:"progress_rating" db:"progress_rating"`
+	RecordedBy     *int       `json:"recorded_by" db:"recorded_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ScheduleCheckIns spaces check-ins every intervalDays from the start of a
+// plan, the last one falling on or before its end
+func ScheduleCheckIns(start, end time.Time, intervalDays int) []time.Time {
+	var dates []time.Time
+	if intervalDays <= 0 {
+		return dates
+	}
+	for date := start.AddDate(0, 0, intervalDays); !date.After(end); date = date.AddDate(0, 0, intervalDays) {
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+// EmployeeExit is an exit to record in the HR monolith's tbl_employee_trash
+type EmployeeExit struct {
+	EmployeeID int
+	Action     string
+	Reason     string
+	Date       time.Time
+	CreatedBy  *int
+}
+
+type PerformancePIPRepository interface {
+	// Create stores the plan with its scheduled check-ins
+	Create(pip *PerformancePIP, checkIns []*PIPCheckIn) error
+	GetByID(id int) (*PerformancePIP, error)
+	GetAll(filter *PerformancePIPFilter) ([]*PerformancePIP, error)
+	// GetActive returns every plan without a final outcome
+	GetActive() ([]*PerformancePIP, error)
+	Update(pip *PerformancePIP) error
+	// RecordOutcome saves the plan's outcome and, when exit is given, records
+	// the employee's exit in the same transaction
+	RecordOutcome(pip *PerformancePIP, exit *EmployeeExit) error
+}
+
+type PIPCheckInRepository interface {
+	Create(checkIn *PIPCheckIn) error
+	GetByID(id int) (*PIPCheckIn, error)
+	GetByPIP(pipID int) ([]*PIPCheckIn, error)
+	Update(checkIn *PIPCheckIn) error
+	Delete(id int) error
+}
+
+type PerformancePIPFilter struct {
+	EmployeeID  *int
+	AppraisalID *int
+	Status      string
+	Limit       int
+	Offset      int
+}
@@ -0,0 +1,215 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WeightTolerance absorbs rounding when weights entered with decimals are
+// checked to add up to 100%
+const WeightTolerance = 0.01
+
+// RatingBand is one step of the rating scale in tbl_pf_rating_scales. A score
+// gets the rating of the highest band whose MinScore it reaches.
+type RatingBand struct {
+	ID          int       `json:"id" db:"id"`
+	MinScore    float64   `json:"min_score" db:"min_score"`
+	Rating      string    `json:"rating" db:"rating"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// GoalScore is one goal's contribution to an appraisal's goal score
+type GoalScore struct {
+	GoalID      int     `json:"goal_id"`
+	Title       string  `json:"title"`
+	Weight      float64 `json:"weight"`
+	Achievement float64 `json:"achievement"`
+	Weighted    float64 `json:"weighted"`
+}
+
+// CompetencyScore is one competency rating's contribution to an appraisal's
+// competency score
+type CompetencyScore struct {
+	CompetencyID int     `json:"competency_id"`
+	Title        string  `json:"title"`
+	Weight       float64 `json:"weight"`
+	Rating       int     `json:"rating"`
+	Score        float64 `json:"score"`
+	Weighted     float64 `json:"weighted"`
+}
+
+// ScoreBreakdown shows how an appraisal's overall score was reached. Section
+// scores are out of 100 and combined using the cycle's section weights.
+// FeedbackScore is nil when 360 feedback did not count.
+type ScoreBreakdown struct {
+	AppraisalID      int               `json:"appraisal_id"`
+	GoalWeight       float64           `json:"goal_weight"`
+	GoalScore        float64           `json:"goal_score"`
+	CompetencyWeight float64           `json:"competency_weight"`
+	CompetencyScore  float64           `json:"competency_score"`
+	FeedbackWeight   float64           `json:"feedback_weight"`
+	FeedbackScore    *float64          `json:"feedback_score"`
+	OverallScore     float64           `json:"overall_score"`
+	OverallRating    string            `json:"overall_rating"`
+	Goals            []GoalScore       `json:"goals"`
+	Competencies     []CompetencyScore `json:"competencies"`
+}
+
+// ScoreAppraisal combines weighted goal achievement, competency ratings and
+// 360 feedback into an overall score and maps it onto the rating scale. A
+// goal or competency section the cycle gives weight to must have items whose
+// weights add up to 100%. Feedback that has not been released, because too
+// few reviewers answered, is left out and the other sections share its
+// weight in proportion.
+func ScoreAppraisal(appraisal *PerformanceAppraisal, cycle *PerformanceCycle, goals []*PerformanceGoal, ratings []*CompetencyRating, feedback *FeedbackSummary, scale []RatingBand) (*ScoreBreakdown, error) {
+	breakdown := &ScoreBreakdown{
+		AppraisalID:      appraisal.ID,
+		GoalWeight:       cycle.GoalWeight,
+		CompetencyWeight: cycle.CompetencyWeight,
+		FeedbackWeight:   cycle.FeedbackWeight,
+		Goals:            []GoalScore{},
+		Competencies:     []CompetencyScore{},
+	}
+
+	if cycle.GoalWeight > 0 {
+		weights := make([]float64, 0, len(goals))
+		for _, goal := range goals {
+			achievement := goal.Achievement()
+			weighted := achievement * goal.Weight / 100
+			breakdown.GoalScore += weighted
+			breakdown.Goals = append(breakdown.Goals, GoalScore{
+				GoalID:      goal.ID,
+				Title:       goal.Title,
+				Weight:      goal.Weight,
+				Achievement: roundScore(achievement),
+				Weighted:    roundScore(weighted),
+			})
+			weights = append(weights, goal.Weight)
+		}
+		if err := ValidateWeights("goal weights", weights); err != nil {
+			return nil, err
+		}
+	}
+
+	if cycle.CompetencyWeight > 0 {
+		weights := make([]float64, 0, len(ratings))
+		for _, rating := range ratings {
+			score := float64(rating.Rating) / CompetencyRatingMax * 100
+			weighted := score * rating.Weight / 100
+			breakdown.CompetencyScore += weighted
+			breakdown.Competencies = append(breakdown.Competencies, CompetencyScore{
+				CompetencyID: rating.CompetencyID,
+				Title:        rating.Title,
+				Weight:       rating.Weight,
+				Rating:       rating.Rating,
+				Score:        roundScore(score),
+				Weighted:     roundScore(weighted),
+			})
+			weights = append(weights, rating.Weight)
+		}
+		if err := ValidateWeights("weights of the rated competencies", weights); err != nil {
+			return nil, err
+		}
+	}
+
+	overall := breakdown.GoalScore*cycle.GoalWeight/100 + breakdown.CompetencyScore*cycle.CompetencyWeight/100
+	if cycle.FeedbackWeight > 0 {
+		if feedback != nil && feedback.Score != nil {
+			score := *feedback.Score
+			breakdown.FeedbackScore = &score
+			overall += score * cycle.FeedbackWeight / 100
+		} else {
+			others := cycle.GoalWeight + cycle.CompetencyWeight
+			if others == 0 {
+				return nil, fmt.Errorf("360 feedback is the whole score but too few reviewers have answered")
+			}
+			overall = overall * 100 / others
+		}
+	}
+	breakdown.GoalScore = roundScore(breakdown.GoalScore)
+	breakdown.CompetencyScore = roundScore(breakdown.CompetencyScore)
+	breakdown.OverallScore = roundScore(overall)
+
+	rating, err := RatingFor(scale, breakdown.OverallScore)
+	if err != nil {
+		return nil, err
+	}
+	breakdown.OverallRating = rating
+	return breakdown, nil
+}
+
+// ValidateWeights checks that weights, in percent, add up to 100
+func ValidateWeights(name string, weights []float64) error {
+	if len(weights) == 0 {
+		return fmt.Errorf("%s are missing, they must add up to 100%%", name)
+	}
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	if math.Abs(total-100) > WeightTolerance {
+		return fmt.Errorf("%s add up to %.2f%%, they must add up to 100%%", name, total)
+	}
+	return nil
+}
+
+// ValidateRatingScale checks the bands cover every score from 0 to 100 once:
+// one band starts at 0, none start above 100, and starts and ratings are
+// unique
+func ValidateRatingScale(scale []RatingBand) error {
+	if len(scale) == 0 {
+		return fmt.Errorf("rating scale needs at least one band")
+	}
+	starts := map[float64]bool{}
+	ratings := map[string]bool{}
+	for _, band := range scale {
+		if band.MinScore < 0 || band.MinScore > 100 {
+			return fmt.Errorf("min_score must be between 0 and 100")
+		}
+		if strings.TrimSpace(band.Rating) == "" {
+			return fmt.Errorf("every band needs a rating")
+		}
+		if starts[band.MinScore] {
+			return fmt.Errorf("more than one band starts at %.2f", band.MinScore)
+		}
+		key := strings.ToLower(strings.TrimSpace(band.Rating))
+		if ratings[key] {
+			return fmt.Errorf("rating %q is used by more than one band", band.Rating)
+		}
+		starts[band.MinScore] = true
+		ratings[key] = true
+	}
+	if !starts[0] {
+		return fmt.Errorf("the lowest band must start at 0")
+	}
+	return nil
+}
+
+// RatingFor maps a score onto the rating scale
+func RatingFor(scale []RatingBand, score float64) (string, error) {
+	bands := make([]RatingBand, len(scale))
+	copy(bands, scale)
+	sort.Slice(bands, func(i, j int) bool { return bands[i].MinScore > bands[j].MinScore })
+
+	for _, band := range bands {
+		if score >= band.MinScore {
+			return band.Rating, nil
+		}
+	}
+	return "", fmt.Errorf("rating scale has no band for a score of %.2f", score)
+}
+
+type RatingScaleRepository interface {
+	GetAll() ([]RatingBand, error)
+	// Replace swaps the whole scale for the given bands
+	Replace(scale []RatingBand) error
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
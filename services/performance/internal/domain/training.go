@@ -0,0 +1,266 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Training statuses. Closing a training settles its outstanding enrolments:
+// completing it completes them, cancelling it cancels them.
+const (
+	TrainingStatusActive    = "active"
+	TrainingStatusCompleted = "completed"
+	TrainingStatusCancelled = "cancelled"
+)
+
+// Enrolment statuses. Cancelled enrolments cost nothing.
+const (
+	EnrolmentStatusEnrolled  = "enrolled"
+	EnrolmentStatusCompleted = "completed"
+	EnrolmentStatusCancelled = "cancelled"
+)
+
+// PerformanceTraining is a course or session employees enrol on, stored in
+// tbl_pf_trainings. Cost is the fee per participant and Hours the length of
+// the training. A training assigned to an employee is only open to them.
+type PerformanceTraining struct {
+	ID                   int        `json:"id" db:"id"`
+	Title                string     `json:"title" db:"title"`
+	Description          string     `json:"description" db:"description"`
+	TrainingType         string     `json:"training_type" db:"training_type"`
+	AssignedToEmployeeID *int       `json:"assigned_to_employee_id" db:"assigned_to_employee_id"`
+	LinkedGoalID         *int       `json:"linked_goal_id" db:"linked_goal_id"`
+	LinkedCompetencyID   *int       `json:"linked_competency_id" db:"linked_competency_id"`
+	Status               string     `json:"status" db:"status"` // active, completed, cancelled
+	StartDate            *time.Time `json:"start_date" db:"start_date"`
+	EndDate              *time.Time `json:"end_date" db:"end_date"`
+	Completed            int        `json:"completed" db:"completed"`
+	CompletionDate       *time.Time `json:"completion_date" db:"completion_date"`
+	Cost                 *float64   `json:"cost" db:"cost"`
+	Hours                *float64   `json:"hours" db:"hours"`
+	Provider             string     `json:"provider" db:"provider"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Validate checks the training's title, dates and amounts
+func (t *PerformanceTraining) Validate() error {
+	if strings.TrimSpace(t.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if t.StartDate != nil && t.EndDate != nil && t.EndDate.Before(*t.StartDate) {
+		return fmt.Errorf("end_date cannot be before start_date")
+	}
+	if t.Cost != nil && *t.Cost < 0 {
+		return fmt.Errorf("cost cannot be negative")
+	}
+	if t.Hours != nil && *t.Hours < 0 {
+		return fmt.Errorf("hours cannot be negative")
+	}
+	return nil
+}
+
+// TrainingEnrolment is an employee's place on a training, stored in
+// tbl_pf_training_enrolments. Cost is fixed when the employee enrols and
+// DepartmentID is the department they were in, so spend stays with the
+// department that incurred it. Hours are recorded on completion.
+type TrainingEnrolment struct {
+	ID           int        `json:"id" db:"id"`
+	TrainingID   int        `json:"training_id" db:"training_id"`
+	EmployeeID   int        `json:"employee_id" db:"employee_id"`
+	DepartmentID *int       `json:"department_id" db:"department_id"`
+	Status       string     `json:"status" db:"status"` // enrolled, completed, cancelled
+	Cost         float64    `json:"cost" db:"cost"`
+	Hours        float64    `json:"hours" db:"hours"`
+	CompletedAt  *time.Time `json:"completed_at" db:"completed_at"`
+	EnrolledBy   *int       `json:"enrolled_by" db:"enrolled_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// TrainingBudget is a department's training budget for a year, stored in
+// tbl_pf_training_budgets
+type TrainingBudget struct {
+	ID           int       `json:"id" db:"id"`
+	DepartmentID int       `json:"department_id" db:"department_id"`
+	Year         int       `json:"year" db:"year"`
+	Amount       float64   `json:"amount" db:"amount"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TrainingNeed is a competency an employee was rated low on, with the
+// trainings on that competency they are already enrolled on
+type TrainingNeed struct {
+	EmployeeID   int    `json:"employee_id"`
+	DepartmentID *int   `json:"department_id"`
+	AppraisalID  int    `json:"appraisal_id"`
+	CycleID      int    `json:"cycle_id"`
+	CompetencyID int    `json:"competency_id"`
+	Competency   string `json:"competency"`
+	Rating       int    `json:"rating"`
+	TrainingIDs  []int  `json:"training_ids"`
+	Covered      bool   `json:"covered"`
+}
+
+// DepartmentTrainingSpend is a department's training spend for a year
+// against its budget. Committed spend includes employees still enrolled.
+// Enrolments without a department are reported without a budget.
+type DepartmentTrainingSpend struct {
+	DepartmentID   *int     `json:"department_id"`
+	Budget         *float64 `json:"budget"`
+	Enrolments     int      `json:"enrolments"`
+	Committed      float64  `json:"committed"`
+	Spent          float64  `json:"spent"` // on completed enrolments
+	Remaining      *float64 `json:"remaining"`
+	PercentUsed    *float64 `json:"percent_used"`
+	OverBudget     bool     `json:"over_budget"`
+	HoursDelivered float64  `json:"hours_delivered"`
+}
+
+// EmployeeTrainingSummary is an employee's training in a year
+type EmployeeTrainingSummary struct {
+	EmployeeID   int     `json:"employee_id"`
+	DepartmentID *int    `json:"department_id"`
+	Enrolled     int     `json:"enrolled"`
+	Completed    int     `json:"completed"`
+	Hours        float64 `json:"hours"` // on completed enrolments
+	Spend        float64 `json:"spend"`
+}
+
+// BudgetReport sets each department's spend against its budget. Every
+// budgeted department is listed, spending or not, ordered by department.
+func BudgetReport(budgets []*TrainingBudget, enrolments []*TrainingEnrolment) []DepartmentTrainingSpend {
+	byDepartment := map[int]*DepartmentTrainingSpend{}
+	var unassigned *DepartmentTrainingSpend
+	spendFor := func(departmentID *int) *DepartmentTrainingSpend {
+		if departmentID == nil {
+			if unassigned == nil {
+				unassigned = &DepartmentTrainingSpend{}
+			}
+			return unassigned
+		}
+		if byDepartment[*departmentID] == nil {
+			id := *departmentID
+			byDepartment[id] = &DepartmentTrainingSpend{DepartmentID: &id}
+		}
+		return byDepartment[*departmentID]
+	}
+
+	for _, budget := range budgets {
+		amount := budget.Amount
+		spendFor(&budget.DepartmentID).Budget = &amount
+	}
+	for _, enrolment := range enrolments {
+		if enrolment.Status == EnrolmentStatusCancelled {
+			continue
+		}
+		spend := spendFor(enrolment.DepartmentID)
+		spend.Enrolments++
+		spend.Committed += enrolment.Cost
+		if enrolment.Status == EnrolmentStatusCompleted {
+			spend.Spent += enrolment.Cost
+			spend.HoursDelivered += enrolment.Hours
+		}
+	}
+
+	report := make([]DepartmentTrainingSpend, 0, len(byDepartment)+1)
+	for _, spend := range byDepartment {
+		spend.Committed = roundScore(spend.Committed)
+		spend.Spent = roundScore(spend.Spent)
+		spend.HoursDelivered = roundScore(spend.HoursDelivered)
+		if spend.Budget != nil {
+			remaining := roundScore(*spend.Budget - spend.Committed)
+			spend.Remaining = &remaining
+			spend.OverBudget = remaining < 0
+			if *spend.Budget > 0 {
+				used := roundScore(spend.Committed / *spend.Budget * 100)
+				spend.PercentUsed = &used
+			}
+		}
+		report = append(report, *spend)
+	}
+	sort.Slice(report, func(i, j int) bool { return *report[i].DepartmentID < *report[j].DepartmentID })
+	if unassigned != nil {
+		unassigned.Committed = roundScore(unassigned.Committed)
+		unassigned.Spent = roundScore(unassigned.Spent)
+		unassigned.HoursDelivered = roundScore(unassigned.HoursDelivered)
+		report = append(report, *unassigned)
+	}
+	return report
+}
+
+// SummarizeTraining totals each employee's enrolments, highest spend first
+func SummarizeTraining(enrolments []*TrainingEnrolment) []EmployeeTrainingSummary {
+	byEmployee := map[int]*EmployeeTrainingSummary{}
+	for _, enrolment := range enrolments {
+		if enrolment.Status == EnrolmentStatusCancelled {
+			continue
+		}
+		summary := byEmployee[enrolment.EmployeeID]
+		if summary == nil {
+			summary = &EmployeeTrainingSummary{EmployeeID: enrolment.EmployeeID}
+			byEmployee[enrolment.EmployeeID] = summary
+		}
+		summary.DepartmentID = enrolment.DepartmentID
+		summary.Enrolled++
+		summary.Spend += enrolment.Cost
+		if enrolment.Status == EnrolmentStatusCompleted {
+			summary.Completed++
+			summary.Hours += enrolment.Hours
+		}
+	}
+
+	summaries := make([]EmployeeTrainingSummary, 0, len(byEmployee))
+	for _, summary := range byEmployee {
+		summary.Hours = roundScore(summary.Hours)
+		summary.Spend = roundScore(summary.Spend)
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Spend != summaries[j].Spend {
+			return summaries[i].Spend > summaries[j].Spend
+		}
+		return summaries[i].EmployeeID < summaries[j].EmployeeID
+	})
+	return summaries
+}
+
+type PerformanceTrainingRepository interface {
+	Create(training *PerformanceTraining) error
+	GetByID(id int) (*PerformanceTraining, error)
+	GetAll(filter *PerformanceTrainingFilter) ([]*PerformanceTraining, error)
+	Update(training *PerformanceTraining) error
+	// Close saves a completed or cancelled training and settles its
+	// outstanding enrolments the same way in one transaction
+	Close(training *PerformanceTraining) error
+	// GetNeeds returns the competencies rated at or below maxRating on the
+	// cycle's appraisals
+	GetNeeds(cycleID, maxRating int) ([]*TrainingNeed, error)
+}
+
+type TrainingEnrolmentRepository interface {
+	Create(enrolment *TrainingEnrolment) error
+	GetByID(id int) (*TrainingEnrolment, error)
+	GetByTraining(trainingID int) ([]*TrainingEnrolment, error)
+	// GetByYear returns the enrolments on trainings held in the year, by
+	// start date or, without one, enrolment date
+	GetByYear(year int) ([]*TrainingEnrolment, error)
+	Update(enrolment *TrainingEnrolment) error
+}
+
+type TrainingBudgetRepository interface {
+	// Save sets the department's budget for the year
+	Save(budget *TrainingBudget) error
+	GetByYear(year int) ([]*TrainingBudget, error)
+}
+
+type PerformanceTrainingFilter struct {
+	EmployeeID   *int // assigned to or enrolled on
+	CompetencyID *int
+	Status       string
+	Limit        int
+	Offset       int
+}
@@ -0,0 +1,1629 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"yathuerp/services/performance/internal/application"
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/middleware"
+	"yathuerp/shared/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const dateLayout = "2006-01-02"
+
+type Handler struct {
+	cycleUseCase       *application.CycleUseCase
+	goalUseCase        *application.GoalUseCase
+	competencyUseCase  *application.CompetencyUseCase
+	scoringUseCase     *application.ScoringUseCase
+	kpiUseCase         *application.KPIUseCase
+	feedbackUseCase    *application.FeedbackUseCase
+	signOffUseCase     *application.SignOffUseCase
+	calibrationUseCase *application.CalibrationUseCase
+	pipUseCase         *application.PIPUseCase
+	trainingUseCase    *application.TrainingUseCase
+	logger             utils.Logger
+}
+
+func NewHandler(
+	cycleUseCase *application.CycleUseCase,
+	goalUseCase *application.GoalUseCase,
+	competencyUseCase *application.CompetencyUseCase,
+	scoringUseCase *application.ScoringUseCase,
+	kpiUseCase *application.KPIUseCase,
+	feedbackUseCase *application.FeedbackUseCase,
+	signOffUseCase *application.SignOffUseCase,
+	calibrationUseCase *application.CalibrationUseCase,
+	pipUseCase *application.PIPUseCase,
+	trainingUseCase *application.TrainingUseCase,
+	logger utils.Logger,
+) *Handler {
+	return &Handler{
+		cycleUseCase:       cycleUseCase,
+		goalUseCase:        goalUseCase,
+		competencyUseCase:  competencyUseCase,
+		scoringUseCase:     scoringUseCase,
+		kpiUseCase:         kpiUseCase,
+		feedbackUseCase:    feedbackUseCase,
+		signOffUseCase:     signOffUseCase,
+		calibrationUseCase: calibrationUseCase,
+		pipUseCase:         pipUseCase,
+		trainingUseCase:    trainingUseCase,
+		logger:             logger,
+	}
+}
+
+// Cycles
+
+func (h *Handler) GetCycles(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.PerformanceCycleFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	cycles, err := h.cycleUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get performance cycles", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get performance cycles")
+	}
+
+	return utils.SendSuccess(c, "Performance cycles retrieved successfully", cycles)
+}
+
+func (h *Handler) GetCycleByID(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+
+	cycle, err := h.cycleUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get performance cycle")
+	}
+
+	return utils.SendSuccess(c, "Performance cycle retrieved successfully", cycle)
+}
+
+func (h *Handler) CreateCycle(c *fiber.Ctx) error {
+	var req application.CycleRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	cycle, err := h.cycleUseCase.Create(c.Context(), &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create performance cycle")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Performance cycle created successfully",
+		Data:    cycle,
+	})
+}
+
+func (h *Handler) UpdateCycle(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+
+	var req application.CycleRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	cycle, err := h.cycleUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update performance cycle")
+	}
+
+	return utils.SendSuccess(c, "Performance cycle updated successfully", cycle)
+}
+
+func (h *Handler) DeleteCycle(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+
+	if err := h.cycleUseCase.Delete(c.Context(), id); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete performance cycle")
+	}
+
+	return utils.SendSuccess(c, "Performance cycle deleted successfully", nil)
+}
+
+func (h *Handler) LaunchCycle(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+
+	generation, err := h.cycleUseCase.Launch(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to launch performance cycle")
+	}
+
+	return utils.SendSuccess(c, "Performance cycle launched successfully", generation)
+}
+
+func (h *Handler) GenerateAppraisals(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+
+	generation, err := h.cycleUseCase.GenerateAppraisals(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to generate appraisals")
+	}
+
+	return utils.SendSuccess(c, "Appraisals generated successfully", generation)
+}
+
+func (h *Handler) GetCycleProgress(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+
+	progress, err := h.cycleUseCase.Progress(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get performance cycle progress")
+	}
+
+	return utils.SendSuccess(c, "Performance cycle progress retrieved successfully", progress)
+}
+
+func (h *Handler) SyncCycleStatuses(c *fiber.Ctx) error {
+	date, err := dateQuery(c, "date", time.Now())
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid date, expected YYYY-MM-DD")
+	}
+
+	cycles, err := h.cycleUseCase.SyncStatuses(c.Context(), date)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to sync performance cycle statuses")
+	}
+
+	return utils.SendSuccess(c, "Performance cycle statuses synced successfully", cycles)
+}
+
+// Appraisals
+
+func (h *Handler) GetAppraisals(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.PerformanceAppraisalFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	var err error
+	if filter.CycleID, err = intQuery(c, "cycle_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+	if filter.EmployeeID, err = intQuery(c, "employee_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+	if filter.ManagerID, err = intQuery(c, "manager_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid manager ID")
+	}
+
+	appraisals, err := h.cycleUseCase.ListAppraisals(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get appraisals", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get appraisals")
+	}
+
+	return utils.SendSuccess(c, "Appraisals retrieved successfully", appraisals)
+}
+
+func (h *Handler) GetAppraisalByID(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	appraisal, err := h.cycleUseCase.GetAppraisal(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get appraisal")
+	}
+
+	return utils.SendSuccess(c, "Appraisal retrieved successfully", appraisal)
+}
+
+// Appraisal sign-off
+
+func (h *Handler) UpdateAppraisalReview(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	var req application.AppraisalReviewRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	appraisal, err := h.signOffUseCase.UpdateReview(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update appraisal review")
+	}
+
+	return utils.SendSuccess(c, "Appraisal review updated successfully", appraisal)
+}
+
+func (h *Handler) SubmitAppraisal(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	appraisal, err := h.signOffUseCase.Submit(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to submit appraisal")
+	}
+
+	return utils.SendSuccess(c, "Appraisal submitted successfully", appraisal)
+}
+
+func (h *Handler) RecordAppraisalDiscussion(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	var req application.DiscussionRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	appraisal, err := h.signOffUseCase.RecordDiscussion(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to record appraisal discussion")
+	}
+
+	return utils.SendSuccess(c, "Appraisal discussion recorded successfully", appraisal)
+}
+
+func (h *Handler) SignAppraisalAsEmployee(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	var req application.EmployeeSignRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	appraisal, err := h.signOffUseCase.SignAsEmployee(c.Context(), id, &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to sign appraisal")
+	}
+
+	return utils.SendSuccess(c, "Appraisal signed successfully", appraisal)
+}
+
+func (h *Handler) SignAppraisalAsManager(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	appraisal, err := h.signOffUseCase.SignAsManager(c.Context(), id, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to sign appraisal")
+	}
+
+	return utils.SendSuccess(c, "Appraisal signed successfully", appraisal)
+}
+
+func (h *Handler) ReopenAppraisal(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	appraisal, err := h.signOffUseCase.Reopen(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to reopen appraisal")
+	}
+
+	return utils.SendSuccess(c, "Appraisal reopened successfully", appraisal)
+}
+
+// 360 feedback
+
+func (h *Handler) GetFeedbackNominations(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	nominations, err := h.feedbackUseCase.ListNominations(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get feedback nominations")
+	}
+
+	return utils.SendSuccess(c, "Feedback nominations retrieved successfully", nominations)
+}
+
+func (h *Handler) NominateReviewer(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	var req application.NominationRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	nomination, err := h.feedbackUseCase.Nominate(c.Context(), id, &req, currentUserID(c), currentUserIsHR(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to nominate reviewer")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Reviewer nominated successfully",
+		Data:    nomination,
+	})
+}
+
+func (h *Handler) WithdrawNomination(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+	nominationID, err := intParam(c, "nominationId")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid nomination ID")
+	}
+
+	if err := h.feedbackUseCase.WithdrawNomination(c.Context(), id, nominationID, currentUserID(c), currentUserIsHR(c)); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to withdraw nomination")
+	}
+
+	return utils.SendSuccess(c, "Nomination withdrawn successfully", nil)
+}
+
+func (h *Handler) GetFeedbackSummary(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	summary, err := h.feedbackUseCase.Summary(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get feedback summary")
+	}
+
+	return utils.SendSuccess(c, "Feedback summary retrieved successfully", summary)
+}
+
+func (h *Handler) GetFeedbackQuestionnaire(c *fiber.Ctx) error {
+	questionnaire, err := h.feedbackUseCase.Questionnaire(c.Context(), c.Params("token"))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get feedback questionnaire")
+	}
+
+	return utils.SendSuccess(c, "Feedback questionnaire retrieved successfully", questionnaire)
+}
+
+func (h *Handler) SubmitFeedback(c *fiber.Ctx) error {
+	var req application.FeedbackSubmission
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.feedbackUseCase.Submit(c.Context(), c.Params("token"), &req); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to submit feedback")
+	}
+
+	return utils.SendSuccess(c, "Feedback submitted successfully", nil)
+}
+
+func (h *Handler) DeclineFeedback(c *fiber.Ctx) error {
+	if err := h.feedbackUseCase.Decline(c.Context(), c.Params("token")); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to decline feedback")
+	}
+
+	return utils.SendSuccess(c, "Feedback declined successfully", nil)
+}
+
+// Goals
+
+func (h *Handler) GetGoals(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.PerformanceGoalFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	var err error
+	if filter.EmployeeID, err = intQuery(c, "employee_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+	if filter.CycleID, err = intQuery(c, "cycle_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+
+	goals, err := h.goalUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get goals", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get goals")
+	}
+
+	return utils.SendSuccess(c, "Goals retrieved successfully", goals)
+}
+
+func (h *Handler) GetGoalByID(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid goal ID")
+	}
+
+	goal, err := h.goalUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get goal")
+	}
+
+	return utils.SendSuccess(c, "Goal retrieved successfully", goal)
+}
+
+func (h *Handler) CreateGoal(c *fiber.Ctx) error {
+	var req application.GoalRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	goal, err := h.goalUseCase.Create(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create goal")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Goal created successfully",
+		Data:    goal,
+	})
+}
+
+func (h *Handler) UpdateGoal(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid goal ID")
+	}
+
+	var req application.GoalRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	goal, err := h.goalUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update goal")
+	}
+
+	return utils.SendSuccess(c, "Goal updated successfully", goal)
+}
+
+func (h *Handler) DeleteGoal(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid goal ID")
+	}
+
+	if err := h.goalUseCase.Delete(c.Context(), id); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete goal")
+	}
+
+	return utils.SendSuccess(c, "Goal deleted successfully", nil)
+}
+
+func (h *Handler) GetGoalMeasurements(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid goal ID")
+	}
+
+	measurements, err := h.goalUseCase.GetMeasurements(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get KPI measurements")
+	}
+
+	return utils.SendSuccess(c, "KPI measurements retrieved successfully", measurements)
+}
+
+func (h *Handler) RecordGoalMeasurement(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid goal ID")
+	}
+
+	var req application.MeasurementRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	measurement, err := h.goalUseCase.RecordMeasurement(c.Context(), id, &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to record KPI measurement")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "KPI measurement recorded successfully",
+		Data:    measurement,
+	})
+}
+
+func (h *Handler) DeleteGoalMeasurement(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid goal ID")
+	}
+	measurementID, err := intParam(c, "measurementId")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid measurement ID")
+	}
+
+	if err := h.goalUseCase.DeleteMeasurement(c.Context(), id, measurementID); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete KPI measurement")
+	}
+
+	return utils.SendSuccess(c, "KPI measurement deleted successfully", nil)
+}
+
+func (h *Handler) GetGoalTrend(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid goal ID")
+	}
+	interval, err := trendInterval(c)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	trend, err := h.goalUseCase.Trend(c.Context(), id, interval)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get goal trend")
+	}
+
+	return utils.SendSuccess(c, "Goal trend retrieved successfully", trend)
+}
+
+// Competencies
+
+func (h *Handler) GetCompetencies(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.PerformanceCompetencyFilter{
+		Level:      c.Query("level"),
+		ActiveOnly: c.QueryBool("active_only"),
+		Limit:      limit,
+		Offset:     offset,
+	}
+
+	competencies, err := h.competencyUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get competencies", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get competencies")
+	}
+
+	return utils.SendSuccess(c, "Competencies retrieved successfully", competencies)
+}
+
+func (h *Handler) GetCompetencyByID(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid competency ID")
+	}
+
+	competency, err := h.competencyUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get competency")
+	}
+
+	return utils.SendSuccess(c, "Competency retrieved successfully", competency)
+}
+
+func (h *Handler) CreateCompetency(c *fiber.Ctx) error {
+	var req application.CompetencyRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	competency, err := h.competencyUseCase.Create(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create competency")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Competency created successfully",
+		Data:    competency,
+	})
+}
+
+func (h *Handler) UpdateCompetency(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid competency ID")
+	}
+
+	var req application.CompetencyRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	competency, err := h.competencyUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update competency")
+	}
+
+	return utils.SendSuccess(c, "Competency updated successfully", competency)
+}
+
+// KPIs
+
+func (h *Handler) GetKPIs(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.PerformanceKPIFilter{
+		ActiveOnly: c.QueryBool("active_only"),
+		Limit:      limit,
+		Offset:     offset,
+	}
+	var err error
+	if filter.DepartmentID, err = intQuery(c, "department_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid department ID")
+	}
+
+	kpis, err := h.kpiUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get KPIs", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get KPIs")
+	}
+
+	return utils.SendSuccess(c, "KPIs retrieved successfully", kpis)
+}
+
+func (h *Handler) GetKPIByID(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid KPI ID")
+	}
+
+	kpi, err := h.kpiUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get KPI")
+	}
+
+	return utils.SendSuccess(c, "KPI retrieved successfully", kpi)
+}
+
+func (h *Handler) CreateKPI(c *fiber.Ctx) error {
+	var req application.KPIRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	kpi, err := h.kpiUseCase.Create(c.Context(), &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create KPI")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "KPI created successfully",
+		Data:    kpi,
+	})
+}
+
+func (h *Handler) UpdateKPI(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid KPI ID")
+	}
+
+	var req application.KPIRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	kpi, err := h.kpiUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update KPI")
+	}
+
+	return utils.SendSuccess(c, "KPI updated successfully", kpi)
+}
+
+func (h *Handler) EvaluateKPITarget(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid KPI ID")
+	}
+	baseline, err := floatQuery(c, "baseline")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid baseline")
+	}
+
+	target, err := h.kpiUseCase.EvaluateTarget(c.Context(), id, baseline)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to evaluate KPI target")
+	}
+
+	return utils.SendSuccess(c, "KPI target evaluated successfully", fiber.Map{
+		"baseline": baseline,
+		"target":   target,
+	})
+}
+
+func (h *Handler) GetKPITrend(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid KPI ID")
+	}
+	cycleID, err := intQuery(c, "cycle_id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+	interval, err := trendInterval(c)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	trend, err := h.kpiUseCase.Trend(c.Context(), id, cycleID, interval)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get KPI trend")
+	}
+
+	return utils.SendSuccess(c, "KPI trend retrieved successfully", trend)
+}
+
+// Scoring
+
+func (h *Handler) GetCompetencyRatings(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	ratings, err := h.scoringUseCase.GetCompetencyRatings(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get competency ratings")
+	}
+
+	return utils.SendSuccess(c, "Competency ratings retrieved successfully", ratings)
+}
+
+func (h *Handler) RateCompetency(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	var req application.CompetencyRatingRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	rating, err := h.scoringUseCase.RateCompetency(c.Context(), id, &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to rate competency")
+	}
+
+	return utils.SendSuccess(c, "Competency rated successfully", rating)
+}
+
+func (h *Handler) DeleteCompetencyRating(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+	competencyID, err := intParam(c, "competencyId")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid competency ID")
+	}
+
+	if err := h.scoringUseCase.DeleteCompetencyRating(c.Context(), id, competencyID, currentUserID(c)); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to delete competency rating")
+	}
+
+	return utils.SendSuccess(c, "Competency rating deleted successfully", nil)
+}
+
+func (h *Handler) PreviewAppraisalScore(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	breakdown, err := h.scoringUseCase.Preview(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to score appraisal")
+	}
+
+	return utils.SendSuccess(c, "Appraisal score calculated successfully", breakdown)
+}
+
+func (h *Handler) ScoreAppraisal(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	breakdown, err := h.scoringUseCase.Score(c.Context(), id, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to score appraisal")
+	}
+
+	return utils.SendSuccess(c, "Appraisal scored successfully", breakdown)
+}
+
+func (h *Handler) GetRatingScale(c *fiber.Ctx) error {
+	scale, err := h.scoringUseCase.GetRatingScale(c.Context())
+	if err != nil {
+		h.logger.Error("Failed to get rating scale", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get rating scale")
+	}
+
+	return utils.SendSuccess(c, "Rating scale retrieved successfully", scale)
+}
+
+func (h *Handler) SetRatingScale(c *fiber.Ctx) error {
+	var req application.RatingScaleRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	scale, err := h.scoringUseCase.SetRatingScale(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update rating scale")
+	}
+
+	return utils.SendSuccess(c, "Rating scale updated successfully", scale)
+}
+
+// Calibration
+
+func (h *Handler) GetCalibrationSessions(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.CalibrationSessionFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	var err error
+	if filter.CycleID, err = intQuery(c, "cycle_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+	if filter.DepartmentID, err = intQuery(c, "department_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid department ID")
+	}
+
+	sessions, err := h.calibrationUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get calibration sessions", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get calibration sessions")
+	}
+
+	return utils.SendSuccess(c, "Calibration sessions retrieved successfully", sessions)
+}
+
+func (h *Handler) GetCalibrationSessionByID(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid calibration session ID")
+	}
+
+	session, err := h.calibrationUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get calibration session")
+	}
+
+	return utils.SendSuccess(c, "Calibration session retrieved successfully", session)
+}
+
+func (h *Handler) CreateCalibrationSession(c *fiber.Ctx) error {
+	var req application.CalibrationSessionRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	session, err := h.calibrationUseCase.Create(c.Context(), &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create calibration session")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Calibration session created successfully",
+		Data:    session,
+	})
+}
+
+func (h *Handler) UpdateCalibrationSession(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid calibration session ID")
+	}
+
+	var req application.CalibrationSessionRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	session, err := h.calibrationUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update calibration session")
+	}
+
+	return utils.SendSuccess(c, "Calibration session updated successfully", session)
+}
+
+func (h *Handler) GetRatingDistribution(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid calibration session ID")
+	}
+
+	distribution, err := h.calibrationUseCase.Distribution(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get rating distribution")
+	}
+
+	return utils.SendSuccess(c, "Rating distribution retrieved successfully", distribution)
+}
+
+func (h *Handler) GetCalibrationAdjustments(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid calibration session ID")
+	}
+
+	adjustments, err := h.calibrationUseCase.Adjustments(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get calibration adjustments")
+	}
+
+	return utils.SendSuccess(c, "Calibration adjustments retrieved successfully", adjustments)
+}
+
+func (h *Handler) AdjustRating(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid calibration session ID")
+	}
+
+	var req application.CalibrationAdjustmentRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	adjustment, err := h.calibrationUseCase.Adjust(c.Context(), id, &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to adjust rating")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Rating adjusted successfully",
+		Data:    adjustment,
+	})
+}
+
+func (h *Handler) FinalizeCalibrationSession(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid calibration session ID")
+	}
+
+	session, err := h.calibrationUseCase.Finalize(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to finalize calibration session")
+	}
+
+	return utils.SendSuccess(c, "Calibration session finalized successfully", session)
+}
+
+// Performance improvement plans
+
+func (h *Handler) GetPIPs(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.PerformancePIPFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	var err error
+	if filter.EmployeeID, err = intQuery(c, "employee_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+	if filter.AppraisalID, err = intQuery(c, "appraisal_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+
+	pips, err := h.pipUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get PIPs", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get PIPs")
+	}
+
+	return utils.SendSuccess(c, "PIPs retrieved successfully", pips)
+}
+
+func (h *Handler) GetAppraisalPIPs(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid appraisal ID")
+	}
+	limit, offset := pagination(c)
+
+	pips, err := h.pipUseCase.List(c.Context(), &domain.PerformancePIPFilter{
+		AppraisalID: &id,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		h.logger.Error("Failed to get appraisal PIPs", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get PIPs")
+	}
+
+	return utils.SendSuccess(c, "PIPs retrieved successfully", pips)
+}
+
+func (h *Handler) GetPIPByID(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid PIP ID")
+	}
+
+	pip, err := h.pipUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get PIP")
+	}
+
+	return utils.SendSuccess(c, "PIP retrieved successfully", pip)
+}
+
+func (h *Handler) CreatePIP(c *fiber.Ctx) error {
+	var req application.PIPRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	pip, err := h.pipUseCase.Create(c.Context(), &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create PIP")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "PIP created successfully",
+		Data:    pip,
+	})
+}
+
+func (h *Handler) UpdatePIP(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid PIP ID")
+	}
+
+	var req application.PIPUpdateRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	pip, err := h.pipUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update PIP")
+	}
+
+	return utils.SendSuccess(c, "PIP updated successfully", pip)
+}
+
+func (h *Handler) RecordPIPOutcome(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid PIP ID")
+	}
+
+	var req application.PIPOutcomeRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	pip, err := h.pipUseCase.RecordOutcome(c.Context(), id, &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to record PIP outcome")
+	}
+
+	return utils.SendSuccess(c, "PIP outcome recorded successfully", pip)
+}
+
+func (h *Handler) SendPIPReminders(c *fiber.Ctx) error {
+	date, err := dateQuery(c, "date", time.Now())
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid date, expected YYYY-MM-DD")
+	}
+
+	reminders, err := h.pipUseCase.Reminders(c.Context(), date)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get PIP reminders")
+	}
+
+	return utils.SendSuccess(c, "PIP reminders retrieved successfully", reminders)
+}
+
+func (h *Handler) GetPIPCheckIns(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid PIP ID")
+	}
+
+	checkIns, err := h.pipUseCase.CheckIns(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get PIP check-ins")
+	}
+
+	return utils.SendSuccess(c, "PIP check-ins retrieved successfully", checkIns)
+}
+
+func (h *Handler) SchedulePIPCheckIn(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid PIP ID")
+	}
+
+	var req application.CheckInScheduleRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	checkIn, err := h.pipUseCase.ScheduleCheckIn(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to schedule PIP check-in")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "PIP check-in scheduled successfully",
+		Data:    checkIn,
+	})
+}
+
+func (h *Handler) RecordPIPCheckIn(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid PIP ID")
+	}
+	checkInID, err := intParam(c, "checkInId")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid check-in ID")
+	}
+
+	var req application.CheckInRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	checkIn, err := h.pipUseCase.RecordCheckIn(c.Context(), id, checkInID, &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to record PIP check-in")
+	}
+
+	return utils.SendSuccess(c, "PIP check-in recorded successfully", checkIn)
+}
+
+func (h *Handler) CancelPIPCheckIn(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid PIP ID")
+	}
+	checkInID, err := intParam(c, "checkInId")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid check-in ID")
+	}
+
+	if err := h.pipUseCase.CancelCheckIn(c.Context(), id, checkInID); err != nil {
+		return h.sendUseCaseError(c, err, "Failed to cancel PIP check-in")
+	}
+
+	return utils.SendSuccess(c, "PIP check-in cancelled successfully", nil)
+}
+
+// Trainings
+
+func (h *Handler) GetTrainings(c *fiber.Ctx) error {
+	limit, offset := pagination(c)
+	filter := &domain.PerformanceTrainingFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	var err error
+	if filter.EmployeeID, err = intQuery(c, "employee_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+	if filter.CompetencyID, err = intQuery(c, "competency_id"); err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid competency ID")
+	}
+
+	trainings, err := h.trainingUseCase.List(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get trainings", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get trainings")
+	}
+
+	return utils.SendSuccess(c, "Trainings retrieved successfully", trainings)
+}
+
+func (h *Handler) GetTrainingByID(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid training ID")
+	}
+
+	training, err := h.trainingUseCase.Get(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get training")
+	}
+
+	return utils.SendSuccess(c, "Training retrieved successfully", training)
+}
+
+func (h *Handler) CreateTraining(c *fiber.Ctx) error {
+	var req application.TrainingRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	training, err := h.trainingUseCase.Create(c.Context(), &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to create training")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Training created successfully",
+		Data:    training,
+	})
+}
+
+func (h *Handler) UpdateTraining(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid training ID")
+	}
+
+	var req application.TrainingRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	training, err := h.trainingUseCase.Update(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to update training")
+	}
+
+	return utils.SendSuccess(c, "Training updated successfully", training)
+}
+
+func (h *Handler) CompleteTraining(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid training ID")
+	}
+
+	var req application.TrainingCompleteRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	training, err := h.trainingUseCase.Complete(c.Context(), id, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to complete training")
+	}
+
+	return utils.SendSuccess(c, "Training completed successfully", training)
+}
+
+func (h *Handler) CancelTraining(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid training ID")
+	}
+
+	training, err := h.trainingUseCase.Cancel(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to cancel training")
+	}
+
+	return utils.SendSuccess(c, "Training cancelled successfully", training)
+}
+
+func (h *Handler) GetTrainingEnrolments(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid training ID")
+	}
+
+	enrolments, err := h.trainingUseCase.Enrolments(c.Context(), id)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get training enrolments")
+	}
+
+	return utils.SendSuccess(c, "Training enrolments retrieved successfully", enrolments)
+}
+
+func (h *Handler) EnrolOnTraining(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid training ID")
+	}
+
+	var req application.EnrolmentRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	enrolment, err := h.trainingUseCase.Enrol(c.Context(), id, &req, currentUserID(c))
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to enrol on training")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.APIResponse{
+		Success: true,
+		Message: "Employee enrolled successfully",
+		Data:    enrolment,
+	})
+}
+
+func (h *Handler) CompleteTrainingEnrolment(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid training ID")
+	}
+	enrolmentID, err := intParam(c, "enrolmentId")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid enrolment ID")
+	}
+
+	var req application.EnrolmentCompleteRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	enrolment, err := h.trainingUseCase.CompleteEnrolment(c.Context(), id, enrolmentID, &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to complete training enrolment")
+	}
+
+	return utils.SendSuccess(c, "Training enrolment completed successfully", enrolment)
+}
+
+func (h *Handler) CancelTrainingEnrolment(c *fiber.Ctx) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid training ID")
+	}
+	enrolmentID, err := intParam(c, "enrolmentId")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid enrolment ID")
+	}
+
+	enrolment, err := h.trainingUseCase.CancelEnrolment(c.Context(), id, enrolmentID)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to cancel training enrolment")
+	}
+
+	return utils.SendSuccess(c, "Training enrolment cancelled successfully", enrolment)
+}
+
+func (h *Handler) GetTrainingNeeds(c *fiber.Ctx) error {
+	cycleID, err := intQuery(c, "cycle_id")
+	if err != nil || cycleID == nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid cycle ID")
+	}
+	maxRating, err := intQuery(c, "max_rating")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid max rating")
+	}
+	departmentID, err := intQuery(c, "department_id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid department ID")
+	}
+
+	needs, err := h.trainingUseCase.Needs(c.Context(), *cycleID, maxRating, departmentID)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get training needs")
+	}
+
+	return utils.SendSuccess(c, "Training needs retrieved successfully", needs)
+}
+
+func (h *Handler) GetTrainingBudgets(c *fiber.Ctx) error {
+	year, err := yearQuery(c)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid year")
+	}
+
+	budgets, err := h.trainingUseCase.Budgets(c.Context(), year)
+	if err != nil {
+		h.logger.Error("Failed to get training budgets", "error", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to get training budgets")
+	}
+
+	return utils.SendSuccess(c, "Training budgets retrieved successfully", budgets)
+}
+
+func (h *Handler) SetTrainingBudget(c *fiber.Ctx) error {
+	var req application.TrainingBudgetRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	budget, err := h.trainingUseCase.SetBudget(c.Context(), &req)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to set training budget")
+	}
+
+	return utils.SendSuccess(c, "Training budget set successfully", budget)
+}
+
+func (h *Handler) GetTrainingBudgetReport(c *fiber.Ctx) error {
+	year, err := yearQuery(c)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid year")
+	}
+	departmentID, err := intQuery(c, "department_id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid department ID")
+	}
+
+	report, err := h.trainingUseCase.BudgetReport(c.Context(), year, departmentID)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get training budget report")
+	}
+
+	return utils.SendSuccess(c, "Training budget report retrieved successfully", report)
+}
+
+func (h *Handler) GetEmployeeTrainingReport(c *fiber.Ctx) error {
+	year, err := yearQuery(c)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid year")
+	}
+	departmentID, err := intQuery(c, "department_id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid department ID")
+	}
+	employeeID, err := intQuery(c, "employee_id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid employee ID")
+	}
+
+	report, err := h.trainingUseCase.EmployeeReport(c.Context(), year, departmentID, employeeID)
+	if err != nil {
+		return h.sendUseCaseError(c, err, "Failed to get employee training report")
+	}
+
+	return utils.SendSuccess(c, "Employee training report retrieved successfully", report)
+}
+
+// sendUseCaseError maps use case failures onto HTTP status codes
+func (h *Handler) sendUseCaseError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return utils.SendError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, application.ErrInvalidState):
+		return utils.SendError(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, application.ErrForbidden):
+		return utils.SendError(c, fiber.StatusForbidden, err.Error())
+	}
+
+	h.logger.Error(message, "error", err)
+	return utils.SendError(c, fiber.StatusBadRequest, err.Error())
+}
+
+func intParam(c *fiber.Ctx, name string) (int, error) {
+	return strconv.Atoi(c.Params(name))
+}
+
+// intQuery parses an optional integer query parameter, returning nil when it
+// is absent
+func intQuery(c *fiber.Ctx, name string) (*int, error) {
+	if c.Query(name) == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(c.Query(name))
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// floatQuery parses an optional decimal query parameter, returning nil when
+// it is absent
+func floatQuery(c *fiber.Ctx, name string) (*float64, error) {
+	if c.Query(name) == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(c.Query(name), 64)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// trendInterval reads the interval query parameter, week or month, which
+// defaults to month
+func trendInterval(c *fiber.Ctx) (string, error) {
+	interval := c.Query("interval", domain.TrendIntervalMonth)
+	if interval != domain.TrendIntervalWeek && interval != domain.TrendIntervalMonth {
+		return "", fmt.Errorf("interval must be week or month")
+	}
+	return interval, nil
+}
+
+// currentUserID returns the authenticated user set by the JWT middleware, or
+// nil for service tokens without one. Performance records use the HR
+// monolith's integer user IDs.
+func currentUserID(c *fiber.Ctx) *int {
+	userID, _ := c.Locals("user_id").(string)
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// currentUserIsHR reports whether the caller holds an HR role
+func currentUserIsHR(c *fiber.Ctx) bool {
+	return middleware.HasRole(c, middleware.RoleHR, middleware.RoleAdmin)
+}
+
+// pagination reads the page and limit query parameters as limit and offset
+func pagination(c *fiber.Ctx) (int, int) {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	return limit, (page - 1) * limit
+}
+
+// dateQuery parses a YYYY-MM-DD query parameter, falling back to the date of
+// def when it is absent
+func dateQuery(c *fiber.Ctx, name string, def time.Time) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		year, month, day := def.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Parse(dateLayout, value)
+}
+
+// yearQuery reads the year query parameter, which defaults to the current
+// year
+func yearQuery(c *fiber.Ctx) (int, error) {
+	if c.Query("year") == "" {
+		return time.Now().Year(), nil
+	}
+	return strconv.Atoi(c.Query("year"))
+}
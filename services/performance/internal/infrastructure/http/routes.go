@@ -0,0 +1,153 @@
+package http
+
+import (
+	"yathuerp/shared/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupPublicRoutes registers the routes reached without a JWT. They must be
+// set up before the JWT middleware is mounted.
+func SetupPublicRoutes(app *fiber.App, handler *Handler) {
+	// 360 feedback, answered with the reviewer's token by reviewers who may
+	// have no account
+	feedback := app.Group("/api/v1/performance/feedback")
+	{
+		feedback.Get("/:token", handler.GetFeedbackQuestionnaire)
+		feedback.Post("/:token", handler.SubmitFeedback)
+		feedback.Post("/:token/decline", handler.DeclineFeedback)
+	}
+}
+
+func SetupRoutes(app *fiber.App, handler *Handler, auth *middleware.AuthMiddleware) {
+	// API versioning
+	api := app.Group("/api/v1")
+	performance := api.Group("/performance")
+
+	job := auth.JobAuth()
+	hr := auth.RoleBasedAuth(middleware.RoleHR, middleware.RoleAdmin)
+	manager := auth.RoleBasedAuth(middleware.RoleHR, middleware.RoleAdmin, middleware.RoleManager)
+
+	// Review cycles
+	cycles := performance.Group("/cycles")
+	{
+		cycles.Get("/", handler.GetCycles)
+		cycles.Post("/", hr, handler.CreateCycle)
+		cycles.Post("/sync-status", job, handler.SyncCycleStatuses)
+		cycles.Get("/:id", handler.GetCycleByID)
+		cycles.Put("/:id", hr, handler.UpdateCycle)
+		cycles.Delete("/:id", hr, handler.DeleteCycle)
+		cycles.Post("/:id/launch", hr, handler.LaunchCycle)
+		cycles.Post("/:id/appraisals", hr, handler.GenerateAppraisals)
+		cycles.Get("/:id/progress", handler.GetCycleProgress)
+	}
+
+	// Appraisals
+	appraisals := performance.Group("/appraisals")
+	{
+		appraisals.Get("/", handler.GetAppraisals)
+		appraisals.Get("/:id", handler.GetAppraisalByID)
+		appraisals.Get("/:id/competencies", handler.GetCompetencyRatings)
+		appraisals.Post("/:id/competencies", handler.RateCompetency)
+		appraisals.Delete("/:id/competencies/:competencyId", handler.DeleteCompetencyRating)
+		appraisals.Get("/:id/score", handler.PreviewAppraisalScore)
+		appraisals.Post("/:id/score", handler.ScoreAppraisal)
+		appraisals.Put("/:id/review", handler.UpdateAppraisalReview)
+		appraisals.Post("/:id/submit", handler.SubmitAppraisal)
+		appraisals.Post("/:id/discussion", handler.RecordAppraisalDiscussion)
+		appraisals.Post("/:id/sign/employee", handler.SignAppraisalAsEmployee)
+		appraisals.Post("/:id/sign/manager", handler.SignAppraisalAsManager)
+		appraisals.Post("/:id/reopen", handler.ReopenAppraisal)
+		appraisals.Get("/:id/pips", handler.GetAppraisalPIPs)
+		appraisals.Get("/:id/feedback", handler.GetFeedbackSummary)
+		appraisals.Get("/:id/feedback/nominations", handler.GetFeedbackNominations)
+		appraisals.Post("/:id/feedback/nominations", manager, handler.NominateReviewer)
+		appraisals.Delete("/:id/feedback/nominations/:nominationId", manager, handler.WithdrawNomination)
+	}
+
+	// Rating calibration
+	calibrations := performance.Group("/calibrations", hr)
+	{
+		calibrations.Get("/", handler.GetCalibrationSessions)
+		calibrations.Post("/", handler.CreateCalibrationSession)
+		calibrations.Get("/:id", handler.GetCalibrationSessionByID)
+		calibrations.Put("/:id", handler.UpdateCalibrationSession)
+		calibrations.Get("/:id/distribution", handler.GetRatingDistribution)
+		calibrations.Get("/:id/adjustments", handler.GetCalibrationAdjustments)
+		calibrations.Post("/:id/adjustments", handler.AdjustRating)
+		calibrations.Post("/:id/finalize", handler.FinalizeCalibrationSession)
+	}
+
+	// Performance improvement plans
+	pips := performance.Group("/pips")
+	{
+		pips.Get("/", handler.GetPIPs)
+		pips.Post("/", hr, handler.CreatePIP)
+		pips.Post("/reminders", job, handler.SendPIPReminders)
+		pips.Get("/:id", handler.GetPIPByID)
+		pips.Put("/:id", hr, handler.UpdatePIP)
+		pips.Post("/:id/outcome", hr, handler.RecordPIPOutcome)
+		pips.Get("/:id/check-ins", handler.GetPIPCheckIns)
+		pips.Post("/:id/check-ins", handler.SchedulePIPCheckIn)
+		pips.Put("/:id/check-ins/:checkInId", handler.RecordPIPCheckIn)
+		pips.Delete("/:id/check-ins/:checkInId", handler.CancelPIPCheckIn)
+	}
+
+	// Trainings
+	trainings := performance.Group("/trainings")
+	{
+		trainings.Get("/", handler.GetTrainings)
+		trainings.Post("/", handler.CreateTraining)
+		trainings.Get("/needs", handler.GetTrainingNeeds)
+		trainings.Get("/budgets", handler.GetTrainingBudgets)
+		trainings.Put("/budgets", handler.SetTrainingBudget)
+		trainings.Get("/reports/budget", handler.GetTrainingBudgetReport)
+		trainings.Get("/reports/employees", handler.GetEmployeeTrainingReport)
+		trainings.Get("/:id", handler.GetTrainingByID)
+		trainings.Put("/:id", handler.UpdateTraining)
+		trainings.Post("/:id/complete", handler.CompleteTraining)
+		trainings.Post("/:id/cancel", handler.CancelTraining)
+		trainings.Get("/:id/enrolments", handler.GetTrainingEnrolments)
+		trainings.Post("/:id/enrolments", handler.EnrolOnTraining)
+		trainings.Post("/:id/enrolments/:enrolmentId/complete", handler.CompleteTrainingEnrolment)
+		trainings.Post("/:id/enrolments/:enrolmentId/cancel", handler.CancelTrainingEnrolment)
+	}
+
+	// Goals
+	goals := performance.Group("/goals")
+	{
+		goals.Get("/", handler.GetGoals)
+		goals.Post("/", handler.CreateGoal)
+		goals.Get("/:id", handler.GetGoalByID)
+		goals.Put("/:id", handler.UpdateGoal)
+		goals.Delete("/:id", handler.DeleteGoal)
+		goals.Get("/:id/measurements", handler.GetGoalMeasurements)
+		goals.Post("/:id/measurements", handler.RecordGoalMeasurement)
+		goals.Delete("/:id/measurements/:measurementId", handler.DeleteGoalMeasurement)
+		goals.Get("/:id/trend", handler.GetGoalTrend)
+	}
+
+	// Competency catalogue
+	competencies := performance.Group("/competencies")
+	{
+		competencies.Get("/", handler.GetCompetencies)
+		competencies.Post("/", handler.CreateCompetency)
+		competencies.Get("/:id", handler.GetCompetencyByID)
+		competencies.Put("/:id", handler.UpdateCompetency)
+	}
+
+	// KPI catalogue
+	kpis := performance.Group("/kpis")
+	{
+		kpis.Get("/", handler.GetKPIs)
+		kpis.Post("/", handler.CreateKPI)
+		kpis.Get("/:id", handler.GetKPIByID)
+		kpis.Put("/:id", handler.UpdateKPI)
+		kpis.Get("/:id/target", handler.EvaluateKPITarget)
+		kpis.Get("/:id/trend", handler.GetKPITrend)
+	}
+
+	// Score to rating bands
+	performance.Get("/rating-scale", handler.GetRatingScale)
+	performance.Put("/rating-scale", hr, handler.SetRatingScale)
+}
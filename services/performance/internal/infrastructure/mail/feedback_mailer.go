@@ -0,0 +1,66 @@
+package mail
+
+import (
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strings"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/config"
+	"yathuerp/shared/utils"
+)
+
+// feedbackMailer sends 360 feedback invitations over SMTP
+type feedbackMailer struct {
+	cfg    config.SMTPConfig
+	logger utils.Logger
+}
+
+func NewFeedbackMailer(cfg config.SMTPConfig, logger utils.Logger) domain.FeedbackMailer {
+	return &feedbackMailer{
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// SendFeedbackInvitation mails the reviewer the portal link holding their
+// token. The token is never logged.
+func (m *feedbackMailer) SendFeedbackInvitation(email, name, token string) error {
+	to := netmail.Address{Name: name, Address: email}
+	from := netmail.Address{Address: m.cfg.From}
+	link := fmt.Sprintf("%s/feedback/%s", strings.TrimRight(m.cfg.PortalURL, "/"), token)
+
+	greeting := "Hello,"
+	if name != "" {
+		greeting = fmt.Sprintf("Hello %s,", name)
+	}
+
+	var message strings.Builder
+	message.WriteString("From: " + from.String() + "\r\n")
+	message.WriteString("To: " + to.String() + "\r\n")
+	message.WriteString("Subject: Your feedback has been requested\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(greeting + "\r\n\r\n")
+	message.WriteString("You have been asked to give feedback on a colleague's appraisal. ")
+	message.WriteString("Your answers are not linked back to you.\r\n\r\n")
+	message.WriteString("Give your feedback, or decline, at:\r\n" + link + "\r\n")
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		host, _, err := net.SplitHostPort(m.cfg.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address: %w", err)
+		}
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, host)
+	}
+
+	if err := smtp.SendMail(m.cfg.Addr, auth, m.cfg.From, []string{email}, []byte(message.String())); err != nil {
+		m.logger.Error("Failed to send feedback invitation", "error", err)
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const appraisalColumns = `
+	id, cycle_id, employee_id, manager_id, status, overall_score, overall_rating,
+	employee_comments, manager_comments, employee_signed_at, manager_signed_at,
+	discussion_date, next_period_goals, development_plan, calibration_session_id,
+	created_at, updated_at`
+
+type appraisalRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewAppraisalRepository(db *pgxpool.Pool, logger utils.Logger) domain.PerformanceAppraisalRepository {
+	return &appraisalRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateMissing inserts the appraisals in one transaction, leaving any
+// employee who already has one in the cycle untouched
+func (r *appraisalRepository) CreateMissing(appraisals []*domain.PerformanceAppraisal) ([]*domain.PerformanceAppraisal, error) {
+	query := `
+		INSERT INTO tbl_pf_appraisals (
+			cycle_id, employee_id, manager_id, status, overall_score, overall_rating,
+			employee_comments, manager_comments, next_period_goals, development_plan,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (cycle_id, employee_id) DO NOTHING
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var created []*domain.PerformanceAppraisal
+	for _, appraisal := range appraisals {
+		err := tx.QueryRow(ctx, query,
+			appraisal.CycleID,
+			appraisal.EmployeeID,
+			appraisal.ManagerID,
+			appraisal.Status,
+			appraisal.OverallScore,
+			appraisal.OverallRating,
+			appraisal.EmployeeComments,
+			appraisal.ManagerComments,
+			appraisal.NextPeriodGoals,
+			appraisal.DevelopmentPlan,
+			appraisal.CreatedAt,
+			appraisal.UpdatedAt,
+		).Scan(&appraisal.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			r.logger.Error("Failed to create appraisal", "error", err, "cycle_id", appraisal.CycleID, "employee_id", appraisal.EmployeeID)
+			return nil, fmt.Errorf("failed to create appraisal: %w", err)
+		}
+		created = append(created, appraisal)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit appraisals: %w", err)
+	}
+
+	r.logger.Info("Appraisals created successfully", "count", len(created))
+	return created, nil
+}
+
+func (r *appraisalRepository) GetByID(id int) (*domain.PerformanceAppraisal, error) {
+	query := `SELECT ` + appraisalColumns + ` FROM tbl_pf_appraisals WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	appraisal, err := scanAppraisal(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("appraisal %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get appraisal", "error", err, "appraisal_id", id)
+		return nil, fmt.Errorf("failed to get appraisal: %w", err)
+	}
+
+	return appraisal, nil
+}
+
+func (r *appraisalRepository) GetAll(filter *domain.PerformanceAppraisalFilter) ([]*domain.PerformanceAppraisal, error) {
+	query := `SELECT ` + appraisalColumns + ` FROM tbl_pf_appraisals WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.CycleID != nil {
+		query += fmt.Sprintf(" AND cycle_id = $%d", argIndex)
+		args = append(args, *filter.CycleID)
+		argIndex++
+	}
+
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(" AND employee_id = $%d", argIndex)
+		args = append(args, *filter.EmployeeID)
+		argIndex++
+	}
+
+	if filter.ManagerID != nil {
+		query += fmt.Sprintf(" AND manager_id = $%d", argIndex)
+		args = append(args, *filter.ManagerID)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY cycle_id DESC, employee_id LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	return r.query(query, args...)
+}
+
+func (r *appraisalRepository) GetByCycle(cycleID int) ([]*domain.PerformanceAppraisal, error) {
+	query := `SELECT ` + appraisalColumns + ` FROM tbl_pf_appraisals WHERE cycle_id = $1 ORDER BY employee_id`
+
+	return r.query(query, cycleID)
+}
+
+func (r *appraisalRepository) query(query string, args ...interface{}) ([]*domain.PerformanceAppraisal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query appraisals", "error", err)
+		return nil, fmt.Errorf("failed to query appraisals: %w", err)
+	}
+	defer rows.Close()
+
+	var appraisals []*domain.PerformanceAppraisal
+	for rows.Next() {
+		appraisal, err := scanAppraisal(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan appraisal row", "error", err)
+			return nil, fmt.Errorf("failed to scan appraisal: %w", err)
+		}
+		appraisals = append(appraisals, appraisal)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning appraisal rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return appraisals, nil
+}
+
+func (r *appraisalRepository) CountByStatus(cycleID int) (map[string]int, error) {
+	query := `SELECT status, COUNT(*) FROM tbl_pf_appraisals WHERE cycle_id = $1 GROUP BY status`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, cycleID)
+	if err != nil {
+		r.logger.Error("Failed to count appraisals", "error", err, "cycle_id", cycleID)
+		return nil, fmt.Errorf("failed to count appraisals: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			r.logger.Error("Failed to scan appraisal count row", "error", err)
+			return nil, fmt.Errorf("failed to scan appraisal count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning appraisal count rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ProgressByManager counts each manager's appraisals in the cycle, named from
+// tbl_employees, with the managers furthest behind first
+func (r *appraisalRepository) ProgressByManager(cycleID int) ([]domain.ManagerProgress, error) {
+	query := `
+		SELECT a.manager_id,
+			COALESCE(TRIM(CONCAT(e.first_name, ' ', e.last_name)), ''),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE a.status = $2)
+		FROM tbl_pf_appraisals a
+		LEFT JOIN tbl_employees e ON e.id = a.manager_id
+		WHERE a.cycle_id = $1
+		GROUP BY a.manager_id, e.first_name, e.last_name
+		ORDER BY COUNT(*) FILTER (WHERE a.status = $2)::float / COUNT(*), a.manager_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, cycleID, domain.AppraisalStatusCompleted)
+	if err != nil {
+		r.logger.Error("Failed to query manager progress", "error", err, "cycle_id", cycleID)
+		return nil, fmt.Errorf("failed to query manager progress: %w", err)
+	}
+	defer rows.Close()
+
+	managers := []domain.ManagerProgress{}
+	for rows.Next() {
+		var progress domain.ManagerProgress
+		if err := rows.Scan(&progress.ManagerID, &progress.Name, &progress.Appraisals, &progress.Completed); err != nil {
+			r.logger.Error("Failed to scan manager progress row", "error", err)
+			return nil, fmt.Errorf("failed to scan manager progress: %w", err)
+		}
+		managers = append(managers, progress)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning manager progress rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return managers, nil
+}
+
+func (r *appraisalRepository) Update(appraisal *domain.PerformanceAppraisal) error {
+	query := `
+		UPDATE tbl_pf_appraisals SET
+			manager_id = $2, status = $3, overall_score = $4, overall_rating = $5,
+			employee_comments = $6, manager_comments = $7, employee_signed_at = $8,
+			manager_signed_at = $9, discussion_date = $10, next_period_goals = $11,
+			development_plan = $12, updated_at = $13
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	appraisal.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		appraisal.ID,
+		appraisal.ManagerID,
+		appraisal.Status,
+		appraisal.OverallScore,
+		appraisal.OverallRating,
+		appraisal.EmployeeComments,
+		appraisal.ManagerComments,
+		appraisal.EmployeeSignedAt,
+		appraisal.ManagerSignedAt,
+		appraisal.DiscussionDate,
+		appraisal.NextPeriodGoals,
+		appraisal.DevelopmentPlan,
+		appraisal.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update appraisal", "error", err, "appraisal_id", appraisal.ID)
+		return fmt.Errorf("failed to update appraisal: %w", err)
+	}
+
+	r.logger.Info("Appraisal updated successfully", "appraisal_id", appraisal.ID, "status", appraisal.Status)
+	return nil
+}
+
+func scanAppraisal(row pgx.Row) (*domain.PerformanceAppraisal, error) {
+	appraisal := &domain.PerformanceAppraisal{}
+	err := row.Scan(
+		&appraisal.ID,
+		&appraisal.CycleID,
+		&appraisal.EmployeeID,
+		&appraisal.ManagerID,
+		&appraisal.Status,
+		&appraisal.OverallScore,
+		&appraisal.OverallRating,
+		&appraisal.EmployeeComments,
+		&appraisal.ManagerComments,
+		&appraisal.EmployeeSignedAt,
+		&appraisal.ManagerSignedAt,
+		&appraisal.DiscussionDate,
+		&appraisal.NextPeriodGoals,
+		&appraisal.DevelopmentPlan,
+		&appraisal.CalibrationSessionID,
+		&appraisal.CreatedAt,
+		&appraisal.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return appraisal, nil
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type calibrationAdjustmentRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewCalibrationAdjustmentRepository(db *pgxpool.Pool, logger utils.Logger) domain.CalibrationAdjustmentRepository {
+	return &calibrationAdjustmentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *calibrationAdjustmentRepository) Create(adjustment *domain.CalibrationAdjustment) error {
+	query := `
+		INSERT INTO tbl_pf_calibration_adjustments (
+			session_id, appraisal_id, previous_rating, new_rating, justification,
+			adjusted_by, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
+		adjustment.SessionID,
+		adjustment.AppraisalID,
+		adjustment.PreviousRating,
+		adjustment.NewRating,
+		adjustment.Justification,
+		adjustment.AdjustedBy,
+		adjustment.CreatedAt,
+	).Scan(&adjustment.ID)
+	if err != nil {
+		r.logger.Error("Failed to create calibration adjustment", "error", err, "appraisal_id", adjustment.AppraisalID)
+		return fmt.Errorf("failed to create calibration adjustment: %w", err)
+	}
+
+	// the rating must not have moved, nor the appraisal been signed or
+	// calibrated by another session, since it was read
+	tag, err := tx.Exec(ctx, `
+		UPDATE tbl_pf_appraisals SET overall_rating = $2, calibration_session_id = $5, updated_at = $3
+		WHERE id = $1 AND overall_rating = $4
+			AND employee_signed_at IS NULL AND manager_signed_at IS NULL
+			AND (calibration_session_id IS NULL OR calibration_session_id = $5)`,
+		adjustment.AppraisalID, adjustment.NewRating, adjustment.CreatedAt, adjustment.PreviousRating, adjustment.SessionID)
+	if err != nil {
+		r.logger.Error("Failed to apply calibrated rating", "error", err, "appraisal_id", adjustment.AppraisalID)
+		return fmt.Errorf("failed to apply calibrated rating: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("the appraisal has changed or been signed, reload and try again")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit calibration adjustment: %w", err)
+	}
+
+	r.logger.Info("Calibration adjustment created successfully",
+		"session_id", adjustment.SessionID,
+		"appraisal_id", adjustment.AppraisalID,
+		"new_rating", adjustment.NewRating)
+	return nil
+}
+
+func (r *calibrationAdjustmentRepository) GetBySession(sessionID int) ([]*domain.CalibrationAdjustment, error) {
+	query := `
+		SELECT id, session_id, appraisal_id, previous_rating, new_rating, justification,
+			adjusted_by, created_at
+		FROM tbl_pf_calibration_adjustments
+		WHERE session_id = $1
+		ORDER BY created_at, id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, sessionID)
+	if err != nil {
+		r.logger.Error("Failed to query calibration adjustments", "error", err, "session_id", sessionID)
+		return nil, fmt.Errorf("failed to query calibration adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	var adjustments []*domain.CalibrationAdjustment
+	for rows.Next() {
+		adjustment := &domain.CalibrationAdjustment{}
+		err := rows.Scan(
+			&adjustment.ID,
+			&adjustment.SessionID,
+			&adjustment.AppraisalID,
+			&adjustment.PreviousRating,
+			&adjustment.NewRating,
+			&adjustment.Justification,
+			&adjustment.AdjustedBy,
+			&adjustment.CreatedAt,
+		)
+		if err != nil {
+			r.logger.Error("Failed to scan calibration adjustment row", "error", err)
+			return nil, fmt.Errorf("failed to scan calibration adjustment: %w", err)
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning calibration adjustment rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return adjustments, nil
+}
@@ -0,0 +1,310 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const calibrationSessionColumns = `
+	id, cycle_id, department_id, name, notes, status, created_by, finalized_at,
+	created_at, updated_at`
+
+type calibrationSessionRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewCalibrationSessionRepository(db *pgxpool.Pool, logger utils.Logger) domain.CalibrationSessionRepository {
+	return &calibrationSessionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *calibrationSessionRepository) Create(session *domain.CalibrationSession) error {
+	query := `
+		INSERT INTO tbl_pf_calibration_sessions (
+			cycle_id, department_id, name, notes, status, created_by, finalized_at,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
+		session.CycleID,
+		session.DepartmentID,
+		session.Name,
+		session.Notes,
+		session.Status,
+		session.CreatedBy,
+		session.FinalizedAt,
+		session.CreatedAt,
+		session.UpdatedAt,
+	).Scan(&session.ID)
+	if err != nil {
+		r.logger.Error("Failed to create calibration session", "error", err, "cycle_id", session.CycleID)
+		return fmt.Errorf("failed to create calibration session: %w", err)
+	}
+
+	if err := r.saveLimits(ctx, tx, session); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit calibration session: %w", err)
+	}
+
+	r.logger.Info("Calibration session created successfully", "session_id", session.ID, "cycle_id", session.CycleID)
+	return nil
+}
+
+func (r *calibrationSessionRepository) GetByID(id int) (*domain.CalibrationSession, error) {
+	query := `SELECT ` + calibrationSessionColumns + ` FROM tbl_pf_calibration_sessions WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, err := scanCalibrationSession(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("calibration session %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get calibration session", "error", err, "session_id", id)
+		return nil, fmt.Errorf("failed to get calibration session: %w", err)
+	}
+
+	if err := r.loadLimits(ctx, []*domain.CalibrationSession{session}); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *calibrationSessionRepository) GetAll(filter *domain.CalibrationSessionFilter) ([]*domain.CalibrationSession, error) {
+	query := `SELECT ` + calibrationSessionColumns + ` FROM tbl_pf_calibration_sessions WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.CycleID != nil {
+		query += fmt.Sprintf(" AND cycle_id = $%d", argIndex)
+		args = append(args, *filter.CycleID)
+		argIndex++
+	}
+
+	if filter.DepartmentID != nil {
+		query += fmt.Sprintf(" AND department_id = $%d", argIndex)
+		args = append(args, *filter.DepartmentID)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query calibration sessions", "error", err)
+		return nil, fmt.Errorf("failed to query calibration sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*domain.CalibrationSession
+	for rows.Next() {
+		session, err := scanCalibrationSession(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan calibration session row", "error", err)
+			return nil, fmt.Errorf("failed to scan calibration session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning calibration session rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	if err := r.loadLimits(ctx, sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *calibrationSessionRepository) Update(session *domain.CalibrationSession) error {
+	query := `
+		UPDATE tbl_pf_calibration_sessions SET
+			name = $2, notes = $3, status = $4, finalized_at = $5, updated_at = $6
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	session.UpdatedAt = time.Now()
+	_, err = tx.Exec(ctx, query,
+		session.ID,
+		session.Name,
+		session.Notes,
+		session.Status,
+		session.FinalizedAt,
+		session.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.Error("Failed to update calibration session", "error", err, "session_id", session.ID)
+		return fmt.Errorf("failed to update calibration session: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM tbl_pf_calibration_limits WHERE session_id = $1`, session.ID); err != nil {
+		r.logger.Error("Failed to clear calibration limits", "error", err, "session_id", session.ID)
+		return fmt.Errorf("failed to clear calibration limits: %w", err)
+	}
+	if err := r.saveLimits(ctx, tx, session); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit calibration session: %w", err)
+	}
+
+	r.logger.Info("Calibration session updated successfully", "session_id", session.ID, "status", session.Status)
+	return nil
+}
+
+func (r *calibrationSessionRepository) Finalize(session *domain.CalibrationSession, appraisalIDs []int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	session.UpdatedAt = time.Now()
+	tag, err := tx.Exec(ctx, `
+		UPDATE tbl_pf_calibration_sessions SET status = $2, finalized_at = $3, updated_at = $4
+		WHERE id = $1 AND status = $5`,
+		session.ID, session.Status, session.FinalizedAt, session.UpdatedAt, domain.CalibrationStatusOpen)
+	if err != nil {
+		r.logger.Error("Failed to finalize calibration session", "error", err, "session_id", session.ID)
+		return fmt.Errorf("failed to finalize calibration session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("calibration session %d is no longer open", session.ID)
+	}
+
+	// ratings already fixed by an earlier session keep that session
+	_, err = tx.Exec(ctx, `
+		UPDATE tbl_pf_appraisals SET calibration_session_id = $1, updated_at = $2
+		WHERE id = ANY($3) AND calibration_session_id IS NULL`,
+		session.ID, session.UpdatedAt, appraisalIDs)
+	if err != nil {
+		r.logger.Error("Failed to lock calibrated ratings", "error", err, "session_id", session.ID)
+		return fmt.Errorf("failed to lock calibrated ratings: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit calibration session: %w", err)
+	}
+
+	r.logger.Info("Calibration session finalized successfully", "session_id", session.ID, "appraisals", len(appraisalIDs))
+	return nil
+}
+
+func (r *calibrationSessionRepository) saveLimits(ctx context.Context, tx pgx.Tx, session *domain.CalibrationSession) error {
+	query := `INSERT INTO tbl_pf_calibration_limits (session_id, rating, max_percent) VALUES ($1, $2, $3)`
+	for _, limit := range session.Limits {
+		if _, err := tx.Exec(ctx, query, session.ID, limit.Rating, limit.MaxPercent); err != nil {
+			r.logger.Error("Failed to create calibration limit", "error", err, "session_id", session.ID)
+			return fmt.Errorf("failed to create calibration limit: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadLimits reads the forced-distribution limits of the sessions
+func (r *calibrationSessionRepository) loadLimits(ctx context.Context, sessions []*domain.CalibrationSession) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(sessions))
+	byID := map[int]*domain.CalibrationSession{}
+	for _, session := range sessions {
+		session.Limits = []domain.DistributionLimit{}
+		ids = append(ids, session.ID)
+		byID[session.ID] = session
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT session_id, rating, max_percent FROM tbl_pf_calibration_limits
+		WHERE session_id = ANY($1) ORDER BY session_id, id`, ids)
+	if err != nil {
+		r.logger.Error("Failed to query calibration limits", "error", err)
+		return fmt.Errorf("failed to query calibration limits: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionID int
+		var limit domain.DistributionLimit
+		if err := rows.Scan(&sessionID, &limit.Rating, &limit.MaxPercent); err != nil {
+			r.logger.Error("Failed to scan calibration limit row", "error", err)
+			return fmt.Errorf("failed to scan calibration limit: %w", err)
+		}
+		byID[sessionID].Limits = append(byID[sessionID].Limits, limit)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning calibration limit rows", "error", err)
+		return fmt.Errorf("error after scanning rows: %w", err)
+	}
+	return nil
+}
+
+func scanCalibrationSession(row pgx.Row) (*domain.CalibrationSession, error) {
+	session := &domain.CalibrationSession{}
+	err := row.Scan(
+		&session.ID,
+		&session.CycleID,
+		&session.DepartmentID,
+		&session.Name,
+		&session.Notes,
+		&session.Status,
+		&session.CreatedBy,
+		&session.FinalizedAt,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type competencyRatingRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewCompetencyRatingRepository(db *pgxpool.Pool, logger utils.Logger) domain.CompetencyRatingRepository {
+	return &competencyRatingRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *competencyRatingRepository) Save(rating *domain.CompetencyRating) error {
+	query := `
+		INSERT INTO tbl_pf_competency_ratings (
+			appraisal_id, competency_id, rating, comments, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (appraisal_id, competency_id) DO UPDATE SET
+			rating = EXCLUDED.rating, comments = EXCLUDED.comments, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		rating.AppraisalID,
+		rating.CompetencyID,
+		rating.Rating,
+		rating.Comments,
+		rating.CreatedAt,
+		rating.UpdatedAt,
+	).Scan(&rating.ID, &rating.CreatedAt)
+
+	if err != nil {
+		r.logger.Error("Failed to save competency rating", "error", err, "appraisal_id", rating.AppraisalID)
+		return fmt.Errorf("failed to save competency rating: %w", err)
+	}
+
+	r.logger.Info("Competency rating saved successfully",
+		"appraisal_id", rating.AppraisalID,
+		"competency_id", rating.CompetencyID)
+	return nil
+}
+
+func (r *competencyRatingRepository) GetByAppraisal(appraisalID int) ([]*domain.CompetencyRating, error) {
+	query := `
+		SELECT r.id, r.appraisal_id, r.competency_id, c.title, c.weight, r.rating,
+			r.comments, r.created_at, r.updated_at
+		FROM tbl_pf_competency_ratings r
+		JOIN tbl_pf_competencies c ON c.id = r.competency_id
+		WHERE r.appraisal_id = $1
+		ORDER BY c.title, r.competency_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, appraisalID)
+	if err != nil {
+		r.logger.Error("Failed to query competency ratings", "error", err, "appraisal_id", appraisalID)
+		return nil, fmt.Errorf("failed to query competency ratings: %w", err)
+	}
+	defer rows.Close()
+
+	var ratings []*domain.CompetencyRating
+	for rows.Next() {
+		rating := &domain.CompetencyRating{}
+		err := rows.Scan(
+			&rating.ID,
+			&rating.AppraisalID,
+			&rating.CompetencyID,
+			&rating.Title,
+			&rating.Weight,
+			&rating.Rating,
+			&rating.Comments,
+			&rating.CreatedAt,
+			&rating.UpdatedAt,
+		)
+		if err != nil {
+			r.logger.Error("Failed to scan competency rating row", "error", err)
+			return nil, fmt.Errorf("failed to scan competency rating: %w", err)
+		}
+		ratings = append(ratings, rating)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning competency rating rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return ratings, nil
+}
+
+func (r *competencyRatingRepository) Delete(appraisalID, competencyID int) error {
+	query := `DELETE FROM tbl_pf_competency_ratings WHERE appraisal_id = $1 AND competency_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tag, err := r.db.Exec(ctx, query, appraisalID, competencyID)
+	if err != nil {
+		r.logger.Error("Failed to delete competency rating", "error", err, "appraisal_id", appraisalID)
+		return fmt.Errorf("failed to delete competency rating: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("competency %d rating on appraisal %d: %w", competencyID, appraisalID, domain.ErrNotFound)
+	}
+
+	r.logger.Info("Competency rating deleted successfully", "appraisal_id", appraisalID, "competency_id", competencyID)
+	return nil
+}
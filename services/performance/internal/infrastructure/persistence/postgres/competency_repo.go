@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const competencyColumns = `
+	id, parent_id, title, description, competency_level, behavioral_indicators,
+	weight, is_active, created_at, updated_at`
+
+type competencyRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewCompetencyRepository(db *pgxpool.Pool, logger utils.Logger) domain.PerformanceCompetencyRepository {
+	return &competencyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *competencyRepository) Create(competency *domain.PerformanceCompetency) error {
+	query := `
+		INSERT INTO tbl_pf_competencies (
+			parent_id, title, description, competency_level, behavioral_indicators,
+			weight, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		competency.ParentID,
+		competency.Title,
+		competency.Description,
+		competency.CompetencyLevel,
+		competency.BehavioralIndicators,
+		competency.Weight,
+		competency.IsActive,
+		competency.CreatedAt,
+		competency.UpdatedAt,
+	).Scan(&competency.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to create competency", "error", err)
+		return fmt.Errorf("failed to create competency: %w", err)
+	}
+
+	r.logger.Info("Competency created successfully", "competency_id", competency.ID)
+	return nil
+}
+
+func (r *competencyRepository) GetByID(id int) (*domain.PerformanceCompetency, error) {
+	query := `SELECT ` + competencyColumns + ` FROM tbl_pf_competencies WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	competency, err := scanCompetency(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("competency %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get competency", "error", err, "competency_id", id)
+		return nil, fmt.Errorf("failed to get competency: %w", err)
+	}
+
+	return competency, nil
+}
+
+func (r *competencyRepository) GetAll(filter *domain.PerformanceCompetencyFilter) ([]*domain.PerformanceCompetency, error) {
+	query := `SELECT ` + competencyColumns + ` FROM tbl_pf_competencies WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.Level != "" {
+		query += fmt.Sprintf(" AND competency_level = $%d", argIndex)
+		args = append(args, filter.Level)
+		argIndex++
+	}
+
+	if filter.ActiveOnly {
+		query += " AND is_active = 1"
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY title, id LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query competencies", "error", err)
+		return nil, fmt.Errorf("failed to query competencies: %w", err)
+	}
+	defer rows.Close()
+
+	var competencies []*domain.PerformanceCompetency
+	for rows.Next() {
+		competency, err := scanCompetency(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan competency row", "error", err)
+			return nil, fmt.Errorf("failed to scan competency: %w", err)
+		}
+		competencies = append(competencies, competency)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning competency rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return competencies, nil
+}
+
+func (r *competencyRepository) Update(competency *domain.PerformanceCompetency) error {
+	query := `
+		UPDATE tbl_pf_competencies SET
+			parent_id = $2, title = $3, description = $4, competency_level = $5,
+			behavioral_indicators = $6, weight = $7, is_active = $8, updated_at = $9
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	competency.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		competency.ID,
+		competency.ParentID,
+		competency.Title,
+		competency.Description,
+		competency.CompetencyLevel,
+		competency.BehavioralIndicators,
+		competency.Weight,
+		competency.IsActive,
+		competency.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update competency", "error", err, "competency_id", competency.ID)
+		return fmt.Errorf("failed to update competency: %w", err)
+	}
+
+	r.logger.Info("Competency updated successfully", "competency_id", competency.ID)
+	return nil
+}
+
+func scanCompetency(row pgx.Row) (*domain.PerformanceCompetency, error) {
+	competency := &domain.PerformanceCompetency{}
+	err := row.Scan(
+		&competency.ID,
+		&competency.ParentID,
+		&competency.Title,
+		&competency.Description,
+		&competency.CompetencyLevel,
+		&competency.BehavioralIndicators,
+		&competency.Weight,
+		&competency.IsActive,
+		&competency.CreatedAt,
+		&competency.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return competency, nil
+}
@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const cycleColumns = `
+	id, name, description, start_date, end_date, evaluation_start_date,
+	evaluation_end_date, status, goal_weight, competency_weight, feedback_weight,
+	feedback_min_respondents, launched_at, created_by, created_at, updated_at`
+
+type cycleRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewCycleRepository(db *pgxpool.Pool, logger utils.Logger) domain.PerformanceCycleRepository {
+	return &cycleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *cycleRepository) Create(cycle *domain.PerformanceCycle) error {
+	query := `
+		INSERT INTO tbl_pf_cycles (
+			name, description, start_date, end_date, evaluation_start_date,
+			evaluation_end_date, status, goal_weight, competency_weight, feedback_weight,
+			feedback_min_respondents, launched_at, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		cycle.Name,
+		cycle.Description,
+		cycle.StartDate,
+		cycle.EndDate,
+		cycle.EvaluationStartDate,
+		cycle.EvaluationEndDate,
+		cycle.Status,
+		cycle.GoalWeight,
+		cycle.CompetencyWeight,
+		cycle.FeedbackWeight,
+		cycle.MinRespondents,
+		cycle.LaunchedAt,
+		cycle.CreatedBy,
+		cycle.CreatedAt,
+		cycle.UpdatedAt,
+	).Scan(&cycle.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to create performance cycle", "error", err)
+		return fmt.Errorf("failed to create performance cycle: %w", err)
+	}
+
+	r.logger.Info("Performance cycle created successfully", "cycle_id", cycle.ID)
+	return nil
+}
+
+func (r *cycleRepository) GetByID(id int) (*domain.PerformanceCycle, error) {
+	query := `SELECT ` + cycleColumns + ` FROM tbl_pf_cycles WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cycle, err := scanCycle(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("performance cycle %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get performance cycle", "error", err, "cycle_id", id)
+		return nil, fmt.Errorf("failed to get performance cycle: %w", err)
+	}
+
+	return cycle, nil
+}
+
+func (r *cycleRepository) GetAll(filter *domain.PerformanceCycleFilter) ([]*domain.PerformanceCycle, error) {
+	query := `SELECT ` + cycleColumns + ` FROM tbl_pf_cycles WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY start_date DESC, id DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	return r.query(query, args...)
+}
+
+func (r *cycleRepository) GetLaunched() ([]*domain.PerformanceCycle, error) {
+	query := `SELECT ` + cycleColumns + ` FROM tbl_pf_cycles
+		WHERE status IN ($1, $2) ORDER BY start_date, id`
+
+	return r.query(query, domain.CycleStatusActive, domain.CycleStatusEvaluation)
+}
+
+func (r *cycleRepository) Update(cycle *domain.PerformanceCycle) error {
+	query := `
+		UPDATE tbl_pf_cycles SET
+			name = $2, description = $3, start_date = $4, end_date = $5,
+			evaluation_start_date = $6, evaluation_end_date = $7, status = $8,
+			goal_weight = $9, competency_weight = $10, feedback_weight = $11,
+			feedback_min_respondents = $12, launched_at = $13, updated_at = $14
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cycle.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		cycle.ID,
+		cycle.Name,
+		cycle.Description,
+		cycle.StartDate,
+		cycle.EndDate,
+		cycle.EvaluationStartDate,
+		cycle.EvaluationEndDate,
+		cycle.Status,
+		cycle.GoalWeight,
+		cycle.CompetencyWeight,
+		cycle.FeedbackWeight,
+		cycle.MinRespondents,
+		cycle.LaunchedAt,
+		cycle.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update performance cycle", "error", err, "cycle_id", cycle.ID)
+		return fmt.Errorf("failed to update performance cycle: %w", err)
+	}
+
+	r.logger.Info("Performance cycle updated successfully", "cycle_id", cycle.ID, "status", cycle.Status)
+	return nil
+}
+
+// Delete removes a cycle outright; tbl_pf_cycles has no soft delete and only
+// draft cycles, which have no appraisals, are deleted
+func (r *cycleRepository) Delete(id int) error {
+	query := `DELETE FROM tbl_pf_cycles WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		r.logger.Error("Failed to delete performance cycle", "error", err, "cycle_id", id)
+		return fmt.Errorf("failed to delete performance cycle: %w", err)
+	}
+
+	r.logger.Info("Performance cycle deleted successfully", "cycle_id", id)
+	return nil
+}
+
+func (r *cycleRepository) query(query string, args ...interface{}) ([]*domain.PerformanceCycle, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query performance cycles", "error", err)
+		return nil, fmt.Errorf("failed to query performance cycles: %w", err)
+	}
+	defer rows.Close()
+
+	var cycles []*domain.PerformanceCycle
+	for rows.Next() {
+		cycle, err := scanCycle(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan performance cycle row", "error", err)
+			return nil, fmt.Errorf("failed to scan performance cycle: %w", err)
+		}
+		cycles = append(cycles, cycle)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning performance cycle rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return cycles, nil
+}
+
+func scanCycle(row pgx.Row) (*domain.PerformanceCycle, error) {
+	cycle := &domain.PerformanceCycle{}
+	err := row.Scan(
+		&cycle.ID,
+		&cycle.Name,
+		&cycle.Description,
+		&cycle.StartDate,
+		&cycle.EndDate,
+		&cycle.EvaluationStartDate,
+		&cycle.EvaluationEndDate,
+		&cycle.Status,
+		&cycle.GoalWeight,
+		&cycle.CompetencyWeight,
+		&cycle.FeedbackWeight,
+		&cycle.MinRespondents,
+		&cycle.LaunchedAt,
+		&cycle.CreatedBy,
+		&cycle.CreatedAt,
+		&cycle.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return cycle, nil
+}
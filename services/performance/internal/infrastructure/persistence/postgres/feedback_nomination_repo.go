@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const feedbackNominationColumns = `
+	id, appraisal_id, reviewer_type, reviewer_employee_id, reviewer_name,
+	reviewer_email, token_hash, status, nominated_by, responded_at, created_at,
+	updated_at`
+
+type feedbackNominationRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewFeedbackNominationRepository(db *pgxpool.Pool, logger utils.Logger) domain.FeedbackNominationRepository {
+	return &feedbackNominationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *feedbackNominationRepository) Create(nomination *domain.FeedbackNomination) error {
+	query := `
+		INSERT INTO tbl_pf_feedback_nominations (
+			appraisal_id, reviewer_type, reviewer_employee_id, reviewer_name,
+			reviewer_email, token_hash, status, nominated_by, responded_at, created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		nomination.AppraisalID,
+		nomination.ReviewerType,
+		nomination.ReviewerEmployeeID,
+		nomination.ReviewerName,
+		nomination.ReviewerEmail,
+		nomination.TokenHash,
+		nomination.Status,
+		nomination.NominatedBy,
+		nomination.RespondedAt,
+		nomination.CreatedAt,
+		nomination.UpdatedAt,
+	).Scan(&nomination.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to create feedback nomination", "error", err, "appraisal_id", nomination.AppraisalID)
+		return fmt.Errorf("failed to create feedback nomination: %w", err)
+	}
+
+	r.logger.Info("Feedback nomination created successfully", "nomination_id", nomination.ID, "appraisal_id", nomination.AppraisalID)
+	return nil
+}
+
+func (r *feedbackNominationRepository) GetByID(id int) (*domain.FeedbackNomination, error) {
+	query := `SELECT ` + feedbackNominationColumns + ` FROM tbl_pf_feedback_nominations WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	nomination, err := scanFeedbackNomination(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("feedback nomination %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get feedback nomination", "error", err, "nomination_id", id)
+		return nil, fmt.Errorf("failed to get feedback nomination: %w", err)
+	}
+
+	return nomination, nil
+}
+
+func (r *feedbackNominationRepository) GetByTokenHash(tokenHash string) (*domain.FeedbackNomination, error) {
+	query := `SELECT ` + feedbackNominationColumns + ` FROM tbl_pf_feedback_nominations WHERE token_hash = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	nomination, err := scanFeedbackNomination(r.db.QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("feedback request: %w", domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get feedback nomination by token", "error", err)
+		return nil, fmt.Errorf("failed to get feedback nomination: %w", err)
+	}
+
+	return nomination, nil
+}
+
+func (r *feedbackNominationRepository) GetByAppraisal(appraisalID int) ([]*domain.FeedbackNomination, error) {
+	query := `SELECT ` + feedbackNominationColumns + ` FROM tbl_pf_feedback_nominations
+		WHERE appraisal_id = $1 ORDER BY reviewer_type, id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, appraisalID)
+	if err != nil {
+		r.logger.Error("Failed to query feedback nominations", "error", err, "appraisal_id", appraisalID)
+		return nil, fmt.Errorf("failed to query feedback nominations: %w", err)
+	}
+	defer rows.Close()
+
+	var nominations []*domain.FeedbackNomination
+	for rows.Next() {
+		nomination, err := scanFeedbackNomination(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan feedback nomination row", "error", err)
+			return nil, fmt.Errorf("failed to scan feedback nomination: %w", err)
+		}
+		nominations = append(nominations, nomination)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning feedback nomination rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return nominations, nil
+}
+
+func (r *feedbackNominationRepository) Update(nomination *domain.FeedbackNomination) error {
+	query := `
+		UPDATE tbl_pf_feedback_nominations SET
+			status = $2, responded_at = $3, updated_at = $4
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	nomination.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		nomination.ID,
+		nomination.Status,
+		nomination.RespondedAt,
+		nomination.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update feedback nomination", "error", err, "nomination_id", nomination.ID)
+		return fmt.Errorf("failed to update feedback nomination: %w", err)
+	}
+
+	r.logger.Info("Feedback nomination updated successfully", "nomination_id", nomination.ID, "status", nomination.Status)
+	return nil
+}
+
+func (r *feedbackNominationRepository) Delete(id int) error {
+	query := `DELETE FROM tbl_pf_feedback_nominations WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		r.logger.Error("Failed to delete feedback nomination", "error", err, "nomination_id", id)
+		return fmt.Errorf("failed to delete feedback nomination: %w", err)
+	}
+
+	r.logger.Info("Feedback nomination deleted successfully", "nomination_id", id)
+	return nil
+}
+
+func scanFeedbackNomination(row pgx.Row) (*domain.FeedbackNomination, error) {
+	nomination := &domain.FeedbackNomination{}
+	err := row.Scan(
+		&nomination.ID,
+		&nomination.AppraisalID,
+		&nomination.ReviewerType,
+		&nomination.ReviewerEmployeeID,
+		&nomination.ReviewerName,
+		&nomination.ReviewerEmail,
+		&nomination.TokenHash,
+		&nomination.Status,
+		&nomination.NominatedBy,
+		&nomination.RespondedAt,
+		&nomination.CreatedAt,
+		&nomination.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return nomination, nil
+}
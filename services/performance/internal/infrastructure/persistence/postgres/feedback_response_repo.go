@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type feedbackResponseRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewFeedbackResponseRepository(db *pgxpool.Pool, logger utils.Logger) domain.FeedbackResponseRepository {
+	return &feedbackResponseRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *feedbackResponseRepository) Submit(nomination *domain.FeedbackNomination, responses []*domain.FeedbackResponse) error {
+	query := `
+		INSERT INTO tbl_pf_feedback_responses (nomination_id, competency_id, rating, comments, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// only a pending nomination can be answered, so a second submission
+	// racing the first finds nothing to update
+	tag, err := tx.Exec(ctx, `
+		UPDATE tbl_pf_feedback_nominations SET status = $2, responded_at = $3, updated_at = $3
+		WHERE id = $1 AND status = $4`,
+		nomination.ID, domain.NominationStatusSubmitted, nomination.RespondedAt, domain.NominationStatusPending)
+	if err != nil {
+		r.logger.Error("Failed to mark feedback nomination submitted", "error", err, "nomination_id", nomination.ID)
+		return fmt.Errorf("failed to submit feedback: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("feedback has already been given or declined")
+	}
+
+	for _, response := range responses {
+		err := tx.QueryRow(ctx, query,
+			response.NominationID,
+			response.CompetencyID,
+			response.Rating,
+			response.Comments,
+			response.CreatedAt,
+		).Scan(&response.ID)
+		if err != nil {
+			r.logger.Error("Failed to create feedback response", "error", err, "nomination_id", nomination.ID)
+			return fmt.Errorf("failed to submit feedback: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit feedback: %w", err)
+	}
+
+	nomination.Status = domain.NominationStatusSubmitted
+	r.logger.Info("Feedback submitted successfully", "appraisal_id", nomination.AppraisalID, "responses", len(responses))
+	return nil
+}
+
+func (r *feedbackResponseRepository) GetAnswers(appraisalID int) ([]domain.FeedbackAnswer, error) {
+	query := `
+		SELECT n.reviewer_type, fr.competency_id, fr.rating, fr.comments
+		FROM tbl_pf_feedback_responses fr
+		JOIN tbl_pf_feedback_nominations n ON n.id = fr.nomination_id
+		WHERE n.appraisal_id = $1 AND n.status = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, appraisalID, domain.NominationStatusSubmitted)
+	if err != nil {
+		r.logger.Error("Failed to query feedback responses", "error", err, "appraisal_id", appraisalID)
+		return nil, fmt.Errorf("failed to query feedback responses: %w", err)
+	}
+	defer rows.Close()
+
+	var answers []domain.FeedbackAnswer
+	for rows.Next() {
+		var answer domain.FeedbackAnswer
+		if err := rows.Scan(&answer.ReviewerType, &answer.CompetencyID, &answer.Rating, &answer.Comments); err != nil {
+			r.logger.Error("Failed to scan feedback response row", "error", err)
+			return nil, fmt.Errorf("failed to scan feedback response: %w", err)
+		}
+		answers = append(answers, answer)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning feedback response rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return answers, nil
+}
@@ -0,0 +1,250 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const goalColumns = `
+	id, employee_id, cycle_id, kpi_id, title, description, target_value,
+	baseline_value, current_value, weight, progress, due_date, start_date,
+	status, is_smart_goal, created_at, updated_at`
+
+type goalRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewGoalRepository(db *pgxpool.Pool, logger utils.Logger) domain.PerformanceGoalRepository {
+	return &goalRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *goalRepository) Create(goal *domain.PerformanceGoal) error {
+	query := `
+		INSERT INTO tbl_pf_goals (
+			employee_id, cycle_id, kpi_id, title, description, target_value,
+			baseline_value, current_value, weight, progress, due_date, start_date,
+			status, is_smart_goal, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		goal.EmployeeID,
+		goal.CycleID,
+		goal.KpiID,
+		goal.Title,
+		goal.Description,
+		goal.TargetValue,
+		goal.BaselineValue,
+		goal.CurrentValue,
+		goal.Weight,
+		goal.Progress,
+		goal.DueDate,
+		goal.StartDate,
+		goal.Status,
+		goal.IsSmartGoal,
+		goal.CreatedAt,
+		goal.UpdatedAt,
+	).Scan(&goal.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to create goal", "error", err)
+		return fmt.Errorf("failed to create goal: %w", err)
+	}
+
+	r.logger.Info("Goal created successfully", "goal_id", goal.ID)
+	return nil
+}
+
+func (r *goalRepository) GetByID(id int) (*domain.PerformanceGoal, error) {
+	query := `SELECT ` + goalColumns + ` FROM tbl_pf_goals WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	goal, err := scanGoal(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("goal %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get goal", "error", err, "goal_id", id)
+		return nil, fmt.Errorf("failed to get goal: %w", err)
+	}
+
+	return goal, nil
+}
+
+func (r *goalRepository) GetAll(filter *domain.PerformanceGoalFilter) ([]*domain.PerformanceGoal, error) {
+	query := `SELECT ` + goalColumns + ` FROM tbl_pf_goals WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(" AND employee_id = $%d", argIndex)
+		args = append(args, *filter.EmployeeID)
+		argIndex++
+	}
+
+	if filter.CycleID != nil {
+		query += fmt.Sprintf(" AND cycle_id = $%d", argIndex)
+		args = append(args, *filter.CycleID)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY due_date, id LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	return r.query(query, args...)
+}
+
+func (r *goalRepository) GetForAppraisal(employeeID, cycleID int) ([]*domain.PerformanceGoal, error) {
+	query := `SELECT ` + goalColumns + ` FROM tbl_pf_goals
+		WHERE employee_id = $1 AND cycle_id = $2 AND status <> $3
+		ORDER BY due_date, id`
+
+	return r.query(query, employeeID, cycleID, domain.GoalStatusCancelled)
+}
+
+func (r *goalRepository) GetByKPI(kpiID int, cycleID *int) ([]*domain.PerformanceGoal, error) {
+	query := `SELECT ` + goalColumns + ` FROM tbl_pf_goals WHERE kpi_id = $1 AND status <> $2`
+	args := []interface{}{kpiID, domain.GoalStatusCancelled}
+	if cycleID != nil {
+		query += " AND cycle_id = $3"
+		args = append(args, *cycleID)
+	}
+	query += " ORDER BY employee_id, id"
+
+	return r.query(query, args...)
+}
+
+func (r *goalRepository) Update(goal *domain.PerformanceGoal) error {
+	query := `
+		UPDATE tbl_pf_goals SET
+			cycle_id = $2, kpi_id = $3, title = $4, description = $5, target_value = $6,
+			baseline_value = $7, current_value = $8, weight = $9, progress = $10,
+			due_date = $11, start_date = $12, status = $13, is_smart_goal = $14,
+			updated_at = $15
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	goal.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		goal.ID,
+		goal.CycleID,
+		goal.KpiID,
+		goal.Title,
+		goal.Description,
+		goal.TargetValue,
+		goal.BaselineValue,
+		goal.CurrentValue,
+		goal.Weight,
+		goal.Progress,
+		goal.DueDate,
+		goal.StartDate,
+		goal.Status,
+		goal.IsSmartGoal,
+		goal.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update goal", "error", err, "goal_id", goal.ID)
+		return fmt.Errorf("failed to update goal: %w", err)
+	}
+
+	r.logger.Info("Goal updated successfully", "goal_id", goal.ID)
+	return nil
+}
+
+func (r *goalRepository) Delete(id int) error {
+	query := `DELETE FROM tbl_pf_goals WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		r.logger.Error("Failed to delete goal", "error", err, "goal_id", id)
+		return fmt.Errorf("failed to delete goal: %w", err)
+	}
+
+	r.logger.Info("Goal deleted successfully", "goal_id", id)
+	return nil
+}
+
+func (r *goalRepository) query(query string, args ...interface{}) ([]*domain.PerformanceGoal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query goals", "error", err)
+		return nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []*domain.PerformanceGoal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan goal row", "error", err)
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		goals = append(goals, goal)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning goal rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return goals, nil
+}
+
+func scanGoal(row pgx.Row) (*domain.PerformanceGoal, error) {
+	goal := &domain.PerformanceGoal{}
+	err := row.Scan(
+		&goal.ID,
+		&goal.EmployeeID,
+		&goal.CycleID,
+		&goal.KpiID,
+		&goal.Title,
+		&goal.Description,
+		&goal.TargetValue,
+		&goal.BaselineValue,
+		&goal.CurrentValue,
+		&goal.Weight,
+		&goal.Progress,
+		&goal.DueDate,
+		&goal.StartDate,
+		&goal.Status,
+		&goal.IsSmartGoal,
+		&goal.CreatedAt,
+		&goal.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const kpiMeasurementColumns = `m.id, m.goal_id, m.measured_on, m.value, m.notes, m.recorded_by, m.created_at`
+
+type kpiMeasurementRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewKPIMeasurementRepository(db *pgxpool.Pool, logger utils.Logger) domain.KPIMeasurementRepository {
+	return &kpiMeasurementRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *kpiMeasurementRepository) Create(measurement *domain.KPIMeasurement) error {
+	query := `
+		INSERT INTO tbl_pf_kpi_measurements (goal_id, measured_on, value, notes, recorded_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		measurement.GoalID,
+		measurement.MeasuredOn,
+		measurement.Value,
+		measurement.Notes,
+		measurement.RecordedBy,
+		measurement.CreatedAt,
+	).Scan(&measurement.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to create KPI measurement", "error", err, "goal_id", measurement.GoalID)
+		return fmt.Errorf("failed to create KPI measurement: %w", err)
+	}
+
+	r.logger.Info("KPI measurement created successfully", "measurement_id", measurement.ID, "goal_id", measurement.GoalID)
+	return nil
+}
+
+func (r *kpiMeasurementRepository) GetByID(id int) (*domain.KPIMeasurement, error) {
+	query := `SELECT ` + kpiMeasurementColumns + ` FROM tbl_pf_kpi_measurements m WHERE m.id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	measurement, err := scanKPIMeasurement(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("KPI measurement %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get KPI measurement", "error", err, "measurement_id", id)
+		return nil, fmt.Errorf("failed to get KPI measurement: %w", err)
+	}
+
+	return measurement, nil
+}
+
+func (r *kpiMeasurementRepository) GetByGoal(goalID int) ([]*domain.KPIMeasurement, error) {
+	query := `SELECT ` + kpiMeasurementColumns + ` FROM tbl_pf_kpi_measurements m
+		WHERE m.goal_id = $1 ORDER BY m.measured_on, m.id`
+
+	return r.query(query, goalID)
+}
+
+func (r *kpiMeasurementRepository) GetByKPI(kpiID int, cycleID *int) ([]*domain.KPIMeasurement, error) {
+	query := `SELECT ` + kpiMeasurementColumns + ` FROM tbl_pf_kpi_measurements m
+		JOIN tbl_pf_goals g ON g.id = m.goal_id
+		WHERE g.kpi_id = $1 AND g.status <> $2`
+	args := []interface{}{kpiID, domain.GoalStatusCancelled}
+	if cycleID != nil {
+		query += " AND g.cycle_id = $3"
+		args = append(args, *cycleID)
+	}
+	query += " ORDER BY m.measured_on, m.id"
+
+	return r.query(query, args...)
+}
+
+func (r *kpiMeasurementRepository) Delete(id int) error {
+	query := `DELETE FROM tbl_pf_kpi_measurements WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		r.logger.Error("Failed to delete KPI measurement", "error", err, "measurement_id", id)
+		return fmt.Errorf("failed to delete KPI measurement: %w", err)
+	}
+
+	r.logger.Info("KPI measurement deleted successfully", "measurement_id", id)
+	return nil
+}
+
+func (r *kpiMeasurementRepository) query(query string, args ...interface{}) ([]*domain.KPIMeasurement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query KPI measurements", "error", err)
+		return nil, fmt.Errorf("failed to query KPI measurements: %w", err)
+	}
+	defer rows.Close()
+
+	var measurements []*domain.KPIMeasurement
+	for rows.Next() {
+		measurement, err := scanKPIMeasurement(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan KPI measurement row", "error", err)
+			return nil, fmt.Errorf("failed to scan KPI measurement: %w", err)
+		}
+		measurements = append(measurements, measurement)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning KPI measurement rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return measurements, nil
+}
+
+func scanKPIMeasurement(row pgx.Row) (*domain.KPIMeasurement, error) {
+	measurement := &domain.KPIMeasurement{}
+	err := row.Scan(
+		&measurement.ID,
+		&measurement.GoalID,
+		&measurement.MeasuredOn,
+		&measurement.Value,
+		&measurement.Notes,
+		&measurement.RecordedBy,
+		&measurement.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return measurement, nil
+}
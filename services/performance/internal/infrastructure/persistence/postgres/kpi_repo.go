@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const kpiColumns = `
+	id, department_id, title, description, unit, data_type, aggregation,
+	target_calculation_formula, is_active, created_by, created_at, updated_at`
+
+type kpiRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewKPIRepository(db *pgxpool.Pool, logger utils.Logger) domain.PerformanceKPIRepository {
+	return &kpiRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *kpiRepository) Create(kpi *domain.PerformanceKPI) error {
+	query := `
+		INSERT INTO tbl_pf_kpis (
+			department_id, title, description, unit, data_type, aggregation,
+			target_calculation_formula, is_active, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		kpi.DepartmentID,
+		kpi.Title,
+		kpi.Description,
+		kpi.Unit,
+		kpi.DataType,
+		kpi.Aggregation,
+		kpi.TargetCalculationFormula,
+		kpi.IsActive,
+		kpi.CreatedBy,
+		kpi.CreatedAt,
+		kpi.UpdatedAt,
+	).Scan(&kpi.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to create KPI", "error", err)
+		return fmt.Errorf("failed to create KPI: %w", err)
+	}
+
+	r.logger.Info("KPI created successfully", "kpi_id", kpi.ID)
+	return nil
+}
+
+func (r *kpiRepository) GetByID(id int) (*domain.PerformanceKPI, error) {
+	query := `SELECT ` + kpiColumns + ` FROM tbl_pf_kpis WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	kpi, err := scanKPI(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("KPI %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get KPI", "error", err, "kpi_id", id)
+		return nil, fmt.Errorf("failed to get KPI: %w", err)
+	}
+
+	return kpi, nil
+}
+
+func (r *kpiRepository) GetAll(filter *domain.PerformanceKPIFilter) ([]*domain.PerformanceKPI, error) {
+	query := `SELECT ` + kpiColumns + ` FROM tbl_pf_kpis WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.DepartmentID != nil {
+		query += fmt.Sprintf(" AND department_id = $%d", argIndex)
+		args = append(args, *filter.DepartmentID)
+		argIndex++
+	}
+
+	if filter.ActiveOnly {
+		query += " AND is_active = 1"
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY title, id LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query KPIs", "error", err)
+		return nil, fmt.Errorf("failed to query KPIs: %w", err)
+	}
+	defer rows.Close()
+
+	var kpis []*domain.PerformanceKPI
+	for rows.Next() {
+		kpi, err := scanKPI(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan KPI row", "error", err)
+			return nil, fmt.Errorf("failed to scan KPI: %w", err)
+		}
+		kpis = append(kpis, kpi)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning KPI rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return kpis, nil
+}
+
+func (r *kpiRepository) Update(kpi *domain.PerformanceKPI) error {
+	query := `
+		UPDATE tbl_pf_kpis SET
+			department_id = $2, title = $3, description = $4, unit = $5, data_type = $6,
+			aggregation = $7, target_calculation_formula = $8, is_active = $9, updated_at = $10
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	kpi.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		kpi.ID,
+		kpi.DepartmentID,
+		kpi.Title,
+		kpi.Description,
+		kpi.Unit,
+		kpi.DataType,
+		kpi.Aggregation,
+		kpi.TargetCalculationFormula,
+		kpi.IsActive,
+		kpi.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update KPI", "error", err, "kpi_id", kpi.ID)
+		return fmt.Errorf("failed to update KPI: %w", err)
+	}
+
+	r.logger.Info("KPI updated successfully", "kpi_id", kpi.ID)
+	return nil
+}
+
+func scanKPI(row pgx.Row) (*domain.PerformanceKPI, error) {
+	kpi := &domain.PerformanceKPI{}
+	err := row.Scan(
+		&kpi.ID,
+		&kpi.DepartmentID,
+		&kpi.Title,
+		&kpi.Description,
+		&kpi.Unit,
+		&kpi.DataType,
+		&kpi.Aggregation,
+		&kpi.TargetCalculationFormula,
+		&kpi.IsActive,
+		&kpi.CreatedBy,
+		&kpi.CreatedAt,
+		&kpi.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return kpi, nil
+}
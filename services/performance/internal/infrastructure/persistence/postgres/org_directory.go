@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// orgDirectory reads the hierarchy from the current grades kept by the HR
+// monolith. An employee has left while an exit in tbl_employee_trash has not
+// been reversed.
+type orgDirectory struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewOrgDirectory(db *pgxpool.Pool, logger utils.Logger) domain.OrgDirectory {
+	return &orgDirectory{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *orgDirectory) Employees() ([]domain.OrgEmployee, error) {
+	query := `
+		SELECT DISTINCT ON (g.employee_id) g.employee_id, g.manager_id, g.department_id,
+			NOT EXISTS (
+				SELECT 1 FROM tbl_employee_trash t
+				WHERE t.employee_id = g.employee_id AND t.activated_date IS NULL AND t.deleted = 0
+			)
+		FROM tbl_employee_grades g
+		JOIN tbl_employees e ON e.id = g.employee_id AND e.deleted = 0
+		WHERE g.is_current = 1 AND g.deleted = 0
+		ORDER BY g.employee_id, g.start_date DESC NULLS LAST`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("Failed to query org hierarchy", "error", err)
+		return nil, fmt.Errorf("failed to query org hierarchy: %w", err)
+	}
+	defer rows.Close()
+
+	var employees []domain.OrgEmployee
+	for rows.Next() {
+		var employee domain.OrgEmployee
+		if err := rows.Scan(&employee.EmployeeID, &employee.ManagerID, &employee.DepartmentID, &employee.Active); err != nil {
+			r.logger.Error("Failed to scan org hierarchy row", "error", err)
+			return nil, fmt.Errorf("failed to scan org employee: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning org hierarchy rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return employees, nil
+}
+
+func (r *orgDirectory) EmployeeOfUser(userID int) (*int, error) {
+	query := `SELECT employee_id FROM tbl_users WHERE id = $1 AND deleted = 0`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var employeeID *int
+	err := r.db.QueryRow(ctx, query, userID).Scan(&employeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to get employee of user", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("failed to get employee of user: %w", err)
+	}
+	return employeeID, nil
+}
+
+func (r *orgDirectory) EmployeeEmail(employeeID int) (string, error) {
+	query := `SELECT COALESCE(email, '') FROM tbl_employees WHERE id = $1 AND deleted = 0`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var email string
+	err := r.db.QueryRow(ctx, query, employeeID).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("employee %d: %w", employeeID, domain.ErrNotFound)
+	}
+	if err != nil {
+		r.logger.Error("Failed to get employee email", "error", err, "employee_id", employeeID)
+		return "", fmt.Errorf("failed to get employee email: %w", err)
+	}
+	return email, nil
+}
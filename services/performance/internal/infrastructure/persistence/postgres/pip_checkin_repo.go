@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const pipCheckInColumns = `
+	id, pip_id, scheduled_date, held_date, notes, progress_rating, recorded_by,
+	created_at, updated_at`
+
+// pipCheckInInsert is shared with the PIP repository, which schedules a new
+// plan's check-ins in the same transaction
+const pipCheckInInsert = `
+	INSERT INTO tbl_pf_pip_checkins (
+		pip_id, scheduled_date, held_date, notes, progress_rating, recorded_by,
+		created_at, updated_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id`
+
+type pipCheckInRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewPIPCheckInRepository(db *pgxpool.Pool, logger utils.Logger) domain.PIPCheckInRepository {
+	return &pipCheckInRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *pipCheckInRepository) Create(checkIn *domain.PIPCheckIn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, pipCheckInInsert,
+		checkIn.PIPID,
+		checkIn.ScheduledDate,
+		checkIn.HeldDate,
+		checkIn.Notes,
+		checkIn.ProgressRating,
+		checkIn.RecordedBy,
+		checkIn.CreatedAt,
+		checkIn.UpdatedAt,
+	).Scan(&checkIn.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to create PIP check-in", "error", err, "pip_id", checkIn.PIPID)
+		return fmt.Errorf("failed to create PIP check-in: %w", err)
+	}
+
+	r.logger.Info("PIP check-in created successfully", "check_in_id", checkIn.ID, "pip_id", checkIn.PIPID)
+	return nil
+}
+
+func (r *pipCheckInRepository) GetByID(id int) (*domain.PIPCheckIn, error) {
+	query := `SELECT ` + pipCheckInColumns + ` FROM tbl_pf_pip_checkins WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	checkIn, err := scanPIPCheckIn(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("PIP check-in %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get PIP check-in", "error", err, "check_in_id", id)
+		return nil, fmt.Errorf("failed to get PIP check-in: %w", err)
+	}
+
+	return checkIn, nil
+}
+
+func (r *pipCheckInRepository) GetByPIP(pipID int) ([]*domain.PIPCheckIn, error) {
+	query := `SELECT ` + pipCheckInColumns + ` FROM tbl_pf_pip_checkins
+		WHERE pip_id = $1
+		ORDER BY scheduled_date, id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, pipID)
+	if err != nil {
+		r.logger.Error("Failed to query PIP check-ins", "error", err, "pip_id", pipID)
+		return nil, fmt.Errorf("failed to query PIP check-ins: %w", err)
+	}
+	defer rows.Close()
+
+	checkIns := []*domain.PIPCheckIn{}
+	for rows.Next() {
+		checkIn, err := scanPIPCheckIn(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan PIP check-in row", "error", err)
+			return nil, fmt.Errorf("failed to scan PIP check-in: %w", err)
+		}
+		checkIns = append(checkIns, checkIn)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning PIP check-in rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return checkIns, nil
+}
+
+func (r *pipCheckInRepository) Update(checkIn *domain.PIPCheckIn) error {
+	query := `
+		UPDATE tbl_pf_pip_checkins SET
+			scheduled_date = $2, held_date = $3, notes = $4, progress_rating = $5,
+			recorded_by = $6, updated_at = $7
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	checkIn.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		checkIn.ID,
+		checkIn.ScheduledDate,
+		checkIn.HeldDate,
+		checkIn.Notes,
+		checkIn.ProgressRating,
+		checkIn.RecordedBy,
+		checkIn.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update PIP check-in", "error", err, "check_in_id", checkIn.ID)
+		return fmt.Errorf("failed to update PIP check-in: %w", err)
+	}
+
+	r.logger.Info("PIP check-in updated successfully", "check_in_id", checkIn.ID)
+	return nil
+}
+
+func (r *pipCheckInRepository) Delete(id int) error {
+	query := `DELETE FROM tbl_pf_pip_checkins WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		r.logger.Error("Failed to delete PIP check-in", "error", err, "check_in_id", id)
+		return fmt.Errorf("failed to delete PIP check-in: %w", err)
+	}
+
+	r.logger.Info("PIP check-in deleted successfully", "check_in_id", id)
+	return nil
+}
+
+func scanPIPCheckIn(row pgx.Row) (*domain.PIPCheckIn, error) {
+	checkIn := &domain.PIPCheckIn{}
+	err := row.Scan(
+		&checkIn.ID,
+		&checkIn.PIPID,
+		&checkIn.ScheduledDate,
+		&checkIn.HeldDate,
+		&checkIn.Notes,
+		&checkIn.ProgressRating,
+		&checkIn.RecordedBy,
+		&checkIn.CreatedAt,
+		&checkIn.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return checkIn, nil
+}
@@ -0,0 +1,300 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const pipColumns = `
+	id, employee_id, appraisal_id, reason, objectives, success_criteria,
+	start_date, end_date, original_end_date, reminder_days, reminder_sent_at,
+	status, outcome, outcome_notes, outcome_date, created_by, created_at,
+	updated_at`
+
+type pipRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewPIPRepository(db *pgxpool.Pool, logger utils.Logger) domain.PerformancePIPRepository {
+	return &pipRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *pipRepository) Create(pip *domain.PerformancePIP, checkIns []*domain.PIPCheckIn) error {
+	query := `
+		INSERT INTO tbl_pf_pips (
+			employee_id, appraisal_id, reason, objectives, success_criteria,
+			start_date, end_date, original_end_date, reminder_days, reminder_sent_at,
+			status, outcome, outcome_notes, outcome_date, created_by, created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
+		pip.EmployeeID,
+		pip.AppraisalID,
+		pip.Reason,
+		pip.Objectives,
+		pip.SuccessCriteria,
+		pip.StartDate,
+		pip.EndDate,
+		pip.OriginalEndDate,
+		pip.ReminderDays,
+		pip.ReminderSentAt,
+		pip.Status,
+		pip.Outcome,
+		pip.OutcomeNotes,
+		pip.OutcomeDate,
+		pip.CreatedBy,
+		pip.CreatedAt,
+		pip.UpdatedAt,
+	).Scan(&pip.ID)
+	if err != nil {
+		r.logger.Error("Failed to create PIP", "error", err, "employee_id", pip.EmployeeID)
+		return fmt.Errorf("failed to create PIP: %w", err)
+	}
+
+	for _, checkIn := range checkIns {
+		checkIn.PIPID = pip.ID
+		err := tx.QueryRow(ctx, pipCheckInInsert,
+			checkIn.PIPID,
+			checkIn.ScheduledDate,
+			checkIn.HeldDate,
+			checkIn.Notes,
+			checkIn.ProgressRating,
+			checkIn.RecordedBy,
+			checkIn.CreatedAt,
+			checkIn.UpdatedAt,
+		).Scan(&checkIn.ID)
+		if err != nil {
+			r.logger.Error("Failed to schedule PIP check-in", "error", err, "pip_id", pip.ID)
+			return fmt.Errorf("failed to schedule PIP check-in: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit PIP: %w", err)
+	}
+
+	r.logger.Info("PIP created successfully", "pip_id", pip.ID, "check_ins", len(checkIns))
+	return nil
+}
+
+func (r *pipRepository) GetByID(id int) (*domain.PerformancePIP, error) {
+	query := `SELECT ` + pipColumns + ` FROM tbl_pf_pips WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pip, err := scanPIP(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("PIP %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get PIP", "error", err, "pip_id", id)
+		return nil, fmt.Errorf("failed to get PIP: %w", err)
+	}
+
+	return pip, nil
+}
+
+func (r *pipRepository) GetAll(filter *domain.PerformancePIPFilter) ([]*domain.PerformancePIP, error) {
+	query := `SELECT ` + pipColumns + ` FROM tbl_pf_pips WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(" AND employee_id = $%d", argIndex)
+		args = append(args, *filter.EmployeeID)
+		argIndex++
+	}
+
+	if filter.AppraisalID != nil {
+		query += fmt.Sprintf(" AND appraisal_id = $%d", argIndex)
+		args = append(args, *filter.AppraisalID)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY end_date DESC, id DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	return r.query(query, args...)
+}
+
+func (r *pipRepository) GetActive() ([]*domain.PerformancePIP, error) {
+	query := `SELECT ` + pipColumns + ` FROM tbl_pf_pips WHERE status = $1 ORDER BY end_date, id`
+
+	return r.query(query, domain.PIPStatusActive)
+}
+
+func (r *pipRepository) Update(pip *domain.PerformancePIP) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pip.UpdatedAt = time.Now()
+	if _, err := r.db.Exec(ctx, pipUpdate, pipUpdateArgs(pip)...); err != nil {
+		r.logger.Error("Failed to update PIP", "error", err, "pip_id", pip.ID)
+		return fmt.Errorf("failed to update PIP: %w", err)
+	}
+
+	r.logger.Info("PIP updated successfully", "pip_id", pip.ID)
+	return nil
+}
+
+func (r *pipRepository) RecordOutcome(pip *domain.PerformancePIP, exit *domain.EmployeeExit) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	pip.UpdatedAt = time.Now()
+	if _, err := tx.Exec(ctx, pipUpdate, pipUpdateArgs(pip)...); err != nil {
+		r.logger.Error("Failed to record PIP outcome", "error", err, "pip_id", pip.ID)
+		return fmt.Errorf("failed to record PIP outcome: %w", err)
+	}
+
+	if exit != nil {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO tbl_employee_trash (
+				employee_id, action, action_reason, action_date, created_by,
+				created_at, updated_at, deleted
+			) VALUES ($1, $2, $3, $4, $5, $6, $6, 0)`,
+			exit.EmployeeID,
+			exit.Action,
+			exit.Reason,
+			exit.Date,
+			exit.CreatedBy,
+			pip.UpdatedAt,
+		)
+		if err != nil {
+			r.logger.Error("Failed to record employee exit", "error", err, "employee_id", exit.EmployeeID)
+			return fmt.Errorf("failed to record employee exit: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit PIP outcome: %w", err)
+	}
+
+	r.logger.Info("PIP outcome recorded successfully",
+		"pip_id", pip.ID,
+		"outcome", pip.Outcome,
+		"exit_recorded", exit != nil)
+	return nil
+}
+
+const pipUpdate = `
+	UPDATE tbl_pf_pips SET
+		appraisal_id = $2, reason = $3, objectives = $4, success_criteria = $5,
+		start_date = $6, end_date = $7, original_end_date = $8, reminder_days = $9,
+		reminder_sent_at = $10, status = $11, outcome = $12, outcome_notes = $13,
+		outcome_date = $14, updated_at = $15
+	WHERE id = $1`
+
+func pipUpdateArgs(pip *domain.PerformancePIP) []interface{} {
+	return []interface{}{
+		pip.ID,
+		pip.AppraisalID,
+		pip.Reason,
+		pip.Objectives,
+		pip.SuccessCriteria,
+		pip.StartDate,
+		pip.EndDate,
+		pip.OriginalEndDate,
+		pip.ReminderDays,
+		pip.ReminderSentAt,
+		pip.Status,
+		pip.Outcome,
+		pip.OutcomeNotes,
+		pip.OutcomeDate,
+		pip.UpdatedAt,
+	}
+}
+
+func (r *pipRepository) query(query string, args ...interface{}) ([]*domain.PerformancePIP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query PIPs", "error", err)
+		return nil, fmt.Errorf("failed to query PIPs: %w", err)
+	}
+	defer rows.Close()
+
+	var pips []*domain.PerformancePIP
+	for rows.Next() {
+		pip, err := scanPIP(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan PIP row", "error", err)
+			return nil, fmt.Errorf("failed to scan PIP: %w", err)
+		}
+		pips = append(pips, pip)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning PIP rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return pips, nil
+}
+
+func scanPIP(row pgx.Row) (*domain.PerformancePIP, error) {
+	pip := &domain.PerformancePIP{}
+	err := row.Scan(
+		&pip.ID,
+		&pip.EmployeeID,
+		&pip.AppraisalID,
+		&pip.Reason,
+		&pip.Objectives,
+		&pip.SuccessCriteria,
+		&pip.StartDate,
+		&pip.EndDate,
+		&pip.OriginalEndDate,
+		&pip.ReminderDays,
+		&pip.ReminderSentAt,
+		&pip.Status,
+		&pip.Outcome,
+		&pip.OutcomeNotes,
+		&pip.OutcomeDate,
+		&pip.CreatedBy,
+		&pip.CreatedAt,
+		&pip.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pip, nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ratingScaleRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewRatingScaleRepository(db *pgxpool.Pool, logger utils.Logger) domain.RatingScaleRepository {
+	return &ratingScaleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ratingScaleRepository) GetAll() ([]domain.RatingBand, error) {
+	query := `SELECT id, min_score, rating, description, created_at, updated_at
+		FROM tbl_pf_rating_scales ORDER BY min_score`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("Failed to query rating scale", "error", err)
+		return nil, fmt.Errorf("failed to query rating scale: %w", err)
+	}
+	defer rows.Close()
+
+	scale := []domain.RatingBand{}
+	for rows.Next() {
+		var band domain.RatingBand
+		if err := rows.Scan(&band.ID, &band.MinScore, &band.Rating, &band.Description, &band.CreatedAt, &band.UpdatedAt); err != nil {
+			r.logger.Error("Failed to scan rating band row", "error", err)
+			return nil, fmt.Errorf("failed to scan rating band: %w", err)
+		}
+		scale = append(scale, band)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning rating band rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return scale, nil
+}
+
+func (r *ratingScaleRepository) Replace(scale []domain.RatingBand) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM tbl_pf_rating_scales`); err != nil {
+		r.logger.Error("Failed to clear rating scale", "error", err)
+		return fmt.Errorf("failed to clear rating scale: %w", err)
+	}
+
+	query := `
+		INSERT INTO tbl_pf_rating_scales (min_score, rating, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+	for i := range scale {
+		band := &scale[i]
+		if err := tx.QueryRow(ctx, query,
+			band.MinScore,
+			band.Rating,
+			band.Description,
+			band.CreatedAt,
+			band.UpdatedAt,
+		).Scan(&band.ID); err != nil {
+			r.logger.Error("Failed to create rating band", "error", err)
+			return fmt.Errorf("failed to create rating band: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit rating scale: %w", err)
+	}
+
+	r.logger.Info("Rating scale replaced successfully", "bands", len(scale))
+	return nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type trainingBudgetRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewTrainingBudgetRepository(db *pgxpool.Pool, logger utils.Logger) domain.TrainingBudgetRepository {
+	return &trainingBudgetRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *trainingBudgetRepository) Save(budget *domain.TrainingBudget) error {
+	query := `
+		INSERT INTO tbl_pf_training_budgets (
+			department_id, year, amount, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (department_id, year) DO UPDATE SET
+			amount = EXCLUDED.amount, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		budget.DepartmentID,
+		budget.Year,
+		budget.Amount,
+		budget.CreatedAt,
+		budget.UpdatedAt,
+	).Scan(&budget.ID, &budget.CreatedAt)
+
+	if err != nil {
+		r.logger.Error("Failed to save training budget", "error", err, "department_id", budget.DepartmentID)
+		return fmt.Errorf("failed to save training budget: %w", err)
+	}
+
+	r.logger.Info("Training budget saved successfully",
+		"department_id", budget.DepartmentID,
+		"year", budget.Year)
+	return nil
+}
+
+func (r *trainingBudgetRepository) GetByYear(year int) ([]*domain.TrainingBudget, error) {
+	query := `
+		SELECT id, department_id, year, amount, created_at, updated_at
+		FROM tbl_pf_training_budgets
+		WHERE year = $1
+		ORDER BY department_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, year)
+	if err != nil {
+		r.logger.Error("Failed to query training budgets", "error", err, "year", year)
+		return nil, fmt.Errorf("failed to query training budgets: %w", err)
+	}
+	defer rows.Close()
+
+	budgets := []*domain.TrainingBudget{}
+	for rows.Next() {
+		budget := &domain.TrainingBudget{}
+		err := rows.Scan(
+			&budget.ID,
+			&budget.DepartmentID,
+			&budget.Year,
+			&budget.Amount,
+			&budget.CreatedAt,
+			&budget.UpdatedAt,
+		)
+		if err != nil {
+			r.logger.Error("Failed to scan training budget row", "error", err)
+			return nil, fmt.Errorf("failed to scan training budget: %w", err)
+		}
+		budgets = append(budgets, budget)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning training budget rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return budgets, nil
+}
@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const trainingEnrolmentColumns = `
+	e.id, e.training_id, e.employee_id, e.department_id, e.status, e.cost, e.hours,
+	e.completed_at, e.enrolled_by, e.created_at, e.updated_at`
+
+type trainingEnrolmentRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewTrainingEnrolmentRepository(db *pgxpool.Pool, logger utils.Logger) domain.TrainingEnrolmentRepository {
+	return &trainingEnrolmentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *trainingEnrolmentRepository) Create(enrolment *domain.TrainingEnrolment) error {
+	query := `
+		INSERT INTO tbl_pf_training_enrolments (
+			training_id, employee_id, department_id, status, cost, hours, completed_at,
+			enrolled_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		enrolment.TrainingID,
+		enrolment.EmployeeID,
+		enrolment.DepartmentID,
+		enrolment.Status,
+		enrolment.Cost,
+		enrolment.Hours,
+		enrolment.CompletedAt,
+		enrolment.EnrolledBy,
+		enrolment.CreatedAt,
+		enrolment.UpdatedAt,
+	).Scan(&enrolment.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to create training enrolment", "error", err, "training_id", enrolment.TrainingID)
+		return fmt.Errorf("failed to create training enrolment: %w", err)
+	}
+
+	r.logger.Info("Training enrolment created successfully",
+		"enrolment_id", enrolment.ID,
+		"training_id", enrolment.TrainingID,
+		"employee_id", enrolment.EmployeeID)
+	return nil
+}
+
+func (r *trainingEnrolmentRepository) GetByID(id int) (*domain.TrainingEnrolment, error) {
+	query := `SELECT ` + trainingEnrolmentColumns + ` FROM tbl_pf_training_enrolments e WHERE e.id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	enrolment, err := scanTrainingEnrolment(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("training enrolment %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get training enrolment", "error", err, "enrolment_id", id)
+		return nil, fmt.Errorf("failed to get training enrolment: %w", err)
+	}
+
+	return enrolment, nil
+}
+
+func (r *trainingEnrolmentRepository) GetByTraining(trainingID int) ([]*domain.TrainingEnrolment, error) {
+	query := `SELECT ` + trainingEnrolmentColumns + ` FROM tbl_pf_training_enrolments e
+		WHERE e.training_id = $1
+		ORDER BY e.employee_id, e.id`
+
+	return r.query(query, trainingID)
+}
+
+func (r *trainingEnrolmentRepository) GetByYear(year int) ([]*domain.TrainingEnrolment, error) {
+	query := `SELECT ` + trainingEnrolmentColumns + ` FROM tbl_pf_training_enrolments e
+		JOIN tbl_pf_trainings t ON t.id = e.training_id
+		WHERE EXTRACT(YEAR FROM COALESCE(t.start_date, e.created_at::date)) = $1
+		ORDER BY e.employee_id, e.id`
+
+	return r.query(query, year)
+}
+
+func (r *trainingEnrolmentRepository) Update(enrolment *domain.TrainingEnrolment) error {
+	query := `
+		UPDATE tbl_pf_training_enrolments SET
+			status = $2, cost = $3, hours = $4, completed_at = $5, updated_at = $6
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	enrolment.UpdatedAt = time.Now()
+	_, err := r.db.Exec(ctx, query,
+		enrolment.ID,
+		enrolment.Status,
+		enrolment.Cost,
+		enrolment.Hours,
+		enrolment.CompletedAt,
+		enrolment.UpdatedAt,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to update training enrolment", "error", err, "enrolment_id", enrolment.ID)
+		return fmt.Errorf("failed to update training enrolment: %w", err)
+	}
+
+	r.logger.Info("Training enrolment updated successfully", "enrolment_id", enrolment.ID, "status", enrolment.Status)
+	return nil
+}
+
+func (r *trainingEnrolmentRepository) query(query string, args ...interface{}) ([]*domain.TrainingEnrolment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query training enrolments", "error", err)
+		return nil, fmt.Errorf("failed to query training enrolments: %w", err)
+	}
+	defer rows.Close()
+
+	enrolments := []*domain.TrainingEnrolment{}
+	for rows.Next() {
+		enrolment, err := scanTrainingEnrolment(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan training enrolment row", "error", err)
+			return nil, fmt.Errorf("failed to scan training enrolment: %w", err)
+		}
+		enrolments = append(enrolments, enrolment)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning training enrolment rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return enrolments, nil
+}
+
+func scanTrainingEnrolment(row pgx.Row) (*domain.TrainingEnrolment, error) {
+	enrolment := &domain.TrainingEnrolment{}
+	err := row.Scan(
+		&enrolment.ID,
+		&enrolment.TrainingID,
+		&enrolment.EmployeeID,
+		&enrolment.DepartmentID,
+		&enrolment.Status,
+		&enrolment.Cost,
+		&enrolment.Hours,
+		&enrolment.CompletedAt,
+		&enrolment.EnrolledBy,
+		&enrolment.CreatedAt,
+		&enrolment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return enrolment, nil
+}
@@ -0,0 +1,319 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"yathuerp/services/performance/internal/domain"
+	"yathuerp/shared/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const trainingColumns = `
+	id, title, description, training_type, assigned_to_employee_id, linked_goal_id,
+	linked_competency_id, status, start_date, end_date, completed, completion_date,
+	cost, hours, provider, created_at, updated_at`
+
+type trainingRepository struct {
+	db     *pgxpool.Pool
+	logger utils.Logger
+}
+
+func NewTrainingRepository(db *pgxpool.Pool, logger utils.Logger) domain.PerformanceTrainingRepository {
+	return &trainingRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *trainingRepository) Create(training *domain.PerformanceTraining) error {
+	query := `
+		INSERT INTO tbl_pf_trainings (
+			title, description, training_type, assigned_to_employee_id, linked_goal_id,
+			linked_competency_id, status, start_date, end_date, completed, completion_date,
+			cost, hours, provider, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.db.QueryRow(ctx, query,
+		training.Title,
+		training.Description,
+		training.TrainingType,
+		training.AssignedToEmployeeID,
+		training.LinkedGoalID,
+		training.LinkedCompetencyID,
+		training.Status,
+		training.StartDate,
+		training.EndDate,
+		training.Completed,
+		training.CompletionDate,
+		training.Cost,
+		training.Hours,
+		training.Provider,
+		training.CreatedAt,
+		training.UpdatedAt,
+	).Scan(&training.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to create training", "error", err)
+		return fmt.Errorf("failed to create training: %w", err)
+	}
+
+	r.logger.Info("Training created successfully", "training_id", training.ID)
+	return nil
+}
+
+func (r *trainingRepository) GetByID(id int) (*domain.PerformanceTraining, error) {
+	query := `SELECT ` + trainingColumns + ` FROM tbl_pf_trainings WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	training, err := scanTraining(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("training %d: %w", id, domain.ErrNotFound)
+		}
+		r.logger.Error("Failed to get training", "error", err, "training_id", id)
+		return nil, fmt.Errorf("failed to get training: %w", err)
+	}
+
+	return training, nil
+}
+
+func (r *trainingRepository) GetAll(filter *domain.PerformanceTrainingFilter) ([]*domain.PerformanceTraining, error) {
+	query := `SELECT ` + trainingColumns + ` FROM tbl_pf_trainings t WHERE 1 = 1`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(` AND (t.assigned_to_employee_id = $%d OR EXISTS (
+			SELECT 1 FROM tbl_pf_training_enrolments e
+			WHERE e.training_id = t.id AND e.employee_id = $%d
+		))`, argIndex, argIndex)
+		args = append(args, *filter.EmployeeID)
+		argIndex++
+	}
+
+	if filter.CompetencyID != nil {
+		query += fmt.Sprintf(" AND t.linked_competency_id = $%d", argIndex)
+		args = append(args, *filter.CompetencyID)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND t.status = $%d", argIndex)
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	// Add pagination
+	query += fmt.Sprintf(" ORDER BY t.start_date DESC NULLS LAST, t.id DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query trainings", "error", err)
+		return nil, fmt.Errorf("failed to query trainings: %w", err)
+	}
+	defer rows.Close()
+
+	var trainings []*domain.PerformanceTraining
+	for rows.Next() {
+		training, err := scanTraining(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan training row", "error", err)
+			return nil, fmt.Errorf("failed to scan training: %w", err)
+		}
+		trainings = append(trainings, training)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning training rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return trainings, nil
+}
+
+func (r *trainingRepository) Update(training *domain.PerformanceTraining) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	training.UpdatedAt = time.Now()
+	if _, err := r.db.Exec(ctx, trainingUpdate, trainingUpdateArgs(training)...); err != nil {
+		r.logger.Error("Failed to update training", "error", err, "training_id", training.ID)
+		return fmt.Errorf("failed to update training: %w", err)
+	}
+
+	r.logger.Info("Training updated successfully", "training_id", training.ID)
+	return nil
+}
+
+func (r *trainingRepository) Close(training *domain.PerformanceTraining) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	training.UpdatedAt = time.Now()
+	if _, err := tx.Exec(ctx, trainingUpdate, trainingUpdateArgs(training)...); err != nil {
+		r.logger.Error("Failed to close training", "error", err, "training_id", training.ID)
+		return fmt.Errorf("failed to close training: %w", err)
+	}
+
+	// completed participants are credited with the training's hours
+	query := `
+		UPDATE tbl_pf_training_enrolments SET status = $2, updated_at = $3
+		WHERE training_id = $1 AND status = $4`
+	args := []interface{}{training.ID, domain.EnrolmentStatusCancelled, training.UpdatedAt, domain.EnrolmentStatusEnrolled}
+	if training.Status == domain.TrainingStatusCompleted {
+		query = `
+			UPDATE tbl_pf_training_enrolments SET
+				status = $2, updated_at = $3, hours = COALESCE($5, 0), completed_at = $6
+			WHERE training_id = $1 AND status = $4`
+		args = []interface{}{
+			training.ID, domain.EnrolmentStatusCompleted, training.UpdatedAt, domain.EnrolmentStatusEnrolled,
+			training.Hours, training.CompletionDate,
+		}
+	}
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to settle training enrolments", "error", err, "training_id", training.ID)
+		return fmt.Errorf("failed to settle training enrolments: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit training: %w", err)
+	}
+
+	r.logger.Info("Training closed successfully",
+		"training_id", training.ID,
+		"status", training.Status,
+		"enrolments", tag.RowsAffected())
+	return nil
+}
+
+func (r *trainingRepository) GetNeeds(cycleID, maxRating int) ([]*domain.TrainingNeed, error) {
+	query := `
+		SELECT a.employee_id, a.id, a.cycle_id, r.competency_id, c.title, r.rating,
+			ARRAY(
+				SELECT DISTINCT t.id FROM tbl_pf_trainings t
+				JOIN tbl_pf_training_enrolments e ON e.training_id = t.id
+				WHERE t.linked_competency_id = r.competency_id AND e.employee_id = a.employee_id
+					AND t.status <> $3 AND e.status <> $4
+				ORDER BY t.id
+			)
+		FROM tbl_pf_competency_ratings r
+		JOIN tbl_pf_appraisals a ON a.id = r.appraisal_id
+		JOIN tbl_pf_competencies c ON c.id = r.competency_id
+		WHERE a.cycle_id = $1 AND r.rating <= $2
+		ORDER BY r.rating, a.employee_id, c.title`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, query, cycleID, maxRating, domain.TrainingStatusCancelled, domain.EnrolmentStatusCancelled)
+	if err != nil {
+		r.logger.Error("Failed to query training needs", "error", err, "cycle_id", cycleID)
+		return nil, fmt.Errorf("failed to query training needs: %w", err)
+	}
+	defer rows.Close()
+
+	needs := []*domain.TrainingNeed{}
+	for rows.Next() {
+		need := &domain.TrainingNeed{}
+		err := rows.Scan(
+			&need.EmployeeID,
+			&need.AppraisalID,
+			&need.CycleID,
+			&need.CompetencyID,
+			&need.Competency,
+			&need.Rating,
+			&need.TrainingIDs,
+		)
+		if err != nil {
+			r.logger.Error("Failed to scan training need row", "error", err)
+			return nil, fmt.Errorf("failed to scan training need: %w", err)
+		}
+		need.Covered = len(need.TrainingIDs) > 0
+		needs = append(needs, need)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error after scanning training need rows", "error", err)
+		return nil, fmt.Errorf("error after scanning rows: %w", err)
+	}
+
+	return needs, nil
+}
+
+const trainingUpdate = `
+	UPDATE tbl_pf_trainings SET
+		title = $2, description = $3, training_type = $4, assigned_to_employee_id = $5,
+		linked_goal_id = $6, linked_competency_id = $7, status = $8, start_date = $9,
+		end_date = $10, completed = $11, completion_date = $12, cost = $13, hours = $14,
+		provider = $15, updated_at = $16
+	WHERE id = $1`
+
+func trainingUpdateArgs(training *domain.PerformanceTraining) []interface{} {
+	return []interface{}{
+		training.ID,
+		training.Title,
+		training.Description,
+		training.TrainingType,
+		training.AssignedToEmployeeID,
+		training.LinkedGoalID,
+		training.LinkedCompetencyID,
+		training.Status,
+		training.StartDate,
+		training.EndDate,
+		training.Completed,
+		training.CompletionDate,
+		training.Cost,
+		training.Hours,
+		training.Provider,
+		training.UpdatedAt,
+	}
+}
+
+func scanTraining(row pgx.Row) (*domain.PerformanceTraining, error) {
+	training := &domain.PerformanceTraining{}
+	err := row.Scan(
+		&training.ID,
+		&training.Title,
+		&training.Description,
+		&training.TrainingType,
+		&training.AssignedToEmployeeID,
+		&training.LinkedGoalID,
+		&training.LinkedCompetencyID,
+		&training.Status,
+		&training.StartDate,
+		&training.EndDate,
+		&training.Completed,
+		&training.CompletionDate,
+		&training.Cost,
+		&training.Hours,
+		&training.Provider,
+		&training.CreatedAt,
+		&training.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return training, nil
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_pf_appraisals_manager;
+DROP INDEX IF EXISTS idx_pf_appraisals_cycle_employee;
+DROP INDEX IF EXISTS idx_pf_cycles_status;
+ALTER TABLE tbl_pf_cycles DROP COLUMN IF EXISTS launched_at;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS tbl_pf_cycles (
+    id                    SERIAL PRIMARY KEY,
+    name                  VARCHAR(255) NOT NULL,
+    description           TEXT NOT NULL DEFAULT '',
+    start_date            DATE NOT NULL,
+    end_date              DATE NOT NULL,
+    evaluation_start_date DATE,
+    evaluation_end_date   DATE NOT NULL,
+    status                VARCHAR(20) NOT NULL DEFAULT 'draft',
+    created_by            INTEGER,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE tbl_pf_cycles ADD COLUMN IF NOT EXISTS launched_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_pf_cycles_status ON tbl_pf_cycles (status);
+
+CREATE TABLE IF NOT EXISTS tbl_pf_appraisals (
+    id                 SERIAL PRIMARY KEY,
+    cycle_id           INTEGER NOT NULL REFERENCES tbl_pf_cycles (id),
+    employee_id        INTEGER NOT NULL,
+    manager_id         INTEGER NOT NULL,
+    status             VARCHAR(20) NOT NULL DEFAULT 'draft',
+    overall_score      NUMERIC(6, 2),
+    overall_rating     VARCHAR(50) NOT NULL DEFAULT '',
+    employee_comments  TEXT NOT NULL DEFAULT '',
+    manager_comments   TEXT NOT NULL DEFAULT '',
+    employee_signed_at TIMESTAMPTZ,
+    manager_signed_at  TIMESTAMPTZ,
+    discussion_date    DATE,
+    next_period_goals  TEXT NOT NULL DEFAULT '',
+    development_plan   TEXT NOT NULL DEFAULT '',
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pf_appraisals_cycle_employee ON tbl_pf_appraisals (cycle_id, employee_id);
+CREATE INDEX IF NOT EXISTS idx_pf_appraisals_manager ON tbl_pf_appraisals (manager_id, cycle_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS tbl_pf_rating_scales;
+DROP TABLE IF EXISTS tbl_pf_competency_ratings;
+DROP INDEX IF EXISTS idx_pf_goals_employee_cycle;
+ALTER TABLE tbl_pf_cycles DROP COLUMN IF EXISTS competency_weight;
+ALTER TABLE tbl_pf_cycles DROP COLUMN IF EXISTS goal_weight;
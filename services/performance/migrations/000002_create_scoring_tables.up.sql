@@ -0,0 +1,71 @@
+ALTER TABLE tbl_pf_cycles ADD COLUMN IF NOT EXISTS goal_weight NUMERIC(5, 2) NOT NULL DEFAULT 70.00;
+ALTER TABLE tbl_pf_cycles ADD COLUMN IF NOT EXISTS competency_weight NUMERIC(5, 2) NOT NULL DEFAULT 30.00;
+
+CREATE TABLE IF NOT EXISTS tbl_pf_goals (
+    id             SERIAL PRIMARY KEY,
+    employee_id    INTEGER NOT NULL,
+    cycle_id       INTEGER REFERENCES tbl_pf_cycles (id),
+    kpi_id         INTEGER,
+    title          VARCHAR(255) NOT NULL,
+    description    TEXT NOT NULL DEFAULT '',
+    target_value   NUMERIC(15, 2),
+    baseline_value NUMERIC(15, 2),
+    current_value  NUMERIC(15, 2),
+    weight         NUMERIC(5, 2) NOT NULL DEFAULT 0.00,
+    progress       NUMERIC(5, 2) NOT NULL DEFAULT 0.00,
+    due_date       DATE NOT NULL,
+    start_date     DATE,
+    status         VARCHAR(20) NOT NULL DEFAULT 'active',
+    is_smart_goal  SMALLINT NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pf_goals_employee_cycle ON tbl_pf_goals (employee_id, cycle_id);
+
+CREATE TABLE IF NOT EXISTS tbl_pf_competencies (
+    id                    SERIAL PRIMARY KEY,
+    parent_id             INTEGER REFERENCES tbl_pf_competencies (id),
+    title                 VARCHAR(255) NOT NULL,
+    description           TEXT NOT NULL DEFAULT '',
+    competency_level      VARCHAR(50) NOT NULL,
+    behavioral_indicators TEXT NOT NULL DEFAULT '',
+    weight                NUMERIC(5, 2) NOT NULL DEFAULT 0.00,
+    is_active             SMALLINT NOT NULL DEFAULT 1,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A manager's rating of one competency on an appraisal, from 1 to 5
+CREATE TABLE IF NOT EXISTS tbl_pf_competency_ratings (
+    id            SERIAL PRIMARY KEY,
+    appraisal_id  INTEGER NOT NULL REFERENCES tbl_pf_appraisals (id),
+    competency_id INTEGER NOT NULL REFERENCES tbl_pf_competencies (id),
+    rating        SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comments      TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pf_competency_ratings_appraisal ON tbl_pf_competency_ratings (appraisal_id, competency_id);
+
+-- Rating bands: a score maps to the band with the highest min_score it reaches
+CREATE TABLE IF NOT EXISTS tbl_pf_rating_scales (
+    id          SERIAL PRIMARY KEY,
+    min_score   NUMERIC(5, 2) NOT NULL UNIQUE,
+    rating      VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO tbl_pf_rating_scales (min_score, rating, description)
+SELECT band.min_score, band.rating, band.description
+FROM (VALUES
+    (0.00, 'Unsatisfactory', 'Well below expectations'),
+    (40.00, 'Needs Improvement', 'Meets some expectations'),
+    (60.00, 'Meets Expectations', 'Consistently meets expectations'),
+    (80.00, 'Exceeds Expectations', 'Frequently exceeds expectations'),
+    (95.00, 'Outstanding', 'Consistently far exceeds expectations')
+) AS band (min_score, rating, description)
+WHERE NOT EXISTS (SELECT 1 FROM tbl_pf_rating_scales);
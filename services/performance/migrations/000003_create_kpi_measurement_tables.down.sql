@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pf_goals_kpi;
+DROP TABLE IF EXISTS tbl_pf_kpi_measurements;
+ALTER TABLE tbl_pf_kpis DROP COLUMN IF EXISTS aggregation;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS tbl_pf_kpis (
+    id                         SERIAL PRIMARY KEY,
+    department_id              INTEGER,
+    title                      VARCHAR(255) NOT NULL,
+    description                TEXT NOT NULL DEFAULT '',
+    unit                       VARCHAR(50) NOT NULL,
+    data_type                  VARCHAR(20) NOT NULL DEFAULT 'numeric',
+    target_calculation_formula TEXT NOT NULL DEFAULT '',
+    is_active                  SMALLINT NOT NULL DEFAULT 1,
+    created_by                 INTEGER,
+    created_at                 TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at                 TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE tbl_pf_kpis ADD COLUMN IF NOT EXISTS aggregation VARCHAR(20) NOT NULL DEFAULT 'latest';
+
+CREATE TABLE IF NOT EXISTS tbl_pf_kpi_measurements (
+    id          SERIAL PRIMARY KEY,
+    goal_id     INTEGER NOT NULL REFERENCES tbl_pf_goals (id) ON DELETE CASCADE,
+    measured_on DATE NOT NULL,
+    value       NUMERIC(15, 2) NOT NULL,
+    notes       TEXT NOT NULL DEFAULT '',
+    recorded_by INTEGER,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pf_kpi_measurements_goal ON tbl_pf_kpi_measurements (goal_id, measured_on);
+CREATE INDEX IF NOT EXISTS idx_pf_goals_kpi ON tbl_pf_goals (kpi_id);
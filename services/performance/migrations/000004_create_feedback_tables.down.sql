@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS tbl_pf_feedback_responses;
+DROP TABLE IF EXISTS tbl_pf_feedback_nominations;
+ALTER TABLE tbl_pf_cycles DROP COLUMN IF EXISTS feedback_min_respondents;
+ALTER TABLE tbl_pf_cycles DROP COLUMN IF EXISTS feedback_weight;
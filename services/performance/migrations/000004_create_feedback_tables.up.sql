@@ -0,0 +1,37 @@
+ALTER TABLE tbl_pf_cycles ADD COLUMN IF NOT EXISTS feedback_weight NUMERIC(5, 2) NOT NULL DEFAULT 0.00;
+ALTER TABLE tbl_pf_cycles ADD COLUMN IF NOT EXISTS feedback_min_respondents SMALLINT NOT NULL DEFAULT 3;
+
+-- A reviewer asked for 360 feedback on an appraisal. Only the hash of the
+-- token sent to the reviewer is kept.
+CREATE TABLE IF NOT EXISTS tbl_pf_feedback_nominations (
+    id                   SERIAL PRIMARY KEY,
+    appraisal_id         INTEGER NOT NULL REFERENCES tbl_pf_appraisals (id),
+    reviewer_type        VARCHAR(20) NOT NULL,
+    reviewer_employee_id INTEGER,
+    reviewer_name        VARCHAR(255) NOT NULL DEFAULT '',
+    reviewer_email       VARCHAR(255) NOT NULL DEFAULT '',
+    token_hash           CHAR(64) NOT NULL UNIQUE,
+    status               VARCHAR(20) NOT NULL DEFAULT 'pending',
+    nominated_by         INTEGER,
+    responded_at         TIMESTAMPTZ,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pf_feedback_nominations_appraisal ON tbl_pf_feedback_nominations (appraisal_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pf_feedback_nominations_employee
+    ON tbl_pf_feedback_nominations (appraisal_id, reviewer_employee_id) WHERE reviewer_employee_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pf_feedback_nominations_email
+    ON tbl_pf_feedback_nominations (appraisal_id, LOWER(reviewer_email)) WHERE reviewer_employee_id IS NULL;
+
+-- A reviewer's rating of one competency, from 1 to 5
+CREATE TABLE IF NOT EXISTS tbl_pf_feedback_responses (
+    id            SERIAL PRIMARY KEY,
+    nomination_id INTEGER NOT NULL REFERENCES tbl_pf_feedback_nominations (id) ON DELETE CASCADE,
+    competency_id INTEGER NOT NULL REFERENCES tbl_pf_competencies (id),
+    rating        SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comments      TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pf_feedback_responses_nomination ON tbl_pf_feedback_responses (nomination_id, competency_id);
@@ -0,0 +1,4 @@
+ALTER TABLE tbl_pf_appraisals DROP COLUMN IF EXISTS calibration_session_id;
+DROP TABLE IF EXISTS tbl_pf_calibration_adjustments;
+DROP TABLE IF EXISTS tbl_pf_calibration_limits;
+DROP TABLE IF EXISTS tbl_pf_calibration_sessions;
@@ -0,0 +1,45 @@
+-- HR's review of the ratings given in a cycle, for one department or, with
+-- no department, the whole organisation
+CREATE TABLE IF NOT EXISTS tbl_pf_calibration_sessions (
+    id            SERIAL PRIMARY KEY,
+    cycle_id      INTEGER NOT NULL REFERENCES tbl_pf_cycles (id),
+    department_id INTEGER,
+    name          VARCHAR(255) NOT NULL,
+    notes         TEXT NOT NULL DEFAULT '',
+    status        VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_by    INTEGER,
+    finalized_at  TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pf_calibration_sessions_cycle ON tbl_pf_calibration_sessions (cycle_id, department_id);
+
+-- Forced-distribution limits: the largest share of rated appraisals a rating
+-- may be given
+CREATE TABLE IF NOT EXISTS tbl_pf_calibration_limits (
+    id          SERIAL PRIMARY KEY,
+    session_id  INTEGER NOT NULL REFERENCES tbl_pf_calibration_sessions (id) ON DELETE CASCADE,
+    rating      VARCHAR(50) NOT NULL,
+    max_percent NUMERIC(5, 2) NOT NULL CHECK (max_percent BETWEEN 0 AND 100)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pf_calibration_limits_session ON tbl_pf_calibration_limits (session_id, rating);
+
+CREATE TABLE IF NOT EXISTS tbl_pf_calibration_adjustments (
+    id              SERIAL PRIMARY KEY,
+    session_id      INTEGER NOT NULL REFERENCES tbl_pf_calibration_sessions (id),
+    appraisal_id    INTEGER NOT NULL REFERENCES tbl_pf_appraisals (id),
+    previous_rating VARCHAR(50) NOT NULL,
+    new_rating      VARCHAR(50) NOT NULL,
+    justification   TEXT NOT NULL,
+    adjusted_by     INTEGER,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pf_calibration_adjustments_session ON tbl_pf_calibration_adjustments (session_id);
+CREATE INDEX IF NOT EXISTS idx_pf_calibration_adjustments_appraisal ON tbl_pf_calibration_adjustments (appraisal_id);
+
+-- the session that fixed an appraisal's rating; scoring leaves it alone from
+-- then on
+ALTER TABLE tbl_pf_appraisals ADD COLUMN IF NOT EXISTS calibration_session_id INTEGER REFERENCES tbl_pf_calibration_sessions (id);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS tbl_pf_pip_checkins;
+DROP INDEX IF EXISTS idx_pf_pips_appraisal;
+DROP INDEX IF EXISTS idx_pf_pips_employee;
+ALTER TABLE tbl_pf_pips DROP COLUMN IF EXISTS outcome_date;
+ALTER TABLE tbl_pf_pips DROP COLUMN IF EXISTS outcome_notes;
+ALTER TABLE tbl_pf_pips DROP COLUMN IF EXISTS reminder_sent_at;
+ALTER TABLE tbl_pf_pips DROP COLUMN IF EXISTS reminder_days;
+ALTER TABLE tbl_pf_pips DROP COLUMN IF EXISTS original_end_date;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS tbl_pf_pips (
+    id               SERIAL PRIMARY KEY,
+    employee_id      INTEGER NOT NULL,
+    appraisal_id     INTEGER REFERENCES tbl_pf_appraisals (id),
+    reason           TEXT NOT NULL,
+    objectives       TEXT NOT NULL,
+    success_criteria TEXT NOT NULL,
+    start_date       DATE NOT NULL,
+    end_date         DATE NOT NULL,
+    status           VARCHAR(20) NOT NULL DEFAULT 'active',
+    outcome          VARCHAR(20) NOT NULL DEFAULT '',
+    created_by       INTEGER,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE tbl_pf_pips ADD COLUMN IF NOT EXISTS original_end_date DATE;
+ALTER TABLE tbl_pf_pips ADD COLUMN IF NOT EXISTS reminder_days INTEGER NOT NULL DEFAULT 7;
+ALTER TABLE tbl_pf_pips ADD COLUMN IF NOT EXISTS reminder_sent_at TIMESTAMPTZ;
+ALTER TABLE tbl_pf_pips ADD COLUMN IF NOT EXISTS outcome_notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE tbl_pf_pips ADD COLUMN IF NOT EXISTS outcome_date DATE;
+
+CREATE INDEX IF NOT EXISTS idx_pf_pips_employee ON tbl_pf_pips (employee_id, status);
+CREATE INDEX IF NOT EXISTS idx_pf_pips_appraisal ON tbl_pf_pips (appraisal_id);
+
+-- Progress reviews on a plan; a check-in is held once held_date is recorded
+CREATE TABLE IF NOT EXISTS tbl_pf_pip_checkins (
+    id              SERIAL PRIMARY KEY,
+    pip_id          INTEGER NOT NULL REFERENCES tbl_pf_pips (id),
+    scheduled_date  DATE NOT NULL,
+    held_date       DATE,
+    notes           TEXT NOT NULL DEFAULT '',
+    progress_rating SMALLINT CHECK (progress_rating BETWEEN 1 AND 5),
+    recorded_by     INTEGER,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pf_pip_checkins_pip ON tbl_pf_pip_checkins (pip_id, scheduled_date);
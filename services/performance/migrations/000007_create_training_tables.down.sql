@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS tbl_pf_training_budgets;
+DROP TABLE IF EXISTS tbl_pf_training_enrolments;
+DROP INDEX IF EXISTS idx_pf_trainings_competency;
+ALTER TABLE tbl_pf_trainings DROP COLUMN IF EXISTS hours;
@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS tbl_pf_trainings (
+    id                      SERIAL PRIMARY KEY,
+    title                   VARCHAR(255) NOT NULL,
+    description             TEXT NOT NULL DEFAULT '',
+    training_type           VARCHAR(50) NOT NULL DEFAULT '',
+    assigned_to_employee_id INTEGER,
+    linked_goal_id          INTEGER REFERENCES tbl_pf_goals (id),
+    linked_competency_id    INTEGER REFERENCES tbl_pf_competencies (id),
+    status                  VARCHAR(20) NOT NULL DEFAULT 'active',
+    start_date              DATE,
+    end_date                DATE,
+    completed               SMALLINT NOT NULL DEFAULT 0,
+    completion_date         DATE,
+    cost                    NUMERIC(15, 2),
+    provider                VARCHAR(255) NOT NULL DEFAULT '',
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE tbl_pf_trainings ADD COLUMN IF NOT EXISTS hours NUMERIC(6, 2);
+
+CREATE INDEX IF NOT EXISTS idx_pf_trainings_competency ON tbl_pf_trainings (linked_competency_id);
+
+-- An employee's place on a training. Cost and department are kept as they
+-- were at enrolment so spend stays with the department that incurred it.
+CREATE TABLE IF NOT EXISTS tbl_pf_training_enrolments (
+    id            SERIAL PRIMARY KEY,
+    training_id   INTEGER NOT NULL REFERENCES tbl_pf_trainings (id),
+    employee_id   INTEGER NOT NULL,
+    department_id INTEGER,
+    status        VARCHAR(20) NOT NULL DEFAULT 'enrolled',
+    cost          NUMERIC(15, 2) NOT NULL DEFAULT 0.00,
+    hours         NUMERIC(6, 2) NOT NULL DEFAULT 0.00,
+    completed_at  DATE,
+    enrolled_by   INTEGER,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pf_training_enrolments_training ON tbl_pf_training_enrolments (training_id);
+CREATE INDEX IF NOT EXISTS idx_pf_training_enrolments_employee ON tbl_pf_training_enrolments (employee_id);
+
+CREATE TABLE IF NOT EXISTS tbl_pf_training_budgets (
+    id            SERIAL PRIMARY KEY,
+    department_id INTEGER NOT NULL,
+    year          INTEGER NOT NULL,
+    amount        NUMERIC(15, 2) NOT NULL CHECK (amount >= 0),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pf_training_budgets_department_year ON tbl_pf_training_budgets (department_id, year);
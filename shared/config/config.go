@@ -16,6 +16,17 @@ type Config struct {
 	LogLevel    string
 	JWTSecret   string
 	Timeout     time.Duration
+	SMTP        SMTPConfig
+}
+
+// SMTPConfig is the mail server the services send notifications through
+type SMTPConfig struct {
+	Addr     string // host:port
+	Username string // empty when the server needs no authentication
+	Password string
+	From     string
+	// PortalURL is the web app's base URL, for the links in mails
+	PortalURL string
 }
 
 type DatabaseConfig struct {
@@ -38,6 +49,13 @@ func Load() *Config {
 		LogLevel:    getEnv("LOG_LEVEL", "info"),
 		JWTSecret:   getEnv("JWT_SECRET", "your-super-secret-jwt-key"),
 		Timeout:     getTimeout(),
+		SMTP: SMTPConfig{
+			Addr:      getEnv("SMTP_ADDR", "localhost:25"),
+			Username:  getEnv("SMTP_USERNAME", ""),
+			Password:  getEnv("SMTP_PASSWORD", ""),
+			From:      getEnv("MAIL_FROM", "no-reply@yathuerp.local"),
+			PortalURL: getEnv("PORTAL_URL", "http://localhost:3000"),
+		},
 	}
 }
 
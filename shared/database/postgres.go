@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -48,12 +52,79 @@ func (db *Database) GetPool() *pgxpool.Pool {
 	return db.Pool
 }
 
-// RunMigrations executes database migrations for a specific service
+// RunMigrations executes database migrations for a specific service.
+// Migrations are read from the directory in MIGRATIONS_DIR (default
+// "migrations", relative to the service root) using the golang-migrate
+// naming convention "<version>_<name>.up.sql" and applied in version order.
+// Applied versions are tracked per service in schema_migrations.
 func RunMigrations(db *Database, serviceName string) error {
 	log.Printf("Running migrations for service: %s", serviceName)
 
-	// TODO: Implement migration logic
-	// This would typically use a migration tool like golang-migrate
+	dir := os.Getenv("MIGRATIONS_DIR")
+	if dir == "" {
+		dir = "migrations"
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+	if len(files) == 0 {
+		log.Printf("No migrations found for service: %s", serviceName)
+		return nil
+	}
+	sort.Strings(files)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	_, err = db.Pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			service    VARCHAR(100) NOT NULL,
+			version    VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (service, version)
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	for _, file := range files {
+		version := strings.TrimSuffix(filepath.Base(file), ".up.sql")
+
+		var applied bool
+		err := db.Pool.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE service = $1 AND version = $2)`,
+			serviceName, version,
+		).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", version, err)
+		}
+		if applied {
+			continue
+		}
+
+		script, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", version, err)
+		}
+
+		err = db.WithTransaction(ctx, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, string(script)); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx,
+				`INSERT INTO schema_migrations (service, version) VALUES ($1, $2)`,
+				serviceName, version,
+			)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", version, err)
+		}
+
+		log.Printf("Applied migration %s for service: %s", version, serviceName)
+	}
 
 	return nil
 }
@@ -89,3 +89,12 @@ func Close() {
 		Logger.Sync()
 	}
 }
+
+// ServiceLogger exposes the package-level helpers through the utils.Logger
+// interface so they can be injected into repositories, use cases and handlers
+type ServiceLogger struct{}
+
+func (ServiceLogger) Info(msg string, args ...interface{})  { Info(msg, args...) }
+func (ServiceLogger) Error(msg string, args ...interface{}) { Error(msg, args...) }
+func (ServiceLogger) Debug(msg string, args ...interface{}) { Debug(msg, args...) }
+func (ServiceLogger) Warn(msg string, args ...interface{})  { Warn(msg, args...) }
@@ -5,13 +5,22 @@ import (
 	"strings"
 	"time"
 
-	"yathuerp/shared/logger"
 	"yathuerp/shared/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Roles carried in the JWT roles claim. RoleService is given to the tokens
+// of scheduled jobs.
+const (
+	RoleAdmin   = "admin"
+	RoleHR      = "hr"
+	RoleFinance = "finance"
+	RoleManager = "manager"
+	RoleService = "service"
+)
+
 type Claims struct {
 	UserID string   `json:"user_id"`
 	Email  string   `json:"email"`
@@ -20,10 +29,10 @@ type Claims struct {
 }
 
 type AuthMiddleware struct {
-	logger logger.Logger
+	logger utils.Logger
 }
 
-func NewAuthMiddleware(logger logger.Logger) *AuthMiddleware {
+func NewAuthMiddleware(logger utils.Logger) *AuthMiddleware {
 	return &AuthMiddleware{
 		logger: logger,
 	}
@@ -99,6 +108,25 @@ func (am *AuthMiddleware) RoleBasedAuth(allowedRoles ...string) fiber.Handler {
 	}
 }
 
+// JobAuth lets through the batch jobs that act on every record, run either by
+// HR or by a scheduler with a RoleService token
+func (am *AuthMiddleware) JobAuth() fiber.Handler {
+	return am.RoleBasedAuth(RoleHR, RoleAdmin, RoleService)
+}
+
+// HasRole reports whether the authenticated user holds any of the roles
+func HasRole(c *fiber.Ctx, roles ...string) bool {
+	userRoles, _ := c.Locals("roles").([]string)
+	for _, role := range roles {
+		for _, userRole := range userRoles {
+			if userRole == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GenerateJWT creates a new JWT token
 func GenerateJWT(userID, email string, roles []string, secret string) (string, error) {
 	claims := &Claims{
@@ -89,6 +89,18 @@ func ValidateStruct(s interface{}) error {
 	return nil
 }
 
+// ParseBody parses and validates the request body. It returns a 400
+// *fiber.Error for the error handler to send, so handlers stop on failure.
+func ParseBody(c *fiber.Ctx, req interface{}) error {
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := ValidateStruct(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
 // formatValidationError formats a validation error message
 func formatValidationError(err validator.FieldError) string {
 	switch err.Tag() {